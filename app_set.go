@@ -0,0 +1,277 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SuspendedInstancesLabel is the label the instances of a suspended application are kept in, so it
+// can be resumed at the same scale
+const SuspendedInstancesLabel = "GO_MARATHON_SUSPENDED_INSTANCES"
+
+// AppSelector selects the applications of an AppSet
+type AppSelector struct {
+	// Prefix selects the applications whose id starts with it, e.g. a group id
+	Prefix string
+	// Labels selects the applications having all the labels, an empty value matching any value
+	Labels map[string]string
+}
+
+// Matches checks if the application is selected
+func (s AppSelector) Matches(application *Application) bool {
+	if s.Prefix != "" && !strings.HasPrefix(application.ID, validateID(s.Prefix)) {
+		return false
+	}
+	for name, value := range s.Labels {
+		if application.Labels == nil {
+			return false
+		}
+		found, exists := (*application.Labels)[name]
+		if !exists || (value != "" && found != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the applications the predicate selects, as a new collection
+//		predicate:	the function selecting the applications
+func (r *Applications) Filter(predicate func(*Application) bool) *Applications {
+	filtered := &Applications{Apps: []Application{}}
+	for i := range r.Apps {
+		if predicate(&r.Apps[i]) {
+			filtered.Apps = append(filtered.Apps, r.Apps[i])
+		}
+	}
+	return filtered
+}
+
+// FilterByLabel returns the applications having the label, as a new collection
+//		name:		the name of the label
+//		value:		the value of the label, an empty value matching any value
+func (r *Applications) FilterByLabel(name, value string) *Applications {
+	return r.Filter(AppSelector{Labels: map[string]string{name: value}}.Matches)
+}
+
+// FilterByIDPrefix returns the applications whose id starts with the prefix, e.g. a group id, as a
+// new collection
+//		prefix:		the prefix of the application ids
+func (r *Applications) FilterByIDPrefix(prefix string) *Applications {
+	return r.Filter(AppSelector{Prefix: prefix}.Matches)
+}
+
+// AppSetResult is the outcome of a bulk operation on an application of the set
+type AppSetResult struct {
+	// ID is the id of the application
+	ID string
+	// Deployment is the deployment started by the operation, nil when there was nothing to do
+	Deployment *DeploymentID
+	// Err is the error of the operation
+	Err error
+}
+
+// AppSetError is the error of a bulk operation which failed on some applications of the set
+type AppSetError struct {
+	// Total is the number of applications operated on
+	Total int
+	// Failed are the results of the applications the operation failed on
+	Failed []AppSetResult
+}
+
+// Error returns the string message
+func (e *AppSetError) Error() string {
+	var failures []string
+	for _, result := range e.Failed {
+		failures = append(failures, fmt.Sprintf("%s: %s", result.ID, result.Err))
+	}
+	return fmt.Sprintf("%d of %d applications failed: %s", len(e.Failed), e.Total, strings.Join(failures, "; "))
+}
+
+// Unwrap returns the errors of the failed applications, for errors.Is and errors.As
+func (e *AppSetError) Unwrap() []error {
+	var errs []error
+	for _, result := range e.Failed {
+		errs = append(errs, result.Err)
+	}
+	return errs
+}
+
+// AppSet is a collection of related applications, selected by id prefix or labels, operated on in
+// bulk, e.g. to scale down all the services of a team. The applications are selected at each
+// operation, so the set follows the applications created and deleted.
+type AppSet struct {
+	client   Marathon
+	selector AppSelector
+}
+
+// NewAppSet creates the set of the applications the selector matches
+//		client:		the client the applications are operated on with
+//		selector:	the selector of the applications
+func NewAppSet(client Marathon, selector AppSelector) *AppSet {
+	return &AppSet{client: client, selector: selector}
+}
+
+// Applications retrieves the applications of the set
+func (s *AppSet) Applications() ([]Application, error) {
+	applications, err := s.client.Applications(nil)
+	if err != nil {
+		return nil, err
+	}
+	return applications.Filter(s.selector.Matches).Apps, nil
+}
+
+// IDs retrieves the ids of the applications of the set
+func (s *AppSet) IDs() ([]string, error) {
+	applications, err := s.Applications()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, application := range applications {
+		ids = append(ids, application.ID)
+	}
+	return ids, nil
+}
+
+// Scale changes the number of instances of all the applications of the set
+//		instances:	the number of instances of each application
+//		force:		used to force the operation in case of blocked deployment
+func (s *AppSet) Scale(instances int, force bool) ([]AppSetResult, error) {
+	return s.each(func(application *Application) (*DeploymentID, error) {
+		return s.client.ScaleApplicationInstances(application.ID, instances, force)
+	})
+}
+
+// Restart performs a rolling restart of all the applications of the set
+//		force:		used to force the operation in case of blocked deployment
+func (s *AppSet) Restart(force bool) ([]AppSetResult, error) {
+	return s.each(func(application *Application) (*DeploymentID, error) {
+		return s.client.RestartApplication(application.ID, force)
+	})
+}
+
+// Suspend scales all the applications of the set down to zero instances, keeping their number of
+// instances in the SuspendedInstancesLabel label for Resume. The suspended applications are skipped.
+//		force:		used to force the operation in case of blocked deployment
+func (s *AppSet) Suspend(force bool) ([]AppSetResult, error) {
+	return s.each(func(application *Application) (*DeploymentID, error) {
+		if application.Labels != nil {
+			if _, found := (*application.Labels)[SuspendedInstancesLabel]; found {
+				return nil, nil
+			}
+		}
+		instances := 0
+		if application.Instances != nil {
+			instances = *application.Instances
+		}
+		changes := s.changes(application, 0)
+		(*changes.Labels)[SuspendedInstancesLabel] = strconv.Itoa(instances)
+		return s.client.UpdateApplication(changes, force)
+	})
+}
+
+// Resume scales all the suspended applications of the set back up to their number of instances
+// before Suspend. The applications which aren't suspended are skipped.
+//		force:		used to force the operation in case of blocked deployment
+func (s *AppSet) Resume(force bool) ([]AppSetResult, error) {
+	return s.each(func(application *Application) (*DeploymentID, error) {
+		if application.Labels == nil {
+			return nil, nil
+		}
+		value, found := (*application.Labels)[SuspendedInstancesLabel]
+		if !found {
+			return nil, nil
+		}
+		instances, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s label: %q", SuspendedInstancesLabel, value)
+		}
+		changes := s.changes(application, instances)
+		delete(*changes.Labels, SuspendedInstancesLabel)
+		return s.client.UpdateApplication(changes, force)
+	})
+}
+
+// Wait waits for all the applications of the set to be deployed, concurrently
+//		timeout:	the time to wait for each application
+func (s *AppSet) Wait(timeout time.Duration) ([]AppSetResult, error) {
+	applications, err := s.Applications()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]AppSetResult, len(applications))
+	var wg sync.WaitGroup
+	for i := range applications {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := applications[i].ID
+			results[i] = AppSetResult{ID: id, Err: s.client.WaitOnApplication(id, timeout)}
+		}(i)
+	}
+	wg.Wait()
+	return results, aggregateResults(results)
+}
+
+// changes returns the partial update of the instances of the application, carrying a copy of its
+// labels since the labels are replaced as a whole
+func (s *AppSet) changes(application *Application, instances int) *Application {
+	changes := new(Application)
+	changes.ID = application.ID
+	changes.Instances = &instances
+	changes.EmptyLabels()
+	if application.Labels != nil {
+		for name, value := range *application.Labels {
+			(*changes.Labels)[name] = value
+		}
+	}
+	return changes
+}
+
+// each applies the operation to the applications of the set, returning the result of each
+// application, and an AppSetError when the operation failed on some of them
+func (s *AppSet) each(operation func(*Application) (*DeploymentID, error)) ([]AppSetResult, error) {
+	applications, err := s.Applications()
+	if err != nil {
+		return nil, err
+	}
+	var results []AppSetResult
+	for i := range applications {
+		deployment, err := operation(&applications[i])
+		results = append(results, AppSetResult{ID: applications[i].ID, Deployment: deployment, Err: err})
+	}
+	return results, aggregateResults(results)
+}
+
+// aggregateResults returns an AppSetError with the failed results, if any
+func aggregateResults(results []AppSetResult) error {
+	var failed []AppSetResult
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &AppSetError{Total: len(results), Failed: failed}
+}
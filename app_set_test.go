@@ -0,0 +1,201 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fleetMarathon holds applications and applies the updates of their instances and labels
+type fleetMarathon struct {
+	Marathon
+	sync.Mutex
+	apps    []Application
+	failing map[string]error
+	calls   []string
+}
+
+func (f *fleetMarathon) Applications(v url.Values) (*Applications, error) {
+	return &Applications{Apps: f.apps}, nil
+}
+
+func (f *fleetMarathon) find(name string) *Application {
+	for i := range f.apps {
+		if f.apps[i].ID == name {
+			return &f.apps[i]
+		}
+	}
+	return nil
+}
+
+func (f *fleetMarathon) record(call, name string) error {
+	f.Lock()
+	defer f.Unlock()
+	f.calls = append(f.calls, call+" "+name)
+	return f.failing[name]
+}
+
+func (f *fleetMarathon) ScaleApplicationInstances(name string, instances int, force bool) (*DeploymentID, error) {
+	if err := f.record("scale", name); err != nil {
+		return nil, err
+	}
+	f.find(name).Instances = &instances
+	return &DeploymentID{DeploymentID: "scale" + name}, nil
+}
+
+func (f *fleetMarathon) RestartApplication(name string, force bool) (*DeploymentID, error) {
+	if err := f.record("restart", name); err != nil {
+		return nil, err
+	}
+	return &DeploymentID{DeploymentID: "restart" + name}, nil
+}
+
+func (f *fleetMarathon) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	if err := f.record("update", application.ID); err != nil {
+		return nil, err
+	}
+	current := f.find(application.ID)
+	current.Instances = application.Instances
+	current.Labels = application.Labels
+	return &DeploymentID{DeploymentID: "update" + application.ID}, nil
+}
+
+func (f *fleetMarathon) WaitOnApplication(name string, timeout time.Duration) error {
+	return f.record("wait", name)
+}
+
+func newFleet() *fleetMarathon {
+	return &fleetMarathon{apps: []Application{
+		*new(Application).Name("/team/web").Count(3).AddLabel("tier", "frontend"),
+		*new(Application).Name("/team/api").Count(2).AddLabel("tier", "backend"),
+		*new(Application).Name("/team-other/web").Count(1).AddLabel("tier", "frontend"),
+		*new(Application).Name("/other").Count(1),
+	}}
+}
+
+func TestAppSelector(t *testing.T) {
+	application := new(Application).Name("/team/web").AddLabel("tier", "frontend")
+
+	assert.True(t, AppSelector{}.Matches(application))
+	assert.True(t, AppSelector{Prefix: "/team/"}.Matches(application))
+	assert.True(t, AppSelector{Prefix: "team"}.Matches(application))
+	assert.False(t, AppSelector{Prefix: "/other"}.Matches(application))
+	assert.True(t, AppSelector{Labels: map[string]string{"tier": "frontend"}}.Matches(application))
+	assert.True(t, AppSelector{Labels: map[string]string{"tier": ""}}.Matches(application))
+	assert.False(t, AppSelector{Labels: map[string]string{"tier": "backend"}}.Matches(application))
+	assert.False(t, AppSelector{Labels: map[string]string{"tier": ""}}.Matches(new(Application).Name("/unlabelled")))
+}
+
+func TestApplicationsFilter(t *testing.T) {
+	applications := &Applications{Apps: newFleet().apps}
+	ids := func(filtered *Applications) []string {
+		list := []string{}
+		for _, application := range filtered.Apps {
+			list = append(list, application.ID)
+		}
+		return list
+	}
+
+	assert.Equal(t, []string{"/team/web", "/team-other/web"}, ids(applications.FilterByLabel("tier", "frontend")))
+	assert.Equal(t, []string{"/team/web", "/team/api", "/team-other/web"}, ids(applications.FilterByLabel("tier", "")))
+	assert.Equal(t, []string{}, ids(applications.FilterByLabel("owner", "")))
+	assert.Equal(t, []string{"/team/web", "/team/api"}, ids(applications.FilterByIDPrefix("/team/")))
+	assert.Equal(t, []string{"/other"}, ids(applications.Filter(func(application *Application) bool {
+		return *application.Instances == 1 && application.Labels == nil
+	})))
+	assert.Equal(t, []string{"/team/api"}, ids(applications.FilterByIDPrefix("team").FilterByLabel("tier", "backend")))
+	assert.Len(t, applications.Apps, 4)
+}
+
+func TestAppSet(t *testing.T) {
+	fleet := newFleet()
+	set := NewAppSet(fleet, AppSelector{Prefix: "/team/"})
+
+	ids, err := set.IDs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/team/web", "/team/api"}, ids)
+
+	results, err := set.Scale(4, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, AppSetResult{ID: "/team/web", Deployment: &DeploymentID{DeploymentID: "scale/team/web"}}, results[0])
+	assert.Equal(t, 4, *fleet.find("/team/api").Instances)
+	assert.Equal(t, 1, *fleet.find("/other").Instances)
+
+	_, err = set.Restart(false)
+	require.NoError(t, err)
+
+	frontend := NewAppSet(fleet, AppSelector{Labels: map[string]string{"tier": "frontend"}})
+	results, err = frontend.Wait(time.Second)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	assert.Equal(t, []string{
+		"scale /team/web", "scale /team/api",
+		"restart /team/web", "restart /team/api",
+	}, fleet.calls[:4])
+}
+
+func TestAppSetSuspendResume(t *testing.T) {
+	fleet := newFleet()
+	set := NewAppSet(fleet, AppSelector{Prefix: "/team/"})
+
+	_, err := set.Suspend(false)
+	require.NoError(t, err)
+	web := fleet.find("/team/web")
+	assert.Equal(t, 0, *web.Instances)
+	assert.Equal(t, map[string]string{"tier": "frontend", SuspendedInstancesLabel: "3"}, *web.Labels)
+
+	// step: the suspended applications are skipped
+	results, err := set.Suspend(false)
+	require.NoError(t, err)
+	assert.Nil(t, results[0].Deployment)
+	assert.Equal(t, "3", (*web.Labels)[SuspendedInstancesLabel])
+
+	_, err = set.Resume(false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, *web.Instances)
+	assert.Equal(t, 2, *fleet.find("/team/api").Instances)
+	assert.Equal(t, map[string]string{"tier": "frontend"}, *web.Labels)
+
+	// step: the applications which aren't suspended are skipped
+	results, err = set.Resume(false)
+	require.NoError(t, err)
+	assert.Nil(t, results[1].Deployment)
+}
+
+func TestAppSetErrors(t *testing.T) {
+	fleet := newFleet()
+	fleet.failing = map[string]error{"/team/api": errors.New("deployment locked")}
+	set := NewAppSet(fleet, AppSelector{Prefix: "/team"})
+
+	results, err := set.Scale(0, false)
+	require.Error(t, err)
+	assert.Len(t, results, 3)
+	require.IsType(t, &AppSetError{}, err)
+	assert.Equal(t, "1 of 3 applications failed: /team/api: deployment locked", err.Error())
+	assert.True(t, errors.Is(err, fleet.failing["/team/api"]))
+	// step: the failure doesn't stop the operation on the other applications
+	assert.Equal(t, 0, *fleet.find("/team-other/web").Instances)
+}
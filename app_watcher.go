@@ -0,0 +1,268 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// the default interval the application is polled at
+const defaultAppWatchInterval = 30 * time.Second
+
+// the events which may change the application
+const appWatchEvents = EventIDAPIRequest | EventIDStatusUpdate | EventIDChangedHealthCheck | EventIDAppTerminated |
+	EventIDDeploymentSuccess | EventIDDeploymentFailed
+
+// AppChangeType is the kind of change of a watched application
+type AppChangeType string
+
+const (
+	// AppChangeCreated is the creation of the application, or its first retrieval
+	AppChangeCreated AppChangeType = "created"
+	// AppChangeDeleted is the deletion of the application
+	AppChangeDeleted AppChangeType = "deleted"
+	// AppChangeScaled is a change of the number of instances of the application
+	AppChangeScaled AppChangeType = "scaled"
+	// AppChangeVersion is a change of the configuration of the application, i.e. a new version
+	AppChangeVersion AppChangeType = "version"
+	// AppChangeTasks is a change of the number of staged, running, healthy or unhealthy tasks
+	AppChangeTasks AppChangeType = "tasks"
+)
+
+// AppChange is a change of a watched application
+type AppChange struct {
+	// Type is the kind of change
+	Type AppChangeType
+	// AppID is the id of the application
+	AppID string
+	// Previous is the application before the change, nil when created
+	Previous *Application
+	// Current is the application after the change, nil when deleted
+	Current *Application
+}
+
+// AppWatcherOpts contains the options of the AppWatcher
+//		interval:	the interval the application is polled at, between the events, defaults to 30 seconds
+//		pollOnly:	polls the application without subscribing the client to the events
+//		onError:	called with the errors retrieving the application
+type AppWatcherOpts struct {
+	Interval time.Duration
+	PollOnly bool
+	OnError  func(err error)
+}
+
+// AppWatcher watches an application, sending its changes on the channel returned by Changes. The
+// application is retrieved on the events concerning it, subscribing the client to the events, and
+// polled at the interval in any case, so the changes are seen even if events are lost.
+type AppWatcher struct {
+	// the client the application is retrieved with
+	client Marathon
+	appID  string
+	opts   AppWatcherOpts
+	// the events of the application, nil when polling only
+	events  EventsChannel
+	changes chan AppChange
+	// the last application retrieved, nil when it doesn't exist
+	current  *Application
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// WatchApplication retrieves the application and starts watching its changes. The first change
+// is AppChangeCreated when the application exists.
+//		client:		the client the application is retrieved with
+//		appID:		the id of the application
+//		opts:		the options of the watcher, nil for the defaults
+func WatchApplication(client Marathon, appID string, opts *AppWatcherOpts) (*AppWatcher, error) {
+	watcher := &AppWatcher{
+		client:  client,
+		appID:   validateID(appID),
+		changes: make(chan AppChange, 16),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if opts != nil {
+		watcher.opts = *opts
+	}
+	if watcher.opts.Interval <= 0 {
+		watcher.opts.Interval = defaultAppWatchInterval
+	}
+
+	// step: the first retrieval fails the watch, e.g. on a wrong configuration
+	if _, err := watcher.retrieve(); err != nil {
+		return nil, err
+	}
+	// step: fall back to polling when the client can't subscribe to the events
+	if !watcher.opts.PollOnly {
+		if events, err := client.AddEventsListener(appWatchEvents); err == nil {
+			watcher.events = events
+		}
+	}
+	go watcher.watch()
+
+	return watcher, nil
+}
+
+// Changes returns the channel of the changes of the application, closed once the watcher is stopped
+func (w *AppWatcher) Changes() <-chan AppChange {
+	return w.changes
+}
+
+// Stop stops watching the application
+func (w *AppWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// watch checks the application on the events and at each interval until stopped
+func (w *AppWatcher) watch() {
+	defer close(w.done)
+	defer close(w.changes)
+	if w.events != nil {
+		defer w.client.RemoveEventsListener(w.events)
+	}
+
+	// step: the application was retrieved on start
+	if w.current != nil && !w.send(AppChange{Type: AppChangeCreated, AppID: w.appID, Current: w.current}) {
+		return
+	}
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case event := <-w.events:
+			if !appWatchEventConcerns(event, w.appID) {
+				continue
+			}
+		}
+		if !w.check() {
+			return
+		}
+	}
+}
+
+// check retrieves the application and sends its changes, returning false once stopped
+func (w *AppWatcher) check() bool {
+	previous := w.current
+	current, err := w.retrieve()
+	if err != nil {
+		if w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		return true
+	}
+	for _, change := range appChanges(w.appID, previous, current) {
+		if !w.send(change) {
+			return false
+		}
+	}
+	return true
+}
+
+// retrieve retrieves the application, nil when it doesn't exist
+func (w *AppWatcher) retrieve() (*Application, error) {
+	application, err := w.client.Application(w.appID)
+	if errors.Is(err, ErrNotFound) {
+		application, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	w.current = application
+	return application, nil
+}
+
+// send sends the change, returning false if the watcher was stopped meanwhile
+func (w *AppWatcher) send(change AppChange) bool {
+	select {
+	case w.changes <- change:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+// appChanges returns the changes between two retrievals of the application
+func appChanges(appID string, previous, current *Application) []AppChange {
+	change := func(kind AppChangeType) AppChange {
+		return AppChange{Type: kind, AppID: appID, Previous: previous, Current: current}
+	}
+	switch {
+	case previous == nil && current == nil:
+		return nil
+	case previous == nil:
+		return []AppChange{change(AppChangeCreated)}
+	case current == nil:
+		return []AppChange{change(AppChangeDeleted)}
+	}
+
+	var changes []AppChange
+	if instances(previous) != instances(current) {
+		changes = append(changes, change(AppChangeScaled))
+	}
+	if configVersion(previous) != configVersion(current) {
+		changes = append(changes, change(AppChangeVersion))
+	}
+	if previous.TasksStaged != current.TasksStaged || previous.TasksRunning != current.TasksRunning ||
+		previous.TasksHealthy != current.TasksHealthy || previous.TasksUnhealthy != current.TasksUnhealthy {
+		changes = append(changes, change(AppChangeTasks))
+	}
+	return changes
+}
+
+// instances returns the number of instances of the application
+func instances(application *Application) int {
+	if application.Instances == nil {
+		return 1
+	}
+	return *application.Instances
+}
+
+// configVersion returns the version of the configuration of the application, which unlike its
+// version doesn't change when scaling
+func configVersion(application *Application) string {
+	if application.VersionInfo != nil && application.VersionInfo.LastConfigChangeAt != "" {
+		return application.VersionInfo.LastConfigChangeAt
+	}
+	return application.Version
+}
+
+// appWatchEventConcerns checks if the event may change the application. Any deployment event does,
+// as it may finish the deployment of the application
+func appWatchEventConcerns(event *Event, appID string) bool {
+	switch e := event.Event.(type) {
+	case *EventAPIRequest:
+		return e.AppDefinition != nil && e.AppDefinition.ID == appID
+	case *EventStatusUpdate:
+		return e.AppID == appID
+	case *EventHealthCheckChanged:
+		return e.AppID == appID
+	case *EventAppTerminated:
+		return e.AppID == appID
+	case *EventDeploymentSuccess, *EventDeploymentFailed:
+		return true
+	}
+	return false
+}
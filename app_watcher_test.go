@@ -0,0 +1,144 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// watchedClient serves the application, nil when it doesn't exist, and the events of the test
+type watchedClient struct {
+	Marathon
+	sync.Mutex
+	application *Application
+	events      EventsChannel
+	removed     bool
+}
+
+func (c *watchedClient) set(application *Application) {
+	c.Lock()
+	defer c.Unlock()
+	c.application = application
+}
+
+func (c *watchedClient) Application(name string) (*Application, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.application == nil {
+		return nil, &APIError{ErrCode: ErrCodeNotFound, message: "App '/web' does not exist"}
+	}
+	application := *c.application
+	return &application, nil
+}
+
+func (c *watchedClient) AddEventsListener(filter int) (EventsChannel, error) {
+	if c.events == nil {
+		return nil, ErrMarathonDown
+	}
+	return c.events, nil
+}
+
+func (c *watchedClient) RemoveEventsListener(channel EventsChannel) {
+	c.Lock()
+	defer c.Unlock()
+	c.removed = true
+}
+
+func nextAppChange(t *testing.T, watcher *AppWatcher) AppChange {
+	select {
+	case change := <-watcher.Changes():
+		return change
+	case <-time.After(time.Second):
+		require.FailNow(t, "no change of the application")
+	}
+	return AppChange{}
+}
+
+func TestAppChanges(t *testing.T) {
+	application := new(Application).Name("/web").Count(2)
+	application.VersionInfo = &VersionInfo{LastConfigChangeAt: "2018-01-01T00:00:00.000Z"}
+	scaled := *application
+	scaled.Count(3)
+	reconfigured := *application
+	reconfigured.VersionInfo = &VersionInfo{LastConfigChangeAt: "2018-01-02T00:00:00.000Z"}
+	running := *application
+	running.TasksRunning = 2
+
+	kinds := func(changes []AppChange) []AppChangeType {
+		var list []AppChangeType
+		for _, change := range changes {
+			list = append(list, change.Type)
+		}
+		return list
+	}
+	assert.Nil(t, kinds(appChanges("/web", nil, nil)))
+	assert.Equal(t, []AppChangeType{AppChangeCreated}, kinds(appChanges("/web", nil, application)))
+	assert.Equal(t, []AppChangeType{AppChangeDeleted}, kinds(appChanges("/web", application, nil)))
+	assert.Nil(t, kinds(appChanges("/web", application, application)))
+	assert.Equal(t, []AppChangeType{AppChangeScaled}, kinds(appChanges("/web", application, &scaled)))
+	assert.Equal(t, []AppChangeType{AppChangeVersion}, kinds(appChanges("/web", application, &reconfigured)))
+	assert.Equal(t, []AppChangeType{AppChangeTasks}, kinds(appChanges("/web", application, &running)))
+}
+
+func TestAppWatcherEvents(t *testing.T) {
+	client := &watchedClient{application: new(Application).Name("/web").Count(1), events: make(EventsChannel)}
+	watcher, err := WatchApplication(client, "web", &AppWatcherOpts{Interval: time.Hour})
+	require.NoError(t, err)
+
+	change := nextAppChange(t, watcher)
+	assert.Equal(t, AppChangeCreated, change.Type)
+	assert.Equal(t, "/web", change.AppID)
+
+	// step: the events of other applications are ignored
+	client.set(new(Application).Name("/web").Count(2))
+	client.events <- &Event{ID: EventIDStatusUpdate, Event: &EventStatusUpdate{AppID: "/api"}}
+	select {
+	case change := <-watcher.Changes():
+		assert.Fail(t, "unexpected change", change)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	client.events <- &Event{ID: EventIDStatusUpdate, Event: &EventStatusUpdate{AppID: "/web"}}
+	change = nextAppChange(t, watcher)
+	assert.Equal(t, AppChangeScaled, change.Type)
+	assert.Equal(t, 1, *change.Previous.Instances)
+	assert.Equal(t, 2, *change.Current.Instances)
+
+	watcher.Stop()
+	_, open := <-watcher.Changes()
+	assert.False(t, open)
+	assert.True(t, client.removed)
+}
+
+func TestAppWatcherPolling(t *testing.T) {
+	client := &watchedClient{}
+	watcher, err := WatchApplication(client, "/web", &AppWatcherOpts{Interval: 5 * time.Millisecond})
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	client.set(new(Application).Name("/web"))
+	assert.Equal(t, AppChangeCreated, nextAppChange(t, watcher).Type)
+	client.set(nil)
+	change := nextAppChange(t, watcher)
+	assert.Equal(t, AppChangeDeleted, change.Type)
+	assert.Nil(t, change.Current)
+}
@@ -24,6 +24,13 @@ import (
 	"time"
 )
 
+const (
+	// the number of times ScaleBy attempts the scaling rejected by a conflicting deployment
+	scaleByAttempts = 5
+	// the number of times an update waiting on the conflicting deployments is attempted
+	conflictAttempts = 3
+)
+
 var (
 	// ErrNoApplicationContainer is thrown when a container has been specified yet
 	ErrNoApplicationContainer = errors.New("you have not specified a docker container yet")
@@ -61,7 +68,7 @@ type Application struct {
 	Args        *[]string   `json:"args,omitempty"`
 	Constraints *[][]string `json:"constraints,omitempty"`
 	Container   *Container  `json:"container,omitempty"`
-	CPUs        float64     `json:"cpus,omitempty"`
+	CPUs        *float64    `json:"cpus,omitempty"`
 	GPUs        *float64    `json:"gpus,omitempty"`
 	Disk        *float64    `json:"disk,omitempty"`
 	// Contains non-secret environment variables. Secrets environment variables are part of the Secrets map.
@@ -100,7 +107,11 @@ type Application struct {
 	LastTaskFailure       *LastTaskFailure        `json:"lastTaskFailure,omitempty"`
 	Fetch                 *[]Fetch                `json:"fetch,omitempty"`
 	IPAddressPerTask      *IPAddressPerTask       `json:"ipAddress,omitempty"`
+	Networks              *[]PodNetwork           `json:"networks,omitempty"`
 	Residency             *Residency              `json:"residency,omitempty"`
+	TTY                   *bool                   `json:"tty,omitempty"`
+	ExecutorResources     *ExecutorResources      `json:"executorResources,omitempty"`
+	Role                  *string                 `json:"role,omitempty"`
 	Secrets               *map[string]Secret      `json:"-"`
 }
 
@@ -135,10 +146,52 @@ type GetAppOpts struct {
 	Embed []string `url:"embed,omitempty"`
 }
 
-// DeleteAppOpts contains a payload for DeleteApplication method
-//		force:		overrides a currently running deployment.
+// DeleteAppOpts contains a payload for DeleteApplicationBy method
+//		force:				overrides a currently running deployment.
+//		forceOnConflict:	called with the ErrConflict error of a deletion without force, the
+//							deletion being retried with force when it returns true
+//		ignoreNotFound:		succeeds with no deployment when the application doesn't exist, e.g.
+//							when retrying a partially failed teardown
 type DeleteAppOpts struct {
-	Force bool `url:"force,omitempty"`
+	Force           bool             `url:"force,omitempty"`
+	ForceOnConflict func(error) bool `url:"-"`
+	IgnoreNotFound  bool             `url:"-"`
+}
+
+// ScaleAppOpts contains a payload for ScaleApplication and ScaleBy methods
+//		force:		overrides a currently running deployment.
+//		track:		tracks the launch of the instances when set
+//		wait:		blocks until the deployment of the scaling finishes
+//		timeout:	the time to wait for the deployment, Config.DeploymentTimeout when zero
+type ScaleAppOpts struct {
+	Force   bool
+	Track   *LaunchTrackerOpts
+	Wait    bool
+	Timeout time.Duration
+}
+
+// UpdateAppOpts contains a payload for UpdateApplicationBy and SetApplicationVersionBy methods
+//		force:				overrides a currently running deployment.
+//		forceOnConflict:	called with the ErrConflict error of an update without force, the update
+//							being retried with force when it returns true
+//		retryOnConflict:	waits on the deployments locking the application and retries the
+//							update, when not forced
+//		timeout:			the time to wait for each deployment, Config.DeploymentTimeout when zero
+type UpdateAppOpts struct {
+	Force           bool
+	ForceOnConflict func(err error) bool
+	RetryOnConflict bool
+	Timeout         time.Duration
+}
+
+// RestartAppOpts contains a payload for RestartApplicationBy method
+//		force:		overrides a currently running deployment.
+//		wait:		blocks until the deployment of the restart finishes
+//		timeout:	the time to wait for the deployment, Config.DeploymentTimeout when zero
+type RestartAppOpts struct {
+	Force   bool
+	Wait    bool
+	Timeout time.Duration
 }
 
 // TaskStats is a container for Stats
@@ -193,7 +246,7 @@ func (r *Application) Command(cmd string) *Application {
 // CPU set the amount of CPU shares per instance which is assigned to the application
 //		cpu:	the CPU shared (check Docker docs) per instance
 func (r *Application) CPU(cpu float64) *Application {
-	r.CPUs = cpu
+	r.CPUs = &cpu
 	return r
 }
 
@@ -221,6 +274,18 @@ func (r *Application) Storage(disk float64) *Application {
 	return r
 }
 
+// SetBackoff sets the delay before relaunching the failed tasks of the application, multiplied by
+// the factor after each failure, up to the maximum delay
+//		seconds:		the initial delay in seconds
+//		factor:			the factor the delay is multiplied by after each failure
+//		maxLaunchDelaySeconds:	the maximum delay in seconds
+func (r *Application) SetBackoff(seconds, factor, maxLaunchDelaySeconds float64) *Application {
+	r.BackoffSeconds = &seconds
+	r.BackoffFactor = &factor
+	r.MaxLaunchDelaySeconds = &maxLaunchDelaySeconds
+	return r
+}
+
 // AllTaskRunning checks to see if all the application tasks are running, i.e. the instances is equal
 // to the number of running tasks
 func (r *Application) AllTaskRunning() bool {
@@ -387,7 +452,7 @@ func (r *Application) EmptyEnvs() *Application {
 }
 
 // AddSecret adds a secret declaration
-// envVar: the name of the environment variable
+// envVar: the name of the environment variable, empty for a secret referenced elsewhere, e.g. by a pull config
 // name:	the name of the secret
 // source:	the source ID of the secret
 func (r *Application) AddSecret(envVar, name, source string) *Application {
@@ -415,6 +480,15 @@ func (r *Application) SetExecutor(executor string) *Application {
 	return r
 }
 
+// SetExecutorResources sets the resources allocated to the custom executor of the application,
+// on top of the resources of its tasks
+//		resources:	the cpus, mem and disk of the executor
+func (r *Application) SetExecutorResources(resources ExecutorResources) *Application {
+	r.ExecutorResources = &resources
+
+	return r
+}
+
 // AddHealthCheck adds a health check
 // 	healthCheck the health check that should be added
 func (r *Application) AddHealthCheck(healthCheck HealthCheck) *Application {
@@ -438,6 +512,32 @@ func (r *Application) EmptyHealthChecks() *Application {
 	return r
 }
 
+// AllTasksHealthy checks to see if all the application tasks are running and pass their health
+// checks, i.e. every task reports alive health check results. An application without health checks
+// only needs its tasks running.
+func (r *Application) AllTasksHealthy() bool {
+	if !r.AllTaskRunning() {
+		return false
+	}
+	if !r.HasHealthChecks() {
+		return true
+	}
+	for _, task := range r.Tasks {
+		// Health check results may not be available immediately. Assume
+		// non-healthiness if they are missing for any task.
+		if task.HealthCheckResults == nil {
+			return false
+		}
+		for _, check := range task.HealthCheckResults {
+			// When a task is flapping in Marathon, this is sometimes nil
+			if check == nil || !check.Alive {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // HasHealthChecks is a helper method, used to check if an application has health checks
 func (r *Application) HasHealthChecks() bool {
 	return r.HealthChecks != nil && len(*r.HealthChecks) > 0
@@ -604,6 +704,30 @@ func (r *Application) EmptyUnreachableStrategy() *Application {
 	return r
 }
 
+// AddNetwork adds a network definition, available since Marathon 1.5
+//		name:	the name of the network, only used for container mode networks
+//		mode:	the mode of the network
+func (r *Application) AddNetwork(name string, mode PodNetworkMode) *Application {
+	if r.Networks == nil {
+		r.EmptyNetworks()
+	}
+
+	networks := *r.Networks
+	networks = append(networks, PodNetwork{Name: name, Mode: mode})
+	r.Networks = &networks
+
+	return r
+}
+
+// EmptyNetworks explicitly empties the networks -- use this if you need to empty
+// the networks of an application that already has networks set (setting networks to nil will
+// keep the current value)
+func (r *Application) EmptyNetworks() *Application {
+	r.Networks = &[]PodNetwork{}
+
+	return r
+}
+
 // SetResidency sets behavior for resident applications, an application is resident when
 // it has local persistent volumes set
 func (r *Application) SetResidency(whenLost TaskLostBehaviorType) *Application {
@@ -621,6 +745,22 @@ func (r *Application) EmptyResidency() *Application {
 	return r
 }
 
+// SetRole sets the Mesos role the resources of the application are reserved for, e.g. to enroll it
+// in the quota of the role, available since Marathon 1.9. See Info.Roles for the roles allowed.
+//		role:	the Mesos role
+func (r *Application) SetRole(role string) *Application {
+	r.Role = &role
+	return r
+}
+
+// SetTTY sets whether the tasks of the application get a pseudo-terminal, e.g. for interactive
+// or debug workloads
+//		tty:	true / false
+func (r *Application) SetTTY(tty bool) *Application {
+	r.TTY = &tty
+	return r
+}
+
 // String returns the json representation of this application
 func (r *Application) String() string {
 	s, err := json.MarshalIndent(r, "", "  ")
@@ -639,7 +779,7 @@ func (r *marathonClient) Applications(v url.Values) (*Applications, error) {
 	}
 
 	applications := new(Applications)
-	err := r.apiGet(marathonAPIApps+query, nil, applications)
+	err := r.apiGet("Applications", marathonAPIApps+query, nil, applications)
 	if err != nil {
 		return nil, err
 	}
@@ -661,6 +801,19 @@ func (r *marathonClient) ListApplications(v url.Values) ([]string, error) {
 	return list, nil
 }
 
+// HasApplication checks if the application exists, with a request for the application alone rather
+// than listing all the applications
+// 		name: 		the id used to identify the application
+func (r *marathonClient) HasApplication(name string) (bool, error) {
+	if err := r.apiGet("HasApplication", buildPath(name), nil, nil); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
 // HasApplicationVersion checks to see if the application version exists in Marathon
 // 		name: 		the id used to identify the application
 //		version: 	the version (normally a timestamp) your looking for
@@ -679,7 +832,7 @@ func (r *marathonClient) HasApplicationVersion(name, version string) (bool, erro
 func (r *marathonClient) ApplicationVersions(name string) (*ApplicationVersions, error) {
 	path := fmt.Sprintf("%s/versions", buildPath(name))
 	versions := new(ApplicationVersions)
-	if err := r.apiGet(path, nil, versions); err != nil {
+	if err := r.apiGet("ApplicationVersions", path, nil, versions); err != nil {
 		return nil, err
 	}
 	return versions, nil
@@ -691,13 +844,62 @@ func (r *marathonClient) ApplicationVersions(name string) (*ApplicationVersions,
 func (r *marathonClient) SetApplicationVersion(name string, version *ApplicationVersion) (*DeploymentID, error) {
 	path := buildPath(name)
 	deploymentID := new(DeploymentID)
-	if err := r.apiPut(path, version, deploymentID); err != nil {
+	if err := r.apiPut("SetApplicationVersion", path, version, deploymentID); err != nil {
 		return nil, err
 	}
 
 	return deploymentID, nil
 }
 
+// SetApplicationVersionBy changes the version of the application, optionally overriding the
+// deployments locking the application
+// 		name: 		the id used to identify the application
+//		version: 	the version (normally a timestamp) you wish to change to
+//		opts:		the options of the update, see UpdateAppOpts
+func (r *marathonClient) SetApplicationVersionBy(name string, version *ApplicationVersion, opts *UpdateAppOpts) (*DeploymentID, error) {
+	return r.updateOnConflict(name, opts, func(force bool) (*DeploymentID, error) {
+		path := buildPathWithForceParam(name, force)
+		deploymentID := new(DeploymentID)
+		if err := r.apiPut("SetApplicationVersionBy", path, version, deploymentID); err != nil {
+			return nil, err
+		}
+		return deploymentID, nil
+	})
+}
+
+// forceOnConflict performs the change, retrying it with force when it conflicts with a deployment
+// and the decision is to override it
+func (r *marathonClient) forceOnConflict(name string, force bool, decide func(error) bool, change func(force bool) (*DeploymentID, error)) (*DeploymentID, error) {
+	deploymentID, err := change(force)
+	if force || decide == nil || !errors.Is(err, ErrConflict) || !decide(err) {
+		return deploymentID, err
+	}
+	r.log(LogModuleOrchestration).Infof("forcing the change of %s over the conflicting deployments: %s", name, err)
+
+	return change(true)
+}
+
+// updateOnConflict performs the update, handling the conflicts with the deployments locking the
+// application as decided by the options
+func (r *marathonClient) updateOnConflict(name string, opts *UpdateAppOpts, update func(force bool) (*DeploymentID, error)) (*DeploymentID, error) {
+	if opts == nil {
+		opts = &UpdateAppOpts{}
+	}
+	for attempt := 1; ; attempt++ {
+		deploymentID, err := r.forceOnConflict(name, opts.Force, opts.ForceOnConflict, update)
+		deployments := ConflictingDeployments(err)
+		if !opts.RetryOnConflict || len(deployments) == 0 || attempt >= conflictAttempts {
+			return deploymentID, err
+		}
+		r.log(LogModuleOrchestration).Debugf("updateOnConflict(): %s is locked by the deployments %v, waiting on them", name, deployments)
+		for _, id := range deployments {
+			if err := r.WaitOnDeployment(id, opts.Timeout); err != nil {
+				return nil, fmt.Errorf("waiting on the deployment %s locking %s: %w", id, name, err)
+			}
+		}
+	}
+}
+
 // Application retrieves the application configuration from marathon
 // 		name: 		the id used to identify the application
 func (r *marathonClient) Application(name string) (*Application, error) {
@@ -705,7 +907,7 @@ func (r *marathonClient) Application(name string) (*Application, error) {
 		Application *Application `json:"app"`
 	}
 
-	if err := r.apiGet(buildPath(name), nil, &wrapper); err != nil {
+	if err := r.apiGet("Application", buildPath(name), nil, &wrapper); err != nil {
 		return nil, err
 	}
 
@@ -724,7 +926,7 @@ func (r *marathonClient) ApplicationBy(name string, opts *GetAppOpts) (*Applicat
 		Application *Application `json:"app"`
 	}
 
-	if err := r.apiGet(path, nil, &wrapper); err != nil {
+	if err := r.apiGet("ApplicationBy", path, nil, &wrapper); err != nil {
 		return nil, err
 	}
 
@@ -738,7 +940,7 @@ func (r *marathonClient) ApplicationByVersion(name, version string) (*Applicatio
 	app := new(Application)
 
 	path := fmt.Sprintf("%s/versions/%s", buildPath(name), version)
-	if err := r.apiGet(path, nil, app); err != nil {
+	if err := r.apiGet("ApplicationByVersion", path, nil, app); err != nil {
 		return nil, err
 	}
 
@@ -755,33 +957,12 @@ func (r *marathonClient) ApplicationOK(name string) (bool, error) {
 		return false, err
 	}
 
-	// step: check if all the tasks are running?
-	if !application.AllTaskRunning() {
-		return false, nil
-	}
-
-	// step: if the application has not health checks, just return true
-	if application.HealthChecks == nil || len(*application.HealthChecks) == 0 {
+	// step: an application in maintenance is degraded on purpose
+	if r.config.TolerateMaintenance && application.InMaintenance() {
 		return true, nil
 	}
 
-	// step: iterate the application checks and look for false
-	for _, task := range application.Tasks {
-		// Health check results may not be available immediately. Assume
-		// non-healthiness if they are missing for any task.
-		if task.HealthCheckResults == nil {
-			return false, nil
-		}
-
-		for _, check := range task.HealthCheckResults {
-			//When a task is flapping in Marathon, this is sometimes nil
-			if check == nil || !check.Alive {
-				return false, nil
-			}
-		}
-	}
-
-	return true, nil
+	return application.AllTasksHealthy(), nil
 }
 
 // ApplicationDeployments retrieves an array of Deployment IDs for an application
@@ -798,8 +979,20 @@ func (r *marathonClient) ApplicationDeployments(name string) ([]*DeploymentID, e
 // CreateApplication creates a new application in Marathon
 // 		application:		the structure holding the application configuration
 func (r *marathonClient) CreateApplication(application *Application) (*Application, error) {
+	if err := application.CheckLimits(r.config.Limits); err != nil {
+		return nil, err
+	}
 	result := new(Application)
-	if err := r.apiPost(marathonAPIApps, application, result); err != nil {
+	err := r.deploy(&DeployContext{Operation: DeployOperationCreate, Application: application}, func() (*DeploymentID, error) {
+		if err := r.apiPost("CreateApplication", marathonAPIApps, application, result); err != nil {
+			return nil, r.applicationFeatures(application, err)
+		}
+		if deployments := result.DeploymentIDs(); len(deployments) > 0 {
+			return deployments[0], nil
+		}
+		return nil, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -810,15 +1003,41 @@ func (r *marathonClient) CreateApplication(application *Application) (*Applicati
 //		name:		the id of the application
 //		timeout:	a duration of time to wait for an application to deploy
 func (r *marathonClient) WaitOnApplication(name string, timeout time.Duration) error {
-	return r.wait(name, timeout, r.appExistAndRunning)
+	if err := r.wait(name, timeout, r.appExistAndRunning); err != nil {
+		return diagnoseTimeout(r, err, name)
+	}
+	return nil
+}
+
+// WaitOnApplicationHealthy waits for an application to be deployed with all its tasks running and
+// passing their health checks, unlike WaitOnApplication which only waits for the tasks to run
+//		name:		the id of the application
+//		timeout:	a duration of time to wait for an application to be healthy
+func (r *marathonClient) WaitOnApplicationHealthy(name string, timeout time.Duration) error {
+	if err := r.wait(name, timeout, r.appHealthy); err != nil {
+		return diagnoseTimeout(r, err, name)
+	}
+	return nil
+}
+
+// appHealthy checks the application has no deployment in progress and all its tasks are healthy
+func (r *marathonClient) appHealthy(name string) bool {
+	app, err := r.Application(name)
+	if err != nil {
+		return false
+	}
+	if r.config.TolerateMaintenance && app.InMaintenance() {
+		return true
+	}
+	return len(app.Deployments) == 0 && app.AllTasksHealthy()
 }
 
 func (r *marathonClient) appExistAndRunning(name string) bool {
 	app, err := r.Application(name)
-	if apiErr, ok := err.(*APIError); ok && apiErr.ErrCode == ErrCodeNotFound {
+	if errors.Is(err, ErrNotFound) {
 		return false
 	}
-	if err == nil && app.AllTaskRunning() {
+	if err == nil && (app.AllTaskRunning() || r.config.TolerateMaintenance && app.InMaintenance()) {
 		return true
 	}
 	return false
@@ -831,26 +1050,63 @@ func (r *marathonClient) DeleteApplication(name string, force bool) (*Deployment
 	path := buildPathWithForceParam(name, force)
 	// step: check of the application already exists
 	deployID := new(DeploymentID)
-	if err := r.apiDelete(path, nil, deployID); err != nil {
+	if err := r.apiDelete("DeleteApplication", path, nil, deployID); err != nil {
 		return nil, err
 	}
 
 	return deployID, nil
 }
 
+// DeleteApplicationBy deletes an application from marathon, optionally overriding the deployments
+// locking the application. The conflicts not overridden are returned as an APIError listing the
+// locking deployments, see ConflictingDeployments.
+// 		name: 		the id used to identify the application
+//		opts:		the options of the deletion, see DeleteAppOpts
+func (r *marathonClient) DeleteApplicationBy(name string, opts *DeleteAppOpts) (*DeploymentID, error) {
+	if opts == nil {
+		opts = &DeleteAppOpts{}
+	}
+	deployID, err := r.forceOnConflict(name, opts.Force, opts.ForceOnConflict, func(force bool) (*DeploymentID, error) {
+		return r.DeleteApplication(name, force)
+	})
+	if opts.IgnoreNotFound && errors.Is(err, ErrNotFound) {
+		r.log(LogModuleOrchestration).Debugf("DeleteApplicationBy(): %s doesn't exist", name)
+		return nil, nil
+	}
+
+	return deployID, err
+}
+
 // RestartApplication performs a rolling restart of marathon application
 // 		name: 		the id used to identify the application
 func (r *marathonClient) RestartApplication(name string, force bool) (*DeploymentID, error) {
 	deployment := new(DeploymentID)
 	var options struct{}
 	path := buildPathWithForceParam(fmt.Sprintf("%s/restart", name), force)
-	if err := r.apiPost(path, &options, deployment); err != nil {
+	if err := r.apiPost("RestartApplication", path, &options, deployment); err != nil {
 		return nil, err
 	}
 
 	return deployment, nil
 }
 
+// RestartApplicationBy performs a rolling restart of marathon application, optionally waiting on
+// the deployment. The deployment is returned along with the error of the wait.
+// 		name: 		the id used to identify the application
+//		opts:		the options of the restart, see RestartAppOpts
+func (r *marathonClient) RestartApplicationBy(name string, opts *RestartAppOpts) (*DeploymentID, error) {
+	if opts == nil {
+		opts = &RestartAppOpts{}
+	}
+	deployment, err := r.RestartApplication(name, opts.Force)
+	if err != nil || !opts.Wait {
+		return deployment, err
+	}
+	r.log(LogModuleOrchestration).Debugf("RestartApplicationBy(): waiting on the deployment %s of %s", deployment.DeploymentID, name)
+
+	return deployment, r.WaitOnDeployment(deployment.DeploymentID, opts.Timeout)
+}
+
 // ScaleApplicationInstances changes the number of instance an application is running
 // 		name: 		the id used to identify the application
 // 		instances:	the number of instances you wish to change to
@@ -861,24 +1117,127 @@ func (r *marathonClient) ScaleApplicationInstances(name string, instances int, f
 	changes.Instances = &instances
 	path := buildPathWithForceParam(name, force)
 	deployID := new(DeploymentID)
-	if err := r.apiPut(path, changes, deployID); err != nil {
+	if err := r.apiPut("ScaleApplicationInstances", path, changes, deployID); err != nil {
 		return nil, err
 	}
 
 	return deployID, nil
 }
 
+// ScaleApplication changes the number of instance an application is running, returning a tracker of
+// the launch of the instances when requested in the options
+// 		name: 		the id used to identify the application
+// 		instances:	the number of instances you wish to change to
+//		opts:		the scaling options
+func (r *marathonClient) ScaleApplication(name string, instances int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	if opts == nil {
+		opts = &ScaleAppOpts{}
+	}
+	if opts.Track == nil {
+		deployID, err := r.ScaleApplicationInstances(name, instances, opts.Force)
+		if err != nil {
+			return nil, nil, err
+		}
+		return deployID, nil, r.waitOnScaling(name, deployID, opts)
+	}
+
+	deployID, err := r.ScaleApplicationInstances(name, instances, opts.Force)
+	if err != nil {
+		return nil, nil, err
+	}
+	trackerOpts := *opts.Track
+	if trackerOpts.Interval <= 0 {
+		trackerOpts.Interval = r.config.PollingWaitTime
+	}
+	// step: track the launch by the deployment of the scaling
+	tracker, err := NewLaunchTracker(r, name, instances, deployID, &trackerOpts)
+	if err != nil {
+		return deployID, nil, err
+	}
+	r.log(LogModuleOrchestration).Debugf("ScaleApplication(): tracking the launch of %d instances of %s", instances, name)
+
+	return deployID, tracker, r.waitOnScaling(name, deployID, opts)
+}
+
+// waitOnScaling waits on the deployment of the scaling when requested by the options
+func (r *marathonClient) waitOnScaling(name string, deployID *DeploymentID, opts *ScaleAppOpts) error {
+	if !opts.Wait {
+		return nil
+	}
+	r.log(LogModuleOrchestration).Debugf("ScaleApplication(): waiting on the deployment %s of %s", deployID.DeploymentID, name)
+
+	return r.WaitOnDeployment(deployID.DeploymentID, opts.Timeout)
+}
+
+// ScaleBy changes the number of instances of an application relatively to its current number,
+// never below zero. The scaling is retried on the current number of instances when rejected by a
+// conflicting deployment, so concurrent changes add up rather than overwrite each other.
+// 		name: 		the id used to identify the application
+// 		delta:		the number of instances to add, or to remove when negative
+//		opts:		the options of the scaling, see ScaleAppOpts
+func (r *marathonClient) ScaleBy(name string, delta int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	var err error
+	for attempt := 0; attempt < scaleByAttempts; attempt++ {
+		if attempt > 0 {
+			r.log(LogModuleOrchestration).Debugf("ScaleBy(): %s is locked by a deployment, retrying: %s", name, err)
+			select {
+			case <-time.After(r.config.PollingWaitTime):
+			case <-r.client.done():
+				return nil, nil, ErrClientClosed
+			}
+		}
+		var application *Application
+		if application, err = r.Application(name); err != nil {
+			return nil, nil, err
+		}
+		instances := delta
+		if application.Instances != nil {
+			instances += *application.Instances
+		}
+		if instances < 0 {
+			instances = 0
+		}
+		var deployID *DeploymentID
+		var tracker *LaunchTracker
+		deployID, tracker, err = r.ScaleApplication(name, instances, opts)
+		if !errors.Is(err, ErrConflict) || deployID != nil {
+			return deployID, tracker, err
+		}
+	}
+
+	return nil, nil, err
+}
+
 // UpdateApplication updates an application in Marathon
 // 		application:		the structure holding the application configuration
 func (r *marathonClient) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	if err := application.CheckLimits(r.config.Limits); err != nil {
+		return nil, err
+	}
 	result := new(DeploymentID)
 	path := buildPathWithForceParam(application.ID, force)
-	if err := r.apiPut(path, application, result); err != nil {
+	err := r.deploy(&DeployContext{Operation: DeployOperationUpdate, Application: application, Force: force}, func() (*DeploymentID, error) {
+		if err := r.apiPut("UpdateApplication", path, application, result); err != nil {
+			return nil, r.applicationFeatures(application, err)
+		}
+		return result, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// UpdateApplicationBy updates an application in Marathon, optionally overriding the deployments
+// locking the application
+// 		application:		the structure holding the application configuration
+//		opts:				the options of the update, see UpdateAppOpts
+func (r *marathonClient) UpdateApplicationBy(application *Application, opts *UpdateAppOpts) (*DeploymentID, error) {
+	return r.updateOnConflict(application.ID, opts, func(force bool) (*DeploymentID, error) {
+		return r.UpdateApplication(application, force)
+	})
+}
+
 func buildPathWithForceParam(rootPath string, force bool) string {
 	path := buildPath(rootPath)
 	if force {
@@ -0,0 +1,82 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LoadApplicationFromFile reads the definition of an application from a JSON file, or from a
+// YAML file when its extension is .yaml or .yml
+//		path:		the path of the file
+func LoadApplicationFromFile(path string) (*Application, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	application := new(Application)
+	if isYAMLFile(path) {
+		err = yaml.Unmarshal(content, application)
+	} else {
+		err = json.Unmarshal(content, application)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the application from %s: %w", path, err)
+	}
+	return application, nil
+}
+
+// WriteToFile writes the definition of the application to a JSON file, or to a YAML file when
+// its extension is .yaml or .yml. The fields populated by Marathon, such as the tasks, the
+// deployments and the version, are left out, so the file can be deployed as is.
+//		path:		the path of the file
+func (r *Application) WriteToFile(path string) error {
+	fields, err := definitionFields(r)
+	if err != nil {
+		return err
+	}
+	for _, field := range applicationStatusFields {
+		delete(fields, field)
+	}
+
+	var content []byte
+	if isYAMLFile(path) {
+		content, err = yaml.Marshal(fields)
+	} else {
+		content, err = json.MarshalIndent(fields, "", "  ")
+		content = append(content, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// isYAMLFile checks if the file holds YAML according to its extension
+func isYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
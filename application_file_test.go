@@ -0,0 +1,78 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-marathon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	application := NewDockerApplication().Name("/product/web").Count(2).
+		AddEnv("LOG_LEVEL", "info").
+		AddSecret("TOKEN", "token", "/secrets/token")
+	application.Container.Docker.Container("nginx")
+	application.Version = "2017-03-06T10:00:00.000Z"
+	application.Tasks = []*Task{{ID: "web.1"}}
+	application.Deployments = []map[string]string{{"id": "deployment"}}
+	application.TasksRunning = 2
+
+	expected := application.Clone()
+	expected.Version = ""
+	expected.Tasks = nil
+	expected.Deployments = nil
+	expected.TasksRunning = 0
+
+	for _, name := range []string{"web.json", "web.yaml", "web.YML"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, application.WriteToFile(path))
+
+		content, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "tasks", name)
+		assert.NotContains(t, string(content), "deployments", name)
+		assert.NotContains(t, string(content), "version", name)
+
+		loaded, err := LoadApplicationFromFile(path)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, loaded, name)
+	}
+}
+
+func TestLoadApplicationFromFileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-marathon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = LoadApplicationFromFile(filepath.Join(dir, "missing.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	path := filepath.Join(dir, "invalid.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = LoadApplicationFromFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load the application from "+path)
+}
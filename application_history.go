@@ -0,0 +1,228 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// FieldChange is the change of a field of a definition, e.g. of an application between two versions
+type FieldChange struct {
+	// Path is the path of the field, e.g. container.docker.image, env.LOG_LEVEL or constraints[0]
+	Path string
+	// Old is the previous value, nil when the field was added
+	Old interface{}
+	// New is the new value, nil when the field was removed
+	New interface{}
+}
+
+// String returns a description of the change
+func (c FieldChange) String() string {
+	switch {
+	case c.Old == nil:
+		return fmt.Sprintf("%s: added %v", c.Path, c.New)
+	case c.New == nil:
+		return fmt.Sprintf("%s: removed %v", c.Path, c.Old)
+	}
+	return fmt.Sprintf("%s: %v -> %v", c.Path, c.Old, c.New)
+}
+
+// ApplicationRevision is the definition of an application at one of its versions
+type ApplicationRevision struct {
+	// Version is the version of the definition
+	Version string
+	// Application is the definition
+	Application *Application
+	// Changes are the changes since the previous revision, nil for the oldest revision
+	Changes []FieldChange
+}
+
+// VersionHistory is the history of the definition of an application
+type VersionHistory struct {
+	// ID is the id of the application
+	ID string
+	// Revisions are the revisions of the application, the oldest first
+	Revisions []ApplicationRevision
+}
+
+// ApplicationHistory retrieves the definitions of the last versions of the application along with
+// the changes between them, e.g. to find what changed since a given day
+//		client:		the client the versions are retrieved with
+//		name:		the id of the application
+//		limit:		the number of versions retrieved, all of them when not positive
+func ApplicationHistory(client Marathon, name string, limit int) (*VersionHistory, error) {
+	versions, err := client.ApplicationVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	ids := versions.Sorted()
+	// step: the most recent versions are kept
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	history := &VersionHistory{ID: validateID(name)}
+	for i := len(ids) - 1; i >= 0; i-- {
+		application, err := client.ApplicationByVersion(name, ids[i])
+		if err != nil {
+			return nil, err
+		}
+		revision := ApplicationRevision{Version: ids[i], Application: application}
+		if count := len(history.Revisions); count > 0 {
+			if revision.Changes, err = DiffApplications(history.Revisions[count-1].Application, application); err != nil {
+				return nil, err
+			}
+		}
+		history.Revisions = append(history.Revisions, revision)
+	}
+	return history, nil
+}
+
+// At returns the revision which was deployed at the time, nil if the history starts later
+func (h *VersionHistory) At(at time.Time) *ApplicationRevision {
+	var found *ApplicationRevision
+	for i := range h.Revisions {
+		version, err := ParseVersionTime(h.Revisions[i].Version)
+		if err != nil || version.After(at) {
+			break
+		}
+		found = &h.Revisions[i]
+	}
+	return found
+}
+
+// Latest returns the most recent revision, nil if there is none
+func (h *VersionHistory) Latest() *ApplicationRevision {
+	if len(h.Revisions) == 0 {
+		return nil
+	}
+	return &h.Revisions[len(h.Revisions)-1]
+}
+
+// applicationStatusFields are the fields of an application populated by Marathon, which aren't
+// part of its definition
+var applicationStatusFields = []string{
+	"version", "versionInfo", "tasks", "deployments", "readinessCheckResults", "tasksRunning",
+	"tasksStaged", "tasksHealthy", "tasksUnhealthy", "taskStats", "lastTaskFailure",
+}
+
+// Diff returns the changes of the fields of the definition of the application the update makes,
+// sorted by path, e.g. to review an update before submitting it. See DiffApplications.
+//		updated:	the new definition
+func (r *Application) Diff(updated *Application) ([]FieldChange, error) {
+	return DiffApplications(r, updated)
+}
+
+// DiffApplications returns the changes of the fields of the definition of the application, sorted
+// by path. The fields populated by Marathon, such as the tasks, the deployments and the version,
+// are ignored.
+//		from:		the previous definition
+//		to:		the new definition
+func DiffApplications(from, to *Application) ([]FieldChange, error) {
+	return diffDefinitions(from, to, applicationStatusFields)
+}
+
+// diffDefinitions returns the changes of the JSON fields of the definitions, sorted by path
+//		from:		the previous definition
+//		to:		the new definition
+//		ignored:	the top-level fields which aren't compared
+func diffDefinitions(from, to interface{}, ignored []string) ([]FieldChange, error) {
+	old, err := definitionFields(from)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := definitionFields(to)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range ignored {
+		delete(old, field)
+		delete(updated, field)
+	}
+
+	var changes []FieldChange
+	diffValues("", old, updated, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// definitionFields decodes the JSON fields of the definition
+func definitionFields(definition interface{}) (map[string]interface{}, error) {
+	content, err := json.Marshal(definition)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// diffValues adds the changes between the two decoded JSON values
+func diffValues(path string, old, updated interface{}, changes *[]FieldChange) {
+	oldObject, oldIsObject := old.(map[string]interface{})
+	updatedObject, updatedIsObject := updated.(map[string]interface{})
+	if oldIsObject && updatedIsObject {
+		for name, value := range oldObject {
+			diffValues(joinFieldPath(path, name), value, updatedObject[name], changes)
+		}
+		for name, value := range updatedObject {
+			if _, found := oldObject[name]; !found {
+				diffValues(joinFieldPath(path, name), nil, value, changes)
+			}
+		}
+		return
+	}
+
+	oldArray, oldIsArray := old.([]interface{})
+	updatedArray, updatedIsArray := updated.([]interface{})
+	if oldIsArray && updatedIsArray {
+		for i := 0; i < len(oldArray) || i < len(updatedArray); i++ {
+			var oldItem, updatedItem interface{}
+			if i < len(oldArray) {
+				oldItem = oldArray[i]
+			}
+			if i < len(updatedArray) {
+				updatedItem = updatedArray[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), oldItem, updatedItem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(old, updated) {
+		*changes = append(*changes, FieldChange{Path: path, Old: old, New: updated})
+	}
+}
+
+// joinFieldPath appends the name of a field to the path of its parent
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
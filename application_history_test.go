@@ -0,0 +1,149 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedClient holds the definitions of an application at each of its versions
+type versionedClient struct {
+	Marathon
+	versions map[string]*Application
+	fetched  []string
+}
+
+func (c *versionedClient) ApplicationVersions(name string) (*ApplicationVersions, error) {
+	versions := new(ApplicationVersions)
+	for version := range c.versions {
+		versions.Versions = append(versions.Versions, version)
+	}
+	return versions, nil
+}
+
+func (c *versionedClient) ApplicationByVersion(name, version string) (*Application, error) {
+	c.fetched = append(c.fetched, version)
+	return c.versions[version], nil
+}
+
+func newVersionedClient() *versionedClient {
+	return &versionedClient{versions: map[string]*Application{
+		"2017-03-06T10:00:00.000Z": NewDockerApplication().Name("/web").Count(2).CPU(0.5).
+			AddEnv("LOG_LEVEL", "info").AddConstraint("hostname", "UNIQUE"),
+		"2017-03-07T10:00:00.000Z": NewDockerApplication().Name("/web").Count(4).CPU(0.5).
+			AddEnv("LOG_LEVEL", "info").AddConstraint("hostname", "UNIQUE"),
+		"2017-03-09T10:00:00.000Z": NewDockerApplication().Name("/web").Count(4).CPU(1).
+			AddEnv("LOG_LEVEL", "debug").AddEnv("DEBUG", "1"),
+	}}
+}
+
+func TestApplicationHistory(t *testing.T) {
+	client := newVersionedClient()
+
+	history, err := ApplicationHistory(client, "web", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "/web", history.ID)
+	require.Len(t, history.Revisions, 3)
+	assert.Equal(t, "2017-03-06T10:00:00.000Z", history.Revisions[0].Version)
+	assert.Nil(t, history.Revisions[0].Changes)
+	assert.Equal(t, []FieldChange{{Path: "instances", Old: float64(2), New: float64(4)}}, history.Revisions[1].Changes)
+	assert.Equal(t, []FieldChange{
+		{Path: "constraints", Old: []interface{}{[]interface{}{"hostname", "UNIQUE"}}, New: nil},
+		{Path: "cpus", Old: 0.5, New: float64(1)},
+		{Path: "env.DEBUG", Old: nil, New: "1"},
+		{Path: "env.LOG_LEVEL", Old: "info", New: "debug"},
+	}, history.Revisions[2].Changes)
+	assert.Equal(t, "env.LOG_LEVEL: info -> debug", history.Revisions[2].Changes[3].String())
+	assert.Equal(t, "env.DEBUG: added 1", history.Revisions[2].Changes[2].String())
+
+	// step: find what changed since a given day
+	tuesday := time.Date(2017, 3, 7, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2017-03-07T10:00:00.000Z", history.At(tuesday).Version)
+	assert.Nil(t, history.At(tuesday.AddDate(0, -1, 0)))
+	changes, err := DiffApplications(history.At(tuesday).Application, history.Latest().Application)
+	require.NoError(t, err)
+	assert.Len(t, changes, 4)
+
+	// step: only the most recent versions are retrieved
+	client.fetched = nil
+	history, err = ApplicationHistory(client, "web", 2)
+	require.NoError(t, err)
+	require.Len(t, history.Revisions, 2)
+	assert.Equal(t, []string{"2017-03-07T10:00:00.000Z", "2017-03-09T10:00:00.000Z"}, client.fetched)
+}
+
+func TestDiffApplications(t *testing.T) {
+	from := NewDockerApplication().Name("/web").Count(1)
+	from.Version = "2017-03-06T10:00:00.000Z"
+	to := NewDockerApplication().Name("/web").Count(1)
+	to.Version = "2017-03-07T10:00:00.000Z"
+
+	changes, err := DiffApplications(from, to)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	to.Container.Docker.Container("nginx")
+	changes, err = DiffApplications(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{{Path: "container.docker.image", Old: nil, New: "nginx"}}, changes)
+
+	// step: the items of the arrays are compared one by one
+	from.AddConstraint("hostname", "UNIQUE")
+	to.AddConstraint("hostname", "CLUSTER", "node-1")
+	changes, err = DiffApplications(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Path: "constraints[0][1]", Old: "UNIQUE", New: "CLUSTER"},
+		{Path: "constraints[0][2]", Old: nil, New: "node-1"},
+		{Path: "container.docker.image", Old: nil, New: "nginx"},
+	}, changes)
+}
+
+func TestApplicationDiff(t *testing.T) {
+	current := NewDockerApplication().Name("/web").Count(2).AddEnv("LOG_LEVEL", "info").AddLabel("tier", "frontend")
+	current.Tasks = []*Task{{ID: "web.1"}}
+	current.Deployments = []map[string]string{{"id": "deployment"}}
+	current.TasksRunning = 2
+	current.LastTaskFailure = &LastTaskFailure{Message: "failed"}
+
+	updated := current.Clone()
+	updated.Tasks = nil
+	updated.Deployments = nil
+	updated.TasksRunning = 0
+	updated.LastTaskFailure = nil
+
+	// step: the fields populated by Marathon are ignored
+	changes, err := current.Diff(updated)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	updated.Count(4).AddEnv("LOG_LEVEL", "debug").AddLabel("owner", "team")
+	delete(*updated.Labels, "tier")
+	changes, err = current.Diff(updated)
+	require.NoError(t, err)
+	assert.Equal(t, []FieldChange{
+		{Path: "env.LOG_LEVEL", Old: "info", New: "debug"},
+		{Path: "instances", Old: float64(2), New: float64(4)},
+		{Path: "labels.owner", Old: nil, New: "team"},
+		{Path: "labels.tier", Old: "frontend", New: nil},
+	}, changes)
+	assert.Equal(t, "instances: 2 -> 4", changes[1].String())
+}
@@ -46,7 +46,7 @@ func (app *Application) UnmarshalJSON(b []byte) error {
 		Alias: (*Alias)(app),
 	}
 	if err := json.Unmarshal(b, aux); err != nil {
-		return fmt.Errorf("malformed application definition %v", err)
+		return fmt.Errorf("malformed application definition %w", err)
 	}
 	env := &map[string]string{}
 	secrets := &map[string]Secret{}
@@ -80,26 +80,36 @@ func (app *Application) UnmarshalJSON(b []byte) error {
 // MarshalJSON marshals the given Application as expected except for environment variables and secrets,
 // which are marshaled from specialized structs.  The environment variable piece of the secrets and other
 // normal environment variables are combined and marshaled to the env field.  The secrets and the related
-// source are marshaled into the secrets field.
+// source are marshaled into the secrets field. Explicitly emptied environment variables and secrets are
+// marshaled as empty objects, so an update clears them.
 func (app *Application) MarshalJSON() ([]byte, error) {
-	env := make(map[string]interface{})
-	secrets := make(map[string]TmpSecret)
+	var env *map[string]interface{}
+	var secrets *map[string]TmpSecret
 
 	if app.Env != nil {
+		env = &map[string]interface{}{}
 		for k, v := range *app.Env {
-			env[string(k)] = string(v)
+			(*env)[string(k)] = string(v)
 		}
 	}
 	if app.Secrets != nil {
+		secrets = &map[string]TmpSecret{}
 		for k, v := range *app.Secrets {
-			env[v.EnvVar] = TmpEnvSecret{Secret: k}
-			secrets[k] = TmpSecret{v.Source}
+			(*secrets)[k] = TmpSecret{v.Source}
+			// step: the secrets without environment variable, e.g. of a pull config, are only declared
+			if v.EnvVar == "" {
+				continue
+			}
+			if env == nil {
+				env = &map[string]interface{}{}
+			}
+			(*env)[v.EnvVar] = TmpEnvSecret{Secret: k}
 		}
 	}
 	aux := &struct {
 		*Alias
-		Env     map[string]interface{} `json:"env,omitempty"`
-		Secrets map[string]TmpSecret   `json:"secrets,omitempty"`
+		Env     *map[string]interface{} `json:"env,omitempty"`
+		Secrets *map[string]TmpSecret   `json:"secrets,omitempty"`
 	}{Alias: (*Alias)(app), Env: env, Secrets: secrets}
 
 	return json.Marshal(aux)
@@ -98,3 +98,67 @@ func TestEnvironmentVariableMarshal(t *testing.T) {
 		assert.Equal(t, targetString, app)
 	}
 }
+
+func TestExplicitZeroValuesMarshal(t *testing.T) {
+	application := new(Application).Name("/web").Count(0).CPU(0).Memory(0).Storage(0).
+		SetBackoff(0, 0, 0).
+		SetUpgradeStrategy(*new(UpgradeStrategy).SetMinimumHealthCapacity(0).SetMaximumOverCapacity(0))
+
+	content, err := json.Marshal(application)
+	require.NoError(t, err)
+	fields := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(content, &fields))
+	for _, name := range []string{"instances", "cpus", "mem", "disk", "backoffSeconds", "backoffFactor", "maxLaunchDelaySeconds"} {
+		assert.Equal(t, 0.0, fields[name], name)
+	}
+	assert.Equal(t, map[string]interface{}{"minimumHealthCapacity": 0.0, "maximumOverCapacity": 0.0}, fields["upgradeStrategy"])
+
+	// step: the unset fields are still left out, keeping their current value on updates
+	content, err = json.Marshal(new(Application).Name("/web"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "cpus")
+	assert.NotContains(t, string(content), "instances")
+}
+
+func TestEmptyFieldsMarshal(t *testing.T) {
+	application := new(Application).Name("/web").EmptyEnvs().EmptySecrets().EmptyLabels().
+		EmptyUris().EmptyArgs().EmptyConstraints()
+
+	content, err := json.Marshal(application)
+	require.NoError(t, err)
+	fields := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(content, &fields))
+	for _, name := range []string{"env", "secrets", "labels"} {
+		assert.Equal(t, map[string]interface{}{}, fields[name], name)
+	}
+	for _, name := range []string{"uris", "args", "constraints"} {
+		assert.Equal(t, []interface{}{}, fields[name], name)
+	}
+
+	// step: the unset fields are left out, keeping their current value on updates
+	content, err = json.Marshal(new(Application).Name("/web"))
+	require.NoError(t, err)
+	for _, name := range []string{"env", "secrets", "labels", "uris", "args", "constraints"} {
+		assert.NotContains(t, string(content), `"`+name+`"`, name)
+	}
+
+	// step: the secrets are added to the environment variables
+	content, err = json.Marshal(new(Application).Name("/web").AddSecret("TOKEN", "token", "/secrets/token"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"env":{"TOKEN":{"secret":"token"}}`)
+}
+
+func TestTTYMarshal(t *testing.T) {
+	var application Application
+	require.NoError(t, json.Unmarshal([]byte(`{"id": "/debug", "cmd": "sh", "tty": true}`), &application))
+	require.NotNil(t, application.TTY)
+	assert.True(t, *application.TTY)
+
+	content, err := json.Marshal(&application)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"tty":true`)
+
+	content, err = json.Marshal(new(Application).Name("/debug").SetTTY(false))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"tty":false`)
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// the first Marathon version supporting the fetch field
+	fetchMinVersion = "0.15.0"
+	// the first Marathon version supporting the portDefinitions field
+	portDefinitionsMinVersion = "0.16.0"
+	// the first Marathon version supporting the networks field
+	networksMinVersion = "1.5.0"
+)
+
+// archiveExtensions are the extensions of URIs the Mesos fetcher extracts by default
+var archiveExtensions = []string{".tgz", ".tar.gz", ".tbz2", ".tar.bz2", ".txz", ".tar.xz", ".zip"}
+
+// ModernizeApplication rewrites the deprecated fields of an application into their modern
+// equivalents supported by the Marathon server and returns a description of each transformation
+// applied, e.g. uris to fetch, ports to portDefinitions and the docker network to networks. The
+// port mappings are moved to the container or to docker, depending on the Marathon version.
+//		application:	the application to rewrite in place
+func (r *marathonClient) ModernizeApplication(application *Application) ([]string, error) {
+	info, err := r.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	return modernizeApplication(application, info.Version), nil
+}
+
+// modernizeApplication rewrites the deprecated fields of the application supported by the given
+// Marathon version. An empty version is considered to be the latest Marathon version.
+func modernizeApplication(application *Application, version string) []string {
+	var report []string
+
+	// step: convert the uris into fetch URIs
+	if application.Uris != nil && len(*application.Uris) > 0 && versionAtLeast(version, fetchMinVersion) {
+		for _, uri := range *application.Uris {
+			application.AddFetchURIs(Fetch{URI: uri, Extract: isArchive(uri)})
+		}
+		report = append(report, fmt.Sprintf("converted %d uris into fetch URIs", len(*application.Uris)))
+		application.Uris = nil
+	}
+
+	// step: convert the ports into port definitions
+	if len(application.Ports) > 0 && versionAtLeast(version, portDefinitionsMinVersion) {
+		if application.PortDefinitions == nil || len(*application.PortDefinitions) == 0 {
+			for _, port := range application.Ports {
+				definition := PortDefinition{Protocol: "tcp"}
+				application.AddPortDefinition(*definition.SetPort(port))
+			}
+			report = append(report, fmt.Sprintf("converted %d ports into port definitions", len(application.Ports)))
+		} else {
+			report = append(report, "dropped ports in favour of the existing port definitions")
+		}
+		application.Ports = nil
+	}
+
+	// step: move the port mappings to the location supported by the Marathon version
+	if container := application.Container; container != nil && container.Docker != nil {
+		if versionAtLeast(version, networksMinVersion) {
+			if mappings := container.Docker.PortMappings; mappings != nil && len(*mappings) > 0 && container.PortMappings == nil {
+				container.PortMappings = mappings
+				container.Docker.PortMappings = nil
+				report = append(report, fmt.Sprintf("moved %d docker port mappings to the container", len(*mappings)))
+			}
+		} else if mappings := container.PortMappings; mappings != nil && len(*mappings) > 0 && container.Docker.PortMappings == nil {
+			dropped := false
+			for i := range *mappings {
+				dropped = dropped || len((*mappings)[i].NetworkNames) > 0
+				(*mappings)[i].NetworkNames = nil
+			}
+			container.Docker.PortMappings = mappings
+			container.PortMappings = nil
+			report = append(report, fmt.Sprintf("moved %d container port mappings to docker", len(*mappings)))
+			if dropped {
+				report = append(report, "dropped the network names of the port mappings, unsupported before Marathon 1.5")
+			}
+		}
+	}
+
+	// step: convert the docker network into networks
+	if application.Container != nil && application.Container.Docker != nil && application.Container.Docker.Network != "" &&
+		versionAtLeast(version, networksMinVersion) {
+		network := application.Container.Docker.Network
+		switch network {
+		case "BRIDGE":
+			application.AddNetwork("", BridgeNetworkMode)
+		case "HOST":
+			application.AddNetwork("", HostNetworkMode)
+		case "USER":
+			var name string
+			if application.IPAddressPerTask != nil {
+				name = application.IPAddressPerTask.NetworkName
+			}
+			application.AddNetwork(name, ContainerNetworkMode)
+		default:
+			report = append(report, fmt.Sprintf("left unknown docker network '%s' untouched", network))
+			return report
+		}
+		application.Container.Docker.Network = ""
+		report = append(report, fmt.Sprintf("converted docker network '%s' into networks", network))
+		// step: Marathon 1.5 rejects the ipAddress along with the networks
+		if application.IPAddressPerTask != nil {
+			application.IPAddressPerTask = nil
+			report = append(report, "dropped the ipAddress in favour of the networks")
+		}
+	}
+
+	return report
+}
+
+// isArchive checks if the uri refers to an archive extracted by the Mesos fetcher
+func isArchive(uri string) bool {
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(uri, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionAtLeast checks if the Marathon version is at least the minimum version. Pre-release
+// suffixes (e.g. 1.5.0-RC1) are ignored and an empty version is considered to be the latest.
+func versionAtLeast(version, minimum string) bool {
+	if version == "" {
+		return true
+	}
+	current, required := parseVersion(version), parseVersion(minimum)
+	for i := range required {
+		if current[i] != required[i] {
+			return current[i] > required[i]
+		}
+	}
+	return true
+}
+
+// parseVersion parses the major, minor and patch numbers of a Marathon version
+func parseVersion(version string) [3]int {
+	var parsed [3]int
+	version = strings.SplitN(strings.TrimPrefix(version, "v"), "-", 2)[0]
+	for i, part := range strings.SplitN(version, ".", 3) {
+		parsed[i], _ = strconv.Atoi(part)
+	}
+	return parsed
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModernizeApplication(t *testing.T) {
+	app := NewDockerApplication().Name("modern").AddUris("http://example.com/bundle.tgz", "http://example.com/run.sh")
+	app.Ports = []int{8080, 9090}
+	app.Container.Docker.Bridged()
+
+	report := modernizeApplication(app, "1.5.1")
+	assert.Len(t, report, 3)
+
+	assert.Nil(t, app.Uris)
+	require.NotNil(t, app.Fetch)
+	assert.Equal(t, []Fetch{
+		{URI: "http://example.com/bundle.tgz", Extract: true},
+		{URI: "http://example.com/run.sh"},
+	}, *app.Fetch)
+
+	assert.Nil(t, app.Ports)
+	require.NotNil(t, app.PortDefinitions)
+	require.Len(t, *app.PortDefinitions, 2)
+	assert.Equal(t, 8080, *(*app.PortDefinitions)[0].Port)
+	assert.Equal(t, "tcp", (*app.PortDefinitions)[1].Protocol)
+
+	assert.Empty(t, app.Container.Docker.Network)
+	require.NotNil(t, app.Networks)
+	assert.Equal(t, []PodNetwork{{Mode: BridgeNetworkMode}}, *app.Networks)
+}
+
+func TestModernizeApplicationUserNetwork(t *testing.T) {
+	app := NewDockerApplication()
+	app.Container.Docker.Network = "USER"
+	app.IPAddressPerTask = &IPAddressPerTask{NetworkName: "dcos"}
+
+	report := modernizeApplication(app, "1.5.0")
+	assert.Equal(t, []string{
+		"converted docker network 'USER' into networks",
+		"dropped the ipAddress in favour of the networks",
+	}, report)
+	assert.Nil(t, app.IPAddressPerTask)
+	require.NotNil(t, app.Networks)
+	assert.Equal(t, []PodNetwork{{Name: "dcos", Mode: ContainerNetworkMode}}, *app.Networks)
+}
+
+func TestModernizeApplicationOldVersion(t *testing.T) {
+	app := NewDockerApplication().AddUris("http://example.com/run.sh")
+	app.Ports = []int{8080}
+	app.Container.Docker.Host()
+
+	report := modernizeApplication(app, "1.1.0")
+	assert.Len(t, report, 2)
+	assert.Nil(t, app.Uris)
+	assert.Nil(t, app.Ports)
+	assert.Equal(t, "HOST", app.Container.Docker.Network)
+	assert.Nil(t, app.Networks)
+
+	app = new(Application).AddUris("http://example.com/run.sh")
+	assert.Empty(t, modernizeApplication(app, "0.14.1"))
+	assert.Len(t, *app.Uris, 1)
+}
+
+func TestModernizeApplicationPortMappings(t *testing.T) {
+	app := NewDockerApplication()
+	app.Container.Docker.Expose(8080)
+
+	report := modernizeApplication(app, "1.5.0")
+	assert.Equal(t, []string{"moved 1 docker port mappings to the container"}, report)
+	assert.Nil(t, app.Container.Docker.PortMappings)
+	assert.Equal(t, []PortMapping{*NewPortMapping(8080, "tcp")}, *app.Container.PortMappings)
+
+	// step: the container port mappings are moved back to docker on older versions
+	app = NewDockerApplication()
+	app.Container.ExposePort(*NewPortMapping(8080, "tcp").SetNetworkNames("front", "back"))
+	report = modernizeApplication(app, "1.4.9")
+	assert.Equal(t, []string{
+		"moved 1 container port mappings to docker",
+		"dropped the network names of the port mappings, unsupported before Marathon 1.5",
+	}, report)
+	assert.Nil(t, app.Container.PortMappings)
+	assert.Equal(t, []PortMapping{*NewPortMapping(8080, "tcp")}, *app.Container.Docker.PortMappings)
+}
+
+func TestModernizeApplicationClient(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	// the fake Marathon reports version 0.7.0 which supports neither of the modern fields
+	app := new(Application).AddUris("http://example.com/run.sh")
+	report, err := endpoint.Client.ModernizeApplication(app)
+	assert.NoError(t, err)
+	assert.Empty(t, report)
+	assert.Len(t, *app.Uris, 1)
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	assert.True(t, versionAtLeast("1.5.0", "1.5.0"))
+	assert.True(t, versionAtLeast("1.10.2", "1.5.0"))
+	assert.True(t, versionAtLeast("1.5.0-RC1", "1.5.0"))
+	assert.True(t, versionAtLeast("", "1.5.0"))
+	assert.False(t, versionAtLeast("1.4.9", "1.5.0"))
+	assert.False(t, versionAtLeast("0.7.0-SNAPSHOT", "0.15.0"))
+}
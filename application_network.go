@@ -0,0 +1,107 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+// SetNetworkMode configures the networks of the application and moves its ports to where the
+// network mode of Marathon 1.5 expects them: the host mode declares them in portDefinitions,
+// the bridge and container modes in the port mappings of the container. The ports already
+// declared in either place are converted, the deprecated docker network and port mappings as
+// well as the ipAddress dropped, and the emptied fields sent empty so an update clears them.
+//		name:	the name of the network, only used for the container mode
+//		mode:	the mode of the network
+func (r *Application) SetNetworkMode(name string, mode PodNetworkMode) *Application {
+	if mode != ContainerNetworkMode {
+		name = ""
+	}
+	mappings, definitions := r.networkPorts()
+	r.EmptyNetworks()
+	r.AddNetwork(name, mode)
+	r.IPAddressPerTask = nil
+
+	if mode == HostNetworkMode {
+		if definitions == nil {
+			definitions = portDefinitionsOf(mappings)
+		}
+		r.PortDefinitions = &definitions
+		if r.Container != nil {
+			r.Container.EmptyPortMappings()
+		}
+	} else {
+		if mappings == nil {
+			mappings = portMappingsOf(definitions)
+		}
+		// step: the bridge and container modes require a container
+		if r.Container == nil {
+			r.Container = &Container{Type: "MESOS"}
+		}
+		r.Container.PortMappings = &mappings
+		r.EmptyPortDefinitions()
+	}
+	if r.Container != nil && r.Container.Docker != nil {
+		r.Container.Docker.Network = ""
+		r.Container.Docker.PortMappings = nil
+	}
+
+	return r
+}
+
+// networkPorts returns the port mappings and the port definitions of the application, nil when
+// it has none, the port mappings of the container taking precedence over the ones of docker
+func (r *Application) networkPorts() ([]PortMapping, []PortDefinition) {
+	var mappings []PortMapping
+	var definitions []PortDefinition
+	if container := r.Container; container != nil {
+		switch {
+		case container.PortMappings != nil && len(*container.PortMappings) > 0:
+			mappings = append(mappings, *container.PortMappings...)
+		case container.Docker != nil && container.Docker.PortMappings != nil && len(*container.Docker.PortMappings) > 0:
+			mappings = append(mappings, *container.Docker.PortMappings...)
+		}
+	}
+	if r.PortDefinitions != nil && len(*r.PortDefinitions) > 0 {
+		definitions = append(definitions, *r.PortDefinitions...)
+	}
+
+	return mappings, definitions
+}
+
+// portDefinitionsOf converts the port mappings into port definitions of their host ports
+func portDefinitionsOf(mappings []PortMapping) []PortDefinition {
+	definitions := []PortDefinition{}
+	for _, mapping := range mappings {
+		definition := PortDefinition{Name: mapping.Name, Protocol: mapping.Protocol, Labels: mapping.Labels}
+		definition.SetPort(mapping.HostPort)
+		definitions = append(definitions, definition)
+	}
+
+	return definitions
+}
+
+// portMappingsOf converts the port definitions into port mappings of the same container ports,
+// the host ports being assigned by Marathon
+func portMappingsOf(definitions []PortDefinition) []PortMapping {
+	mappings := []PortMapping{}
+	for _, definition := range definitions {
+		mapping := PortMapping{Name: definition.Name, Protocol: definition.Protocol, Labels: definition.Labels}
+		if definition.Port != nil {
+			mapping.ContainerPort = *definition.Port
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	return mappings
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetNetworkModeHost(t *testing.T) {
+	application := NewDockerApplication().Name("/web")
+	application.Container.Docker.Bridged().ExposePort(PortMapping{ContainerPort: 80, HostPort: 31080, Name: "http", Protocol: "tcp"})
+
+	application.SetNetworkMode("ignored", HostNetworkMode)
+	require.NotNil(t, application.Networks)
+	assert.Equal(t, []networkCheck{{"", HostNetworkMode}}, networksOf(application))
+	require.NotNil(t, application.PortDefinitions)
+	require.Len(t, *application.PortDefinitions, 1)
+	definition := (*application.PortDefinitions)[0]
+	assert.Equal(t, 31080, *definition.Port)
+	assert.Equal(t, "http", definition.Name)
+	assert.Equal(t, "tcp", definition.Protocol)
+	// step: the deprecated fields are dropped and the port mappings sent empty
+	assert.Empty(t, application.Container.Docker.Network)
+	assert.Nil(t, application.Container.Docker.PortMappings)
+	require.NotNil(t, application.Container.PortMappings)
+	assert.Empty(t, *application.Container.PortMappings)
+	assert.NoError(t, application.Validate())
+}
+
+func TestSetNetworkModeBridge(t *testing.T) {
+	application := NewDockerApplication().Name("/web")
+	application.SetIPAddressPerTask(IPAddressPerTask{NetworkName: "old"})
+	application.AddPortDefinition(*new(PortDefinition).SetPort(8080).SetName("http"))
+
+	application.SetNetworkMode("", BridgeNetworkMode)
+	assert.Equal(t, []networkCheck{{"", BridgeNetworkMode}}, networksOf(application))
+	require.NotNil(t, application.Container.PortMappings)
+	require.Len(t, *application.Container.PortMappings, 1)
+	mapping := (*application.Container.PortMappings)[0]
+	assert.Equal(t, 8080, mapping.ContainerPort)
+	assert.Equal(t, 0, mapping.HostPort)
+	assert.Equal(t, "http", mapping.Name)
+	require.NotNil(t, application.PortDefinitions)
+	assert.Empty(t, *application.PortDefinitions)
+	assert.Nil(t, application.IPAddressPerTask)
+}
+
+func TestSetNetworkModeContainer(t *testing.T) {
+	application := NewDockerApplication().Name("/web")
+	application.Container.ExposePort(PortMapping{ContainerPort: 80, Name: "http"})
+	application.SetNetworkMode("", BridgeNetworkMode)
+
+	// step: switching between the container modes keeps the port mappings
+	application.SetNetworkMode("overlay", ContainerNetworkMode)
+	assert.Equal(t, []networkCheck{{"overlay", ContainerNetworkMode}}, networksOf(application))
+	require.Len(t, *application.Container.PortMappings, 1)
+	assert.Equal(t, 80, (*application.Container.PortMappings)[0].ContainerPort)
+}
+
+func TestSetNetworkModeWithoutContainer(t *testing.T) {
+	application := new(Application).Name("/web")
+	application.SetNetworkMode("overlay", ContainerNetworkMode)
+	require.NotNil(t, application.Container)
+	assert.Equal(t, "MESOS", application.Container.Type)
+	require.NotNil(t, application.Container.PortMappings)
+	assert.Empty(t, *application.Container.PortMappings)
+
+	application = new(Application).Name("/web")
+	application.SetNetworkMode("", HostNetworkMode)
+	assert.Nil(t, application.Container)
+	require.NotNil(t, application.PortDefinitions)
+	assert.Empty(t, *application.PortDefinitions)
+}
+
+// networkCheck is the name and the mode of a network of an application
+type networkCheck struct {
+	Name string
+	Mode PodNetworkMode
+}
+
+func networksOf(application *Application) []networkCheck {
+	var networks []networkCheck
+	for _, network := range *application.Networks {
+		networks = append(networks, networkCheck{network.Name, network.Mode})
+	}
+	return networks
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// EachApplication retrieves the applications like Applications, calling the function with each
+// application as it is decoded from the response, so the applications of large clusters are never
+// all held in memory. The iteration stops on the first error of the function, which is returned.
+//		v:		the query parameters of the applications, e.g. the embedded resources
+//		fn:		the function called with each application
+func (r *marathonClient) EachApplication(v url.Values, fn func(*Application) error) error {
+	path := marathonAPIApps
+	if query := v.Encode(); query != "" {
+		path += "?" + query
+	}
+	body, err := r.apiStream("EachApplication", path, "application/json")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return decodeApplications(json.NewDecoder(body), fn)
+}
+
+// decodeApplications decodes the apps of an Applications document one at a time
+func decodeApplications(decoder *json.Decoder, fn func(*Application) error) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if token != "apps" {
+			// step: skip the value of the other fields
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			application := new(Application)
+			if err := decoder.Decode(application); err != nil {
+				return fmt.Errorf("failed to unmarshal response from Marathon: %w", err)
+			}
+			if err := fn(application); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+// expectDelim reads the next token, failing unless it is the delimiter
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		return fmt.Errorf("failed to unmarshal response from Marathon: unexpected end of the response")
+	}
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("failed to unmarshal response from Marathon: expected %s, found %v", delim, token)
+	}
+	return nil
+}
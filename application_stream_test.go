@@ -0,0 +1,102 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEachApplication(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	applications, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+
+	var streamed []Application
+	err = endpoint.Client.EachApplication(nil, func(application *Application) error {
+		streamed = append(streamed, *application)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, applications.Apps, streamed)
+
+	v := url.Values{}
+	v.Set("cmd", "nginx")
+	count := 0
+	require.NoError(t, endpoint.Client.EachApplication(v, func(*Application) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 1, count)
+
+	// step: the iteration stops on the first error
+	stop := errors.New("stop")
+	count = 0
+	err = endpoint.Client.EachApplication(nil, func(*Application) error {
+		count++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestEachApplicationCompressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Encoding", "gzip")
+		compressed := gzip.NewWriter(writer)
+		json.NewEncoder(compressed).Encode(Applications{Apps: []Application{{ID: "/a"}, {ID: "/b"}}})
+		compressed.Close()
+	}))
+	defer server.Close()
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	var ids []string
+	require.NoError(t, client.EachApplication(nil, func(application *Application) error {
+		ids = append(ids, application.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"/a", "/b"}, ids)
+}
+
+func TestDecodeApplications(t *testing.T) {
+	var ids []string
+	collect := func(application *Application) error {
+		ids = append(ids, application.ID)
+		return nil
+	}
+	content := `{"meta": {"nested": [1, {"apps": []}]}, "apps": [{"id": "/a"}, {"id": "/b"}], "after": true}`
+	require.NoError(t, decodeApplications(json.NewDecoder(strings.NewReader(content)), collect))
+	assert.Equal(t, []string{"/a", "/b"}, ids)
+
+	for _, content := range []string{`[]`, `{"apps": {}}`, `{"apps": [{"id": "/a"}`, `{"apps": [{"id": 1}]}`} {
+		assert.Error(t, decodeApplications(json.NewDecoder(strings.NewReader(content)), collect), content)
+	}
+}
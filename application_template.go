@@ -0,0 +1,159 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ApplicationTemplate is an application definition which can be extended by overlays, e.g. a
+// golden base definition shared by many services with thin per-service overrides.
+//
+// Overlays are merged on top of their parent template:
+//  - environment variables, labels and secrets are merged, the overlay taking precedence
+//  - health checks are merged, an overlay health check replacing the parent health check with
+//    the same protocol, port and path
+//  - any other field set on the overlay replaces the field of the parent
+type ApplicationTemplate struct {
+	parent      *ApplicationTemplate
+	application *Application
+}
+
+// NewApplicationTemplate creates a template from a base application
+//		base:		the base application definition
+func NewApplicationTemplate(base *Application) *ApplicationTemplate {
+	return &ApplicationTemplate{application: base}
+}
+
+// Extend creates a template inheriting from this template with the overlay applied on top
+//		overlay:	the fields to merge into the definition of this template
+func (t *ApplicationTemplate) Extend(overlay *Application) *ApplicationTemplate {
+	return &ApplicationTemplate{parent: t, application: overlay}
+}
+
+// Render produces the final application of the template. The application is a copy, so
+// modifying it leaves the template untouched.
+//		id:			the id of the application, the id of the template is kept if empty
+func (t *ApplicationTemplate) Render(id string) (*Application, error) {
+	var overlays []*Application
+	for template := t; template != nil; template = template.parent {
+		overlays = append([]*Application{template.application}, overlays...)
+	}
+
+	application := new(Application)
+	for _, overlay := range overlays {
+		mergeApplication(application, overlay)
+	}
+
+	// step: copy the merged application, so no field is shared with the templates
+	rendered := application.Clone()
+	if id != "" {
+		rendered.Name(id)
+	}
+	return rendered, nil
+}
+
+// mergeApplication merges the fields set on the overlay into the application
+func mergeApplication(application, overlay *Application) {
+	target := reflect.ValueOf(application).Elem()
+	source := reflect.ValueOf(overlay).Elem()
+	for i := 0; i < source.NumField(); i++ {
+		field := source.Field(i)
+		if isZeroValue(field) {
+			continue
+		}
+		switch source.Type().Field(i).Name {
+		case "Env":
+			application.Env = mergeStringMaps(application.Env, overlay.Env)
+		case "Labels":
+			application.Labels = mergeStringMaps(application.Labels, overlay.Labels)
+		case "Secrets":
+			secrets := map[string]Secret{}
+			if application.Secrets != nil {
+				for k, v := range *application.Secrets {
+					secrets[k] = v
+				}
+			}
+			for k, v := range *overlay.Secrets {
+				secrets[k] = v
+			}
+			application.Secrets = &secrets
+		case "HealthChecks":
+			application.HealthChecks = mergeHealthChecks(application.HealthChecks, overlay.HealthChecks)
+		default:
+			target.Field(i).Set(field)
+		}
+	}
+}
+
+func mergeStringMaps(base, overlay *map[string]string) *map[string]string {
+	merged := map[string]string{}
+	if base != nil {
+		for k, v := range *base {
+			merged[k] = v
+		}
+	}
+	for k, v := range *overlay {
+		merged[k] = v
+	}
+	return &merged
+}
+
+// mergeHealthChecks merges the health checks, replacing the ones checking the same endpoint
+func mergeHealthChecks(base, overlay *[]HealthCheck) *[]HealthCheck {
+	var merged []HealthCheck
+	if base != nil {
+		merged = append(merged, *base...)
+	}
+	for _, check := range *overlay {
+		replaced := false
+		for i := range merged {
+			if healthCheckKey(merged[i]) == healthCheckKey(check) {
+				merged[i] = check
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, check)
+		}
+	}
+	return &merged
+}
+
+// healthCheckKey identifies the endpoint checked by a health check
+func healthCheckKey(check HealthCheck) string {
+	key := check.Protocol
+	if check.PortIndex != nil {
+		key += fmt.Sprintf("|index:%d", *check.PortIndex)
+	}
+	if check.Port != nil {
+		key += fmt.Sprintf("|port:%d", *check.Port)
+	}
+	if check.Path != nil {
+		key += "|path:" + *check.Path
+	}
+	if check.Command != nil {
+		key += "|command:" + check.Command.Value
+	}
+	return key
+}
+
+func isZeroValue(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationTemplate(t *testing.T) {
+	base := NewDockerApplication().
+		CPU(0.5).
+		Memory(256).
+		Count(2).
+		AddEnv("LOG_LEVEL", "info").
+		AddEnv("REGION", "eu").
+		AddLabel("team", "platform").
+		AddHealthCheck(*NewDefaultHealthCheck().SetPath("/health"))
+	base.Container.Docker.Container("golden:1.0")
+
+	overlay := new(Application).
+		Memory(512).
+		AddEnv("LOG_LEVEL", "debug").
+		AddLabel("service", "billing").
+		AddHealthCheck(*NewDefaultHealthCheck().SetPath("/health").SetMaxConsecutiveFailures(5)).
+		AddHealthCheck(*NewDefaultHealthCheck().SetPath("/ready"))
+
+	template := NewApplicationTemplate(base).Extend(overlay)
+	application, err := template.Render("billing")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/billing", application.ID)
+	assert.Equal(t, 0.5, *application.CPUs)
+	assert.Equal(t, 512.0, *application.Mem)
+	assert.Equal(t, 2, *application.Instances)
+	assert.Equal(t, "golden:1.0", application.Container.Docker.Image)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "debug", "REGION": "eu"}, *application.Env)
+	assert.Equal(t, map[string]string{"team": "platform", "service": "billing"}, *application.Labels)
+	require.Len(t, *application.HealthChecks, 2)
+	assert.Equal(t, 5, *(*application.HealthChecks)[0].MaxConsecutiveFailures)
+	assert.Equal(t, "/ready", *(*application.HealthChecks)[1].Path)
+
+	// step: the templates are left untouched
+	assert.Equal(t, "info", (*base.Env)["LOG_LEVEL"])
+	assert.Len(t, *base.HealthChecks, 1)
+	application.Container.Docker.Container("changed")
+	assert.Equal(t, "golden:1.0", base.Container.Docker.Image)
+}
+
+func TestApplicationTemplateChain(t *testing.T) {
+	base := NewApplicationTemplate(new(Application).Name("base").Command("run").AddLabel("tier", "base"))
+	web := base.Extend(new(Application).AddLabel("tier", "web"))
+	canary := web.Extend(new(Application).Count(1))
+
+	application, err := canary.Render("")
+	require.NoError(t, err)
+	assert.Equal(t, "/base", application.ID)
+	assert.Equal(t, "run", *application.Cmd)
+	assert.Equal(t, "web", (*application.Labels)["tier"])
+	assert.Equal(t, 1, *application.Instances)
+
+	application, err = base.Render("other")
+	require.NoError(t, err)
+	assert.Equal(t, "base", (*application.Labels)["tier"])
+	assert.Nil(t, application.Instances)
+}
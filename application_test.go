@@ -17,6 +17,8 @@ limitations under the License.
 package marathon
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -124,9 +126,9 @@ func TestApplicationCommand(t *testing.T) {
 
 func TestApplicationCPU(t *testing.T) {
 	app := NewDockerApplication()
-	assert.Equal(t, 0.0, app.CPUs)
+	assert.Nil(t, app.CPUs)
 	app.CPU(0.1)
-	assert.Equal(t, 0.1, app.CPUs)
+	assert.Equal(t, 0.1, *app.CPUs)
 }
 
 func TestApplicationSetGPUs(t *testing.T) {
@@ -234,6 +236,18 @@ func TestApplicationSetExecutor(t *testing.T) {
 	assert.Equal(t, "", *app.Executor)
 }
 
+func TestApplicationSetExecutorResources(t *testing.T) {
+	app := new(Application).Name("/custom").SetExecutor("/opt/executor")
+	assert.Nil(t, app.ExecutorResources)
+
+	app.SetExecutorResources(ExecutorResources{Cpus: 0.1, Mem: 32})
+	assert.Equal(t, &ExecutorResources{Cpus: 0.1, Mem: 32}, app.ExecutorResources)
+
+	content, err := json.Marshal(app)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"executorResources":{"cpus":0.1,"mem":32}`)
+}
+
 func TestApplicationHealthChecks(t *testing.T) {
 	app := NewDockerApplication()
 	assert.Nil(t, app.HealthChecks)
@@ -459,6 +473,203 @@ func TestRestartApplication(t *testing.T) {
 	assert.Nil(t, id)
 }
 
+func TestRestartApplicationBy(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	id, err := endpoint.Client.RestartApplicationBy(fakeAppName, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", id.DeploymentID)
+	// step: the deployment isn't running anymore
+	id, err = endpoint.Client.RestartApplicationBy(fakeAppName, &RestartAppOpts{Wait: true})
+	require.NoError(t, err)
+	assert.NotNil(t, id)
+	id, err = endpoint.Client.RestartApplicationBy("/not/there", &RestartAppOpts{Wait: true})
+	assert.Error(t, err)
+	assert.Nil(t, id)
+}
+
+func TestRestartApplicationByWait(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 5 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{scope: "app-deployment"}})
+	defer endpoint.Close()
+
+	id, err := endpoint.Client.RestartApplicationBy("/app", &RestartAppOpts{Wait: true, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "deployment-1", id.DeploymentID)
+	// step: the deployment was polled until it finished
+	assert.Len(t, endpoint.Server.Requests("GET", "/v2/deployments"), 4)
+}
+
+func TestRestartApplicationByTimeout(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 5 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{scope: "app-deployment-stuck"}})
+	defer endpoint.Close()
+
+	// step: the deployment is returned along with the failed wait
+	id, err := endpoint.Client.RestartApplicationBy("/app", &RestartAppOpts{Wait: true, Timeout: 50 * time.Millisecond})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	require.NotNil(t, id)
+	assert.Equal(t, "deployment-1", id.DeploymentID)
+}
+
+// scalings returns the instances the application was scaled to, in order
+func scalings(t *testing.T, endpoint *endpoint) []int {
+	var instances []int
+	for _, request := range endpoint.Server.Requests("PUT", "/v2/apps/app") {
+		var changes Application
+		require.NoError(t, json.Unmarshal(request.body, &changes))
+		instances = append(instances, *changes.Instances)
+	}
+	return instances
+}
+
+func TestScaleBy(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{scope: "app-scaling"}})
+	defer endpoint.Close()
+
+	// step: the delta applies to the instances as changed by the conflicting deployments
+	id, _, err := endpoint.Client.ScaleBy("/app", 3, &ScaleAppOpts{Wait: true})
+	require.NoError(t, err)
+	assert.Equal(t, "scale-1", id.DeploymentID)
+	assert.Equal(t, []int{5, 6, 7}, scalings(t, endpoint))
+
+	// step: the instances never go below zero
+	_, _, err = endpoint.Client.ScaleBy("/app", -10, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 6, 7, 0}, scalings(t, endpoint))
+}
+
+func TestScaleByConflict(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{scope: "app-scaling-locked"}})
+	defer endpoint.Close()
+
+	id, _, err := endpoint.Client.ScaleBy("/app", 1, nil)
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.Nil(t, id)
+	assert.Len(t, scalings(t, endpoint), scaleByAttempts)
+}
+
+func TestScaleApplicationWait(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 5 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{scope: "app-deployment"}})
+	defer endpoint.Close()
+
+	id, _, err := endpoint.Client.ScaleApplication("/app", 2, &ScaleAppOpts{Wait: true, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "deployment-1", id.DeploymentID)
+	assert.Len(t, endpoint.Server.Requests("GET", "/v2/deployments"), 4)
+}
+
+// forced returns whether the changes of the application were forced, in order
+func forced(endpoint *endpoint, method string) []bool {
+	var forced []bool
+	for _, request := range endpoint.Server.Requests(method, "/v2/apps/app") {
+		forced = append(forced, strings.Contains(request.uri, "force=true"))
+	}
+	return forced
+}
+
+func TestUpdateApplicationBy(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{server: &serverConfig{scope: "app-locked"}})
+	defer endpoint.Close()
+	client := endpoint.Client
+	application := NewDockerApplication().Name("/app")
+
+	// step: the conflict is returned when not overridden
+	_, err := client.UpdateApplicationBy(application, nil)
+	assert.True(t, errors.Is(err, ErrConflict))
+	var conflict error
+	_, err = client.UpdateApplicationBy(application, &UpdateAppOpts{ForceOnConflict: func(err error) bool {
+		conflict = err
+		return false
+	}})
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.True(t, errors.Is(conflict, ErrConflict))
+	assert.Equal(t, []bool{false, false}, forced(endpoint, "PUT"))
+
+	// step: the update is forced when decided by the caller
+	id, err := client.UpdateApplicationBy(application, &UpdateAppOpts{ForceOnConflict: func(error) bool { return true }})
+	require.NoError(t, err)
+	assert.Equal(t, "forced-1", id.DeploymentID)
+	assert.Equal(t, []bool{false, true}, forced(endpoint, "PUT")[2:])
+
+	_, err = client.UpdateApplicationBy(application, &UpdateAppOpts{Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, forced(endpoint, "PUT")[4:])
+}
+
+func TestSetApplicationVersionBy(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{server: &serverConfig{scope: "app-locked"}})
+	defer endpoint.Close()
+	version := &ApplicationVersion{Version: "2014-03-01T23:29:30.158Z"}
+
+	_, err := endpoint.Client.SetApplicationVersionBy("/app", version, nil)
+	assert.True(t, errors.Is(err, ErrConflict))
+	id, err := endpoint.Client.SetApplicationVersionBy("/app", version, &UpdateAppOpts{ForceOnConflict: func(error) bool { return true }})
+	require.NoError(t, err)
+	assert.Equal(t, "forced-1", id.DeploymentID)
+	assert.Equal(t, []bool{false, false, true}, forced(endpoint, "PUT"))
+}
+
+func TestUpdateApplicationRetryOnConflict(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 5 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{scope: "app-deploying"}})
+	defer endpoint.Close()
+	application := NewDockerApplication().Name("/app")
+
+	// step: the conflicting deployment is waited on before retrying
+	id, err := endpoint.Client.UpdateApplicationBy(application, &UpdateAppOpts{RetryOnConflict: true, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "update-1", id.DeploymentID)
+	assert.Len(t, endpoint.Server.Requests("PUT", "/v2/apps/app"), 2)
+	assert.Len(t, endpoint.Server.Requests("GET", "/v2/deployments"), 4)
+}
+
+func TestUpdateApplicationRetryOnConflictAttempts(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{server: &serverConfig{scope: "app-locked"}})
+	defer endpoint.Close()
+
+	// step: the update gives up on an application which stays locked
+	_, err := endpoint.Client.UpdateApplicationBy(NewDockerApplication().Name("/app"), &UpdateAppOpts{RetryOnConflict: true})
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.Equal(t, []string{"locking-1"}, ConflictingDeployments(err))
+	assert.Len(t, endpoint.Server.Requests("PUT", "/v2/apps/app"), conflictAttempts)
+}
+
+func TestDeleteApplicationBy(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{server: &serverConfig{scope: "app-locked"}})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	// step: the conflict lists the locking deployments for the caller to decide
+	_, err := client.DeleteApplicationBy("/app", nil)
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.Equal(t, []string{"locking-1"}, ConflictingDeployments(err))
+
+	id, err := client.DeleteApplicationBy("/app", &DeleteAppOpts{ForceOnConflict: func(err error) bool {
+		return len(ConflictingDeployments(err)) == 1
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "forced-1", id.DeploymentID)
+	assert.Equal(t, []bool{false, false, true}, forced(endpoint, "DELETE"))
+
+	// step: an application already deleted is only ignored when asked to
+	_, err = client.DeleteApplicationBy("/missing", nil)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	id, err = client.DeleteApplicationBy("/missing", &DeleteAppOpts{IgnoreNotFound: true})
+	assert.NoError(t, err)
+	assert.Nil(t, id)
+}
+
 func TestApplicationUris(t *testing.T) {
 	app := NewDockerApplication()
 	assert.Nil(t, app.Uris)
@@ -503,6 +714,36 @@ func TestSetApplicationVersion(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestHasApplication(t *testing.T) {
+	var paths []string
+	config := NewDefaultConfig()
+	config.Middleware = []Middleware{func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			paths = append(paths, request.URL.Path)
+			return next.Do(request)
+		})
+	}}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	found, err := endpoint.Client.HasApplication(fakeAppName)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = endpoint.Client.HasApplication("no_such_app")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// step: the applications aren't listed
+	assert.Equal(t, []string{"/v2/apps/fake-app", "/v2/apps/no_such_app"}, paths)
+
+	// step: the not found errors are recognised through the wrapping clients
+	wrapped := NewCachingClient(NewScopedClient(endpoint.Client, "/"), nil)
+	found, err = wrapped.HasApplication("no_such_app")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
 func TestHasApplicationVersion(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -545,6 +786,43 @@ func TestApplicationOK(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestApplicationAllTasksHealthy(t *testing.T) {
+	instances := 2
+	app := new(Application)
+	app.Instances = &instances
+	app.Tasks = []*Task{{ID: "web.1"}, {ID: "web.2"}}
+	app.TasksRunning = 2
+	assert.True(t, app.AllTasksHealthy())
+
+	app.AddHealthCheck(*NewDefaultHealthCheck())
+	assert.False(t, app.AllTasksHealthy())
+
+	app.Tasks[0].HealthCheckResults = []*HealthCheckResult{{Alive: true}}
+	app.Tasks[1].HealthCheckResults = []*HealthCheckResult{{Alive: false}}
+	assert.False(t, app.AllTasksHealthy())
+
+	app.Tasks[1].HealthCheckResults[0].Alive = true
+	assert.True(t, app.AllTasksHealthy())
+
+	app.TasksRunning = 1
+	assert.False(t, app.AllTasksHealthy())
+}
+
+func TestWaitOnApplicationHealthy(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 10 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	assert.NoError(t, endpoint.Client.WaitOnApplicationHealthy(fakeAppName, 50*time.Millisecond))
+
+	// step: the tasks of the unhealthy application run, but fail their health checks
+	assert.NoError(t, endpoint.Client.WaitOnApplication(fakeAppNameUnhealthy, 50*time.Millisecond))
+	err := endpoint.Client.WaitOnApplicationHealthy(fakeAppNameUnhealthy, 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Equal(t, ReasonTimeout, Reason(err))
+}
+
 func verifyApplication(application *Application, t *testing.T) {
 	assert.NotNil(t, application)
 	assert.Equal(t, application.ID, fakeAppName)
@@ -764,3 +1042,23 @@ func TestUpgradeStrategy(t *testing.T) {
 	assert.Nil(t, us.MinimumHealthCapacity)
 	assert.Nil(t, us.MaximumOverCapacity)
 }
+
+func TestApplicationSetBackoff(t *testing.T) {
+	app := NewDockerApplication()
+	assert.Nil(t, app.BackoffSeconds)
+	app.SetBackoff(1, 1.15, 3600)
+	assert.Equal(t, 1.0, *app.BackoffSeconds)
+	assert.Equal(t, 1.15, *app.BackoffFactor)
+	assert.Equal(t, 3600.0, *app.MaxLaunchDelaySeconds)
+}
+
+func TestApplicationSetRole(t *testing.T) {
+	app := new(Application).Name("/dev/web")
+	assert.Nil(t, app.Role)
+	app.SetRole("dev")
+	assert.Equal(t, "dev", *app.Role)
+
+	content, err := json.Marshal(app)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"role":"dev"`)
+}
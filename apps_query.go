@@ -0,0 +1,141 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/url"
+	"strings"
+)
+
+// AppsQuery builds the query parameters filtering the applications on the Marathon side, for
+// Applications, EachApplication and ListApplications, e.g.
+//
+//	client.Applications(marathon.NewAppsQuery().ID("/payments").LabelEquals("tier", "frontend").Values())
+type AppsQuery struct {
+	id     string
+	cmd    string
+	labels []string
+	embed  []string
+}
+
+// NewAppsQuery creates an empty query, selecting all the applications
+func NewAppsQuery() *AppsQuery {
+	return &AppsQuery{}
+}
+
+// ID selects the applications whose id contains the string
+//		id:		a part of the application ids, e.g. a group id
+func (q *AppsQuery) ID(id string) *AppsQuery {
+	q.id = id
+	return q
+}
+
+// Cmd selects the applications whose command contains the string
+//		cmd:	a part of the application commands
+func (q *AppsQuery) Cmd(cmd string) *AppsQuery {
+	q.cmd = cmd
+	return q
+}
+
+// LabelExists selects the applications having the label, whatever its value
+//		name:	the name of the label
+func (q *AppsQuery) LabelExists(name string) *AppsQuery {
+	return q.label(escapeLabelTerm(name))
+}
+
+// LabelEquals selects the applications having the label with the value
+//		name:	the name of the label
+//		value:	the value of the label
+func (q *AppsQuery) LabelEquals(name, value string) *AppsQuery {
+	return q.label(escapeLabelTerm(name) + "==" + escapeLabelTerm(value))
+}
+
+// LabelNotEquals selects the applications having the label with another value
+//		name:	the name of the label
+//		value:	the value the label doesn't have
+func (q *AppsQuery) LabelNotEquals(name, value string) *AppsQuery {
+	return q.label(escapeLabelTerm(name) + "!=" + escapeLabelTerm(value))
+}
+
+// LabelIn selects the applications having the label with one of the values
+//		name:	the name of the label
+//		values:	the values of the label
+func (q *AppsQuery) LabelIn(name string, values ...string) *AppsQuery {
+	return q.label(escapeLabelTerm(name) + " in " + labelTermSet(values))
+}
+
+// LabelNotIn selects the applications having the label with none of the values
+//		name:	the name of the label
+//		values:	the values the label doesn't have
+func (q *AppsQuery) LabelNotIn(name string, values ...string) *AppsQuery {
+	return q.label(escapeLabelTerm(name) + " notin " + labelTermSet(values))
+}
+
+// Embed embeds the resources in the applications, e.g. apps.tasks or apps.counts
+//		embed:	the resources to embed
+func (q *AppsQuery) Embed(embed ...string) *AppsQuery {
+	q.embed = append(q.embed, embed...)
+	return q
+}
+
+// Values returns the query parameters of the query
+func (q *AppsQuery) Values() url.Values {
+	v := url.Values{}
+	if q.id != "" {
+		v.Set("id", q.id)
+	}
+	if q.cmd != "" {
+		v.Set("cmd", q.cmd)
+	}
+	if len(q.labels) > 0 {
+		v.Set("label", strings.Join(q.labels, ","))
+	}
+	for _, embed := range q.embed {
+		v.Add("embed", embed)
+	}
+	return v
+}
+
+// label adds a requirement of the label selector, all the requirements having to be met
+func (q *AppsQuery) label(requirement string) *AppsQuery {
+	q.labels = append(q.labels, requirement)
+	return q
+}
+
+// labelTermSet returns the escaped set of values of a label selector, e.g. (a, b)
+func labelTermSet(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeLabelTerm(value)
+	}
+	return "(" + strings.Join(escaped, ", ") + ")"
+}
+
+// escapeLabelTerm escapes the characters of a label name or value which Marathon only accepts
+// escaped with a backslash, i.e. all but the letters, digits and -_.
+func escapeLabelTerm(term string) string {
+	var escaped strings.Builder
+	for _, c := range term {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(c)
+	}
+	return escaped.String()
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppsQuery(t *testing.T) {
+	assert.Equal(t, url.Values{}, NewAppsQuery().Values())
+
+	v := NewAppsQuery().
+		ID("/payments").
+		Cmd("nginx").
+		LabelExists("canary").
+		LabelEquals("tier", "frontend").
+		LabelNotEquals("env", "dev").
+		LabelIn("team", "red", "blue").
+		LabelNotIn("zone", "a").
+		Embed("apps.tasks", "apps.counts").
+		Values()
+	assert.Equal(t, "/payments", v.Get("id"))
+	assert.Equal(t, "nginx", v.Get("cmd"))
+	assert.Equal(t, "canary,tier==frontend,env!=dev,team in (red, blue),zone notin (a)", v.Get("label"))
+	assert.Equal(t, []string{"apps.tasks", "apps.counts"}, v["embed"])
+}
+
+func TestAppsQueryEscaping(t *testing.T) {
+	v := NewAppsQuery().LabelEquals("HAPROXY_0_VHOST", "web.example.com,api").LabelIn("owner", "a b", "c(d)").Values()
+	assert.Equal(t, `HAPROXY_0_VHOST==web.example.com\,api,owner in (a\ b, c\(d\))`, v.Get("label"))
+}
+
+func TestAppsQueryApplications(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	applications, err := endpoint.Client.Applications(NewAppsQuery().Cmd("nginx").Values())
+	require.NoError(t, err)
+	assert.Len(t, applications.Apps, 1)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+)
+
+// UploadArtifact uploads an artifact to the Marathon artifact store and returns its location
+//		name:		the path of the artifact within the store
+//		artifact:	the content of the artifact
+func (r *marathonClient) UploadArtifact(name string, artifact io.Reader) (string, error) {
+	// step: the content is buffered so the upload can be retried on another member
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", path.Base(name))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, artifact); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	response, _, err := r.apiRequest("UploadArtifact", "PUT", buildArtifactPath(name), body.Bytes(), writer.FormDataContentType(), "application/json")
+	if err != nil {
+		return "", err
+	}
+
+	if location := response.Header.Get("Location"); location != "" {
+		return location, nil
+	}
+	return "/" + buildArtifactPath(name), nil
+}
+
+// GetArtifact retrieves the content of an artifact from the Marathon artifact store, to be read as
+// it streams in and closed
+//		name:		the path of the artifact within the store
+func (r *marathonClient) GetArtifact(name string) (io.ReadCloser, error) {
+	return r.apiStream("GetArtifact", buildArtifactPath(name), "*/*")
+}
+
+// DeleteArtifact deletes an artifact from the Marathon artifact store
+//		name:		the path of the artifact within the store
+func (r *marathonClient) DeleteArtifact(name string) error {
+	return r.apiDelete("DeleteArtifact", buildArtifactPath(name), nil, nil)
+}
+
+func buildArtifactPath(name string) string {
+	return fmt.Sprintf("%s/%s", marathonAPIArtifacts, trimRootPath(name))
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadArtifact(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	location, err := endpoint.Client.UploadArtifact("/config/bundle.tgz", strings.NewReader("fake-bundle"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/v2/artifacts/config/bundle.tgz", location)
+
+	_, err = endpoint.Client.UploadArtifact("/config/missing.tgz", strings.NewReader("fake-bundle"))
+	if assert.Error(t, err) {
+		apiErr, ok := err.(*APIError)
+		if assert.True(t, ok) {
+			assert.Equal(t, ErrCodeNotFound, apiErr.ErrCode)
+		}
+	}
+}
+
+func TestGetArtifact(t *testing.T) {
+	var accept string
+	config := NewDefaultConfig()
+	config.Middleware = []Middleware{func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			accept = request.Header.Get("Accept")
+			return next.Do(request)
+		})
+	}}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	artifact, err := endpoint.Client.GetArtifact("/config/bundle.tgz")
+	require.NoError(t, err)
+	defer artifact.Close()
+	content, err := ioutil.ReadAll(artifact)
+	require.NoError(t, err)
+	assert.Equal(t, "fake-bundle\n", string(content))
+	// step: the artifacts are of any type
+	assert.Equal(t, "*/*", accept)
+
+	_, err = endpoint.Client.GetArtifact("/config/missing.tgz")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestDeleteArtifact(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	assert.NoError(t, endpoint.Client.DeleteArtifact("/config/bundle.tgz"))
+}
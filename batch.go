@@ -0,0 +1,118 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultBatchConcurrency is the number of applications submitted at once by default
+const defaultBatchConcurrency = 10
+
+// BatchOpts are the options of the batch operations on applications
+type BatchOpts struct {
+	// Concurrency is the number of applications submitted at once, 10 by default
+	Concurrency int
+	// Force is used to force the updates in case of blocked deployment
+	Force bool
+	// Wait waits for the deployments of the applications to complete
+	Wait bool
+	// Timeout is the time to wait for each application, to be set when waiting
+	Timeout time.Duration
+}
+
+// BatchCreateApplications creates the applications concurrently, with at most opts.Concurrency
+// requests in flight. It returns the result of every application, in the order given, and an
+// *AppSetError listing the applications it failed on, without stopping on the first failure.
+//		client:		the client the applications are created with
+//		applications:	the definitions of the applications
+//		opts:		the options of the batch, nil for the defaults
+func BatchCreateApplications(client Marathon, applications []*Application, opts *BatchOpts) ([]AppSetResult, error) {
+	return batch(client, applications, opts, func(application *Application) (*DeploymentID, error) {
+		created, err := client.CreateApplication(application)
+		if err != nil {
+			return nil, err
+		}
+		for _, deployment := range created.Deployments {
+			if id, found := deployment["id"]; found {
+				return &DeploymentID{DeploymentID: id, Version: created.Version}, nil
+			}
+		}
+		return nil, nil
+	})
+}
+
+// BatchUpdateApplications updates the applications concurrently, with at most opts.Concurrency
+// requests in flight. It returns the result of every application, in the order given, and an
+// *AppSetError listing the applications it failed on, without stopping on the first failure.
+//		client:		the client the applications are updated with
+//		applications:	the definitions of the applications
+//		opts:		the options of the batch, nil for the defaults
+func BatchUpdateApplications(client Marathon, applications []*Application, opts *BatchOpts) ([]AppSetResult, error) {
+	force := opts != nil && opts.Force
+	return batch(client, applications, opts, func(application *Application) (*DeploymentID, error) {
+		return client.UpdateApplication(application, force)
+	})
+}
+
+// batch applies the operation to the applications with a bounded pool of workers, waiting on the
+// deployments started when asked to
+func batch(client Marathon, applications []*Application, opts *BatchOpts, operation func(*Application) (*DeploymentID, error)) ([]AppSetResult, error) {
+	if opts == nil {
+		opts = &BatchOpts{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]AppSetResult, len(applications))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < len(applications); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = batchApply(client, applications[i], opts, operation)
+			}
+		}()
+	}
+	for i := range applications {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, aggregateResults(results)
+}
+
+// batchApply applies the operation to an application of the batch
+func batchApply(client Marathon, application *Application, opts *BatchOpts, operation func(*Application) (*DeploymentID, error)) AppSetResult {
+	result := AppSetResult{ID: application.ID}
+	if result.Deployment, result.Err = operation(application); result.Err != nil || !opts.Wait {
+		return result
+	}
+	// step: wait on the deployment started, else on the application
+	if result.Deployment != nil && result.Deployment.DeploymentID != "" {
+		result.Err = client.WaitOnDeployment(result.Deployment.DeploymentID, opts.Timeout)
+	} else {
+		result.Err = client.WaitOnApplication(application.ID, opts.Timeout)
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// batchMarathon records the calls of a batch and the highest number of them in flight
+type batchMarathon struct {
+	Marathon
+	sync.Mutex
+	inFlight    int
+	maxInFlight int
+	failing     map[string]error
+	waited      []string
+}
+
+func (b *batchMarathon) call(id string) error {
+	b.Lock()
+	b.inFlight++
+	if b.inFlight > b.maxInFlight {
+		b.maxInFlight = b.inFlight
+	}
+	b.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	b.Lock()
+	defer b.Unlock()
+	b.inFlight--
+	return b.failing[id]
+}
+
+func (b *batchMarathon) CreateApplication(application *Application) (*Application, error) {
+	if err := b.call(application.ID); err != nil {
+		return nil, err
+	}
+	created := *application
+	created.Version = "2018-01-01T00:00:00.000Z"
+	created.Deployments = []map[string]string{{"id": "create" + application.ID}}
+	return &created, nil
+}
+
+func (b *batchMarathon) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	if err := b.call(application.ID); err != nil {
+		return nil, err
+	}
+	return &DeploymentID{DeploymentID: "update" + application.ID}, nil
+}
+
+func (b *batchMarathon) WaitOnDeployment(id string, timeout time.Duration) error {
+	b.Lock()
+	defer b.Unlock()
+	b.waited = append(b.waited, id)
+	return nil
+}
+
+func batchApplications(count int) []*Application {
+	var applications []*Application
+	for i := 0; i < count; i++ {
+		applications = append(applications, new(Application).Name(fmt.Sprintf("/svc/%d", i)))
+	}
+	return applications
+}
+
+func TestBatchCreateApplications(t *testing.T) {
+	client := &batchMarathon{failing: map[string]error{"/svc/7": errors.New("conflict")}}
+	applications := batchApplications(20)
+
+	results, err := BatchCreateApplications(client, applications, &BatchOpts{Concurrency: 4, Wait: true, Timeout: time.Second})
+	require.Error(t, err)
+	require.Len(t, results, 20)
+	for i, result := range results {
+		assert.Equal(t, applications[i].ID, result.ID)
+	}
+	assert.Equal(t, "create/svc/0", results[0].Deployment.DeploymentID)
+	assert.Equal(t, "2018-01-01T00:00:00.000Z", results[0].Deployment.Version)
+	assert.Nil(t, results[7].Deployment)
+
+	setErr, ok := err.(*AppSetError)
+	require.True(t, ok)
+	assert.Equal(t, 20, setErr.Total)
+	require.Len(t, setErr.Failed, 1)
+	assert.Equal(t, "/svc/7", setErr.Failed[0].ID)
+
+	assert.True(t, client.maxInFlight <= 4)
+	assert.True(t, client.maxInFlight > 1)
+	assert.Len(t, client.waited, 19)
+}
+
+func TestBatchUpdateApplications(t *testing.T) {
+	client := &batchMarathon{}
+
+	results, err := BatchUpdateApplications(client, batchApplications(3), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "update/svc/2", results[2].Deployment.DeploymentID)
+	assert.Empty(t, client.waited)
+
+	results, err = BatchUpdateApplications(client, nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
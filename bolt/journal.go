@@ -0,0 +1,213 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bolt persists the event journal and the deployment history of the go-marathon client in
+// a BoltDB file, indexed by time and application, so a long-running controller can look them up
+// after a restart.
+//
+//	journal, err := bolt.NewJournalStore("/var/lib/controller/marathon.db")
+//	defer journal.Close()
+//
+//	config := marathon.NewDefaultConfig()
+//	config.Journal = journal
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	"go.etcd.io/bbolt"
+)
+
+// openTimeout is how long to wait for the lock of a file opened by another process
+const openTimeout = 10 * time.Second
+
+var (
+	// the entries keyed by time
+	entriesBucket = []byte("entries")
+	// the keys of the entries of each application, keyed by application id and time
+	appsBucket = []byte("apps")
+)
+
+// the size of the keys of the entries, i.e. the time followed by a sequence number
+const keySize = 16
+
+// the separator of the application id and the key of the entry in the application index
+const appSeparator = 0
+
+// JournalStore is a marathon.JournalStore keeping the entries in a BoltDB file
+type JournalStore struct {
+	db *bbolt.DB
+}
+
+// make sure the store can be set on the client config
+var _ marathon.JournalStore = &JournalStore{}
+
+// NewJournalStore opens the journal in the BoltDB file, which only one process can have open
+//		path:		the file of the journal, created if missing
+func NewJournalStore(path string) (*JournalStore, error) {
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, appsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &JournalStore{db: db}, nil
+}
+
+// Close closes the file of the journal
+func (s *JournalStore) Close() error {
+	return s.db.Close()
+}
+
+// Append stores the entry, indexing it by the id of its application
+func (s *JournalStore) Append(entry marathon.JournalEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		sequence, err := entries.NextSequence()
+		if err != nil {
+			return err
+		}
+		key := entryKey(entry.Time, sequence)
+		if err := entries.Put(key, content); err != nil {
+			return err
+		}
+		if entry.AppID == "" {
+			return nil
+		}
+		return tx.Bucket(appsBucket).Put(append(appPrefix(entry.AppID), key...), nil)
+	})
+}
+
+// Query retrieves the entries selected by the query, the oldest first. The entries of an
+// application, or of a group, are looked up in the index of the applications rather than scanned.
+func (s *JournalStore) Query(query marathon.JournalQuery) ([]marathon.JournalEntry, error) {
+	selected := []marathon.JournalEntry{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		entries := tx.Bucket(entriesBucket)
+		var keys [][]byte
+		if query.AppID == "" {
+			keys = scan(entries, nil, true, query)
+		} else {
+			id := strings.TrimSuffix(appID(query.AppID), "/")
+			apps := tx.Bucket(appsBucket)
+			// step: the entries of the application itself are ordered by time, the ones of the
+			// applications of the group are merged in
+			keys = append(scan(apps, appPrefix(id), true, query), scan(apps, []byte(id+"/"), false, query)...)
+			sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+		}
+
+		// step: the most recent entries are read first, so a limited query stops early
+		for i := len(keys) - 1; i >= 0; i-- {
+			var entry marathon.JournalEntry
+			if err := json.Unmarshal(entries.Get(keys[i]), &entry); err != nil {
+				return err
+			}
+			if !query.Matches(entry) {
+				continue
+			}
+			selected = append(selected, entry)
+			if query.Limit > 0 && len(selected) == query.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(selected)-1; i < j; i, j = i+1, j-1 {
+		selected[i], selected[j] = selected[j], selected[i]
+	}
+	return selected, nil
+}
+
+// scan collects the keys of the entries under the prefix within the time range of the query
+//		bucket:		the bucket of the entries or of the application index
+//		prefix:		the prefix of the keys preceding the keys of the entries
+//		ordered:	whether the keys under the prefix are ordered by time, i.e. the prefix is
+//				the one of a single application or of the entries
+func scan(bucket *bbolt.Bucket, prefix []byte, ordered bool, query marathon.JournalQuery) [][]byte {
+	since := entryKey(query.Since, 0)
+	until := entryKey(query.Until, 0)
+
+	cursor := bucket.Cursor()
+	seek := prefix
+	if ordered && !query.Since.IsZero() {
+		seek = append(append([]byte{}, prefix...), since...)
+	}
+	var keys [][]byte
+	for k, _ := cursor.Seek(seek); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		if len(k) < len(prefix)+keySize {
+			continue
+		}
+		key := k[len(k)-keySize:]
+		if !query.Until.IsZero() && bytes.Compare(key, until) >= 0 {
+			if ordered {
+				break
+			}
+			continue
+		}
+		if !query.Since.IsZero() && bytes.Compare(key, since) < 0 {
+			continue
+		}
+		keys = append(keys, append([]byte{}, key...))
+	}
+	return keys
+}
+
+// entryKey builds the key of an entry, ordering the entries by time then by the order they were
+// appended in
+func entryKey(t time.Time, sequence uint64) []byte {
+	key := make([]byte, keySize)
+	if !t.IsZero() {
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	binary.BigEndian.PutUint64(key[8:], sequence)
+	return key
+}
+
+// appPrefix builds the prefix of the keys of the entries of the application in the index
+func appPrefix(id string) []byte {
+	return append([]byte(appID(id)), appSeparator)
+}
+
+// appID makes the id of the application absolute, as the entries are matched by the query
+func appID(id string) string {
+	if !strings.HasPrefix(id, "/") {
+		return "/" + id
+	}
+	return id
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-marathon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.db")
+
+	store, err := NewJournalStore(path)
+	require.NoError(t, err)
+	start := time.Now()
+	for i, id := range []string{"/prod/api", "/prod/web", "/dev/api", "/prod/api-canary", "", "/prod/api"} {
+		kind := marathon.JournalKindDeployment
+		if id == "" {
+			kind = marathon.JournalKindEvent
+		}
+		require.NoError(t, store.Append(marathon.JournalEntry{
+			Time:  start.Add(time.Duration(i) * time.Second),
+			Kind:  kind,
+			Type:  "PUT",
+			AppID: id,
+		}))
+	}
+	require.NoError(t, store.Close())
+
+	// step: the entries survive reopening the journal
+	store, err = NewJournalStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	entries, err := store.Query(marathon.JournalQuery{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 6)
+
+	entries, err = store.Query(marathon.JournalQuery{AppID: "prod/api"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, start.Equal(entries[0].Time))
+	assert.True(t, start.Add(5*time.Second).Equal(entries[1].Time))
+
+	entries, err = store.Query(marathon.JournalQuery{AppID: "/prod/"})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	for i, id := range []string{"/prod/api", "/prod/web", "/prod/api-canary", "/prod/api"} {
+		assert.Equal(t, id, entries[i].AppID)
+	}
+
+	entries, err = store.Query(marathon.JournalQuery{AppID: "/prod", Since: start.Add(time.Second), Until: start.Add(5 * time.Second)})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/prod/web", entries[0].AppID)
+	assert.Equal(t, "/prod/api-canary", entries[1].AppID)
+
+	entries, err = store.Query(marathon.JournalQuery{Since: start.Add(2 * time.Second), Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, marathon.JournalKindEvent, entries[0].Kind)
+	assert.Equal(t, "/prod/api", entries[1].AppID)
+
+	entries, err = store.Query(marathon.JournalQuery{Kind: marathon.JournalKindEvent})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Empty(t, entries[0].AppID)
+
+	entries, err = store.Query(marathon.JournalQuery{AppID: "/staging"})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
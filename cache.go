@@ -0,0 +1,393 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL is the time the reads are cached by default
+const defaultCacheTTL = 5 * time.Second
+
+// CacheOpts are the options of a caching client
+type CacheOpts struct {
+	// TTL is the time the reads are cached, 5 seconds by default
+	TTL time.Duration
+	// TTLs overrides the TTL of the reads by the name of their method, e.g. Application or Groups,
+	// a zero TTL disabling the caching of the method
+	TTLs map[string]time.Duration
+}
+
+// cacheEntry is a cached result of a read
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// cachingClient is a Marathon implementation caching the reads of the applications and groups
+type cachingClient struct {
+	Marathon
+	sync.Mutex
+	opts    CacheOpts
+	entries map[string]cacheEntry
+	// bumped by the invalidations, so the reads loaded meanwhile are not cached
+	generation uint64
+	// returns the current time, overridden by the tests
+	now func() time.Time
+}
+
+// NewCachingClient wraps a client in a read-through cache of the applications and groups, e.g. for
+// dashboards reading the same applications many times a minute. The reads are cached for their
+// TTL, and the whole cache is invalidated by the writes on applications, pods, tasks, groups
+// and deployments made through the client; the changes made elsewhere are seen once the TTL expires.
+// The failed reads are not cached, and the cached results are shared by the callers so they must
+// not be modified.
+//		client:		the client being wrapped
+//		opts:		the options of the cache, nil for the defaults
+func NewCachingClient(client Marathon, opts *CacheOpts) Marathon {
+	c := &cachingClient{
+		Marathon: client,
+		entries:  make(map[string]cacheEntry),
+		now:      time.Now,
+	}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.TTL <= 0 {
+		c.opts.TTL = defaultCacheTTL
+	}
+	return c
+}
+
+// Scoped returns a caching client bound to the group prefix
+func (c *cachingClient) Scoped(prefix string) Marathon {
+	return NewScopedClient(c, prefix)
+}
+
+// cached returns the cached result of the read, loading it on a miss
+func (c *cachingClient) cached(method, key string, load func() (interface{}, error)) (interface{}, error) {
+	ttl := c.opts.TTL
+	if override, found := c.opts.TTLs[method]; found {
+		ttl = override
+	}
+	if ttl <= 0 {
+		return load()
+	}
+	key = method + " " + key
+
+	c.Lock()
+	entry, found := c.entries[key]
+	generation := c.generation
+	c.Unlock()
+	if found && c.now().Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	// step: a write invalidating the cache during the load may have made the value stale
+	c.Lock()
+	if generation == c.generation {
+		c.entries[key] = cacheEntry{value: value, expires: c.now().Add(ttl)}
+	}
+	c.Unlock()
+	return value, nil
+}
+
+// invalidate empties the cache once the write succeeded
+func (c *cachingClient) invalidate(err error) {
+	if err != nil {
+		return
+	}
+	c.Lock()
+	c.entries = make(map[string]cacheEntry)
+	c.generation++
+	c.Unlock()
+}
+
+// -- APPLICATIONS ---
+
+func (c *cachingClient) Applications(v url.Values) (*Applications, error) {
+	value, err := c.cached("Applications", v.Encode(), func() (interface{}, error) {
+		return c.Marathon.Applications(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Applications), nil
+}
+
+func (c *cachingClient) ListApplications(v url.Values) ([]string, error) {
+	value, err := c.cached("ListApplications", v.Encode(), func() (interface{}, error) {
+		return c.Marathon.ListApplications(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]string), nil
+}
+
+func (c *cachingClient) Application(name string) (*Application, error) {
+	value, err := c.cached("Application", name, func() (interface{}, error) {
+		return c.Marathon.Application(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Application), nil
+}
+
+func (c *cachingClient) ApplicationBy(name string, opts *GetAppOpts) (*Application, error) {
+	value, err := c.cached("ApplicationBy", fmt.Sprintf("%s %v", name, opts), func() (interface{}, error) {
+		return c.Marathon.ApplicationBy(name, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Application), nil
+}
+
+func (c *cachingClient) HasApplication(name string) (bool, error) {
+	value, err := c.cached("HasApplication", name, func() (interface{}, error) {
+		return c.Marathon.HasApplication(name)
+	})
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+func (c *cachingClient) SetApplicationVersion(name string, version *ApplicationVersion) (*DeploymentID, error) {
+	deployment, err := c.Marathon.SetApplicationVersion(name, version)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) SetApplicationVersionBy(name string, version *ApplicationVersion, opts *UpdateAppOpts) (*DeploymentID, error) {
+	deployment, err := c.Marathon.SetApplicationVersionBy(name, version, opts)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) CreateApplication(application *Application) (*Application, error) {
+	created, err := c.Marathon.CreateApplication(application)
+	c.invalidate(err)
+	return created, err
+}
+
+func (c *cachingClient) DeleteApplication(name string, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.DeleteApplication(name, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) DeleteApplicationBy(name string, opts *DeleteAppOpts) (*DeploymentID, error) {
+	deployment, err := c.Marathon.DeleteApplicationBy(name, opts)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.UpdateApplication(application, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) UpdateApplicationBy(application *Application, opts *UpdateAppOpts) (*DeploymentID, error) {
+	deployment, err := c.Marathon.UpdateApplicationBy(application, opts)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) ScaleApplicationInstances(name string, instances int, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.ScaleApplicationInstances(name, instances, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) ScaleApplication(name string, instances int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	deployment, tracker, err := c.Marathon.ScaleApplication(name, instances, opts)
+	if deployment != nil {
+		c.invalidate(nil)
+	}
+	return deployment, tracker, err
+}
+
+func (c *cachingClient) ScaleBy(name string, delta int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	deployment, tracker, err := c.Marathon.ScaleBy(name, delta, opts)
+	// step: the scaling happened even when the wait failed
+	if deployment != nil {
+		c.invalidate(nil)
+	}
+	return deployment, tracker, err
+}
+
+func (c *cachingClient) RestartApplication(name string, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.RestartApplication(name, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) RestartApplicationBy(name string, opts *RestartAppOpts) (*DeploymentID, error) {
+	deployment, err := c.Marathon.RestartApplicationBy(name, opts)
+	// step: the restart happened even when the wait failed
+	if deployment != nil {
+		c.invalidate(nil)
+	}
+	return deployment, err
+}
+
+// -- TASKS ---
+
+func (c *cachingClient) KillApplicationTasks(applicationID string, opts *KillApplicationTasksOpts) (*Tasks, error) {
+	tasks, err := c.Marathon.KillApplicationTasks(applicationID, opts)
+	c.invalidate(err)
+	return tasks, err
+}
+
+func (c *cachingClient) KillTask(taskID string, opts *KillTaskOpts) (*Task, error) {
+	task, err := c.Marathon.KillTask(taskID, opts)
+	c.invalidate(err)
+	return task, err
+}
+
+func (c *cachingClient) KillTasks(taskIDs []string, opts *KillTaskOpts) error {
+	err := c.Marathon.KillTasks(taskIDs, opts)
+	c.invalidate(err)
+	return err
+}
+
+// -- GROUPS ---
+
+func (c *cachingClient) Groups() (*Groups, error) {
+	value, err := c.cached("Groups", "", func() (interface{}, error) {
+		return c.Marathon.Groups()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Groups), nil
+}
+
+func (c *cachingClient) Group(name string) (*Group, error) {
+	value, err := c.cached("Group", name, func() (interface{}, error) {
+		return c.Marathon.Group(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Group), nil
+}
+
+func (c *cachingClient) GroupsBy(opts *GetGroupOpts) (*Groups, error) {
+	value, err := c.cached("GroupsBy", fmt.Sprintf("%v", opts), func() (interface{}, error) {
+		return c.Marathon.GroupsBy(opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Groups), nil
+}
+
+func (c *cachingClient) GroupBy(name string, opts *GetGroupOpts) (*Group, error) {
+	value, err := c.cached("GroupBy", fmt.Sprintf("%s %v", name, opts), func() (interface{}, error) {
+		return c.Marathon.GroupBy(name, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*Group), nil
+}
+
+func (c *cachingClient) HasGroup(name string) (bool, error) {
+	value, err := c.cached("HasGroup", name, func() (interface{}, error) {
+		return c.Marathon.HasGroup(name)
+	})
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+func (c *cachingClient) CreateGroup(group *Group) error {
+	err := c.Marathon.CreateGroup(group)
+	c.invalidate(err)
+	return err
+}
+
+func (c *cachingClient) DeleteGroup(name string, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.DeleteGroup(name, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) UpdateGroup(name string, group *Group, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.UpdateGroup(name, group, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) DeployGroup(group *Group, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.DeployGroup(group, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+// -- PODS ---
+
+func (c *cachingClient) CreatePod(pod *Pod) (*Pod, error) {
+	created, err := c.Marathon.CreatePod(pod)
+	c.invalidate(err)
+	return created, err
+}
+
+func (c *cachingClient) UpdatePod(pod *Pod, force bool) (*Pod, error) {
+	updated, err := c.Marathon.UpdatePod(pod, force)
+	c.invalidate(err)
+	return updated, err
+}
+
+func (c *cachingClient) DeletePod(name string, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.DeletePod(name, force)
+	c.invalidate(err)
+	return deployment, err
+}
+
+func (c *cachingClient) DeletePodInstances(name string, instances []string) ([]*PodInstance, error) {
+	deleted, err := c.Marathon.DeletePodInstances(name, instances)
+	c.invalidate(err)
+	return deleted, err
+}
+
+func (c *cachingClient) DeletePodInstance(name, instance string) (*PodInstance, error) {
+	deleted, err := c.Marathon.DeletePodInstance(name, instance)
+	c.invalidate(err)
+	return deleted, err
+}
+
+// -- DEPLOYMENTS ---
+
+func (c *cachingClient) DeleteDeployment(id string, force bool) (*DeploymentID, error) {
+	deployment, err := c.Marathon.DeleteDeployment(id, force)
+	c.invalidate(err)
+	return deployment, err
+}
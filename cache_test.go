@@ -0,0 +1,228 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingMarathon counts the reads of the applications and groups
+type countingMarathon struct {
+	Marathon
+	reads map[string]int
+	err   error
+	// called while loading the applications, e.g. to write concurrently
+	loading func()
+}
+
+func (c *countingMarathon) Application(name string) (*Application, error) {
+	c.reads["Application "+name]++
+	if c.loading != nil {
+		c.loading()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return new(Application).Name(name), nil
+}
+
+func (c *countingMarathon) Applications(v url.Values) (*Applications, error) {
+	c.reads["Applications "+v.Encode()]++
+	return &Applications{}, nil
+}
+
+func (c *countingMarathon) Group(name string) (*Group, error) {
+	c.reads["Group "+name]++
+	return NewApplicationGroup(name), nil
+}
+
+func (c *countingMarathon) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	return &DeploymentID{DeploymentID: "update"}, c.err
+}
+
+func (c *countingMarathon) CreatePod(pod *Pod) (*Pod, error) {
+	return pod, c.err
+}
+
+func (c *countingMarathon) UpdatePod(pod *Pod, force bool) (*Pod, error) {
+	return pod, c.err
+}
+
+func (c *countingMarathon) DeletePod(name string, force bool) (*DeploymentID, error) {
+	return &DeploymentID{DeploymentID: "delete"}, c.err
+}
+
+func (c *countingMarathon) DeletePodInstances(name string, instances []string) ([]*PodInstance, error) {
+	return []*PodInstance{}, c.err
+}
+
+func (c *countingMarathon) DeletePodInstance(name, instance string) (*PodInstance, error) {
+	return &PodInstance{}, c.err
+}
+
+func newCachingClient(opts *CacheOpts) (*cachingClient, *countingMarathon, *time.Time) {
+	upstream := &countingMarathon{reads: map[string]int{}}
+	client := NewCachingClient(upstream, opts).(*cachingClient)
+	now := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	return client, upstream, &now
+}
+
+func TestCachingClient(t *testing.T) {
+	client, upstream, now := newCachingClient(nil)
+
+	for i := 0; i < 3; i++ {
+		application, err := client.Application("/web")
+		require.NoError(t, err)
+		assert.Equal(t, "/web", application.ID)
+	}
+	_, err := client.Application("/api")
+	require.NoError(t, err)
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+	_, err = client.Applications(url.Values{"cmd": []string{"nginx"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Application /web": 1, "Application /api": 1, "Applications ": 1, "Applications cmd=nginx": 1}, upstream.reads)
+
+	// step: the entries expire after the TTL
+	*now = now.Add(defaultCacheTTL)
+	_, err = client.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 2, upstream.reads["Application /web"])
+
+	// step: the writes invalidate the cache
+	_, err = client.Group("/product")
+	require.NoError(t, err)
+	_, err = client.UpdateApplication(new(Application).Name("/web"), false)
+	require.NoError(t, err)
+	_, err = client.Application("/web")
+	require.NoError(t, err)
+	_, err = client.Group("/product")
+	require.NoError(t, err)
+	assert.Equal(t, 3, upstream.reads["Application /web"])
+	assert.Equal(t, 2, upstream.reads["Group /product"])
+
+	// step: the failed writes don't
+	upstream.err = errors.New("conflict")
+	_, err = client.UpdateApplication(new(Application).Name("/web"), false)
+	assert.Error(t, err)
+	upstream.err = nil
+	_, err = client.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 3, upstream.reads["Application /web"])
+}
+
+func TestCachingClientPodWrites(t *testing.T) {
+	client, upstream, _ := newCachingClient(nil)
+
+	// step: the pods are part of the groups, so their writes invalidate the cache as well
+	writes := map[string]func() error{
+		"CreatePod": func() error {
+			_, err := client.CreatePod(NewPod().Name("/product/db"))
+			return err
+		},
+		"UpdatePod": func() error {
+			_, err := client.UpdatePod(NewPod().Name("/product/db"), false)
+			return err
+		},
+		"DeletePod": func() error {
+			_, err := client.DeletePod("/product/db", false)
+			return err
+		},
+		"DeletePodInstances": func() error {
+			_, err := client.DeletePodInstances("/product/db", []string{"db.instance-1"})
+			return err
+		},
+		"DeletePodInstance": func() error {
+			_, err := client.DeletePodInstance("/product/db", "db.instance-1")
+			return err
+		},
+	}
+	_, err := client.Group("/product")
+	require.NoError(t, err)
+	for name, write := range writes {
+		reads := upstream.reads["Group /product"]
+		require.NoError(t, write(), name)
+		_, err = client.Group("/product")
+		require.NoError(t, err)
+		assert.Equal(t, reads+1, upstream.reads["Group /product"], name)
+	}
+
+	// step: the failed writes don't
+	upstream.err = errors.New("conflict")
+	_, err = client.UpdatePod(NewPod().Name("/product/db"), false)
+	assert.Error(t, err)
+	_, err = client.Group("/product")
+	require.NoError(t, err)
+	assert.Equal(t, len(writes)+1, upstream.reads["Group /product"])
+}
+
+func TestCachingClientTTLs(t *testing.T) {
+	client, upstream, now := newCachingClient(&CacheOpts{
+		TTL:  time.Minute,
+		TTLs: map[string]time.Duration{"Application": 0, "Group": time.Hour},
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Application("/web")
+		require.NoError(t, err)
+		_, err = client.Group("/product")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, upstream.reads["Application /web"])
+	assert.Equal(t, 1, upstream.reads["Group /product"])
+
+	*now = now.Add(30 * time.Minute)
+	_, err := client.Group("/product")
+	require.NoError(t, err)
+	assert.Equal(t, 1, upstream.reads["Group /product"])
+}
+
+func TestCachingClientErrors(t *testing.T) {
+	client, upstream, _ := newCachingClient(nil)
+	upstream.err = errors.New("unavailable")
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Application("/web")
+		assert.Equal(t, upstream.err, err)
+	}
+	assert.Equal(t, 2, upstream.reads["Application /web"])
+}
+
+func TestCachingClientInvalidatedLoad(t *testing.T) {
+	client, upstream, _ := newCachingClient(nil)
+
+	// step: the write invalidates the cache while the application is loaded
+	upstream.loading = func() {
+		upstream.loading = nil
+		_, err := client.UpdateApplication(new(Application).Name("/web"), false)
+		require.NoError(t, err)
+	}
+	_, err := client.Application("/web")
+	require.NoError(t, err)
+
+	// step: the possibly stale application was not cached
+	_, err = client.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 2, upstream.reads["Application /web"])
+}
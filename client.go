@@ -18,49 +18,66 @@ package marathon
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"log"
 	"net"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Marathon is the interface to the marathon API
+// Marathon is the interface to the marathon API. The clients are safe for concurrent use by
+// multiple goroutines: a client is meant to be created once and shared, e.g. by a worker pool.
 type Marathon interface {
 	// -- APPLICATIONS ---
 
 	// get a listing of the application ids
 	ListApplications(url.Values) ([]string, error)
+	// check if an application exists
+	HasApplication(name string) (bool, error)
 	// a list of application versions
 	ApplicationVersions(name string) (*ApplicationVersions, error)
 	// check a application version exists
 	HasApplicationVersion(name, version string) (bool, error)
 	// change an application to a different version
 	SetApplicationVersion(name string, version *ApplicationVersion) (*DeploymentID, error)
+	// change an application to a different version, optionally overriding the conflicting deployments
+	SetApplicationVersionBy(name string, version *ApplicationVersion, opts *UpdateAppOpts) (*DeploymentID, error)
 	// check if an application is ok
 	ApplicationOK(name string) (bool, error)
 	// create an application in marathon
 	CreateApplication(application *Application) (*Application, error)
 	// delete an application
 	DeleteApplication(name string, force bool) (*DeploymentID, error)
+	// delete an application, optionally overriding the conflicting deployments
+	DeleteApplicationBy(name string, opts *DeleteAppOpts) (*DeploymentID, error)
 	// update an application in marathon
 	UpdateApplication(application *Application, force bool) (*DeploymentID, error)
+	// update an application in marathon, optionally overriding the conflicting deployments
+	UpdateApplicationBy(application *Application, opts *UpdateAppOpts) (*DeploymentID, error)
 	// a list of deployments on a application
 	ApplicationDeployments(name string) ([]*DeploymentID, error)
 	// scale a application
 	ScaleApplicationInstances(name string, instances int, force bool) (*DeploymentID, error)
+	// scale a application, optionally tracking the launch of the instances
+	ScaleApplication(name string, instances int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error)
+	// scale a application relatively to its current instances
+	ScaleBy(name string, delta int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error)
 	// restart an application
 	RestartApplication(name string, force bool) (*DeploymentID, error)
+	// restart an application, optionally waiting on the deployment
+	RestartApplicationBy(name string, opts *RestartAppOpts) (*DeploymentID, error)
 	// get a list of applications from marathon
 	Applications(url.Values) (*Applications, error)
+	// iterate over the applications as they are decoded
+	EachApplication(v url.Values, fn func(*Application) error) error
 	// get an application by name
 	Application(name string) (*Application, error)
 	// get an application by options
@@ -69,6 +86,10 @@ type Marathon interface {
 	ApplicationByVersion(name, version string) (*Application, error)
 	// wait of application
 	WaitOnApplication(name string, timeout time.Duration) error
+	// wait of application to be healthy
+	WaitOnApplicationHealthy(name string, timeout time.Duration) error
+	// rewrite the deprecated fields of an application
+	ModernizeApplication(application *Application) ([]string, error)
 
 	// -- PODS ---
 	// whether this version of Marathon supports pods
@@ -108,10 +129,16 @@ type Marathon interface {
 
 	// get a list of tasks for a specific application
 	Tasks(application string) (*Tasks, error)
+	// get a list of tasks for a specific application by the options, e.g. the running ones
+	TasksBy(application string, opts *TasksOpts) (*Tasks, error)
 	// get a list of all tasks
 	AllTasks(opts *AllTasksOpts) (*Tasks, error)
+	// get the endpoints of the tasks of all applications per service port
+	AllTaskEndpoints(opts *AllTasksOpts) ([]*ServiceEndpoints, error)
 	// get the endpoints for a service on a application
 	TaskEndpoints(name string, port int, healthCheck bool) ([]string, error)
+	// get the endpoints for a named port on a application
+	TaskEndpointsByPortName(name, portName string, healthCheck bool) ([]string, error)
 	// kill all the tasks for any application
 	KillApplicationTasks(applicationID string, opts *KillApplicationTasksOpts) (*Tasks, error)
 	// kill a single task
@@ -135,10 +162,14 @@ type Marathon interface {
 	DeleteGroup(name string, force bool) (*DeploymentID, error)
 	// update a groups
 	UpdateGroup(id string, group *Group, force bool) (*DeploymentID, error)
+	// deploy a group with its nested groups and applications in a single deployment
+	DeployGroup(group *Group, force bool) (*DeploymentID, error)
 	// check if a group exists
 	HasGroup(name string) (bool, error)
 	// wait for an group to be deployed
 	WaitOnGroup(name string, timeout time.Duration) error
+	// wait for a group to be deployed with the tasks of all its applications healthy
+	WaitOnGroupHealthy(name string, timeout time.Duration) error
 
 	// --- DEPLOYMENTS ---
 
@@ -167,6 +198,8 @@ type Marathon interface {
 	// --- QUEUE ---
 	// get marathon launch queue
 	Queue() (*Queue, error)
+	// get the marathon queue by the options, e.g. with the last unused offers
+	QueueBy(opts *QueueOpts) (*Queue, error)
 	// resets task launch delay of the specific application
 	DeleteQueueDelay(appID string) error
 
@@ -176,12 +209,33 @@ type Marathon interface {
 	GetMarathonURL() string
 	// ping the marathon
 	Ping() (bool, error)
+	// ping the marathon and return the round-trip latency
+	PingLatency(timeout time.Duration) (time.Duration, error)
 	// grab the marathon server info
 	Info() (*Info, error)
+	// the version of the marathon server, cached once retrieved
+	ServerVersion() (string, error)
+	// whether the marathon server supports a feature
+	Supports(feature Feature) (bool, error)
 	// retrieve the leader info
 	Leader() (string, error)
 	// cause the current leader to abdicate
 	AbdicateLeader() (string, error)
+	// get a client bound to a group prefix
+	Scoped(prefix string) Marathon
+	// the number of requests re-routed from a follower to the leader
+	FollowerResponses() int64
+	// close the client, cancelling the requests in flight and stopping the listeners and waits
+	Close() error
+
+	// --- ARTIFACTS ---
+
+	// upload an artifact to the artifact store
+	UploadArtifact(path string, artifact io.Reader) (string, error)
+	// retrieve an artifact from the artifact store, to be read and closed
+	GetArtifact(path string) (io.ReadCloser, error)
+	// delete an artifact from the artifact store
+	DeleteArtifact(path string) error
 }
 
 var (
@@ -189,6 +243,11 @@ var (
 	ErrMarathonDown = errors.New("all the Marathon hosts are presently down")
 	// ErrTimeoutError is thrown when the operation has timed out
 	ErrTimeoutError = errors.New("the operation has timed out")
+	// ErrCircuitOpen is thrown when the circuit breakers of the Marathon hosts are open after too
+	// many consecutive failures, failing fast until their cool-down
+	ErrCircuitOpen = errors.New("the circuit breaker of the Marathon hosts is open")
+	// ErrClientClosed is thrown by the requests and waits of a closed client
+	ErrClientClosed = errors.New("the Marathon client is closed")
 
 	// Default HTTP client used for SSE subscription requests
 	// It is invalid to set client.Timeout because it includes time to read response so
@@ -217,11 +276,20 @@ type EventsChannelContext struct {
 }
 
 type marathonClient struct {
+	// the number of follower responses, kept first to guarantee 64-bit alignment for atomic operations
+	followerResponses int64
+
+	// guards the events listeners, the subscriptions and the events HTTP server, the state of
+	// the members being guarded by the cluster
 	sync.RWMutex
-	// the configuration for the client
+	// the configuration for the client, read-only once created
 	config Config
-	// the flag used to prevent multiple SSE subscriptions
-	subscribedToSSE bool
+	// closed to stop the SSE subscription shared by the listeners, nil when not subscribed
+	sseStop chan struct{}
+	// closed once the last SSE subscription has closed its connection, nil when never subscribed
+	sseDone chan struct{}
+	// the event types the SSE subscription is restricted to
+	sseFilter int
 	// the ip address of the client
 	ipAddress string
 	// the http server
@@ -230,15 +298,33 @@ type marathonClient struct {
 	hosts *cluster
 	// a map of service you wish to listen to
 	listeners map[EventsChannel]EventsChannelContext
-	// a custom log function for debug messages
-	debugLog func(format string, v ...interface{})
+	// the logger for the messages of the client
+	logger Logger
+	// the instrumentation observing the requests
+	instrumentation Instrumentation
+	// the tracer creating the spans of the API calls
+	tracer Tracer
+	// the codec of the request and response bodies and of the events
+	codec Codec
 	// the marathon HTTP client to ensure consistency in requests
 	client *httpClient
+	// the transport created for the client, closed with it, nil when the transport is shared
+	transport http.RoundTripper
+	// guards closing the client
+	closeOnce sync.Once
+	// guards the version of the Marathon server, cached once retrieved
+	versionLock   sync.Mutex
+	serverVersion string
 }
 
 type httpClient struct {
 	// the configuration for the marathon HTTP client
 	config Config
+	// the context of the requests, cancelled once the client is closed, nil for no cancellation
+	ctx    context.Context
+	cancel context.CancelFunc
+	// the DC/OS token of the requests, nil for the token of the configuration
+	tokens *tokenSource
 }
 
 // newRequestError signals that creating a new http.Request failed
@@ -249,18 +335,66 @@ type newRequestError struct {
 // NewClient creates a new marathon client
 //		config:			the configuration to use
 func NewClient(config Config) (Marathon, error) {
+	// step: apply the TLS options to the transport of the default HTTP clients
+	var ownedTransport http.RoundTripper
+	if config.hasTLSOptions() {
+		if config.Transport != nil {
+			return nil, ErrTLSWithTransport
+		}
+		transport, err := newTLSTransport(config)
+		if err != nil {
+			return nil, err
+		}
+		config.Transport = transport
+		ownedTransport = transport
+	}
+
 	// step: if the SSE HTTP client is missing, prefer a configured regular
-	// client, and otherwise use the default SSE HTTP client.
+	// client, then a configured transport, and otherwise use the default SSE HTTP client.
 	if config.HTTPSSEClient == nil {
-		config.HTTPSSEClient = defaultHTTPSSEClient
-		if config.HTTPClient != nil {
+		switch {
+		case config.HTTPClient != nil:
 			config.HTTPSSEClient = config.HTTPClient
+		case config.Transport != nil:
+			config.HTTPSSEClient = &http.Client{Transport: config.Transport}
+		default:
+			config.HTTPSSEClient = defaultHTTPSSEClient
 		}
 	}
 
-	// step: if a regular HTTP client is missing, use the default one.
+	// step: if a regular HTTP client is missing, use the default one, on the configured
+	// transport if any.
 	if config.HTTPClient == nil {
 		config.HTTPClient = defaultHTTPClient
+		if config.Transport != nil {
+			config.HTTPClient = &http.Client{
+				Timeout:   defaultHTTPClient.Timeout,
+				Transport: config.Transport,
+			}
+		}
+	}
+
+	// step: discover the members when no URL is given
+	if config.URL == "" && config.MemberDiscovery != nil {
+		marathonURL, err := discoverURL(config.MemberDiscovery)
+		if err != nil {
+			return nil, err
+		}
+		config.URL = marathonURL
+	}
+
+	// step: log in as the service account when configured, with the regular HTTP client, on the
+	// cluster of the URL once discovered
+	if config.TokenProvider == nil && config.DCOSServiceAccountID != "" {
+		loginURL, err := dcosLoginURL(config)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := NewServiceAccountTokenProvider(loginURL, config.DCOSServiceAccountID, []byte(config.DCOSServiceAccountKey), config.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		config.TokenProvider = provider
 	}
 
 	// step: if no polling wait time is set, default to 500 milliseconds.
@@ -269,29 +403,45 @@ func NewClient(config Config) (Marathon, error) {
 	}
 
 	// step: setup shared client
-	client := &httpClient{config: config}
+	client := &httpClient{config: config, tokens: newTokenSource(config)}
+	client.ctx, client.cancel = context.WithCancel(context.Background())
 
 	// step: create a new cluster
-	hosts, err := newCluster(client, config.URL, config.DCOSToken != "")
+	hosts, err := newCluster(client, config.URL, config.DCOSToken != "" || config.TokenProvider != nil)
 	if err != nil {
 		return nil, err
 	}
 
-	debugLog := func(string, ...interface{}) {}
-	if config.LogOutput != nil {
-		logger := log.New(config.LogOutput, "", 0)
-		debugLog = func(format string, v ...interface{}) {
-			logger.Printf(format, v...)
-		}
+	// step: requests are neither instrumented nor traced unless configured
+	instrumentation := config.Instrumentation
+	if instrumentation == nil {
+		instrumentation = noopInstrumentation{}
+	}
+	tracer := config.Tracer
+	if tracer == nil {
+		tracer = noopTracer{}
 	}
 
-	return &marathonClient{
-		config:    config,
-		listeners: make(map[EventsChannel]EventsChannelContext),
-		hosts:     hosts,
-		debugLog:  debugLog,
-		client:    client,
-	}, nil
+	// step: the bodies are encoded with encoding/json unless configured
+	codec := config.Codec
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+
+	marathon := &marathonClient{
+		config:          config,
+		listeners:       make(map[EventsChannel]EventsChannelContext),
+		hosts:           hosts,
+		logger:          newLogger(config),
+		instrumentation: instrumentation,
+		tracer:          tracer,
+		codec:           codec,
+		client:          client,
+		transport:       ownedTransport,
+	}
+	hosts.logger = marathon.log(LogModuleCluster)
+
+	return marathon, nil
 }
 
 // GetMarathonURL retrieves the marathon url
@@ -301,136 +451,605 @@ func (r *marathonClient) GetMarathonURL() string {
 
 // Ping pings the current marathon endpoint (note, this is not a ICMP ping, but a rest api call)
 func (r *marathonClient) Ping() (bool, error) {
-	if err := r.apiGet(marathonAPIPing, nil, nil); err != nil {
+	if err := r.apiGet("Ping", marathonAPIPing, nil, nil); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-func (r *marathonClient) apiHead(path string, result interface{}) error {
-	return r.apiCall("HEAD", path, nil, result)
+// PingLatency pings the current marathon endpoint using the given timeout and returns the
+// round-trip latency. Unlike other calls, the request is not retried on another member; instead
+// the member is marked down on failure so that a subsequent probe targets the next member.
+//		timeout:	the maximum amount of time to wait for the pong
+func (r *marathonClient) PingLatency(timeout time.Duration) (time.Duration, error) {
+	metrics := RequestMetrics{Method: "GET", Endpoint: metricsEndpoint(marathonAPIPing)}
+	span := r.startSpan("PingLatency", "GET", marathonAPIPing)
+	start := time.Now()
+
+	latency, err := r.ping(timeout, span, &metrics)
+
+	metrics.Duration = time.Since(start)
+	r.instrumentation.ObserveRequest(metrics)
+	endSpan(span, marathonAPIPing, metrics.StatusCode, nil, err)
+
+	return latency, err
 }
 
-func (r *marathonClient) apiGet(path string, post, result interface{}) error {
-	return r.apiCall("GET", path, post, result)
+// ping sends the ping to the current member, replaying it with a new token when rejected
+func (r *marathonClient) ping(timeout time.Duration, span Span, metrics *RequestMetrics) (time.Duration, error) {
+	refreshed := false
+	for {
+		request, member, err := r.buildAPIRequest("GET", marathonAPIPing, nil)
+		if err != nil {
+			return 0, err
+		}
+		span.Inject(request.Header)
+
+		started := time.Now()
+		response, err := r.client.Do(request, timeout)
+		if err != nil {
+			if r.client.closed() {
+				return 0, ErrClientClosed
+			}
+			r.hosts.markDown(member)
+			return 0, err
+		}
+		latency := time.Since(started)
+		respBody, err := readResponseBody(response)
+		if err != nil {
+			return 0, err
+		}
+		metrics.StatusCode = response.StatusCode
+
+		if response.StatusCode == http.StatusOK {
+			r.hosts.markSuccess(member)
+			return latency, nil
+		}
+		if !refreshed && r.client.refreshesToken(response) {
+			refreshed = true
+			if err := r.client.refreshToken(request); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		r.hosts.markDown(member)
+		return 0, NewAPIError(response.StatusCode, respBody)
+	}
+}
+
+func (r *marathonClient) apiHead(operation, path string, result interface{}) error {
+	return r.apiCall(operation, "HEAD", path, nil, result)
+}
+
+func (r *marathonClient) apiGet(operation, path string, post, result interface{}) error {
+	return r.apiCall(operation, "GET", path, post, result)
 }
 
-func (r *marathonClient) apiPut(path string, post, result interface{}) error {
-	return r.apiCall("PUT", path, post, result)
+func (r *marathonClient) apiPut(operation, path string, post, result interface{}) error {
+	return r.apiCall(operation, "PUT", path, post, result)
 }
 
-func (r *marathonClient) apiPost(path string, post, result interface{}) error {
-	return r.apiCall("POST", path, post, result)
+func (r *marathonClient) apiPost(operation, path string, post, result interface{}) error {
+	return r.apiCall(operation, "POST", path, post, result)
 }
 
-func (r *marathonClient) apiDelete(path string, post, result interface{}) error {
-	return r.apiCall("DELETE", path, post, result)
+func (r *marathonClient) apiDelete(operation, path string, post, result interface{}) error {
+	return r.apiCall(operation, "DELETE", path, post, result)
 }
 
-func (r *marathonClient) apiCall(method, path string, body, result interface{}) error {
+func (r *marathonClient) apiCall(operation, method, path string, body, result interface{}) error {
 	const deploymentHeader = "Marathon-Deployment-Id"
 
-	for {
-		// step: marshall the request to json
-		var requestBody []byte
-		var err error
-		if body != nil {
-			if requestBody, err = json.Marshal(body); err != nil {
-				return err
+	// step: marshall the request to json
+	var requestBody []byte
+	var err error
+	if body != nil {
+		if requestBody, err = r.codec.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	response, respBody, err := r.apiRequest(operation, method, path, requestBody, "application/json", "application/json")
+	if err != nil {
+		return err
+	}
+
+	if result != nil {
+		// If we have a deployment ID header and no response body, give them that
+		// This specifically handles the use case of a DELETE on an app/pod
+		// We need a way to retrieve the deployment ID
+		deploymentID := response.Header.Get(deploymentHeader)
+		if len(respBody) == 0 && deploymentID != "" {
+			d := DeploymentID{
+				DeploymentID: deploymentID,
+			}
+			if deployID, ok := result.(*DeploymentID); ok {
+				*deployID = d
 			}
+		} else {
+			if err := r.codec.Unmarshal(respBody, result); err != nil {
+				return fmt.Errorf("failed to unmarshal response from Marathon: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// apiRequest performs the request on the members of the cluster until one of them responds, and
+// returns the successful response along with its body. Non-successful responses are returned
+// as APIError.
+//		operation:		the client method making the request, e.g. CreateApplication
+//		contentType:	the content type of the request body
+//		accept:			the content type of the response body
+func (r *marathonClient) apiRequest(operation, method, path string, requestBody []byte, contentType, accept string) (*http.Response, []byte, error) {
+	metrics := RequestMetrics{Method: method, Endpoint: metricsEndpoint(path)}
+	span := r.startSpan(operation, method, path)
+	start := time.Now()
+
+	response, respBody, err := r.sendAPIRequest(method, path, requestBody, contentType, accept, span, &metrics)
+
+	metrics.Duration = time.Since(start)
+	r.instrumentation.ObserveRequest(metrics)
+	endSpan(span, path, metrics.StatusCode, respBody, err)
+	if err == nil {
+		r.journalDeployment(method, path, requestBody, respBody)
+	}
+
+	return response, respBody, err
+}
+
+// sendAPIRequest sends the request to the members of the cluster until one of them handles it
+func (r *marathonClient) sendAPIRequest(method, path string, requestBody []byte, contentType, accept string, span Span, metrics *RequestMetrics) (*http.Response, []byte, error) {
+	// step: compress the large bodies when configured
+	sentBody := requestBody
+	compressed := r.config.GzipRequestThreshold > 0 && len(requestBody) >= r.config.GzipRequestThreshold
+	if compressed {
+		var err error
+		if sentBody, err = gzipBody(requestBody); err != nil {
+			return nil, nil, err
 		}
+	}
+	if contentType == "application/json" {
+		if err := r.checkPayloadSize(path, sentBody); err != nil {
+			return nil, nil, err
+		}
+	}
 
+	retriesAfter := 0
+	refreshed := false
+	for attempt := 0; ; attempt++ {
 		// step: create the API request
-		request, member, err := r.buildAPIRequest(method, path, bytes.NewReader(requestBody))
+		request, member, err := r.buildAPIRequest(method, path, bytes.NewReader(sentBody))
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
+		request.Header.Set("Content-Type", contentType)
+		request.Header.Set("Accept", accept)
+		request.Header.Set("Accept-Encoding", gzipEncoding)
+		if compressed {
+			request.Header.Set("Content-Encoding", gzipEncoding)
+		}
+		span.Inject(request.Header)
+		metrics.Retries = attempt
 
 		// step: perform the API request
-		response, err := r.client.Do(request)
+		response, err := r.client.Do(request, r.requestTimeout(method, path))
 		if err != nil {
 			r.hosts.markDown(member)
 			// step: attempt the request on another member
-			r.debugLog("apiCall(): request failed on host: %s, error: %s, trying another", member, err)
+			r.log(LogModuleAPI).Debugf("apiCall(): request failed on host: %s, error: %s, trying another", member, err)
 			continue
 		}
-		defer response.Body.Close()
 
 		// step: read the response body
-		respBody, err := ioutil.ReadAll(response.Body)
+		respBody, err := readResponseBody(response)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
-		if len(requestBody) > 0 {
-			r.debugLog("apiCall(): %v %v %s returned %v %s", request.Method, request.URL.String(), requestBody, response.Status, oneLogLine(respBody))
+		if len(requestBody) > 0 && contentType == "application/json" {
+			r.log(LogModuleAPI).Debugf("apiCall(): %v %v %s returned %v %s", request.Method, request.URL.String(), requestBody, response.Status, oneLogLine(respBody))
 		} else {
-			r.debugLog("apiCall(): %v %v returned %v %s", request.Method, request.URL.String(), response.Status, oneLogLine(respBody))
+			r.log(LogModuleAPI).Debugf("apiCall(): %v %v returned %v %s", request.Method, request.URL.String(), response.Status, oneLogLine(respBody))
+		}
+
+		// step: a follower redirected the request to the leader, re-route it there
+		if leader, found := leaderRedirect(request, response); found {
+			atomic.AddInt64(&r.followerResponses, 1)
+			r.log(LogModuleAPI).Infof("apiCall(): host: %s is a follower, re-routing the request to the leader: %s", member, leader)
+			if response, respBody, err = r.rerouteToLeader(request, leader, sentBody, r.requestTimeout(method, path)); err != nil {
+				return nil, nil, err
+			}
+			r.log(LogModuleAPI).Debugf("apiCall(): %v %v returned %v %s", request.Method, leader, response.Status, oneLogLine(respBody))
+		}
+		metrics.StatusCode = response.StatusCode
+		if response.StatusCode < 500 {
+			r.hosts.markSuccess(member)
 		}
 
 		// step: check for a successfull response
 		if response.StatusCode >= 200 && response.StatusCode <= 299 {
-			if result != nil {
-				// If we have a deployment ID header and no response body, give them that
-				// This specifically handles the use case of a DELETE on an app/pod
-				// We need a way to retrieve the deployment ID
-				deploymentID := response.Header.Get(deploymentHeader)
-				if len(respBody) == 0 && deploymentID != "" {
-					d := DeploymentID{
-						DeploymentID: deploymentID,
-					}
-					if deployID, ok := result.(*DeploymentID); ok {
-						*deployID = d
-					}
-				} else {
-					if err := json.Unmarshal(respBody, result); err != nil {
-						return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
-					}
-				}
+			return response, respBody, nil
+		}
+
+		// step: the token was rejected, e.g. expired, replay the request with a new one
+		if !refreshed && r.client.refreshesToken(response) {
+			refreshed = true
+			r.log(LogModuleAPI).Infof("apiCall(): the token was rejected, refreshing it")
+			if err := r.client.refreshToken(request); err != nil {
+				return nil, nil, err
 			}
-			return nil
+			continue
+		}
+
+		// step: the member asks to retry later, e.g. during a leader election
+		if delay, found := r.retryAfter(response); found && retriesAfter < maxRetryAfterRetries {
+			retriesAfter++
+			r.log(LogModuleAPI).Infof("apiCall(): host: %s is unavailable, retrying after %s", member, delay)
+			if err := r.sleep(delay); err != nil {
+				return nil, nil, err
+			}
+			continue
 		}
 
 		// step: if the member node returns a >= 500 && <= 599 we should try another node?
 		if response.StatusCode >= 500 && response.StatusCode <= 599 {
 			// step: mark the host as down
 			r.hosts.markDown(member)
-			r.debugLog("apiCall(): request failed, host: %s, status: %d, trying another", member, response.StatusCode)
+			r.log(LogModuleAPI).Debugf("apiCall(): request failed, host: %s, status: %d, trying another", member, response.StatusCode)
+			continue
+		}
+
+		return nil, nil, NewAPIError(response.StatusCode, respBody)
+	}
+}
+
+// rerouteToLeader re-issues a request, which was redirected by a follower, on the leader
+func (r *marathonClient) rerouteToLeader(request *http.Request, leader *url.URL, requestBody []byte, timeout time.Duration) (*http.Response, []byte, error) {
+	rerouted, err := http.NewRequest(request.Method, leader.String(), bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, nil, err
+	}
+	rerouted = rerouted.WithContext(request.Context())
+	rerouted.Header = request.Header
+
+	response, err := r.client.Do(rerouted, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	respBody, err := readResponseBody(response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response, respBody, nil
+}
+
+// apiStream performs the GET request on the members of the cluster until one of them responds, and
+// returns the body of the successful response, to be read as it streams in and closed. Non-successful
+// responses are returned as APIError.
+//		operation:	the client method making the request, e.g. EachApplication
+//		accept:		the content type of the response body
+func (r *marathonClient) apiStream(operation, path, accept string) (io.ReadCloser, error) {
+	metrics := RequestMetrics{Method: "GET", Endpoint: metricsEndpoint(path)}
+	span := r.startSpan(operation, "GET", path)
+	start := time.Now()
+
+	body, err := r.sendAPIStream(path, accept, span, &metrics)
+
+	metrics.Duration = time.Since(start)
+	r.instrumentation.ObserveRequest(metrics)
+	endSpan(span, path, metrics.StatusCode, nil, err)
+
+	return body, err
+}
+
+// sendAPIStream sends the GET request to the members of the cluster until one of them handles it
+func (r *marathonClient) sendAPIStream(path, accept string, span Span, metrics *RequestMetrics) (io.ReadCloser, error) {
+	retriesAfter := 0
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		request, member, err := r.buildAPIRequest("GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Accept", accept)
+		request.Header.Set("Accept-Encoding", gzipEncoding)
+		span.Inject(request.Header)
+		metrics.Retries = attempt
+
+		response, err := r.client.Do(request, r.requestTimeout("GET", path))
+		if err != nil {
+			r.hosts.markDown(member)
+			r.log(LogModuleAPI).Debugf("apiStream(): request failed on host: %s, error: %s, trying another", member, err)
 			continue
 		}
+		r.log(LogModuleAPI).Debugf("apiStream(): %v %v returned %v", request.Method, request.URL.String(), response.Status)
+
+		// step: a follower redirected the request to the leader, re-route it there
+		if leader, found := leaderRedirect(request, response); found {
+			response.Body.Close()
+			atomic.AddInt64(&r.followerResponses, 1)
+			rerouted, err := http.NewRequest("GET", leader.String(), nil)
+			if err != nil {
+				return nil, err
+			}
+			rerouted = rerouted.WithContext(request.Context())
+			rerouted.Header = request.Header
+			if response, err = r.client.Do(rerouted, r.requestTimeout("GET", path)); err != nil {
+				return nil, err
+			}
+		}
+		metrics.StatusCode = response.StatusCode
+		if response.StatusCode < 500 {
+			r.hosts.markSuccess(member)
+		}
 
-		return NewAPIError(response.StatusCode, respBody)
+		if response.StatusCode >= 200 && response.StatusCode <= 299 {
+			return responseBodyReader(response)
+		}
+
+		respBody, err := readResponseBody(response)
+		if err != nil {
+			return nil, err
+		}
+		if !refreshed && r.client.refreshesToken(response) {
+			refreshed = true
+			r.log(LogModuleAPI).Infof("apiStream(): the token was rejected, refreshing it")
+			if err := r.client.refreshToken(request); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if delay, found := r.retryAfter(response); found && retriesAfter < maxRetryAfterRetries {
+			retriesAfter++
+			r.log(LogModuleAPI).Infof("apiStream(): host: %s is unavailable, retrying after %s", member, delay)
+			if err := r.sleep(delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if response.StatusCode >= 500 && response.StatusCode <= 599 {
+			r.hosts.markDown(member)
+			r.log(LogModuleAPI).Debugf("apiStream(): request failed, host: %s, status: %d, trying another", member, response.StatusCode)
+			continue
+		}
+
+		return nil, NewAPIError(response.StatusCode, respBody)
+	}
+}
+
+// Close closes the client: the events listeners are removed, closing their channels, the requests
+// in flight are cancelled, the waits stop with ErrClientClosed and the idle connections of the
+// transport created for the client, if any, are closed. The requests made once closed fail with
+// ErrClientClosed. Closing the client again has no effect.
+func (r *marathonClient) Close() error {
+	r.closeOnce.Do(func() {
+		// step: remove the listeners, unsubscribing the callback and closing the event stream
+		r.RLock()
+		channels := make([]EventsChannel, 0, len(r.listeners))
+		for channel := range r.listeners {
+			channels = append(channels, channel)
+		}
+		r.RUnlock()
+		for _, channel := range channels {
+			r.RemoveEventsListener(channel)
+		}
+
+		// step: cancel the requests in flight, which stops the waits and the health checks
+		r.client.cancel()
+
+		r.Lock()
+		if r.eventsHTTP != nil {
+			r.eventsHTTP.Close()
+		}
+		sseDone := r.sseDone
+		r.Unlock()
+
+		// step: wait for the event stream to close its connection
+		if sseDone != nil {
+			<-sseDone
+		}
+
+		// step: the transports shared with other clients are left alone
+		if transport, ok := r.transport.(interface {
+			CloseIdleConnections()
+		}); ok {
+			transport.CloseIdleConnections()
+		}
+	})
+	return nil
+}
+
+// retryAfter returns the delay of the Retry-After header of the 503 Service Unavailable response,
+// in seconds or as a date, and false when there is none or it exceeds Config.MaxRetryAfter
+func (r *marathonClient) retryAfter(response *http.Response) (time.Duration, bool) {
+	maxDelay := r.config.MaxRetryAfter
+	if maxDelay == 0 {
+		maxDelay = defaultMaxRetryAfter
 	}
+	header := response.Header.Get("Retry-After")
+	if response.StatusCode != http.StatusServiceUnavailable || header == "" || maxDelay < 0 {
+		return 0, false
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, delay <= maxDelay
+}
+
+// sleep waits for the delay, returning ErrClientClosed if the client is closed meanwhile
+func (r *marathonClient) sleep(delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-r.client.done():
+		return ErrClientClosed
+	}
+}
+
+// FollowerResponses returns the number of requests which were redirected by a follower and
+// re-routed to the leader. A growing number indicates requests are routed to followers.
+func (r *marathonClient) FollowerResponses() int64 {
+	return atomic.LoadInt64(&r.followerResponses)
+}
+
+// leaderRedirect checks if the response is a redirect of a follower to the leader, and if so,
+// returns the location of the request on the leader.
+func leaderRedirect(request *http.Request, response *http.Response) (*url.URL, bool) {
+	switch response.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, 308:
+	default:
+		return nil, false
+	}
+
+	location, err := response.Location()
+	if err != nil || !isLeaderRedirect(request, location) {
+		return nil, false
+	}
+	return location, true
+}
+
+// isLeaderRedirect checks if the location refers to the same API endpoint on another host, as
+// opposed to e.g. a redirect to a login page
+func isLeaderRedirect(request *http.Request, location *url.URL) bool {
+	return location.Host != request.URL.Host && location.Path == request.URL.Path
 }
 
 // wait waits until the provided function returns true (or times out)
 func (r *marathonClient) wait(name string, timeout time.Duration, fn func(string) bool) error {
-	timer := time.NewTimer(timeout)
+	timer := time.NewTimer(r.waitTimeout(timeout))
 	defer timer.Stop()
 
-	ticker := time.NewTicker(r.config.PollingWaitTime)
-	defer ticker.Stop()
+	events, poll := r.waitListener()
+	if events != nil {
+		defer r.RemoveEventsListener(events)
+	}
 	for {
 		if fn(name) {
 			return nil
 		}
+		r.log(LogModuleWaits).Debugf("wait(): %s is not ready yet", name)
+		if err := r.waitNext(name, timer.C, poll.next(), events); err != nil {
+			r.log(LogModuleWaits).Infof("wait(): timed out waiting on %s", name)
+			return err
+		}
+	}
+}
+
+// waitEvents are the events the event driven waits check again on
+const waitEvents = EventIDStatusUpdate | EventIDChangedHealthCheck | EventIDDeploymentSuccess | EventIDDeploymentFailed
+
+// isUnderPath checks if the application id is the path or under it
+func isUnderPath(id, path string) bool {
+	id, path = trimRootPath(id), trimRootPath(path)
+	return id == path || strings.HasPrefix(id, path+"/")
+}
 
+// waitListener subscribes to the events of the event driven waits, returning the events and the
+// poller of the wait. The events are nil when the waits poll
+func (r *marathonClient) waitListener() (EventsChannel, *poller) {
+	if !r.config.EventDrivenWaits {
+		return nil, newPoller(r.config, r.config.PollingWaitTime)
+	}
+	events, err := r.AddEventsListener(waitEvents)
+	if err != nil {
+		r.log(LogModuleWaits).Infof("waitListener(): polling, unable to subscribe to the events: %s", err)
+		return nil, newPoller(r.config, r.config.PollingWaitTime)
+	}
+	return events, newPoller(r.config, eventDrivenPollingFactor*r.config.PollingWaitTime)
+}
+
+// waitNext blocks until the next check of a wait: the next poll or the next event concerning
+// the application or deployment, returning ErrTimeoutError on the timeout
+//		id:			the id of the application or deployment
+//		timeout:	the channel of the timeout
+//		waitTime:	the wait time before the next poll
+//		events:		the events of the wait, nil when polling
+func (r *marathonClient) waitNext(id string, timeout <-chan time.Time, waitTime time.Duration, events EventsChannel) error {
+	poll := time.NewTimer(waitTime)
+	defer poll.Stop()
+	for {
 		select {
-		case <-timer.C:
+		case <-timeout:
 			return ErrTimeoutError
-		case <-ticker.C:
-			continue
+		case <-r.client.done():
+			return ErrClientClosed
+		case <-poll.C:
+			return nil
+		case event := <-events:
+			if waitEventConcerns(event, id) {
+				return nil
+			}
 		}
 	}
 }
 
+// waitEventConcerns checks if the event may change the outcome of the wait on the id, i.e. the
+// application or group. Any deployment event does, as it may finish the deployment of the application
+func waitEventConcerns(event *Event, id string) bool {
+	switch e := event.Event.(type) {
+	case *EventStatusUpdate:
+		return isUnderPath(e.AppID, id)
+	case *EventHealthCheckChanged:
+		return isUnderPath(e.AppID, id)
+	case *EventDeploymentSuccess, *EventDeploymentFailed:
+		return true
+	}
+	return false
+}
+
+// requestTimeout returns the timeout of the API request, zero for the timeout of the HTTP client
+func (r *marathonClient) requestTimeout(method, path string) time.Duration {
+	endpoint := metricsEndpoint(path)
+	if timeout, found := r.config.RequestTimeouts[method+" "+endpoint]; found {
+		return timeout
+	}
+	if timeout, found := r.config.RequestTimeouts[endpoint]; found {
+		return timeout
+	}
+	return r.config.RequestTimeout
+}
+
+// waitTimeout returns the time to wait for a deployment, the configured deployment timeout when no
+// timeout is given
+func (r *marathonClient) waitTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	if r.config.DeploymentTimeout > 0 {
+		return r.config.DeploymentTimeout
+	}
+	return defaultDeploymentTimeout
+}
+
 // buildAPIRequest creates a default API request.
 // It fails when there is no available member in the cluster anymore or when the request can not be built.
 func (r *marathonClient) buildAPIRequest(method, path string, reader io.Reader) (request *http.Request, member string, err error) {
-	// Grab a member from the cluster
+	if r.client.closed() {
+		return nil, "", ErrClientClosed
+	}
+
+	// Grab a member from the cluster, discovering the members again when they are all down
+	r.hosts.discover(false)
 	member, err = r.hosts.getMember()
+	if err != nil && r.hosts.discover(true) {
+		member, err = r.hosts.getMember()
+	}
 	if err != nil {
-		return nil, "", ErrMarathonDown
+		// step: either ErrMarathonDown or ErrCircuitOpen
+		return nil, "", err
 	}
 
 	// Build the HTTP request to Marathon
@@ -446,8 +1065,8 @@ func (r *marathonClient) buildAPIRequest(method, path string, reader io.Reader)
 func (rc *httpClient) buildMarathonJSONRequest(method, member, path string, reader io.Reader) (request *http.Request, err error) {
 	req, err := rc.buildMarathonRequest(method, member, path, reader)
 	if err == nil {
-		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
 	}
 
 	return req, err
@@ -468,21 +1087,71 @@ func (rc *httpClient) buildMarathonRequest(method, member, path string, reader i
 	if err != nil {
 		return nil, err
 	}
+	if rc.ctx != nil {
+		request = request.WithContext(rc.ctx)
+	}
+
+	// Add the default headers, which the headers set below take precedence over
+	for name, values := range rc.config.Headers {
+		request.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+	if rc.config.UserAgent != "" {
+		request.Header.Set("User-Agent", rc.config.UserAgent)
+	}
 
 	// Add any basic auth and the content headers
 	if rc.config.HTTPBasicAuthUser != "" && rc.config.HTTPBasicPassword != "" {
 		request.SetBasicAuth(rc.config.HTTPBasicAuthUser, rc.config.HTTPBasicPassword)
 	}
 
-	if rc.config.DCOSToken != "" {
-		request.Header.Add("Authorization", "token="+rc.config.DCOSToken)
+	if token := rc.token(); token != "" {
+		request.Header.Set("Authorization", "token="+token)
 	}
 
 	return request, nil
 }
 
-func (rc *httpClient) Do(request *http.Request) (response *http.Response, err error) {
-	return rc.config.HTTPClient.Do(request)
+// done returns the channel closed once the client is closed, nil when it can't be closed
+func (rc *httpClient) done() <-chan struct{} {
+	if rc.ctx == nil {
+		return nil
+	}
+	return rc.ctx.Done()
+}
+
+// closed checks if the client is closed
+func (rc *httpClient) closed() bool {
+	return rc.ctx != nil && rc.ctx.Err() != nil
+}
+
+// Do performs the request. Redirects of followers to the leader are not followed, as the HTTP
+// client would otherwise drop the body of mutating requests; they are re-routed by the caller.
+//		request:	the request to perform
+//		timeout:	overrides the timeout of the HTTP client when positive
+func (rc *httpClient) Do(request *http.Request, timeout time.Duration) (response *http.Response, err error) {
+	client := *rc.config.HTTPClient
+	if timeout > 0 {
+		client.Timeout = timeout
+	}
+	checkRedirect := client.CheckRedirect
+	client.CheckRedirect = func(redirect *http.Request, via []*http.Request) error {
+		if isLeaderRedirect(via[len(via)-1], redirect.URL) {
+			return http.ErrUseLastResponse
+		}
+		if checkRedirect != nil {
+			return checkRedirect(redirect, via)
+		}
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+	// step: the dump is the innermost, seeing the requests as changed by the middleware
+	var doer Doer = &client
+	if rc.config.WireDump != nil {
+		doer = rc.config.WireDump.wrap(doer)
+	}
+	return chainMiddleware(doer, rc.config.Middleware).Do(request)
 }
 
 var oneLogLineRegex = regexp.MustCompile(`(?m)^\s*`)
@@ -18,9 +18,15 @@ package marathon
 
 import (
 	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"net/http"
+	"net/http/httptest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -97,6 +103,126 @@ func TestHTTPClientDefaults(t *testing.T) {
 	}
 }
 
+type countingTransport struct {
+	requests int32
+}
+
+func (c *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&c.requests, 1)
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestTransport(t *testing.T) {
+	transport := new(countingTransport)
+	config := NewDefaultConfig()
+	config.Transport = transport
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	conf := endpoint.Client.(*marathonClient).config
+	assert.Equal(t, transport, conf.HTTPClient.Transport)
+	assert.Equal(t, defaultHTTPClient.Timeout, conf.HTTPClient.Timeout)
+	assert.Equal(t, transport, conf.HTTPSSEClient.Transport)
+	assert.Zero(t, conf.HTTPSSEClient.Timeout)
+
+	_, err := endpoint.Client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.requests))
+
+	// step: the transport is ignored when the clients are configured
+	config = NewDefaultConfig()
+	config.Transport = transport
+	config.HTTPClient = http.DefaultClient
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	conf = client.(*marathonClient).config
+	assert.Equal(t, http.DefaultClient, conf.HTTPClient)
+	assert.Equal(t, http.DefaultClient, conf.HTTPSSEClient)
+}
+
+func TestPathPrefix(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"apps": [{"id": "/web"}]}`))
+	}))
+	defer server.Close()
+
+	for _, prefix := range []string{"/service/marathon-user", "/service/marathon-user/"} {
+		paths = nil
+		config := NewDefaultConfig()
+		config.URL = server.URL + prefix
+		client, err := NewClient(config)
+		require.NoError(t, err)
+
+		_, err = client.Ping()
+		require.NoError(t, err)
+		applications, err := client.ListApplications(nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/web"}, applications)
+		assert.Equal(t, []string{"/service/marathon-user/ping", "/service/marathon-user/v2/apps"}, paths)
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header)
+		w.Write([]byte(`pong`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.UserAgent = "deployer/1.2"
+	config.DCOSToken = "secret"
+	config.Headers = http.Header{
+		"x-tenant":      {"payments"},
+		"Authorization": {"overridden"},
+		"Accept":        {"text/plain"},
+	}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Ping()
+	require.NoError(t, err)
+	require.Len(t, headers, 1)
+	assert.Equal(t, "deployer/1.2", headers[0].Get("User-Agent"))
+	assert.Equal(t, "payments", headers[0].Get("X-Tenant"))
+	// step: the authentication and content headers take precedence
+	assert.Equal(t, []string{"token=secret"}, headers[0]["Authorization"])
+	assert.Equal(t, []string{"application/json"}, headers[0]["Accept"])
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"id": "/", "apps": []}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.RequestTimeout = 10 * time.Millisecond
+	config.RequestTimeouts = map[string]time.Duration{"GET /v2/groups": time.Second}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Group("/")
+	assert.NoError(t, err)
+	_, err = client.Ping()
+	assert.Error(t, err)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	client := &marathonClient{config: NewDefaultConfig()}
+	assert.Equal(t, time.Second, client.waitTimeout(time.Second))
+	assert.Equal(t, defaultDeploymentTimeout, client.waitTimeout(0))
+
+	client.config.DeploymentTimeout = time.Minute
+	assert.Equal(t, time.Minute, client.waitTimeout(0))
+}
+
 func TestLogOutput(t *testing.T) {
 	buf := bytes.NewBuffer(nil)
 	config := Config{
@@ -107,11 +233,90 @@ func TestLogOutput(t *testing.T) {
 	cl, err := NewClient(config)
 	require.Nil(t, err)
 
-	cl.(*marathonClient).debugLog("this is a %s", "test")
+	cl.(*marathonClient).logger.Debugf("this is a %s", "test")
 
 	assert.Equal(t, "this is a test\n", buf.String())
 }
 
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Debugf(format string, v ...interface{}) {
+	l.messages = append(l.messages, "debug: "+fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Infof(format string, v ...interface{}) {
+	l.messages = append(l.messages, "info: "+fmt.Sprintf(format, v...))
+}
+
+func (l *recordingLogger) Errorf(format string, v ...interface{}) {
+	l.messages = append(l.messages, "error: "+fmt.Sprintf(format, v...))
+}
+
+func TestLogger(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	logger := new(recordingLogger)
+	config := Config{
+		URL:       "http://marathon",
+		LogOutput: buf,
+		Logger:    logger,
+	}
+
+	cl, err := NewClient(config)
+	require.Nil(t, err)
+
+	cl.(*marathonClient).logger.Debugf("this is a %s", "test")
+	cl.(*marathonClient).logger.Errorf("this is an %s", "error")
+
+	assert.Equal(t, []string{"debug: this is a test", "error: this is an error"}, logger.messages)
+	assert.Empty(t, buf.String())
+
+	// step: without a logger nor an output the messages are discarded
+	cl, err = NewClient(Config{URL: "http://marathon"})
+	require.Nil(t, err)
+	assert.Equal(t, noopLogger{}, cl.(*marathonClient).logger)
+}
+
+func TestLogLevels(t *testing.T) {
+	logger := new(recordingLogger)
+	levels := NewLogLevels(LogLevelError).Set(LogModuleEvents, LogLevelDebug)
+	config := Config{
+		URL:       "http://marathon",
+		Logger:    logger,
+		LogLevels: levels,
+	}
+
+	cl, err := NewClient(config)
+	require.Nil(t, err)
+	client := cl.(*marathonClient)
+
+	client.log(LogModuleEvents).Debugf("event %d", 1)
+	client.log(LogModuleAPI).Debugf("call %d", 1)
+	client.log(LogModuleAPI).Infof("call %d", 2)
+	client.log(LogModuleAPI).Errorf("call %d", 3)
+	client.hosts.logger.Infof("host %d", 1)
+
+	// step: the levels apply at runtime
+	levels.Set(LogModuleEvents, LogLevelOff).SetDefault(LogLevelInfo)
+	client.log(LogModuleEvents).Errorf("event %d", 2)
+	client.log(LogModuleAPI).Infof("call %d", 4)
+	client.hosts.logger.Infof("host %d", 2)
+
+	assert.Equal(t, []string{
+		"debug: event 1",
+		"error: call 3",
+		"info: call 4",
+		"info: host 2",
+	}, logger.messages)
+	assert.Equal(t, LogLevelInfo, levels.Level(LogModuleWaits))
+	assert.Equal(t, LogLevelOff, levels.Level(LogModuleEvents))
+
+	// step: without log levels all the messages are logged
+	client.config.LogLevels = nil
+	assert.Equal(t, logger, client.log(LogModuleAPI))
+}
+
 func TestInvalidConfig(t *testing.T) {
 	config := Config{
 		URL: "",
@@ -129,6 +334,30 @@ func TestPing(t *testing.T) {
 	assert.True(t, pong)
 }
 
+func TestPingLatency(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	latency, err := endpoint.Client.PingLatency(time.Second)
+	assert.NoError(t, err)
+	assert.True(t, latency > 0)
+
+	// step: the ping goes through the middleware and resets the failures of the member
+	client := endpoint.Client.(*marathonClient)
+	var pings int
+	client.client.config.Middleware = []Middleware{func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			pings++
+			return next.Do(request)
+		})
+	}}
+	client.hosts.members[0].failures = 2
+	_, err = endpoint.Client.PingLatency(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pings)
+	assert.Equal(t, 0, client.hosts.members[0].failures)
+}
+
 func TestGetMarathonURL(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -330,3 +559,385 @@ func TestAPIRequestDCOS(t *testing.T) {
 		endpoint.Close()
 	}
 }
+
+func TestFollowerRedirect(t *testing.T) {
+	var leaderBody []byte
+	leader := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaderBody, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "/fake-app"}`))
+	}))
+	defer leader.Close()
+	follower := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusFound
+		if r.Method == "PUT" {
+			status = http.StatusTemporaryRedirect
+		}
+		http.Redirect(w, r, leader.URL+r.URL.Path, status)
+	}))
+	defer follower.Close()
+
+	config := NewDefaultConfig()
+	config.URL = follower.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	application, err := client.CreateApplication(new(Application).Name(fakeAppName).Count(1))
+	require.NoError(t, err)
+	assert.Equal(t, fakeAppName, application.ID)
+	assert.Contains(t, string(leaderBody), `"instances":1`)
+	assert.Equal(t, int64(1), client.FollowerResponses())
+
+	_, err = client.Application(fakeAppName)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), client.FollowerResponses())
+
+	// step: the body of the updates is replayed on the leader as well
+	_, err = client.UpdateApplication(new(Application).Name(fakeAppName).Count(3), false)
+	require.NoError(t, err)
+	assert.Contains(t, string(leaderBody), `"instances":3`)
+	assert.Equal(t, int64(3), client.FollowerResponses())
+}
+
+func TestLeaderRedirect(t *testing.T) {
+	request, _ := http.NewRequest("GET", "http://follower:8080/v2/apps", nil)
+
+	cases := []struct {
+		StatusCode int
+		Location   string
+		Expected   bool
+	}{
+		{StatusCode: http.StatusTemporaryRedirect, Location: "http://leader:8080/v2/apps", Expected: true},
+		{StatusCode: http.StatusFound, Location: "http://leader:8080/v2/apps", Expected: true},
+		{StatusCode: http.StatusFound, Location: "http://follower:8080/v2/apps", Expected: false},
+		{StatusCode: http.StatusFound, Location: "http://login:8080/login", Expected: false},
+		{StatusCode: http.StatusOK, Location: "http://leader:8080/v2/apps", Expected: false},
+	}
+	for _, x := range cases {
+		response := &http.Response{
+			StatusCode: x.StatusCode,
+			Header:     http.Header{"Location": []string{x.Location}},
+			Request:    request,
+		}
+		_, found := leaderRedirect(request, response)
+		assert.Equal(t, x.Expected, found, "%d %s", x.StatusCode, x.Location)
+	}
+}
+
+func TestWaitEventConcerns(t *testing.T) {
+	cases := []struct {
+		event    *Event
+		expected bool
+	}{
+		{&Event{Event: &EventStatusUpdate{AppID: "/web"}}, true},
+		{&Event{Event: &EventStatusUpdate{AppID: "/api"}}, false},
+		{&Event{Event: &EventStatusUpdate{AppID: "/web/frontend"}}, true},
+		{&Event{Event: &EventStatusUpdate{AppID: "/webapp"}}, false},
+		{&Event{Event: &EventHealthCheckChanged{AppID: "web"}}, true},
+		{&Event{Event: &EventHealthCheckChanged{AppID: "/api"}}, false},
+		{&Event{Event: &EventDeploymentSuccess{ID: "deployment"}}, true},
+		{&Event{Event: &EventDeploymentFailed{ID: "deployment"}}, true},
+		{&Event{Event: &EventAPIRequest{}}, false},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.expected, waitEventConcerns(c.event, "/web"), "case %d", i)
+	}
+}
+
+func TestWaitNext(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+	client := endpoint.Client.(*marathonClient)
+
+	timeout := make(chan time.Time, 1)
+	events := make(EventsChannel, 2)
+
+	// step: the events of other applications are skipped
+	events <- &Event{Event: &EventStatusUpdate{AppID: "/api"}}
+	events <- &Event{Event: &EventStatusUpdate{AppID: "/web"}}
+	assert.NoError(t, client.waitNext("/web", timeout, time.Hour, events))
+	assert.Empty(t, events)
+
+	assert.NoError(t, client.waitNext("/web", timeout, time.Millisecond, nil))
+
+	timeout <- time.Now()
+	assert.Equal(t, ErrTimeoutError, client.waitNext("/web", timeout, time.Hour, nil))
+}
+
+func TestEventDrivenWaits(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	config.EventDrivenWaits = true
+	config.PollingWaitTime = 10 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	client := endpoint.Client.(*marathonClient)
+
+	assert.NoError(t, client.WaitOnApplication(fakeAppName, 50*time.Millisecond))
+	err := client.WaitOnApplicationHealthy(fakeAppNameUnhealthy, 50*time.Millisecond)
+	assert.Equal(t, ReasonTimeout, Reason(err))
+
+	// step: the waits unsubscribe once done
+	client.RLock()
+	defer client.RUnlock()
+	assert.Empty(t, client.listeners)
+}
+
+func TestClose(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	config.PollingWaitTime = 10 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	events, err := client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+	waited := make(chan error, 1)
+	go func() {
+		waited <- client.WaitOnApplicationHealthy(fakeAppNameUnhealthy, time.Hour)
+	}()
+	time.Sleep(SSEConnectWaitTime)
+
+	require.NoError(t, client.Close())
+	select {
+	case err := <-waited:
+		assert.Equal(t, ErrClientClosed, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the wait did not stop")
+	}
+	select {
+	case _, open := <-events:
+		assert.False(t, open)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the events channel was not closed")
+	}
+
+	// step: the closed client fails fast
+	_, err = client.Ping()
+	assert.Equal(t, ErrClientClosed, err)
+	_, err = client.AddEventsListener(EventIDApplications)
+	assert.Equal(t, ErrClientClosed, err)
+	assert.NoError(t, client.Close())
+}
+
+func TestCloseStopsWatchers(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	appWatcher, err := WatchApplication(client, fakeAppName, &AppWatcherOpts{Interval: time.Hour})
+	require.NoError(t, err)
+	deploymentWatcher, err := WatchDeployment(client, fakeDeploymentID, &DeploymentWatcherOpts{Interval: time.Hour})
+	require.NoError(t, err)
+	time.Sleep(SSEConnectWaitTime)
+
+	// step: the events keep coming while the watchers are stopped and the client closed
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := 0; i < 50; i++ {
+			for _, testCase := range testCases {
+				endpoint.Server.PublishEvent(testCase.source)
+			}
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	appWatcher.Stop()
+	deploymentWatcher.Stop()
+	require.NoError(t, client.Close())
+	<-published
+
+	// step: the channels of the watchers are closed once drained
+	for range appWatcher.Changes() {
+	}
+	for range deploymentWatcher.Progress() {
+	}
+}
+
+func TestCloseOwnedTransport(t *testing.T) {
+	// step: the default transports are shared with the other clients
+	client, err := NewClient(NewDefaultConfig())
+	require.NoError(t, err)
+	assert.Nil(t, client.(*marathonClient).transport)
+	require.NoError(t, client.Close())
+
+	config := NewDefaultConfig()
+	config.TLSInsecureSkipVerify = true
+	client, err = NewClient(config)
+	require.NoError(t, err)
+	assert.NotNil(t, client.(*marathonClient).transport)
+	require.NoError(t, client.Close())
+}
+
+func TestCloseCancelsRequests(t *testing.T) {
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	pinged := make(chan error, 1)
+	go func() {
+		_, err := client.Ping()
+		pinged <- err
+	}()
+	<-received
+	require.NoError(t, client.Close())
+	select {
+	case err := <-pinged:
+		assert.Equal(t, ErrClientClosed, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the request was not cancelled")
+	}
+}
+
+func TestClientConcurrentUse(t *testing.T) {
+	var requests int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// step: every other request fails, marking the members down and up again
+		if atomic.AddInt64(&requests, 1)%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"apps": []}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL + "," + server.URL + "/"
+	config.EventsTransport = EventsTransportSSE
+	endpoint, err := NewClient(config)
+	require.NoError(t, err)
+	defer endpoint.Close()
+	client := endpoint.(*marathonClient)
+	client.hosts.healthCheckInterval = time.Millisecond
+
+	// step: the workers share the client
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				client.Applications(nil)
+				client.Ping()
+				client.SubscriptionURL()
+				events, err := client.AddEventsListener(EventIDApplications)
+				require.NoError(t, err)
+				client.handleEvent(`{"eventType": "app_terminated_event", "appId": "/web"}`)
+				client.RemoveEventsListener(events)
+			}
+		}()
+	}
+	wg.Wait()
+
+	client.RLock()
+	defer client.RUnlock()
+	assert.Empty(t, client.listeners)
+}
+
+func TestRetryAfter(t *testing.T) {
+	client := &marathonClient{}
+	cases := []struct {
+		status   int
+		header   string
+		max      time.Duration
+		delay    time.Duration
+		expected bool
+	}{
+		{http.StatusServiceUnavailable, "5", 0, 5 * time.Second, true},
+		{http.StatusServiceUnavailable, "0", 0, 0, true},
+		{http.StatusServiceUnavailable, "-3", 0, 0, true},
+		{http.StatusServiceUnavailable, "Wed, 21 Oct 2015 07:28:00 GMT", 0, 0, true},
+		{http.StatusServiceUnavailable, "120", 0, 0, false},
+		{http.StatusServiceUnavailable, "120", 5 * time.Minute, 2 * time.Minute, true},
+		{http.StatusServiceUnavailable, "5", -1, 0, false},
+		{http.StatusServiceUnavailable, "soon", 0, 0, false},
+		{http.StatusServiceUnavailable, "", 0, 0, false},
+		{http.StatusInternalServerError, "5", 0, 0, false},
+	}
+	for _, x := range cases {
+		client.config.MaxRetryAfter = x.max
+		response := &http.Response{StatusCode: x.status, Header: http.Header{}}
+		if x.header != "" {
+			response.Header.Set("Retry-After", x.header)
+		}
+		delay, found := client.retryAfter(response)
+		assert.Equal(t, x.expected, found, "%d %q", x.status, x.header)
+		if x.expected {
+			assert.Equal(t, x.delay, delay, "%d %q", x.status, x.header)
+		}
+	}
+}
+
+func TestRetryAfterRequest(t *testing.T) {
+	var requests int64
+	retryAfter := "0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// step: the leader is elected on the third request
+		if atomic.AddInt64(&requests, 1) < 3 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"apps": []}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	endpoint, err := NewClient(config)
+	require.NoError(t, err)
+	client := endpoint.(*marathonClient)
+
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&requests))
+	// step: the host was not marked down
+	assert.Len(t, client.hosts.activeMembers(), 1)
+
+	// step: the delays exceeding the maximum are failures of the host
+	atomic.StoreInt64(&requests, 0)
+	retryAfter = "120"
+	_, err = client.Applications(nil)
+	assert.Equal(t, ErrMarathonDown, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&requests))
+}
+
+func TestRetryAfterClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	requested := make(chan error, 1)
+	go func() {
+		_, err := client.Applications(nil)
+		requested <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, client.Close())
+	select {
+	case err := <-requested:
+		assert.Equal(t, ErrClientClosed, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the retry was not stopped")
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "reflect"
+
+// Clone creates a deep copy of the application, sharing no pointer, slice or map with the
+// original, e.g. the containers, health checks, env, labels and volumes. The read-only fields
+// are copied too.
+func (r *Application) Clone() *Application {
+	if r == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(r)).Interface().(*Application)
+}
+
+// Clone creates a deep copy of the container, e.g. its docker parameters, port mappings and volumes
+func (container *Container) Clone() *Container {
+	if container == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(container)).Interface().(*Container)
+}
+
+// Clone creates a deep copy of the health check
+func (h *HealthCheck) Clone() *HealthCheck {
+	if h == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(h)).Interface().(*HealthCheck)
+}
+
+// deepCopy returns a copy of the value, recursively copying the pointers, slices, maps and
+// interfaces it holds. A nil remains nil and an empty slice or map remains empty, since Marathon
+// tells them apart.
+func deepCopy(value reflect.Value) reflect.Value {
+	copied := reflect.New(value.Type()).Elem()
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			element := reflect.New(value.Type().Elem())
+			element.Elem().Set(deepCopy(value.Elem()))
+			copied.Set(element)
+		}
+	case reflect.Interface:
+		if !value.IsNil() {
+			copied.Set(deepCopy(value.Elem()))
+		}
+	case reflect.Slice:
+		if !value.IsNil() {
+			copied.Set(reflect.MakeSlice(value.Type(), value.Len(), value.Len()))
+			for i := 0; i < value.Len(); i++ {
+				copied.Index(i).Set(deepCopy(value.Index(i)))
+			}
+		}
+	case reflect.Map:
+		if !value.IsNil() {
+			copied.Set(reflect.MakeMapWithSize(value.Type(), value.Len()))
+			for _, key := range value.MapKeys() {
+				copied.SetMapIndex(deepCopy(key), deepCopy(value.MapIndex(key)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopy(value.Index(i)))
+		}
+	case reflect.Struct:
+		copied.Set(value)
+		for i := 0; i < value.NumField(); i++ {
+			if copied.Field(i).CanSet() {
+				copied.Field(i).Set(deepCopy(value.Field(i)))
+			}
+		}
+	default:
+		copied.Set(value)
+	}
+	return copied
+}
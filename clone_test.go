@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationClone(t *testing.T) {
+	original := NewDockerApplication().Name("/web").Count(2).
+		AddArgs("serve").
+		AddEnv("STAGE", "prod").
+		AddLabel("tier", "frontend").
+		AddSecret("TOKEN", "token", "/secrets/token").
+		AddHealthCheck(*NewDefaultHealthCheck())
+	original.Container.Volume("/host", "/container", "RW")
+	original.Container.Docker.Container("nginx").AddParameter("memory", "1g").Expose(80)
+	original.EmptyUris()
+	original.Tasks = []*Task{{ID: "web.1"}}
+
+	copied := original.Clone()
+	require.NotNil(t, copied)
+	assert.Equal(t, original, copied)
+
+	// step: mutating the copy leaves the original untouched
+	copied.Name("/api").Count(1).AddArgs("--debug")
+	copied.AddEnv("STAGE", "dev").AddLabel("tier", "backend")
+	(*copied.Secrets)["token"] = Secret{EnvVar: "TOKEN", Source: "/secrets/other"}
+	copied.Container.Volume("/other", "/other", "RO")
+	(*copied.Container.Volumes)[0].Mode = "RO"
+	copied.Container.Docker.Image = "httpd"
+	(*copied.Container.Docker.Parameters)[0].Value = "2g"
+	(*copied.Container.Docker.PortMappings)[0].ContainerPort = 8080
+	(*copied.HealthChecks)[0].SetPath("/health")
+	copied.Tasks[0].ID = "api.1"
+
+	assert.Equal(t, "/web", original.ID)
+	assert.Equal(t, 2, *original.Instances)
+	assert.Equal(t, []string{"serve"}, *original.Args)
+	assert.Equal(t, map[string]string{"STAGE": "prod"}, *original.Env)
+	assert.Equal(t, map[string]string{"tier": "frontend"}, *original.Labels)
+	assert.Equal(t, "/secrets/token", (*original.Secrets)["token"].Source)
+	require.Len(t, *original.Container.Volumes, 1)
+	assert.Equal(t, "RW", (*original.Container.Volumes)[0].Mode)
+	assert.Equal(t, "nginx", original.Container.Docker.Image)
+	assert.Equal(t, "1g", (*original.Container.Docker.Parameters)[0].Value)
+	assert.Equal(t, 80, (*original.Container.Docker.PortMappings)[0].ContainerPort)
+	assert.Empty(t, *(*original.HealthChecks)[0].Path)
+	assert.Equal(t, "web.1", original.Tasks[0].ID)
+
+	// step: the empty and unset fields are preserved
+	require.NotNil(t, copied.Uris)
+	assert.Empty(t, *copied.Uris)
+	assert.Nil(t, copied.Constraints)
+
+	assert.Nil(t, (*Application)(nil).Clone())
+}
+
+func TestContainerClone(t *testing.T) {
+	original := NewDockerContainer().Volume("/host", "/container", "RW")
+	copied := original.Clone()
+	assert.Equal(t, original, copied)
+
+	copied.Docker.Image = "httpd"
+	(*copied.Volumes)[0].HostPath = "/other"
+	assert.Empty(t, original.Docker.Image)
+	assert.Equal(t, "/host", (*original.Volumes)[0].HostPath)
+	assert.Nil(t, (*Container)(nil).Clone())
+}
+
+func TestHealthCheckClone(t *testing.T) {
+	original := NewDefaultHealthCheck()
+	copied := original.Clone()
+	assert.Equal(t, original, copied)
+
+	copied.SetPath("/health").SetPortIndex(1)
+	assert.Empty(t, *original.Path)
+	assert.Equal(t, 0, *original.PortIndex)
+	assert.Nil(t, (*HealthCheck)(nil).Clone())
+}
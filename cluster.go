@@ -34,6 +34,7 @@ type memberStatus int
 
 // cluster is a collection of marathon nodes
 type cluster struct {
+	// guards the members and their status, failures and circuit breakers
 	sync.RWMutex
 	// a collection of nodes
 	members []*member
@@ -42,6 +43,16 @@ type cluster struct {
 	// healthCheckInterval is the interval by which we probe down nodes for
 	// availability again.
 	healthCheckInterval time.Duration
+	// the number of consecutive failures opening the circuit breaker of a node, zero disabling it
+	breakerThreshold int
+	// the time the circuit breaker of a node stays open for
+	breakerCooldown time.Duration
+	// the logger of the cluster module
+	logger Logger
+	// whether the default DCOS path is added to the endpoints with no path
+	isDCOS bool
+	// the time the members were last discovered, when discovered
+	discoveredAt time.Time
 }
 
 // member represents an individual endpoint
@@ -50,10 +61,38 @@ type member struct {
 	endpoint string
 	// the status of the host
 	status memberStatus
+	// the number of consecutive failures of the host
+	failures int
+	// the time the circuit breaker of the host is open until
+	openUntil time.Time
 }
 
 // newCluster returns a new marathon cluster
 func newCluster(client *httpClient, marathonURL string, isDCOS bool) (*cluster, error) {
+	members, err := parseMembers(marathonURL, isDCOS)
+	if err != nil {
+		return nil, err
+	}
+
+	cooldown := client.config.CircuitBreakerCooldown
+	if cooldown <= 0 {
+		cooldown = defaultCircuitBreakerCooldown
+	}
+
+	return &cluster{
+		client:              client,
+		members:             members,
+		healthCheckInterval: 5 * time.Second,
+		breakerThreshold:    client.config.CircuitBreakerThreshold,
+		breakerCooldown:     cooldown,
+		logger:              noopLogger{},
+		isDCOS:              isDCOS,
+		discoveredAt:        time.Now(),
+	}, nil
+}
+
+// parseMembers extracts and validates the members of the comma-separated endpoints
+func parseMembers(marathonURL string, isDCOS bool) ([]*member, error) {
 	// step: extract and basic validate the endpoints
 	var members []*member
 	var defaultProto string
@@ -91,27 +130,36 @@ func newCluster(client *httpClient, marathonURL string, isDCOS bool) (*cluster,
 		if isDCOS && strings.TrimLeft(u.Path, "/") == "" {
 			u.Path = defaultDCOSPath
 		}
+		// step: the API paths are joined to the path prefix, e.g. /service/marathon, with a slash
+		u.Path = strings.TrimRight(u.Path, "/")
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
 
 		// step: create a new node for this endpoint
 		members = append(members, &member{endpoint: u.String()})
 	}
 
-	return &cluster{
-		client:              client,
-		members:             members,
-		healthCheckInterval: 5 * time.Second,
-	}, nil
+	return members, nil
 }
 
 // retrieve the current member, i.e. the current endpoint in use
 func (c *cluster) getMember() (string, error) {
 	c.RLock()
 	defer c.RUnlock()
+	now := time.Now()
+	open := false
 	for _, n := range c.members {
+		// step: fail fast on the nodes whose circuit breaker is open
+		if now.Before(n.openUntil) {
+			open = true
+			continue
+		}
 		if n.status == memberStatusUp {
 			return n.endpoint, nil
 		}
 	}
+	if open {
+		return "", ErrCircuitOpen
+	}
 
 	return "", ErrMarathonDown
 }
@@ -120,13 +168,41 @@ func (c *cluster) getMember() (string, error) {
 func (c *cluster) markDown(endpoint string) {
 	c.Lock()
 	defer c.Unlock()
+	var node *member
 	for _, n := range c.members {
-		// step: check if this is the node and it's marked as up - The double  checking on the
-		// nodes status ensures the multiple calls don't create multiple checks
-		if n.status == memberStatusUp && n.endpoint == endpoint {
-			n.status = memberStatusDown
-			go c.healthCheckNode(n)
-			break
+		// step: prefer the node marked as up - The double checking on the nodes status ensures
+		// the multiple calls don't create multiple checks
+		if n.endpoint == endpoint && (node == nil || n.status == memberStatusUp) {
+			node = n
+			if n.status == memberStatusUp {
+				break
+			}
+		}
+	}
+	if node == nil {
+		return
+	}
+
+	// step: open the circuit breaker after too many consecutive failures
+	node.failures++
+	if c.breakerThreshold > 0 && node.failures >= c.breakerThreshold {
+		node.openUntil = time.Now().Add(c.breakerCooldown)
+		c.logger.Infof("markDown(): circuit breaker of %s open for %s after %d failures", node.endpoint, c.breakerCooldown, node.failures)
+	}
+	if node.status == memberStatusUp {
+		node.status = memberStatusDown
+		c.logger.Infof("markDown(): %s marked down", node.endpoint)
+		go c.healthCheckNode(node)
+	}
+}
+
+// markSuccess resets the consecutive failures of the endpoint, which responded
+func (c *cluster) markSuccess(endpoint string) {
+	c.Lock()
+	defer c.Unlock()
+	for _, n := range c.members {
+		if n.endpoint == endpoint {
+			n.failures = 0
 		}
 	}
 }
@@ -136,17 +212,42 @@ func (c *cluster) healthCheckNode(node *member) {
 	// step: wait for the node to become active ... we are assuming a /ping is enough here
 	ticker := time.NewTicker(c.healthCheckInterval)
 	defer ticker.Stop()
-	for range ticker.C {
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.client.done():
+			// step: the client was closed
+			return
+		}
+		// step: stop checking the nodes which are no longer members
+		if !c.isMember(node) {
+			break
+		}
 		req, err := c.client.buildMarathonRequest("GET", node.endpoint, "ping", nil)
 		if err == nil {
-			res, err := c.client.Do(req)
+			res, err := c.client.Do(req, 0)
+			if err == nil {
+				res.Body.Close()
+			}
+			// step: the token expired meanwhile, refresh it for the next check
+			if err == nil && c.client.refreshesToken(res) {
+				if refreshErr := c.client.refreshToken(req); refreshErr != nil {
+					c.logger.Errorf("healthCheckNode(): %s", refreshErr)
+				}
+			}
 			if err == nil && res.StatusCode == 200 {
 				// step: mark the node as active again
 				c.Lock()
 				node.status = memberStatusUp
 				c.Unlock()
+				c.logger.Infof("healthCheckNode(): %s marked up", node.endpoint)
 				break
 			}
+			if err == nil {
+				c.logger.Debugf("healthCheckNode(): %s is still down, status code: %d", node.endpoint, res.StatusCode)
+			} else {
+				c.logger.Debugf("healthCheckNode(): %s is still down: %s", node.endpoint, err)
+			}
 		}
 	}
 }
@@ -177,6 +278,8 @@ func (c *cluster) membersList(status memberStatus) []string {
 
 // size returns the size of the cluster
 func (c *cluster) size() int {
+	c.RLock()
+	defer c.RUnlock()
 	return len(c.members)
 }
 
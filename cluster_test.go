@@ -17,6 +17,9 @@ limitations under the License.
 package marathon
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -68,6 +71,16 @@ func TestGetMember(t *testing.T) {
 			MarathonURL: fakeMarathonURLWithPath,
 			member:      "http://127.0.0.1:3000/path",
 		},
+		{
+			isDCOS:      false,
+			MarathonURL: "https://cluster.example.com/service/marathon-user/",
+			member:      "https://cluster.example.com/service/marathon-user",
+		},
+		{
+			isDCOS:      true,
+			MarathonURL: "https://cluster.example.com/",
+			member:      "https://cluster.example.com/marathon",
+		},
 	}
 	for _, x := range cases {
 		cluster, err := newCluster(&httpClient{config: Config{HTTPClient: defaultHTTPClient}}, x.MarathonURL, x.isDCOS)
@@ -187,3 +200,117 @@ func TestInvalidClusterHosts(t *testing.T) {
 func newStandardCluster(url string) (*cluster, error) {
 	return newCluster(&httpClient{config: Config{HTTPClient: defaultHTTPClient}}, url, false)
 }
+
+func TestCircuitBreaker(t *testing.T) {
+	client := &httpClient{config: Config{
+		HTTPClient:              defaultHTTPClient,
+		CircuitBreakerThreshold: 2,
+		CircuitBreakerCooldown:  100 * time.Millisecond,
+	}}
+	cluster, err := newCluster(client, "http://127.0.0.1:3000", false)
+	require.NoError(t, err)
+	cluster.healthCheckInterval = time.Hour
+	member := cluster.members[0]
+	markUp := func() {
+		cluster.Lock()
+		defer cluster.Unlock()
+		member.status = memberStatusUp
+	}
+
+	// step: a success resets the consecutive failures
+	cluster.markDown(member.endpoint)
+	markUp()
+	cluster.markSuccess(member.endpoint)
+	cluster.markDown(member.endpoint)
+	markUp()
+	endpoint, err := cluster.getMember()
+	require.NoError(t, err)
+	assert.Equal(t, member.endpoint, endpoint)
+
+	// step: the breaker opens on the threshold, even though the member is up again
+	cluster.markDown(member.endpoint)
+	markUp()
+	_, err = cluster.getMember()
+	assert.Equal(t, ErrCircuitOpen, err)
+	assert.Equal(t, ReasonLeaderLost, Reason(err))
+
+	// step: the requests are let through after the cool-down, a failure opening it again
+	time.Sleep(150 * time.Millisecond)
+	_, err = cluster.getMember()
+	assert.NoError(t, err)
+	cluster.markDown(member.endpoint)
+	markUp()
+	_, err = cluster.getMember()
+	assert.Equal(t, ErrCircuitOpen, err)
+}
+
+func TestCircuitBreakerClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.CircuitBreakerThreshold = 1
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	// step: the requests fail fast once the breaker is open
+	_, err = client.Ping()
+	assert.Equal(t, ErrCircuitOpen, err)
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cluster, err := newCluster(&httpClient{config: Config{HTTPClient: defaultHTTPClient}}, "http://127.0.0.1:3000", false)
+	require.NoError(t, err)
+	cluster.healthCheckInterval = time.Hour
+
+	for i := 0; i < 10; i++ {
+		cluster.markDown(cluster.members[0].endpoint)
+	}
+	_, err = cluster.getMember()
+	assert.Equal(t, ErrMarathonDown, err)
+}
+
+func TestClusterConcurrentBookkeeping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	members := server.URL + "/a," + server.URL + "/b," + server.URL + "/c"
+	client := &httpClient{config: Config{HTTPClient: defaultHTTPClient, CircuitBreakerThreshold: 100}}
+	cluster, err := newCluster(client, members, false)
+	require.NoError(t, err)
+	cluster.healthCheckInterval = time.Millisecond
+
+	// step: the members are marked down, up again by their health checks, and replaced concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if endpoint, err := cluster.getMember(); err == nil {
+					cluster.markDown(endpoint)
+					cluster.markSuccess(endpoint)
+				}
+				cluster.activeMembers()
+				cluster.nonActiveMembers()
+				if i == 0 && j%10 == 0 {
+					discovered, err := parseMembers(members, false)
+					require.NoError(t, err)
+					cluster.setMembers(discovered)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, cluster.size())
+	deadline := time.Now().Add(time.Second)
+	for len(cluster.activeMembers()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Len(t, cluster.activeMembers(), 3)
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "encoding/json"
+
+// Codec encodes the request bodies and decodes the response bodies and the events of the client,
+// e.g. with a faster JSON implementation or one preserving the null values. The implementations
+// must honor the json.Marshaler and json.Unmarshaler implementations of the definitions
+type Codec interface {
+	// Marshal encodes the value, like json.Marshal
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes the data into the value, like json.Unmarshal
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec is the Codec of encoding/json, the default one
+type JSONCodec struct{}
+
+// Marshal encodes the value with json.Marshal
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the data with json.Unmarshal
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
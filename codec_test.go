@@ -0,0 +1,82 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCodec counts the values encoded and decoded with encoding/json, failing the decoding
+// with err when set
+type countingCodec struct {
+	JSONCodec
+	sync.Mutex
+	marshalled   int
+	unmarshalled int
+	err          error
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.Lock()
+	c.marshalled++
+	c.Unlock()
+	return c.JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.Lock()
+	c.unmarshalled++
+	err := c.err
+	c.Unlock()
+	if err != nil {
+		return err
+	}
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func TestCodec(t *testing.T) {
+	codec := &countingCodec{}
+	config := NewDefaultConfig()
+	config.Codec = codec
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	applications, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, applications.Apps)
+	assert.Equal(t, 1, codec.unmarshalled)
+
+	_, err = endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.NoError(t, err)
+	assert.Equal(t, 1, codec.marshalled)
+	assert.Equal(t, 2, codec.unmarshalled)
+
+	// step: the events are decoded with the codec as well
+	client := endpoint.Client.(*marathonClient)
+	require.NoError(t, client.handleEvent(`{"eventType": "app_terminated_event", "appId": "/web"}`))
+	assert.Equal(t, 4, codec.unmarshalled)
+
+	// step: the errors of the codec are returned
+	codec.err = errors.New("invalid character")
+	_, err = endpoint.Client.Applications(nil)
+	assert.True(t, errors.Is(err, codec.err))
+}
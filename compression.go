@@ -0,0 +1,92 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const gzipEncoding = "gzip"
+
+// gzipBody compresses the body of a request
+func gzipBody(body []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// readResponseBody reads and closes the body of the response, decompressing it when gzipped
+func readResponseBody(response *http.Response) ([]byte, error) {
+	defer response.Body.Close()
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), gzipEncoding) {
+		return ioutil.ReadAll(response.Body)
+	}
+	// step: some servers gzip the empty bodies, e.g. of a 204, with no gzip header
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil || len(content) == 0 {
+		return content, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// gzipReadCloser is the decompressed body of a response, closing the body along with the reader
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+// Close closes the decompression and the body
+func (r gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.body.Close()
+}
+
+// responseBodyReader returns the body of the response to be read as it streams in, decompressing it
+// when gzipped
+func responseBodyReader(response *http.Response) (io.ReadCloser, error) {
+	if !strings.EqualFold(response.Header.Get("Content-Encoding"), gzipEncoding) {
+		return response.Body, nil
+	}
+	// step: the empty bodies may be gzipped with no gzip header
+	buffered := bufio.NewReader(response.Body)
+	if _, err := buffered.Peek(1); err == io.EOF {
+		return response.Body, nil
+	}
+	reader, err := gzip.NewReader(buffered)
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	return gzipReadCloser{Reader: reader, body: response.Body}, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzip(t *testing.T) {
+	var contentEncodings []string
+	var received []*Group
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// step: decompress the gzipped request bodies
+		var body io.Reader = r.Body
+		contentEncodings = append(contentEncodings, r.Header.Get("Content-Encoding"))
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			reader, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = reader
+		}
+		content, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		if len(content) > 0 {
+			group := new(Group)
+			require.NoError(t, json.Unmarshal(content, group))
+			received = append(received, group)
+		}
+
+		// step: gzip the responses when accepted
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		defer writer.Close()
+		writer.Write([]byte(`{"id": "/product", "apps": []}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.GzipRequestThreshold = 64
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	group, err := client.Group("/product")
+	require.NoError(t, err)
+	assert.Equal(t, "/product", group.ID)
+
+	require.NoError(t, client.CreateGroup(NewApplicationGroup("/small")))
+	large := NewApplicationGroup("/large")
+	for i := 0; i < 5; i++ {
+		large.App(NewDockerApplication().Name("/large/app").CPU(0.1).Memory(64))
+	}
+	require.NoError(t, client.CreateGroup(large))
+
+	assert.Equal(t, []string{"", "", "gzip"}, contentEncodings)
+	require.Len(t, received, 2)
+	assert.Equal(t, "/small", received[0].ID)
+	assert.Equal(t, "/large", received[1].ID)
+	assert.Len(t, received[1].Apps, 5)
+}
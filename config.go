@@ -23,7 +23,20 @@ import (
 	"time"
 )
 
-const defaultPollingWaitTime = 500 * time.Millisecond
+const (
+	defaultPollingWaitTime = 500 * time.Millisecond
+	// the factor of the polling wait time of the event driven waits, which only poll in case of
+	// a lost event
+	eventDrivenPollingFactor = 10
+	// the default time the WaitOn methods wait for when given no timeout
+	defaultDeploymentTimeout = 900 * time.Second
+	// the default time the circuit breaker of a Marathon host stays open for
+	defaultCircuitBreakerCooldown = 30 * time.Second
+	// the default maximum delay of the Retry-After header honored
+	defaultMaxRetryAfter = 30 * time.Second
+	// the number of times a request is retried after the delay of a Retry-After header
+	maxRetryAfterRetries = 5
+)
 
 const defaultDCOSPath = "marathon"
 
@@ -48,14 +61,130 @@ type Config struct {
 	CallbackURL string
 	// DCOSToken for DCOS environment, This will override the Authorization header
 	DCOSToken string
+	// TokenProvider acquires a new DC/OS token whenever a request is rejected with 401
+	// Unauthorized, e.g. as the token expired, the request being replayed with the new token. It
+	// is first called on the first rejected request unless DCOSToken is set
+	TokenProvider TokenProvider
+	// DCOSServiceAccountID is the id of the DC/OS service account the client logs in as with the
+	// DCOSServiceAccountKey, acquiring its tokens as they expire. It is ignored with a TokenProvider
+	DCOSServiceAccountID string
+	// DCOSServiceAccountKey is the PEM-encoded RSA private key of the DC/OS service account
+	DCOSServiceAccountKey string
+	// DCOSLoginURL is the URL of the DC/OS login endpoint of the service account, defaulting to
+	// /acs/api/v1/auth/login on the first Marathon host
+	DCOSLoginURL string
 	// LogOutput the output for debug log messages
 	LogOutput io.Writer
+	// Logger receives the log messages of the client, taking precedence over LogOutput. When
+	// neither is set the messages are discarded
+	Logger Logger
+	// DeployHooks are called around the deployments of CreateApplication and UpdateApplication
+	DeployHooks *DeployHooks
+	// LogLevels sets the log level of each module of the client, and can be changed at runtime.
+	// When nil all the messages are logged
+	LogLevels *LogLevels
+	// Instrumentation is invoked on every request, e.g. to export request metrics
+	Instrumentation Instrumentation
+	// Tracer creates a span for each API call, e.g. to trace the calls with OpenTelemetry
+	Tracer Tracer
+	// Journal persists the events received and the deployments started by the client, see
+	// NewFileJournalStore
+	Journal JournalStore
+	// TolerateMaintenance makes ApplicationOK and WaitOnApplication treat the applications labelled
+	// as in maintenance as intentionally degraded, i.e. they are OK whatever the state of their tasks
+	TolerateMaintenance bool
+	// Limits are checked before sending the application and group definitions, none by default,
+	// see DefaultLimits
+	Limits Limits
+	// MemberDiscovery discovers the members of the cluster, refreshed every MemberDiscoveryInterval
+	// and when they are all down. The members are discovered when the client is created if URL is empty
+	MemberDiscovery MemberDiscovery
+	// MemberDiscoveryInterval is the interval between the discoveries of the members, 30 seconds
+	// by default
+	MemberDiscoveryInterval time.Duration
 	// HTTPClient is the HTTP client
 	HTTPClient *http.Client
 	// HTTPSSEClient is the HTTP client used for SSE subscriptions, can't have client.Timeout set
 	HTTPSSEClient *http.Client
+	// Transport is the transport of the default HTTP clients, e.g. to control the connection
+	// pooling, the proxies or to instrument the requests. It is ignored by the clients set above
+	Transport http.RoundTripper
+	// Middleware wraps the HTTP client performing the API requests and the health checks of the
+	// hosts, the first middleware being the outermost. Each attempt of a retried request goes
+	// through the chain. The event subscriptions don't.
+	Middleware []Middleware
+	// WireDump writes the requests and responses of the API calls and health checks with their
+	// credentials redacted, see NewWireDump. It can be enabled and disabled at runtime.
+	WireDump *WireDump
+	// UserAgent is the User-Agent header of the requests, the one of the Go HTTP client when empty
+	UserAgent string
+	// Headers are the headers added to every request, e.g. a tenant header required by a router
+	// in front of Marathon. The authentication and content headers take precedence over them.
+	Headers http.Header
+	// GzipRequestThreshold is the size in bytes from which the request bodies, e.g. large group
+	// definitions, are gzipped, zero disabling it. Marathon, or the proxy in front of it, must
+	// accept gzipped bodies. The responses are always requested gzipped.
+	GzipRequestThreshold int
+	// The TLS options below configure the transport of the default HTTP clients, so can't be
+	// combined with Transport and are ignored by the clients set above.
+	//
+	// TLSCAFile is the PEM bundle of the certificate authorities trusted to verify Marathon,
+	// instead of the system roots
+	TLSCAFile string
+	// TLSCertFile is the PEM client certificate presented to Marathon, i.e. for mutual TLS
+	TLSCertFile string
+	// TLSKeyFile is the PEM private key of the client certificate
+	TLSKeyFile string
+	// TLSServerName overrides the name the certificate of Marathon is verified against
+	TLSServerName string
+	// TLSInsecureSkipVerify disables the verification of the certificate of Marathon
+	TLSInsecureSkipVerify bool
+	// TLSPins pins the certificates of the Marathon hosts, rejecting the connections to a host
+	// with no certificate of its chain matching one of its pins, even if the chain is trusted.
+	// The keys are the host names, "*" pinning any other host, and the pins are either
+	// "sha256/<base64>" SPKI hashes or "sha256:<hex>" certificate fingerprints
+	TLSPins map[string][]string
 	// wait time (in milliseconds) between repetitive requests to the API during polling
 	PollingWaitTime time.Duration
+	// PollingBackoffFactor grows the wait time between the polls of the waits by the factor after
+	// each poll, trading the latency of long waits for a lower load of the API. The wait time is
+	// constant when the factor is not above 1
+	PollingBackoffFactor float64
+	// PollingMaxWaitTime caps the wait time between the polls grown by the PollingBackoffFactor
+	PollingMaxWaitTime time.Duration
+	// PollingJitter randomly adds or removes up to the fraction of the wait time between the
+	// polls, between 0 and 1, spreading the polls of concurrent waits
+	PollingJitter float64
+	// EventDrivenWaits subscribes the waits to the event stream, checking again on the status
+	// update, health check and deployment events rather than every PollingWaitTime. They still
+	// poll every 10 PollingWaitTime in case of a lost event, and fall back to polling when the
+	// subscription fails
+	EventDrivenWaits bool
+	// RequestTimeout is the timeout of each API request, overriding the timeout of the HTTP client
+	// when set
+	RequestTimeout time.Duration
+	// RequestTimeouts overrides the timeout of the API requests per endpoint, keyed by the first
+	// two segments of the path with or without the method, e.g. "PUT /v2/groups" or "/ping"
+	RequestTimeouts map[string]time.Duration
+	// DeploymentTimeout is the time the WaitOn methods wait for when given no timeout, independent
+	// of the request timeouts. It defaults to 15 minutes
+	DeploymentTimeout time.Duration
+	// Codec encodes the request bodies and decodes the response bodies and the events, defaulting
+	// to JSONCodec. The applications of EachApplication are decoded with encoding/json, as they
+	// are streamed
+	Codec Codec
+	// CircuitBreakerThreshold is the number of consecutive failures of a Marathon host after which
+	// the requests fail fast with ErrCircuitOpen rather than waiting on it, zero disabling the
+	// circuit breaker
+	CircuitBreakerThreshold int
+	// CircuitBreakerCooldown is the time the circuit breaker of a host stays open for, defaults to
+	// 30 seconds
+	CircuitBreakerCooldown time.Duration
+	// MaxRetryAfter caps the delay of the Retry-After header of the 503 Service Unavailable
+	// responses, e.g. during a leader election, after which the request is retried on the same
+	// host rather than marking it down. The responses with a longer delay are failures of the
+	// host. It defaults to 30 seconds, a negative value ignoring the Retry-After header
+	MaxRetryAfter time.Duration
 }
 
 // NewDefaultConfig create a default client config
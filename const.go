@@ -31,6 +31,7 @@ const (
 	marathonAPIQueue        = marathonAPIVersion + "/queue"
 	marathonAPIInfo         = marathonAPIVersion + "/info"
 	marathonAPILeader       = marathonAPIVersion + "/leader"
+	marathonAPIArtifacts    = marathonAPIVersion + "/artifacts"
 	marathonAPIPing         = "ping"
 )
 
@@ -0,0 +1,93 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ConstraintOperator is the operator of a placement constraint
+type ConstraintOperator string
+
+const (
+	// ConstraintUnique places each task on a different value of the field, e.g. one task per host
+	ConstraintUnique ConstraintOperator = "UNIQUE"
+	// ConstraintCluster places all the tasks on the value of the field, e.g. on the given rack
+	ConstraintCluster ConstraintOperator = "CLUSTER"
+	// ConstraintGroupBy spreads the tasks evenly across the values of the field, optionally given
+	// the number of values
+	ConstraintGroupBy ConstraintOperator = "GROUP_BY"
+	// ConstraintLike places the tasks on the values of the field matching the regular expression
+	ConstraintLike ConstraintOperator = "LIKE"
+	// ConstraintUnlike places the tasks on the values of the field not matching the regular expression
+	ConstraintUnlike ConstraintOperator = "UNLIKE"
+	// ConstraintMaxPer places at most the given number of tasks on each value of the field
+	ConstraintMaxPer ConstraintOperator = "MAX_PER"
+	// ConstraintIs places the tasks on the given value of the field
+	ConstraintIs ConstraintOperator = "IS"
+)
+
+// constraintArities are the minimum and maximum numbers of values of the operators
+var constraintArities = map[ConstraintOperator][2]int{
+	ConstraintUnique:  {0, 0},
+	ConstraintCluster: {0, 1},
+	ConstraintGroupBy: {0, 1},
+	ConstraintLike:    {1, 1},
+	ConstraintUnlike:  {1, 1},
+	ConstraintMaxPer:  {1, 1},
+	ConstraintIs:      {1, 1},
+}
+
+// Constrain adds a placement constraint, see ValidateConstraint for the values each operator takes
+//		field:		the field constrained, e.g. hostname or an agent attribute
+//		operator:	the operator of the constraint
+//		value:		the value of the operator, if any
+func (r *Application) Constrain(field string, operator ConstraintOperator, value ...string) *Application {
+	return r.AddConstraint(append([]string{field, string(operator)}, value...)...)
+}
+
+// ValidateConstraint checks the constraint has a field, a known operator and the number of values
+// the operator takes: none for UNIQUE, an optional value for CLUSTER and GROUP_BY, a number for
+// GROUP_BY and MAX_PER, and a value for the other operators
+//		constraint:	the constraint, i.e. the field, the operator and the value
+func ValidateConstraint(constraint []string) error {
+	if len(constraint) < 2 {
+		return fmt.Errorf("constraint %q must have a field and an operator", constraint)
+	}
+	if constraint[0] == "" {
+		return fmt.Errorf("constraint %q has an empty field", constraint)
+	}
+	operator := ConstraintOperator(constraint[1])
+	arity, found := constraintArities[operator]
+	if !found {
+		return fmt.Errorf("constraint %q has an unknown operator %s", constraint, operator)
+	}
+	values := constraint[2:]
+	if len(values) < arity[0] || len(values) > arity[1] {
+		if arity[0] == arity[1] {
+			return fmt.Errorf("constraint %q: %s takes %d value(s)", constraint, operator, arity[0])
+		}
+		return fmt.Errorf("constraint %q: %s takes %d to %d value(s)", constraint, operator, arity[0], arity[1])
+	}
+	if (operator == ConstraintGroupBy || operator == ConstraintMaxPer) && len(values) == 1 {
+		if _, err := strconv.Atoi(values[0]); err != nil {
+			return fmt.Errorf("constraint %q: %s takes a number", constraint, operator)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationConstrain(t *testing.T) {
+	application := new(Application).Name("/web").
+		Constrain("hostname", ConstraintUnique).
+		Constrain("rack", ConstraintGroupBy, "3").
+		AddConstraint("zone", string(ConstraintLike), "us-.*")
+	assert.Equal(t, [][]string{
+		{"hostname", "UNIQUE"},
+		{"rack", "GROUP_BY", "3"},
+		{"zone", "LIKE", "us-.*"},
+	}, *application.Constraints)
+}
+
+func TestValidateConstraint(t *testing.T) {
+	for _, constraint := range [][]string{
+		{"hostname", "UNIQUE"},
+		{"rack", "CLUSTER"},
+		{"rack", "CLUSTER", "rack-1"},
+		{"rack", "GROUP_BY"},
+		{"rack", "GROUP_BY", "3"},
+		{"hostname", "LIKE", "node-[0-9]+"},
+		{"hostname", "UNLIKE", "node-[0-9]+"},
+		{"hostname", "MAX_PER", "2"},
+		{"zone", "IS", "us-east-1"},
+	} {
+		assert.NoError(t, ValidateConstraint(constraint), "%v", constraint)
+	}
+
+	for _, test := range []struct {
+		constraint []string
+		message    string
+	}{
+		{[]string{"hostname"}, `constraint ["hostname"] must have a field and an operator`},
+		{[]string{"", "UNIQUE"}, `constraint ["" "UNIQUE"] has an empty field`},
+		{[]string{"hostname", "UNIQE"}, `constraint ["hostname" "UNIQE"] has an unknown operator UNIQE`},
+		{[]string{"hostname", "UNIQUE", "true"}, `constraint ["hostname" "UNIQUE" "true"]: UNIQUE takes 0 value(s)`},
+		{[]string{"hostname", "LIKE"}, `constraint ["hostname" "LIKE"]: LIKE takes 1 value(s)`},
+		{[]string{"rack", "CLUSTER", "a", "b"}, `constraint ["rack" "CLUSTER" "a" "b"]: CLUSTER takes 0 to 1 value(s)`},
+		{[]string{"hostname", "MAX_PER", "two"}, `constraint ["hostname" "MAX_PER" "two"]: MAX_PER takes a number`},
+	} {
+		err := ValidateConstraint(test.constraint)
+		require.Error(t, err)
+		assert.Equal(t, test.message, err.Error())
+	}
+}
+
+func TestValidateConstraints(t *testing.T) {
+	application := NewDockerApplication().Name("/web").Constrain("hostname", ConstraintMaxPer)
+	err := application.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []Violation{
+		{Field: "constraints[0]", Message: `constraint ["hostname" "MAX_PER"]: MAX_PER takes 1 value(s)`},
+	}, err.(*ValidationError).Violations)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvalidServiceAccountKey is returned when the private key of the DC/OS service account is not
+// a PEM-encoded RSA private key
+var ErrInvalidServiceAccountKey = errors.New("the private key of the service account is not a PEM-encoded RSA key")
+
+const (
+	// the path of the login endpoint of the DC/OS identity and access management
+	dcosLoginPath = "/acs/api/v1/auth/login"
+	// the validity of the login tokens signed with the key of the service account
+	dcosLoginTokenValidity = 5 * time.Minute
+)
+
+// serviceAccountTokenProvider logs in as a DC/OS service account, exchanging a JWT signed with the
+// private key of the account for an authentication token
+type serviceAccountTokenProvider struct {
+	// the URL of the login endpoint
+	loginURL string
+	// the id of the service account
+	uid string
+	// the private key of the service account
+	key *rsa.PrivateKey
+	// the HTTP client the login requests are sent with
+	client *http.Client
+}
+
+// NewServiceAccountTokenProvider creates a TokenProvider logging in as the DC/OS service account,
+// for the Config.TokenProvider
+//		loginURL:	the URL of the login endpoint, e.g. https://dcos.example.com/acs/api/v1/auth/login
+//		uid:		the id of the service account
+//		privateKey:	the PEM-encoded RSA private key of the service account
+//		client:		the HTTP client the login requests are sent with, nil for the default one
+func NewServiceAccountTokenProvider(loginURL, uid string, privateKey []byte, client *http.Client) (TokenProvider, error) {
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	return &serviceAccountTokenProvider{loginURL: loginURL, uid: uid, key: key, client: client}, nil
+}
+
+// Token logs in as the service account, returning its authentication token
+func (p *serviceAccountTokenProvider) Token() (string, error) {
+	loginToken, err := p.loginToken(time.Now())
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]string{"uid": p.uid, "token": loginToken})
+	if err != nil {
+		return "", err
+	}
+
+	response, err := p.client.Post(p.loginURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to log in as the service account %s: %w", p.uid, err)
+	}
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to log in as the service account %s: %w", p.uid, NewAPIError(response.StatusCode, content))
+	}
+
+	var login struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(content, &login); err != nil || login.Token == "" {
+		return "", fmt.Errorf("failed to log in as the service account %s: no token in the response", p.uid)
+	}
+	return login.Token, nil
+}
+
+// loginToken returns the JWT of the login, signed with the private key of the service account
+func (p *serviceAccountTokenProvider) loginToken(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"uid": p.uid,
+		"exp": now.Add(dcosLoginTokenValidity).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey decodes the PEM-encoded RSA private key, in either the PKCS #1 or #8 format
+func parseRSAPrivateKey(content []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, ErrInvalidServiceAccountKey
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidServiceAccountKey
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidServiceAccountKey
+	}
+	return key, nil
+}
+
+// dcosLoginURL returns the URL of the login endpoint of the configuration, defaulting to the one
+// of the first Marathon host
+func dcosLoginURL(config Config) (string, error) {
+	if config.DCOSLoginURL != "" {
+		return config.DCOSLoginURL, nil
+	}
+	endpoint := strings.TrimSpace(strings.Split(config.URL, ",")[0])
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	host, err := url.Parse(endpoint)
+	if err != nil || host.Host == "" {
+		return "", newInvalidEndpointError("no DC/OS login URL in the Marathon URL: %s", config.URL)
+	}
+	return (&url.URL{Scheme: host.Scheme, Host: host.Host, Path: dcosLoginPath}).String(), nil
+}
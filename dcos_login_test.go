@@ -0,0 +1,173 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newServiceAccountKey generates the key of a service account, returning it PEM-encoded
+func newServiceAccountKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	return key, string(pem.EncodeToMemory(block))
+}
+
+// verifyLoginToken checks the signature and the claims of the login token
+func verifyLoginToken(key *rsa.PublicKey, uid, token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+		return false
+	}
+	content, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		UID string `json:"uid"`
+		Exp int64  `json:"exp"`
+	}
+	return json.Unmarshal(content, &claims) == nil && claims.UID == uid && claims.Exp > time.Now().Unix()
+}
+
+func TestServiceAccountLogin(t *testing.T) {
+	key, pemKey := newServiceAccountKey(t)
+	config := NewDefaultConfig()
+	config.DCOSServiceAccountID = "marathon-ci"
+	config.DCOSServiceAccountKey = pemKey
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{dcosToken: "session-token", serviceAccountKey: &key.PublicKey},
+	})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	_, err = endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	// step: the token is logged in for once
+	assert.Len(t, endpoint.Server.Requests("POST", dcosLoginPath), 1)
+}
+
+func TestServiceAccountLoginDiscovered(t *testing.T) {
+	key, pemKey := newServiceAccountKey(t)
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		server: &serverConfig{dcosToken: "session-token", serviceAccountKey: &key.PublicKey},
+	})
+	defer endpoint.Close()
+
+	// step: the login URL is derived from the members discovered
+	config := NewDefaultConfig()
+	config.URL = ""
+	config.MemberDiscovery = &staticDiscovery{members: []string{endpoint.Server.httpSrv.URL}}
+	config.DCOSServiceAccountID = "marathon-ci"
+	config.DCOSServiceAccountKey = pemKey
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+	assert.Len(t, endpoint.Server.Requests("POST", dcosLoginPath), 1)
+}
+
+func TestServiceAccountLoginRejected(t *testing.T) {
+	key, _ := newServiceAccountKey(t)
+
+	// step: the key of another service account
+	_, otherKey := newServiceAccountKey(t)
+	config := NewDefaultConfig()
+	config.DCOSServiceAccountID = "marathon-ci"
+	config.DCOSServiceAccountKey = otherKey
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{dcosToken: "session-token", serviceAccountKey: &key.PublicKey},
+	})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.Contains(t, err.Error(), "marathon-ci")
+}
+
+func TestServiceAccountInvalidKey(t *testing.T) {
+	config := NewDefaultConfig()
+	config.DCOSServiceAccountID = "marathon-ci"
+	config.DCOSServiceAccountKey = "not a key"
+	_, err := NewClient(config)
+	assert.Equal(t, ErrInvalidServiceAccountKey, err)
+
+	block := &pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}
+	_, err = NewServiceAccountTokenProvider("http://dcos/acs/api/v1/auth/login", "marathon-ci", pem.EncodeToMemory(block), nil)
+	assert.Equal(t, ErrInvalidServiceAccountKey, err)
+}
+
+func TestServiceAccountPKCS8Key(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	content, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: content})
+
+	provider, err := NewServiceAccountTokenProvider("http://dcos/acs/api/v1/auth/login", "marathon-ci", pemKey, nil)
+	require.NoError(t, err)
+	token, err := provider.(*serviceAccountTokenProvider).loginToken(time.Now())
+	require.NoError(t, err)
+	assert.True(t, verifyLoginToken(&key.PublicKey, "marathon-ci", token))
+}
+
+func TestDCOSLoginURL(t *testing.T) {
+	cases := []struct {
+		config   Config
+		expected string
+	}{
+		{Config{URL: "https://dcos.example.com/marathon"}, "https://dcos.example.com/acs/api/v1/auth/login"},
+		{Config{URL: "http://10.0.0.1:8080,10.0.0.2:8080"}, "http://10.0.0.1:8080/acs/api/v1/auth/login"},
+		{Config{URL: "10.0.0.1"}, "http://10.0.0.1/acs/api/v1/auth/login"},
+		{Config{URL: "https://dcos", DCOSLoginURL: "https://iam.example.com/login"}, "https://iam.example.com/login"},
+	}
+	for _, x := range cases {
+		loginURL, err := dcosLoginURL(x.config)
+		require.NoError(t, err)
+		assert.Equal(t, x.expected, loginURL)
+	}
+
+	_, err := dcosLoginURL(Config{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "sync"
+
+const (
+	// DeployOperationCreate is the operation of the deployments of CreateApplication
+	DeployOperationCreate = "CreateApplication"
+	// DeployOperationUpdate is the operation of the deployments of UpdateApplication
+	DeployOperationUpdate = "UpdateApplication"
+)
+
+// DeployContext is the context of a deployment passed to the deploy hooks
+type DeployContext struct {
+	// Operation is the client method deploying, e.g. DeployOperationUpdate
+	Operation string
+	// Application is the definition deployed
+	Application *Application
+	// Force is whether the deployment is forced
+	Force bool
+	// Deployment is the deployment started, set after a successful deploy when Marathon returned it
+	Deployment *DeploymentID
+	// Err is the error of the deploy, set after a failed deploy
+	Err error
+}
+
+// DeployHook is called around the deployments of the client. The errors of the hooks called
+// before a deployment abort it, e.g. to require an approval, while the errors of the hooks called
+// after are logged.
+type DeployHook func(*DeployContext) error
+
+// DeployHooks is the registry of the deploy hooks of a client, which hooks can be added to at
+// runtime
+type DeployHooks struct {
+	sync.RWMutex
+	before  []DeployHook
+	success []DeployHook
+	failure []DeployHook
+}
+
+// NewDeployHooks creates an empty registry of deploy hooks
+func NewDeployHooks() *DeployHooks {
+	return &DeployHooks{}
+}
+
+// BeforeDeploy adds a hook called before the deployments, in the order added. The first error
+// aborts the deployment, which then fails with that error.
+func (h *DeployHooks) BeforeDeploy(hook DeployHook) *DeployHooks {
+	h.Lock()
+	defer h.Unlock()
+	h.before = append(h.before, hook)
+	return h
+}
+
+// AfterDeploySuccess adds a hook called once Marathon accepted a deployment
+func (h *DeployHooks) AfterDeploySuccess(hook DeployHook) *DeployHooks {
+	h.Lock()
+	defer h.Unlock()
+	h.success = append(h.success, hook)
+	return h
+}
+
+// AfterDeployFailure adds a hook called when a deployment failed, including when it was aborted by
+// a hook called before
+func (h *DeployHooks) AfterDeployFailure(hook DeployHook) *DeployHooks {
+	h.Lock()
+	defer h.Unlock()
+	h.failure = append(h.failure, hook)
+	return h
+}
+
+// hooks returns a copy of the hooks, so they are called without holding the lock
+func (h *DeployHooks) hooks() (before, success, failure []DeployHook) {
+	h.RLock()
+	defer h.RUnlock()
+	return append([]DeployHook(nil), h.before...), append([]DeployHook(nil), h.success...),
+		append([]DeployHook(nil), h.failure...)
+}
+
+// deploy performs the deploy call between the deploy hooks, if any
+//		context:	the context of the deployment
+//		call:		performs the deployment, returning the deployment started if known
+func (r *marathonClient) deploy(context *DeployContext, call func() (*DeploymentID, error)) error {
+	if r.config.DeployHooks == nil {
+		_, err := call()
+		return err
+	}
+	before, success, failure := r.config.DeployHooks.hooks()
+
+	var err error
+	for _, hook := range before {
+		if err = hook(context); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		context.Deployment, err = call()
+	}
+
+	// step: the hooks called after can't change the outcome of the deployment
+	after := success
+	if err != nil {
+		context.Err = err
+		after = failure
+	}
+	for _, hook := range after {
+		if hookErr := hook(context); hookErr != nil {
+			r.log(LogModuleOrchestration).Errorf("deploy(): %s hook of %s failed: %s", context.Operation, context.Application.ID, hookErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployHooks(t *testing.T) {
+	var calls []string
+	record := func(name string) DeployHook {
+		return func(context *DeployContext) error {
+			call := name + " " + context.Operation + " " + context.Application.ID
+			if context.Deployment != nil {
+				call += " " + context.Deployment.DeploymentID
+			}
+			if context.Err != nil {
+				call += " " + context.Err.Error()
+			}
+			calls = append(calls, call)
+			return nil
+		}
+	}
+	hooks := NewDeployHooks().BeforeDeploy(record("before")).
+		AfterDeploySuccess(record("success")).
+		AfterDeployFailure(record("failure"))
+
+	config := NewDefaultConfig()
+	config.DeployHooks = hooks
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.NoError(t, err)
+	_, err = endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"before CreateApplication " + fakeAppName,
+		"success CreateApplication " + fakeAppName + " f44fd4fc-4330-4600-a68b-99c7bd33014a",
+		"before UpdateApplication " + fakeAppName,
+		"success UpdateApplication " + fakeAppName + " 83b215a6-4e26-4e44-9333-5c385eda6438",
+	}, calls)
+
+	// step: a hook called before aborts the deployment
+	calls = nil
+	denied := errors.New("not approved")
+	hooks.BeforeDeploy(func(*DeployContext) error {
+		return denied
+	})
+	_, err = endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	assert.Equal(t, denied, err)
+	assert.Equal(t, []string{
+		"before UpdateApplication " + fakeAppName,
+		"failure UpdateApplication " + fakeAppName + " not approved",
+	}, calls)
+}
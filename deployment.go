@@ -69,7 +69,7 @@ type DeploymentPlan struct {
 // Deployments retrieves a list of current deployments
 func (r *marathonClient) Deployments() ([]*Deployment, error) {
 	var deployments []*Deployment
-	err := r.apiGet(marathonAPIDeployments, nil, &deployments)
+	err := r.apiGet("Deployments", marathonAPIDeployments, nil, &deployments)
 	if err != nil {
 		return nil, err
 	}
@@ -113,11 +113,11 @@ func (r *marathonClient) DeleteDeployment(id string, force bool) (*DeploymentID,
 	// if force=true, no body is returned
 	if force {
 		path += "?force=true"
-		return nil, r.apiDelete(path, nil, nil)
+		return nil, r.apiDelete("DeleteDeployment", path, nil, nil)
 	}
 
 	deployment := new(DeploymentID)
-	err := r.apiDelete(path, nil, deployment)
+	err := r.apiDelete("DeleteDeployment", path, nil, deployment)
 
 	if err != nil {
 		return nil, err
@@ -129,46 +129,57 @@ func (r *marathonClient) DeleteDeployment(id string, force bool) (*DeploymentID,
 // HasDeployment checks to see if a deployment exists
 // 	id:		the deployment id you are looking for
 func (r *marathonClient) HasDeployment(id string) (bool, error) {
-	deployments, err := r.Deployments()
+	deployment, err := r.deployment(id)
 	if err != nil {
 		return false, err
 	}
-	for _, deployment := range deployments {
-		if deployment.ID == id {
-			return true, nil
-		}
-	}
-	return false, nil
+	return deployment != nil, nil
 }
 
 // WaitOnDeployment waits on a deployment to finish
 //  version:		the version of the application
 // 	timeout:		the timeout to wait for the deployment to take, otherwise return an error
 func (r *marathonClient) WaitOnDeployment(id string, timeout time.Duration) error {
-	if found, err := r.HasDeployment(id); err != nil {
+	deployment, err := r.deployment(id)
+	if err != nil {
 		return err
-	} else if !found {
+	} else if deployment == nil {
 		return nil
 	}
 
-	nowTime := time.Now()
-	stopTime := nowTime.Add(timeout)
-	if timeout <= 0 {
-		stopTime = nowTime.Add(time.Duration(900) * time.Second)
-	}
+	timer := time.NewTimer(r.waitTimeout(timeout))
+	defer timer.Stop()
 
-	// step: a somewhat naive implementation, but it will work
+	events, poll := r.waitListener()
+	if events != nil {
+		defer r.RemoveEventsListener(events)
+	}
 	for {
-		if time.Now().After(stopTime) {
-			return ErrTimeoutError
-		}
 		found, err := r.HasDeployment(id)
 		if err != nil {
 			return err
 		}
 		if !found {
-			return nil
+			return deploymentOutcome(r, deployment)
+		}
+		r.log(LogModuleWaits).Debugf("WaitOnDeployment(): the deployment %s is still running", id)
+		if err := r.waitNext(id, timer.C, poll.next(), events); err != nil {
+			r.log(LogModuleWaits).Infof("WaitOnDeployment(): timed out waiting on the deployment %s", id)
+			return diagnoseTimeout(r, err, deployment.AffectedApps...)
+		}
+	}
+}
+
+// deployment retrieves the running deployment, nil if it is not running
+func (r *marathonClient) deployment(id string) (*Deployment, error) {
+	deployments, err := r.Deployments()
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		if deployment.ID == id {
+			return deployment, nil
 		}
-		time.Sleep(r.config.PollingWaitTime)
 	}
+	return nil, nil
 }
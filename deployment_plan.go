@@ -0,0 +1,172 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PlanChangeType is the type of a change of a deployment plan
+type PlanChangeType string
+
+const (
+	// PlanChangeAdded is the change of a resource the deployment creates
+	PlanChangeAdded PlanChangeType = "added"
+	// PlanChangeRemoved is the change of a resource the deployment deletes
+	PlanChangeRemoved PlanChangeType = "removed"
+	// PlanChangeUpdated is the change of a resource the deployment updates
+	PlanChangeUpdated PlanChangeType = "updated"
+)
+
+// PlanResource is the kind of a resource of a deployment plan
+type PlanResource string
+
+const (
+	// PlanResourceApp is an application
+	PlanResourceApp PlanResource = "app"
+	// PlanResourcePod is a pod
+	PlanResourcePod PlanResource = "pod"
+	// PlanResourceGroup is a group
+	PlanResourceGroup PlanResource = "group"
+)
+
+// PlanChange is a change of an application, a pod or a group between the original and the target
+// groups of a deployment plan
+type PlanChange struct {
+	// Type is the type of the change
+	Type PlanChangeType
+	// Resource is the kind of the resource changed
+	Resource PlanResource
+	// ID is the id of the resource
+	ID string
+	// Fields are the changes of the fields of the definition of an updated application or pod
+	Fields []FieldChange
+}
+
+// String returns a description of the change
+func (c PlanChange) String() string {
+	if c.Type == PlanChangeUpdated {
+		return fmt.Sprintf("%s %s %s: %d field(s)", c.Type, c.Resource, c.ID, len(c.Fields))
+	}
+	return fmt.Sprintf("%s %s %s", c.Type, c.Resource, c.ID)
+}
+
+// podStatusFields are the fields of a pod populated by Marathon, which aren't part of its definition
+var podStatusFields = []string{"version"}
+
+// Changes returns the changes of the applications, pods and groups the deployment makes, from the
+// original to the target groups of the plan, sorted by id. The groups are only added or removed:
+// the changes of their applications and pods are listed on their own. The plans of the deployment
+// events carry both groups; the plan is compared against empty groups for the missing ones.
+func (r *DeploymentPlan) Changes() ([]PlanChange, error) {
+	original, target := newPlanResources(r.Original), newPlanResources(r.Target)
+
+	var changes []PlanChange
+	for id, group := range original.groups {
+		if _, found := target.groups[id]; !found && !isRootGroup(group) {
+			changes = append(changes, PlanChange{Type: PlanChangeRemoved, Resource: PlanResourceGroup, ID: id})
+		}
+	}
+	for id, group := range target.groups {
+		if _, found := original.groups[id]; !found && !isRootGroup(group) {
+			changes = append(changes, PlanChange{Type: PlanChangeAdded, Resource: PlanResourceGroup, ID: id})
+		}
+	}
+	for id, application := range original.apps {
+		if _, found := target.apps[id]; !found {
+			changes = append(changes, PlanChange{Type: PlanChangeRemoved, Resource: PlanResourceApp, ID: id})
+			continue
+		}
+		fields, err := DiffApplications(application, target.apps[id])
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, PlanChange{Type: PlanChangeUpdated, Resource: PlanResourceApp, ID: id, Fields: fields})
+		}
+	}
+	for id := range target.apps {
+		if _, found := original.apps[id]; !found {
+			changes = append(changes, PlanChange{Type: PlanChangeAdded, Resource: PlanResourceApp, ID: id})
+		}
+	}
+	for id, pod := range original.pods {
+		if _, found := target.pods[id]; !found {
+			changes = append(changes, PlanChange{Type: PlanChangeRemoved, Resource: PlanResourcePod, ID: id})
+			continue
+		}
+		fields, err := diffDefinitions(pod, target.pods[id], podStatusFields)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, PlanChange{Type: PlanChangeUpdated, Resource: PlanResourcePod, ID: id, Fields: fields})
+		}
+	}
+	for id := range target.pods {
+		if _, found := original.pods[id]; !found {
+			changes = append(changes, PlanChange{Type: PlanChangeAdded, Resource: PlanResourcePod, ID: id})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ID != changes[j].ID {
+			return changes[i].ID < changes[j].ID
+		}
+		return changes[i].Resource < changes[j].Resource
+	})
+	return changes, nil
+}
+
+// planResources are the resources of a group tree, by id
+type planResources struct {
+	apps   map[string]*Application
+	pods   map[string]*Pod
+	groups map[string]*Group
+}
+
+// newPlanResources returns the resources of the group and of its nested groups
+func newPlanResources(group *Group) *planResources {
+	resources := &planResources{
+		apps:   make(map[string]*Application),
+		pods:   make(map[string]*Pod),
+		groups: make(map[string]*Group),
+	}
+	if group != nil {
+		resources.add(group)
+	}
+	return resources
+}
+
+func (r *planResources) add(group *Group) {
+	r.groups[group.ID] = group
+	for _, application := range group.Apps {
+		r.apps[application.ID] = application
+	}
+	for _, pod := range group.Pods {
+		r.pods[pod.ID] = pod
+	}
+	for _, nested := range group.Groups {
+		r.add(nested)
+	}
+}
+
+// isRootGroup checks if the group is the root group, which the plans always contain
+func isRootGroup(group *Group) bool {
+	return group.ID == "" || group.ID == "/"
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentPlanChanges(t *testing.T) {
+	web := NewDockerApplication().Name("/product/web").CPU(0.5)
+	web.Version = "2018-01-01T00:00:00.000Z"
+	updated := NewDockerApplication().Name("/product/web").CPU(1)
+	updated.Version = "2018-01-02T00:00:00.000Z"
+	unchanged := NewDockerApplication().Name("/product/db")
+
+	plan := &DeploymentPlan{
+		ID: "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+		Original: &Group{ID: "/", Groups: []*Group{
+			{ID: "/product", Apps: []*Application{web, unchanged}},
+			{ID: "/legacy", Apps: []*Application{NewDockerApplication().Name("/legacy/batch")}},
+		}},
+		Target: &Group{ID: "/", Groups: []*Group{
+			{ID: "/product", Apps: []*Application{updated, unchanged}, Pods: []*Pod{NewPod().Name("/product/cache")}},
+		}},
+	}
+	changes, err := plan.Changes()
+	require.NoError(t, err)
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, PlanChange{Type: PlanChangeRemoved, Resource: PlanResourceGroup, ID: "/legacy"}, changes[0])
+	assert.Equal(t, PlanChange{Type: PlanChangeRemoved, Resource: PlanResourceApp, ID: "/legacy/batch"}, changes[1])
+	assert.Equal(t, PlanChange{Type: PlanChangeAdded, Resource: PlanResourcePod, ID: "/product/cache"}, changes[2])
+	assert.Equal(t, PlanResourceApp, changes[3].Resource)
+	assert.Equal(t, PlanChangeUpdated, changes[3].Type)
+	assert.Equal(t, "/product/web", changes[3].ID)
+	assert.Equal(t, []FieldChange{{Path: "cpus", Old: 0.5, New: 1.0}}, changes[3].Fields)
+	assert.Equal(t, "updated app /product/web: 1 field(s)", changes[3].String())
+}
+
+func TestDeploymentPlanChangesWithoutGroups(t *testing.T) {
+	plan := &DeploymentPlan{Target: &Group{Apps: []*Application{NewDockerApplication().Name("/web")}}}
+	changes, err := plan.Changes()
+	require.NoError(t, err)
+	assert.Equal(t, []PlanChange{{Type: PlanChangeAdded, Resource: PlanResourceApp, ID: "/web"}}, changes)
+
+	changes, err = new(DeploymentPlan).Changes()
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDeploymentPlanDecoding(t *testing.T) {
+	var plan DeploymentPlan
+	err := json.Unmarshal([]byte(`{
+		"id": "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+		"original": {"id": "/", "apps": [], "groups": [], "version": "2018-01-01T00:00:00.000Z"},
+		"target": {"id": "/", "apps": [], "groups": [], "pods": [{"id": "/cache"}], "version": "2018-01-02T00:00:00.000Z"},
+		"steps": []
+	}`), &plan)
+	require.NoError(t, err)
+	assert.Equal(t, "2018-01-02T00:00:00.000Z", plan.Target.Version)
+	changes, err := plan.Changes()
+	require.NoError(t, err)
+	assert.Equal(t, []PlanChange{{Type: PlanChangeAdded, Resource: PlanResourcePod, ID: "/cache"}}, changes)
+}
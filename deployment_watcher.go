@@ -0,0 +1,244 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// the default interval the deployment is polled at
+const defaultDeploymentWatchInterval = 5 * time.Second
+
+// DeploymentProgress is a progress update of a watched deployment
+type DeploymentProgress struct {
+	// ID is the id of the deployment
+	ID string
+	// CurrentStep is the number of the step in progress, from 1
+	CurrentStep int
+	// TotalSteps is the number of steps of the deployment
+	TotalSteps int
+	// CurrentActions are the actions of the step in progress, with their readiness check results
+	CurrentActions []*DeploymentStep
+	// Done is set on the last update, once the deployment finished
+	Done bool
+	// Err is the failure of the finished deployment, a *WaitError, nil on success
+	Err error
+}
+
+// DeploymentWatcherOpts contains the options of the DeploymentWatcher
+//		interval:	the interval the deployment is polled at, between the events, defaults to 5 seconds
+//		pollOnly:	polls the deployment without subscribing the client to the events
+//		onError:	called with the errors retrieving the deployment
+type DeploymentWatcherOpts struct {
+	Interval time.Duration
+	PollOnly bool
+	OnError  func(err error)
+}
+
+// DeploymentWatcher watches a deployment, sending its progress on the channel returned by Progress
+// until it finishes. The deployment is retrieved on its events, subscribing the client to the events,
+// and polled at the interval in any case. Only the deployment_failed event tells a failed deployment
+// from a successful one: when polling, a deployment which is no longer running is reported successful,
+// unless it was cancelled by a later deployment of its applications.
+type DeploymentWatcher struct {
+	// the client the deployment is retrieved with
+	client Marathon
+	id     string
+	opts   DeploymentWatcherOpts
+	// the events of the deployment, nil when polling only
+	events   EventsChannel
+	progress chan DeploymentProgress
+	// the last deployment retrieved
+	current  *Deployment
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// WatchDeployment retrieves the deployment and starts watching its progress. The first update is
+// the progress of the deployment when retrieved, or the last one if it already finished.
+//		client:		the client the deployment is retrieved with
+//		id:			the id of the deployment
+//		opts:		the options of the watcher, nil for the defaults
+func WatchDeployment(client Marathon, id string, opts *DeploymentWatcherOpts) (*DeploymentWatcher, error) {
+	watcher := &DeploymentWatcher{
+		client:   client,
+		id:       id,
+		progress: make(chan DeploymentProgress, 16),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if opts != nil {
+		watcher.opts = *opts
+	}
+	if watcher.opts.Interval <= 0 {
+		watcher.opts.Interval = defaultDeploymentWatchInterval
+	}
+
+	deployment, err := findDeployment(client, id)
+	if err != nil {
+		return nil, err
+	}
+	watcher.current = deployment
+	// step: fall back to polling when the client can't subscribe to the events
+	if deployment != nil && !watcher.opts.PollOnly {
+		if events, err := client.AddEventsListener(EventIDDeployments); err == nil {
+			watcher.events = events
+		}
+	}
+	go watcher.watch()
+
+	return watcher, nil
+}
+
+// Progress returns the channel of the progress of the deployment, closed after the last update or
+// once the watcher is stopped
+func (w *DeploymentWatcher) Progress() <-chan DeploymentProgress {
+	return w.progress
+}
+
+// Stop stops watching the deployment
+func (w *DeploymentWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// watch checks the deployment on its events and at each interval until it finishes or is stopped
+func (w *DeploymentWatcher) watch() {
+	defer close(w.done)
+	defer close(w.progress)
+	if w.events != nil {
+		defer w.client.RemoveEventsListener(w.events)
+	}
+
+	// step: the deployment was retrieved on start
+	if w.current == nil {
+		w.send(DeploymentProgress{ID: w.id, Done: true})
+		return
+	}
+	if !w.send(deploymentProgress(w.current)) {
+		return
+	}
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case event := <-w.events:
+			switch e := event.Event.(type) {
+			case *EventDeploymentSuccess:
+				if e.ID == w.id {
+					w.finish(nil)
+					return
+				}
+				continue
+			case *EventDeploymentFailed:
+				if e.ID == w.id {
+					w.finish(&WaitError{Reason: ReasonDeploymentFailed, ID: w.id, Err: ErrDeploymentFailed})
+					return
+				}
+				continue
+			case *EventDeploymentInfo:
+				if e.Plan == nil || e.Plan.ID != w.id {
+					continue
+				}
+			case *EventDeploymentStepSuccess:
+				if e.Plan == nil || e.Plan.ID != w.id {
+					continue
+				}
+			case *EventDeploymentStepFailure:
+				if e.Plan == nil || e.Plan.ID != w.id {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		if !w.check() {
+			return
+		}
+	}
+}
+
+// check retrieves the deployment and sends its progress, returning false once finished or stopped
+func (w *DeploymentWatcher) check() bool {
+	deployment, err := findDeployment(w.client, w.id)
+	if err != nil {
+		if w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		return true
+	}
+	if deployment == nil {
+		w.finish(deploymentOutcome(w.client, w.current))
+		return false
+	}
+	previous := w.current
+	w.current = deployment
+	if previous.CurrentStep == deployment.CurrentStep && reflect.DeepEqual(previous.CurrentActions, deployment.CurrentActions) {
+		return true
+	}
+	return w.send(deploymentProgress(deployment))
+}
+
+// finish sends the last update of the deployment
+func (w *DeploymentWatcher) finish(err error) {
+	progress := deploymentProgress(w.current)
+	progress.Done = true
+	progress.Err = err
+	w.send(progress)
+}
+
+// send sends the progress, returning false if the watcher was stopped meanwhile
+func (w *DeploymentWatcher) send(progress DeploymentProgress) bool {
+	select {
+	case w.progress <- progress:
+		return true
+	case <-w.stop:
+		return false
+	}
+}
+
+// deploymentProgress returns the progress of the deployment
+func deploymentProgress(deployment *Deployment) DeploymentProgress {
+	return DeploymentProgress{
+		ID:             deployment.ID,
+		CurrentStep:    deployment.CurrentStep,
+		TotalSteps:     deployment.TotalSteps,
+		CurrentActions: deployment.CurrentActions,
+	}
+}
+
+// findDeployment retrieves the running deployment, nil if it is not running
+func findDeployment(client Marathon, id string) (*Deployment, error) {
+	deployments, err := client.Deployments()
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		if deployment.ID == id {
+			return deployment, nil
+		}
+	}
+	return nil, nil
+}
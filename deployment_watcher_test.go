@@ -0,0 +1,173 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deployingClient serves the running deployments and the events of the test
+type deployingClient struct {
+	Marathon
+	sync.Mutex
+	deployments []*Deployment
+	events      EventsChannel
+	removed     bool
+}
+
+func (c *deployingClient) set(deployments ...*Deployment) {
+	c.Lock()
+	defer c.Unlock()
+	c.deployments = deployments
+}
+
+func (c *deployingClient) Deployments() ([]*Deployment, error) {
+	c.Lock()
+	defer c.Unlock()
+	return c.deployments, nil
+}
+
+func (c *deployingClient) Application(name string) (*Application, error) {
+	return nil, &APIError{ErrCode: ErrCodeNotFound, message: "App '/web' does not exist"}
+}
+
+func (c *deployingClient) AddEventsListener(filter int) (EventsChannel, error) {
+	if c.events == nil {
+		return nil, ErrMarathonDown
+	}
+	return c.events, nil
+}
+
+func (c *deployingClient) RemoveEventsListener(channel EventsChannel) {
+	c.Lock()
+	defer c.Unlock()
+	c.removed = true
+}
+
+func nextDeploymentProgress(t *testing.T, watcher *DeploymentWatcher) DeploymentProgress {
+	select {
+	case progress, ok := <-watcher.Progress():
+		require.True(t, ok, "the progress channel was closed")
+		return progress
+	case <-time.After(time.Second):
+		require.FailNow(t, "no progress of the deployment")
+	}
+	return DeploymentProgress{}
+}
+
+func deploymentAtStep(step int) *Deployment {
+	return &Deployment{
+		ID:           "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+		CurrentStep:  step,
+		TotalSteps:   2,
+		AffectedApps: []string{"/web"},
+		CurrentActions: []*DeploymentStep{
+			{Action: "ScaleApplication", App: "/web"},
+		},
+	}
+}
+
+func TestWatchDeploymentEvents(t *testing.T) {
+	client := &deployingClient{events: make(EventsChannel, 1)}
+	client.set(deploymentAtStep(1))
+	watcher, err := WatchDeployment(client, "867ed450-f6a8-4d33-9b0e-e11c5513990b", &DeploymentWatcherOpts{Interval: time.Hour})
+	require.NoError(t, err)
+
+	progress := nextDeploymentProgress(t, watcher)
+	assert.Equal(t, 1, progress.CurrentStep)
+	assert.Equal(t, 2, progress.TotalSteps)
+	assert.Equal(t, "ScaleApplication", progress.CurrentActions[0].Action)
+	assert.False(t, progress.Done)
+
+	// step: the step events of other deployments are ignored
+	client.set(deploymentAtStep(2))
+	client.events <- &Event{Event: &EventDeploymentStepSuccess{Plan: &DeploymentPlan{ID: "other"}}}
+	client.events <- &Event{Event: &EventDeploymentStepSuccess{Plan: &DeploymentPlan{ID: "867ed450-f6a8-4d33-9b0e-e11c5513990b"}}}
+	progress = nextDeploymentProgress(t, watcher)
+	assert.Equal(t, 2, progress.CurrentStep)
+	assert.False(t, progress.Done)
+
+	client.events <- &Event{Event: &EventDeploymentFailed{ID: "867ed450-f6a8-4d33-9b0e-e11c5513990b"}}
+	progress = nextDeploymentProgress(t, watcher)
+	assert.True(t, progress.Done)
+	assert.Equal(t, ReasonDeploymentFailed, Reason(progress.Err))
+	_, ok := <-watcher.Progress()
+	assert.False(t, ok)
+	watcher.Stop()
+	assert.True(t, client.removed)
+}
+
+func TestWatchDeploymentSuccessEvent(t *testing.T) {
+	client := &deployingClient{events: make(EventsChannel, 1)}
+	client.set(deploymentAtStep(1))
+	watcher, err := WatchDeployment(client, "867ed450-f6a8-4d33-9b0e-e11c5513990b", &DeploymentWatcherOpts{Interval: time.Hour})
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	nextDeploymentProgress(t, watcher)
+	client.events <- &Event{Event: &EventDeploymentSuccess{ID: "867ed450-f6a8-4d33-9b0e-e11c5513990b"}}
+	progress := nextDeploymentProgress(t, watcher)
+	assert.True(t, progress.Done)
+	assert.NoError(t, progress.Err)
+}
+
+func TestWatchDeploymentPolling(t *testing.T) {
+	client := &deployingClient{}
+	client.set(deploymentAtStep(1))
+	watcher, err := WatchDeployment(client, "867ed450-f6a8-4d33-9b0e-e11c5513990b", &DeploymentWatcherOpts{Interval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	assert.Equal(t, 1, nextDeploymentProgress(t, watcher).CurrentStep)
+	client.set(deploymentAtStep(2))
+	assert.Equal(t, 2, nextDeploymentProgress(t, watcher).CurrentStep)
+
+	// step: the deployment is no longer running
+	client.set()
+	progress := nextDeploymentProgress(t, watcher)
+	assert.True(t, progress.Done)
+	assert.NoError(t, progress.Err)
+	assert.Equal(t, 2, progress.CurrentStep)
+}
+
+func TestWatchDeploymentFinished(t *testing.T) {
+	client := &deployingClient{events: make(EventsChannel, 1)}
+	watcher, err := WatchDeployment(client, "867ed450-f6a8-4d33-9b0e-e11c5513990b", nil)
+	require.NoError(t, err)
+	defer watcher.Stop()
+
+	progress := nextDeploymentProgress(t, watcher)
+	assert.True(t, progress.Done)
+	assert.Equal(t, "867ed450-f6a8-4d33-9b0e-e11c5513990b", progress.ID)
+	assert.False(t, client.removed)
+}
+
+func TestWatchDeploymentStop(t *testing.T) {
+	client := &deployingClient{}
+	client.set(deploymentAtStep(1))
+	watcher, err := WatchDeployment(client, "867ed450-f6a8-4d33-9b0e-e11c5513990b", &DeploymentWatcherOpts{Interval: time.Hour})
+	require.NoError(t, err)
+	watcher.Stop()
+	watcher.Stop()
+	for range watcher.Progress() {
+	}
+}
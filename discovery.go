@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	// the default interval between the discoveries of the members
+	defaultDiscoveryInterval = 30 * time.Second
+	// the minimum interval between the discoveries forced by the members being down
+	minDiscoveryInterval = time.Second
+)
+
+// ErrNoMemberDiscovered is returned when the discovery of the members of the cluster returned none
+var ErrNoMemberDiscovered = errors.New("no Marathon member discovered")
+
+// MemberDiscovery discovers the members of the Marathon cluster, e.g. from ZooKeeper, so the client
+// follows the replacements of the members instead of a static URL going stale. See the zookeeper
+// subpackage for a discovery from the ZooKeeper state of Marathon.
+type MemberDiscovery interface {
+	// Members returns the URLs of the members, e.g. http://10.0.0.1:8080, the leader first when known
+	Members() ([]string, error)
+}
+
+// discoverURL returns the comma-separated URLs of the members discovered, for the client
+// configured with a discovery and no URL
+func discoverURL(discovery MemberDiscovery) (string, error) {
+	endpoints, err := discovery.Members()
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints) == 0 {
+		return "", ErrNoMemberDiscovered
+	}
+	return strings.Join(endpoints, ","), nil
+}
+
+// discover refreshes the members of the cluster from the discovery, if any, once the discovery
+// interval elapsed, or right away when forced, e.g. as all the members are down. It returns
+// whether the members were refreshed.
+func (c *cluster) discover(force bool) bool {
+	discovery := c.client.config.MemberDiscovery
+	if discovery == nil {
+		return false
+	}
+	interval := c.client.config.MemberDiscoveryInterval
+	if interval <= 0 {
+		interval = defaultDiscoveryInterval
+	}
+	if force {
+		interval = minDiscoveryInterval
+	}
+
+	c.Lock()
+	if time.Since(c.discoveredAt) < interval {
+		c.Unlock()
+		return false
+	}
+	c.discoveredAt = time.Now()
+	c.Unlock()
+
+	// step: the discovery is performed without holding the lock, as it may be slow
+	marathonURL, err := discoverURL(discovery)
+	if err != nil {
+		c.logger.Errorf("discover(): failed to discover the members: %s", err)
+		return false
+	}
+	members, err := parseMembers(marathonURL, c.isDCOS)
+	if err != nil {
+		c.logger.Errorf("discover(): invalid members discovered: %s", err)
+		return false
+	}
+	c.setMembers(members)
+	return true
+}
+
+// setMembers replaces the members of the cluster, keeping the state of the ones already known
+func (c *cluster) setMembers(members []*member) {
+	c.Lock()
+	defer c.Unlock()
+	known := make(map[string]*member)
+	for _, node := range c.members {
+		if _, found := known[node.endpoint]; !found {
+			known[node.endpoint] = node
+		}
+	}
+	changed := len(members) != len(c.members)
+	for i, node := range members {
+		if existing, found := known[node.endpoint]; found {
+			members[i] = existing
+		}
+		if !changed && c.members[i].endpoint != node.endpoint {
+			changed = true
+		}
+	}
+	if changed {
+		c.logger.Infof("discover(): members changed to %s", members)
+	}
+	c.members = members
+}
+
+// isMember checks if the node is still a member of the cluster
+func (c *cluster) isMember(node *member) bool {
+	c.RLock()
+	defer c.RUnlock()
+	for _, n := range c.members {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
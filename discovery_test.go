@@ -0,0 +1,120 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticDiscovery returns the members it is set
+type staticDiscovery struct {
+	sync.Mutex
+	members []string
+	err     error
+	calls   int
+}
+
+func (d *staticDiscovery) Members() ([]string, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.calls++
+	return d.members, d.err
+}
+
+func (d *staticDiscovery) set(members ...string) {
+	d.Lock()
+	defer d.Unlock()
+	d.members = members
+}
+
+func TestDiscovery(t *testing.T) {
+	first := newFakeMarathonEndpoint(t, nil)
+	defer first.Close()
+	second := newFakeMarathonEndpoint(t, nil)
+	defer second.Close()
+
+	discovery := &staticDiscovery{members: []string{first.Server.httpSrv.URL}}
+	config := NewDefaultConfig()
+	config.URL = ""
+	config.MemberDiscovery = discovery
+	config.MemberDiscoveryInterval = time.Hour
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	assert.Equal(t, first.Server.httpSrv.URL, client.GetMarathonURL())
+
+	_, err = client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, 1, discovery.calls)
+
+	// step: the members are discovered again when they are all down
+	first.Server.httpSrv.Close()
+	discovery.set(second.Server.httpSrv.URL)
+	hosts := client.(*marathonClient).hosts
+	hosts.Lock()
+	hosts.discoveredAt = time.Now().Add(-time.Minute)
+	hosts.Unlock()
+	_, err = client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.Server.httpSrv.URL}, hosts.activeMembers())
+}
+
+func TestDiscoveryInterval(t *testing.T) {
+	discovery := &staticDiscovery{members: []string{"http://10.0.0.1:8080"}}
+	client := &httpClient{config: Config{HTTPClient: defaultHTTPClient, MemberDiscovery: discovery, MemberDiscoveryInterval: time.Hour}}
+	cluster, err := newCluster(client, "http://10.0.0.1:8080,http://10.0.0.2:8080", false)
+	require.NoError(t, err)
+
+	// step: the discovery waits for the interval
+	assert.False(t, cluster.discover(false))
+	assert.Equal(t, 0, discovery.calls)
+
+	// step: the state of the members still discovered is kept
+	cluster.members[0].failures = 2
+	cluster.discoveredAt = time.Now().Add(-2 * time.Hour)
+	discovery.set("http://10.0.0.1:8080", "http://10.0.0.3:8080")
+	assert.True(t, cluster.discover(false))
+	assert.Equal(t, []string{"http://10.0.0.1:8080", "http://10.0.0.3:8080"}, cluster.activeMembers())
+	assert.Equal(t, 2, cluster.members[0].failures)
+
+	// step: the failed discoveries keep the members
+	cluster.discoveredAt = time.Now().Add(-2 * time.Hour)
+	discovery.err = errors.New("zookeeper down")
+	assert.False(t, cluster.discover(false))
+	discovery.err = nil
+	cluster.discoveredAt = time.Now().Add(-2 * time.Hour)
+	discovery.set()
+	assert.False(t, cluster.discover(true))
+	assert.Equal(t, 2, cluster.size())
+
+	// step: no discovery without a discovery configured
+	cluster.client = &httpClient{config: Config{HTTPClient: defaultHTTPClient}}
+	assert.False(t, cluster.discover(true))
+}
+
+func TestDiscoveryErrors(t *testing.T) {
+	config := NewDefaultConfig()
+	config.URL = ""
+	config.MemberDiscovery = &staticDiscovery{}
+	_, err := NewClient(config)
+	assert.Equal(t, ErrNoMemberDiscovered, err)
+}
@@ -23,9 +23,11 @@ import (
 
 // Container is the definition for a container type in marathon
 type Container struct {
-	Type    string    `json:"type,omitempty"`
-	Docker  *Docker   `json:"docker,omitempty"`
-	Volumes *[]Volume `json:"volumes,omitempty"`
+	Type         string         `json:"type,omitempty"`
+	Docker       *Docker        `json:"docker,omitempty"`
+	Volumes      *[]Volume      `json:"volumes,omitempty"`
+	PortMappings *[]PortMapping `json:"portMappings,omitempty"`
+	LinuxInfo    *LinuxInfo     `json:"linuxInfo,omitempty"`
 }
 
 // PortMapping is the portmapping structure between container and mesos
@@ -36,6 +38,7 @@ type PortMapping struct {
 	Name          string             `json:"name,omitempty"`
 	ServicePort   int                `json:"servicePort,omitempty"`
 	Protocol      string             `json:"protocol,omitempty"`
+	NetworkNames  []string           `json:"networkNames,omitempty"`
 }
 
 // Parameters is the parameters to pass to the docker client when creating the container
@@ -128,6 +131,20 @@ type Docker struct {
 	Parameters     *[]Parameters  `json:"parameters,omitempty"`
 	PortMappings   *[]PortMapping `json:"portMappings,omitempty"`
 	Privileged     *bool          `json:"privileged,omitempty"`
+	PullConfig     *PullConfig    `json:"pullConfig,omitempty"`
+	Credential     *Credential    `json:"credential,omitempty"`
+}
+
+// PullConfig is the authentication to the registry of a docker image pulled by the Mesos
+// containerizer, i.e. the name of a secret of the application holding a docker config.json
+type PullConfig struct {
+	Secret string `json:"secret,omitempty"`
+}
+
+// Credential is the credential of the principal pulling the docker image with the Mesos containerizer
+type Credential struct {
+	Principal string `json:"principal,omitempty"`
+	Secret    string `json:"secret,omitempty"`
 }
 
 // Volume attachs a volume to the container
@@ -151,6 +168,27 @@ func (container *Container) Volume(hostPath, containerPath, mode string) *Contai
 	return container
 }
 
+// ExposePort exposes a port of the container, with the port mappings of the container introduced
+// in Marathon 1.5 rather than the port mappings of docker
+//		portMapping:	the port mapping, see NewPortMapping
+func (container *Container) ExposePort(portMapping PortMapping) *Container {
+	if container.PortMappings == nil {
+		container.EmptyPortMappings()
+	}
+	portMappings := append(*container.PortMappings, portMapping)
+	container.PortMappings = &portMappings
+
+	return container
+}
+
+// EmptyPortMappings explicitly empties the port mappings of the container -- use this if you need
+// to empty port mappings of an application that already has port mappings set (setting port
+// mappings to nil will keep the current value)
+func (container *Container) EmptyPortMappings() *Container {
+	container.PortMappings = &[]PortMapping{}
+	return container
+}
+
 // EmptyVolumes explicitly empties the volumes -- use this if you need to empty
 // volumes of an application that already has volumes set (setting volumes to nil will
 // keep the current value)
@@ -243,6 +281,23 @@ func (docker *Docker) Container(image string) *Docker {
 	return docker
 }
 
+// WithRegistryAuth authenticates the pull of the image from a private registry with the docker
+// config.json held by the secret, on the Mesos containerizer. The secret has to be declared on the
+// application, e.g. with AddSecret("", secretName, source).
+//		secretName:		the name of the secret of the application
+func (docker *Docker) WithRegistryAuth(secretName string) *Docker {
+	docker.PullConfig = &PullConfig{Secret: secretName}
+	return docker
+}
+
+// SetCredential sets the credential of the principal pulling the image on the Mesos containerizer
+//		principal:		the principal
+//		secret:			the secret of the principal
+func (docker *Docker) SetCredential(principal, secret string) *Docker {
+	docker.Credential = &Credential{Principal: principal, Secret: secret}
+	return docker
+}
+
 // Bridged sets the networking mode to bridged
 func (docker *Docker) Bridged() *Docker {
 	docker.Network = "BRIDGE"
@@ -259,11 +314,7 @@ func (docker *Docker) Host() *Docker {
 //		ports:			the TCP ports the container is exposing
 func (docker *Docker) Expose(ports ...int) *Docker {
 	for _, port := range ports {
-		docker.ExposePort(PortMapping{
-			ContainerPort: port,
-			HostPort:      0,
-			ServicePort:   0,
-			Protocol:      "tcp"})
+		docker.ExposePort(*NewPortMapping(port, "tcp"))
 	}
 	return docker
 }
@@ -272,11 +323,7 @@ func (docker *Docker) Expose(ports ...int) *Docker {
 //		ports:			the UDP ports the container is exposing
 func (docker *Docker) ExposeUDP(ports ...int) *Docker {
 	for _, port := range ports {
-		docker.ExposePort(PortMapping{
-			ContainerPort: port,
-			HostPort:      0,
-			ServicePort:   0,
-			Protocol:      "udp"})
+		docker.ExposePort(*NewPortMapping(port, "udp"))
 	}
 	return docker
 }
@@ -302,6 +349,43 @@ func (docker *Docker) EmptyPortMappings() *Docker {
 	return docker
 }
 
+// NewPortMapping creates a port mapping of the container port, to be exposed with ExposePort. The
+// host port and the service port are assigned by Marathon unless they are set.
+//		containerPort:	the port the container is listening on, 0 to use the host port
+//		protocol:		the protocol of the port, i.e. tcp, udp or udp,tcp
+func NewPortMapping(containerPort int, protocol string) *PortMapping {
+	return &PortMapping{ContainerPort: containerPort, Protocol: protocol}
+}
+
+// SetHostPort sets the port of the host the container port is mapped to
+//		port:	the port of the host, 0 for a random port
+func (p *PortMapping) SetHostPort(port int) *PortMapping {
+	p.HostPort = port
+	return p
+}
+
+// SetServicePort sets the service port of the port mapping, e.g. for the load balancers
+//		port:	the service port, 0 for a port assigned by Marathon
+func (p *PortMapping) SetServicePort(port int) *PortMapping {
+	p.ServicePort = port
+	return p
+}
+
+// SetName sets the name of the port mapping, e.g. to refer to it from the health checks
+//		name:	the name of the port
+func (p *PortMapping) SetName(name string) *PortMapping {
+	p.Name = name
+	return p
+}
+
+// SetNetworkNames sets the networks the port mapping applies to, e.g. when the application
+// joins several container networks
+//		names:	the names of the networks
+func (p *PortMapping) SetNetworkNames(names ...string) *PortMapping {
+	p.NetworkNames = names
+	return p
+}
+
 // AddLabel adds a label to a PortMapping
 //		name:	the name of the label
 //		value: value for this label
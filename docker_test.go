@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -85,6 +86,24 @@ func TestPortMappingLabels(t *testing.T) {
 	assert.Equal(t, 0, len(*pm.Labels))
 }
 
+func TestNewPortMapping(t *testing.T) {
+	app := NewDockerApplication()
+	app.Container.Docker.Bridged().ExposePort(*NewPortMapping(8080, "tcp").
+		SetHostPort(31000).
+		SetServicePort(10000).
+		SetName("http").
+		AddLabel("VIP_0", "/web:80"))
+
+	assert.Equal(t, []PortMapping{{
+		ContainerPort: 8080,
+		HostPort:      31000,
+		ServicePort:   10000,
+		Protocol:      "tcp",
+		Name:          "http",
+		Labels:        &map[string]string{"VIP_0": "/web:80"},
+	}}, *app.Container.Docker.PortMappings)
+}
+
 func TestVolume(t *testing.T) {
 	container := NewDockerApplication().Container
 
@@ -152,3 +171,39 @@ func TestDockerPersistentVolume(t *testing.T) {
 		assert.Empty(t, len(*pVol.Constraints))
 	}
 }
+
+func TestDockerRegistryAuth(t *testing.T) {
+	app := new(Application).Name("/web").AddSecret("", "registry", "/secrets/docker-config")
+	app.Container = &Container{Type: "MESOS", Docker: &Docker{}}
+	app.Container.Docker.Container("registry.example.com/web").WithRegistryAuth("registry")
+
+	content, err := json.Marshal(app)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"pullConfig":{"secret":"registry"}`)
+	assert.Contains(t, string(content), `"secrets":{"registry":{"source":"/secrets/docker-config"}}`)
+	assert.NotContains(t, string(content), `"env"`)
+
+	decoded := new(Application)
+	require.NoError(t, json.Unmarshal(content, decoded))
+	assert.Equal(t, &PullConfig{Secret: "registry"}, decoded.Container.Docker.PullConfig)
+	assert.Equal(t, map[string]Secret{"registry": {Source: "/secrets/docker-config"}}, *decoded.Secrets)
+}
+
+func TestDockerSetCredential(t *testing.T) {
+	docker := NewDockerContainer().Docker.SetCredential("principal", "secret")
+	assert.Equal(t, &Credential{Principal: "principal", Secret: "secret"}, docker.Credential)
+}
+
+func TestContainerExposePort(t *testing.T) {
+	container := NewDockerContainer()
+	container.ExposePort(*NewPortMapping(8080, "tcp").SetName("http").SetNetworkNames("front", "back"))
+
+	content, err := json.Marshal(container)
+	require.NoError(t, err)
+	assert.Contains(t, string(content),
+		`"portMappings":[{"containerPort":8080,"hostPort":0,"name":"http","protocol":"tcp","networkNames":["front","back"]}]`)
+	assert.Nil(t, container.Docker.PortMappings)
+
+	container.EmptyPortMappings()
+	assert.Empty(t, *container.PortMappings)
+}
@@ -0,0 +1,381 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Divergence reports a mutating operation which succeeded on the primary cluster of a dual-write
+// client but failed on the secondary one, i.e. the clusters have diverged
+type Divergence struct {
+	// Operation is the name of the client method, e.g. UpdateApplication
+	Operation string
+	// ID is the id of the application, pod, group or artifact operated on
+	ID string
+	// Err is the error of the secondary cluster
+	Err error
+}
+
+// String returns a description of the divergence
+func (d *Divergence) String() string {
+	return fmt.Sprintf("%s %s failed on the secondary cluster: %s", d.Operation, d.ID, d.Err)
+}
+
+// dualWriteClient is a Marathon implementation mirroring the mutating operations of the primary
+// cluster to a secondary one. The reads are served by the primary cluster.
+type dualWriteClient struct {
+	// the authoritative cluster
+	Marathon
+	// the cluster the mutating operations are mirrored to
+	secondary Marathon
+	// called when an operation failed on the secondary cluster
+	onDivergence func(*Divergence)
+}
+
+// NewDualWriteClient wraps two clusters in a client mirroring the mutating operations, e.g. to
+// migrate live from one Marathon cluster to another without changing the calling code. The
+// primary cluster is authoritative: the operations are applied to it first, its result is returned
+// and the operations it fails are not mirrored. The secondary cluster is best-effort, its failures
+// being reported to onDivergence. The operations on deployment, task and pod instance ids, which
+// are specific to each cluster, as well as the subscriptions and the leader abdication, only apply
+// to the primary cluster.
+//		primary:	the authoritative cluster, serving the reads
+//		secondary:	the cluster the mutating operations are mirrored to
+//		onDivergence:	called with the operations which failed on the secondary cluster, can be nil
+func NewDualWriteClient(primary, secondary Marathon, onDivergence func(*Divergence)) Marathon {
+	return &dualWriteClient{
+		Marathon:     primary,
+		secondary:    secondary,
+		onDivergence: onDivergence,
+	}
+}
+
+// mirror applies the operation to the secondary cluster, reporting its failure
+func (d *dualWriteClient) mirror(operation, id string, call func(secondary Marathon) error) {
+	if err := call(d.secondary); err != nil && d.onDivergence != nil {
+		d.onDivergence(&Divergence{Operation: operation, ID: id, Err: err})
+	}
+}
+
+// Scoped returns a dual-write client bound to the group prefix
+func (d *dualWriteClient) Scoped(prefix string) Marathon {
+	return NewScopedClient(d, prefix)
+}
+
+// Close closes the clients of both clusters, returning the error of the primary cluster first
+func (d *dualWriteClient) Close() error {
+	err := d.Marathon.Close()
+	if secondaryErr := d.secondary.Close(); err == nil {
+		err = secondaryErr
+	}
+	return err
+}
+
+// -- APPLICATIONS ---
+
+func (d *dualWriteClient) SetApplicationVersion(name string, version *ApplicationVersion) (*DeploymentID, error) {
+	deployment, err := d.Marathon.SetApplicationVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+	d.mirrorVersion("SetApplicationVersion", name, version, nil)
+	return deployment, nil
+}
+
+func (d *dualWriteClient) SetApplicationVersionBy(name string, version *ApplicationVersion, opts *UpdateAppOpts) (*DeploymentID, error) {
+	deployment, err := d.Marathon.SetApplicationVersionBy(name, version, opts)
+	if err != nil {
+		return nil, err
+	}
+	d.mirrorVersion("SetApplicationVersionBy", name, version, opts)
+	return deployment, nil
+}
+
+// mirrorVersion mirrors the change of the version of an application, updated with the options
+// when set
+func (d *dualWriteClient) mirrorVersion(operation, name string, version *ApplicationVersion, opts *UpdateAppOpts) {
+	d.mirror(operation, name, func(secondary Marathon) error {
+		// step: the versions are specific to each cluster, so the definition of the version is
+		// deployed instead
+		application, err := d.Marathon.ApplicationByVersion(name, version.Version)
+		if err != nil {
+			return err
+		}
+		application.Version = ""
+		application.VersionInfo = nil
+		if opts == nil {
+			_, err = secondary.UpdateApplication(application, false)
+		} else {
+			_, err = secondary.UpdateApplicationBy(application, opts)
+		}
+		return err
+	})
+}
+
+func (d *dualWriteClient) CreateApplication(application *Application) (*Application, error) {
+	result, err := d.Marathon.CreateApplication(application)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("CreateApplication", application.ID, func(secondary Marathon) error {
+		_, err := secondary.CreateApplication(application)
+		return err
+	})
+	return result, nil
+}
+
+func (d *dualWriteClient) DeleteApplication(name string, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.DeleteApplication(name, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("DeleteApplication", name, func(secondary Marathon) error {
+		_, err := secondary.DeleteApplication(name, force)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) DeleteApplicationBy(name string, opts *DeleteAppOpts) (*DeploymentID, error) {
+	deployment, err := d.Marathon.DeleteApplicationBy(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("DeleteApplicationBy", name, func(secondary Marathon) error {
+		_, err := secondary.DeleteApplicationBy(name, opts)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.UpdateApplication(application, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("UpdateApplication", application.ID, func(secondary Marathon) error {
+		_, err := secondary.UpdateApplication(application, force)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) UpdateApplicationBy(application *Application, opts *UpdateAppOpts) (*DeploymentID, error) {
+	deployment, err := d.Marathon.UpdateApplicationBy(application, opts)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("UpdateApplicationBy", application.ID, func(secondary Marathon) error {
+		_, err := secondary.UpdateApplicationBy(application, opts)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) ScaleApplicationInstances(name string, instances int, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.ScaleApplicationInstances(name, instances, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("ScaleApplicationInstances", name, func(secondary Marathon) error {
+		_, err := secondary.ScaleApplicationInstances(name, instances, force)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) ScaleApplication(name string, instances int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	deployment, tracker, err := d.Marathon.ScaleApplication(name, instances, opts)
+	if deployment == nil {
+		return nil, nil, err
+	}
+	d.mirror("ScaleApplication", name, func(secondary Marathon) error {
+		// step: the launch is only tracked and waited on in the primary cluster
+		_, err := secondary.ScaleApplicationInstances(name, instances, opts != nil && opts.Force)
+		return err
+	})
+	return deployment, tracker, err
+}
+
+func (d *dualWriteClient) ScaleBy(name string, delta int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	deployment, tracker, err := d.Marathon.ScaleBy(name, delta, opts)
+	if deployment == nil {
+		return nil, nil, err
+	}
+	d.mirror("ScaleBy", name, func(secondary Marathon) error {
+		// step: the launch is only tracked and waited on in the primary cluster
+		_, _, err := secondary.ScaleBy(name, delta, &ScaleAppOpts{Force: opts != nil && opts.Force})
+		return err
+	})
+	return deployment, tracker, err
+}
+
+func (d *dualWriteClient) RestartApplication(name string, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.RestartApplication(name, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("RestartApplication", name, func(secondary Marathon) error {
+		_, err := secondary.RestartApplication(name, force)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) RestartApplicationBy(name string, opts *RestartAppOpts) (*DeploymentID, error) {
+	deployment, err := d.Marathon.RestartApplicationBy(name, opts)
+	if deployment == nil {
+		return nil, err
+	}
+	d.mirror("RestartApplicationBy", name, func(secondary Marathon) error {
+		// step: the deployment is only waited on in the primary cluster
+		_, err := secondary.RestartApplication(name, opts != nil && opts.Force)
+		return err
+	})
+	return deployment, err
+}
+
+// -- PODS ---
+
+func (d *dualWriteClient) CreatePod(pod *Pod) (*Pod, error) {
+	result, err := d.Marathon.CreatePod(pod)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("CreatePod", pod.ID, func(secondary Marathon) error {
+		_, err := secondary.CreatePod(pod)
+		return err
+	})
+	return result, nil
+}
+
+func (d *dualWriteClient) UpdatePod(pod *Pod, force bool) (*Pod, error) {
+	result, err := d.Marathon.UpdatePod(pod, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("UpdatePod", pod.ID, func(secondary Marathon) error {
+		_, err := secondary.UpdatePod(pod, force)
+		return err
+	})
+	return result, nil
+}
+
+func (d *dualWriteClient) DeletePod(name string, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.DeletePod(name, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("DeletePod", name, func(secondary Marathon) error {
+		_, err := secondary.DeletePod(name, force)
+		return err
+	})
+	return deployment, nil
+}
+
+// -- GROUPS ---
+
+func (d *dualWriteClient) CreateGroup(group *Group) error {
+	if err := d.Marathon.CreateGroup(group); err != nil {
+		return err
+	}
+	d.mirror("CreateGroup", group.ID, func(secondary Marathon) error {
+		return secondary.CreateGroup(group)
+	})
+	return nil
+}
+
+func (d *dualWriteClient) DeleteGroup(name string, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.DeleteGroup(name, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("DeleteGroup", name, func(secondary Marathon) error {
+		_, err := secondary.DeleteGroup(name, force)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) UpdateGroup(name string, group *Group, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.UpdateGroup(name, group, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("UpdateGroup", name, func(secondary Marathon) error {
+		_, err := secondary.UpdateGroup(name, group, force)
+		return err
+	})
+	return deployment, nil
+}
+
+func (d *dualWriteClient) DeployGroup(group *Group, force bool) (*DeploymentID, error) {
+	deployment, err := d.Marathon.DeployGroup(group, force)
+	if err != nil {
+		return nil, err
+	}
+	d.mirror("DeployGroup", group.ID, func(secondary Marathon) error {
+		_, err := secondary.DeployGroup(group, force)
+		return err
+	})
+	return deployment, nil
+}
+
+// -- QUEUE ---
+
+func (d *dualWriteClient) DeleteQueueDelay(appID string) error {
+	if err := d.Marathon.DeleteQueueDelay(appID); err != nil {
+		return err
+	}
+	d.mirror("DeleteQueueDelay", appID, func(secondary Marathon) error {
+		return secondary.DeleteQueueDelay(appID)
+	})
+	return nil
+}
+
+// -- ARTIFACTS ---
+
+func (d *dualWriteClient) UploadArtifact(artifactPath string, artifact io.Reader) (string, error) {
+	// step: buffer the artifact so it can be uploaded twice
+	content, err := ioutil.ReadAll(artifact)
+	if err != nil {
+		return "", err
+	}
+	location, err := d.Marathon.UploadArtifact(artifactPath, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	d.mirror("UploadArtifact", artifactPath, func(secondary Marathon) error {
+		_, err := secondary.UploadArtifact(artifactPath, bytes.NewReader(content))
+		return err
+	})
+	return location, nil
+}
+
+func (d *dualWriteClient) DeleteArtifact(artifactPath string) error {
+	if err := d.Marathon.DeleteArtifact(artifactPath); err != nil {
+		return err
+	}
+	d.mirror("DeleteArtifact", artifactPath, func(secondary Marathon) error {
+		return secondary.DeleteArtifact(artifactPath)
+	})
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secondaryMarathon records the mutating operations mirrored to it
+type secondaryMarathon struct {
+	Marathon
+	calls []string
+	err   error
+}
+
+func (s *secondaryMarathon) record(call string) error {
+	s.calls = append(s.calls, call)
+	return s.err
+}
+
+func (s *secondaryMarathon) CreateApplication(application *Application) (*Application, error) {
+	return application, s.record("CreateApplication " + application.ID)
+}
+
+func (s *secondaryMarathon) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	return nil, s.record("UpdateApplication " + application.ID)
+}
+
+func (s *secondaryMarathon) DeleteApplication(name string, force bool) (*DeploymentID, error) {
+	return nil, s.record("DeleteApplication " + name)
+}
+
+func (s *secondaryMarathon) ScaleApplicationInstances(name string, instances int, force bool) (*DeploymentID, error) {
+	return nil, s.record("ScaleApplicationInstances " + name)
+}
+
+func (s *secondaryMarathon) Close() error {
+	return s.record("Close")
+}
+
+func (s *secondaryMarathon) UploadArtifact(artifactPath string, artifact io.Reader) (string, error) {
+	content, _ := ioutil.ReadAll(artifact)
+	return "", s.record("UploadArtifact " + artifactPath + " " + string(content))
+}
+
+func TestDualWriteClient(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	secondary := new(secondaryMarathon)
+	var divergences []*Divergence
+	client := NewDualWriteClient(endpoint.Client, secondary, func(divergence *Divergence) {
+		divergences = append(divergences, divergence)
+	})
+
+	_, err := client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.NoError(t, err)
+	_, err = client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	_, err = client.ScaleApplicationInstances(fakeAppName, 2, false)
+	require.NoError(t, err)
+	_, err = client.UploadArtifact("/config/bundle.tgz", strings.NewReader("content"))
+	require.NoError(t, err)
+	// step: the reads are served by the primary cluster
+	application, err := client.Application(fakeAppName)
+	require.NoError(t, err)
+	assert.Equal(t, fakeAppName, application.ID)
+
+	// step: the operations failing on the primary cluster are not mirrored
+	_, err = client.DeleteApplication("/not/there", false)
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"CreateApplication " + fakeAppName,
+		"UpdateApplication " + fakeAppName,
+		"ScaleApplicationInstances " + fakeAppName,
+		"UploadArtifact /config/bundle.tgz content",
+	}, secondary.calls)
+	assert.Empty(t, divergences)
+
+	// step: the failures of the secondary cluster are reported
+	secondary.err = errors.New("secondary down")
+	_, err = client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	require.Len(t, divergences, 1)
+	assert.Equal(t, &Divergence{Operation: "UpdateApplication", ID: fakeAppName, Err: secondary.err}, divergences[0])
+	assert.Equal(t, "UpdateApplication /fake-app failed on the secondary cluster: secondary down", divergences[0].String())
+
+	// step: the scoped client mirrors as well
+	_, err = client.Scoped("/").UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	assert.Len(t, divergences, 2)
+}
+
+func TestDualWriteClientClose(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	secondary := new(secondaryMarathon)
+	client := NewDualWriteClient(endpoint.Client, secondary, nil)
+	require.NoError(t, client.Close())
+	assert.Equal(t, []string{"Close"}, secondary.calls)
+	_, err := endpoint.Client.Ping()
+	assert.Equal(t, ErrClientClosed, err)
+
+	// step: the error of the secondary cluster is returned when the primary one closes
+	secondary.err = errors.New("secondary down")
+	assert.Equal(t, secondary.err, client.Close())
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "fmt"
+
+// applicationPort is a port of an application, in the order of the ports of its tasks
+type applicationPort struct {
+	// the container port of the port mapping, or the port of the port definition
+	port int
+	// the name of the port, if any
+	name string
+}
+
+// ports returns the ports of the application in the order of the ports of its tasks, i.e. its
+// port mappings, either of the container or of docker, or else its port definitions
+func (r *Application) ports() []applicationPort {
+	var ports []applicationPort
+	switch {
+	case r.Container != nil && r.Container.PortMappings != nil:
+		for _, mapping := range *r.Container.PortMappings {
+			ports = append(ports, applicationPort{port: mapping.ContainerPort, name: mapping.Name})
+		}
+	case r.Container != nil && r.Container.Docker != nil && r.Container.Docker.PortMappings != nil:
+		for _, mapping := range *r.Container.Docker.PortMappings {
+			ports = append(ports, applicationPort{port: mapping.ContainerPort, name: mapping.Name})
+		}
+	case r.PortDefinitions != nil:
+		for _, definition := range *r.PortDefinitions {
+			port := applicationPort{name: definition.Name}
+			if definition.Port != nil {
+				port.port = *definition.Port
+			}
+			ports = append(ports, port)
+		}
+	default:
+		for _, port := range r.Ports {
+			ports = append(ports, applicationPort{port: port})
+		}
+	}
+	return ports
+}
+
+// PortIndex finds the index of the port in the ports of the tasks of the application
+//		port:		the container port of a port mapping, or the port of a port definition
+func (r *Application) PortIndex(port int) (int, error) {
+	for index, applicationPort := range r.ports() {
+		if applicationPort.port == port {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("the port %d was not found in the application %s", port, r.ID)
+}
+
+// PortIndexByName finds the index of the named port in the ports of the tasks of the application
+//		name:		the name of the port mapping or port definition
+func (r *Application) PortIndexByName(name string) (int, error) {
+	for index, applicationPort := range r.ports() {
+		if applicationPort.name == name {
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("the port named %s was not found in the application %s", name, r.ID)
+}
+
+// TaskEndpoints returns the host:port endpoints of the tasks of the application, as retrieved
+// with its tasks, for the port at the index
+//		portIndex:		the index of the port, see PortIndex and PortIndexByName
+//		healthCheck:	whether to only return the tasks passing their health checks, if any
+func (r *Application) TaskEndpoints(portIndex int, healthCheck bool) []string {
+	healthCheck = healthCheck && r.HasHealthChecks()
+
+	var endpoints []string
+	for _, task := range r.Tasks {
+		// step: the task may not have been allocated its ports yet
+		if portIndex < 0 || portIndex >= len(task.Ports) {
+			continue
+		}
+		if !healthCheck || task.allHealthChecksAlive() {
+			endpoints = append(endpoints, fmt.Sprintf("%s:%d", task.Host, task.Ports[portIndex]))
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationPortIndex(t *testing.T) {
+	// step: the port mappings of the container take precedence
+	application := NewDockerApplication().Name("/web")
+	application.Container.Docker.Expose(80)
+	application.Container.ExposePort(*NewPortMapping(8080, "tcp").SetName("http"))
+	application.Container.ExposePort(*NewPortMapping(9090, "tcp").SetName("admin"))
+
+	index, err := application.PortIndex(9090)
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+	index, err = application.PortIndexByName("http")
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+	_, err = application.PortIndex(80)
+	assert.Error(t, err)
+
+	// step: the applications with no port mappings use their port definitions
+	application = new(Application).Name("/api")
+	application.AddPortDefinition(*new(PortDefinition).SetPort(10000).SetName("grpc"))
+	application.AddPortDefinition(*new(PortDefinition).SetPort(10001).SetName("metrics"))
+
+	index, err = application.PortIndexByName("metrics")
+	require.NoError(t, err)
+	assert.Equal(t, 1, index)
+	index, err = application.PortIndex(10000)
+	require.NoError(t, err)
+	assert.Equal(t, 0, index)
+	_, err = application.PortIndexByName("http")
+	assert.Error(t, err)
+}
+
+func TestApplicationTaskEndpoints(t *testing.T) {
+	application := new(Application).Name("/web")
+	application.Tasks = []*Task{
+		{Host: "10.0.0.1", Ports: []int{31000, 31001}, HealthCheckResults: []*HealthCheckResult{{Alive: true}}},
+		{Host: "10.0.0.2", Ports: []int{31002, 31003}, HealthCheckResults: []*HealthCheckResult{{Alive: false}}},
+		// step: a task not allocated its ports yet
+		{Host: "10.0.0.3"},
+	}
+
+	assert.Equal(t, []string{"10.0.0.1:31001", "10.0.0.2:31003"}, application.TaskEndpoints(1, true))
+
+	application.AddHealthCheck(*NewTCPHealthCheck())
+	assert.Equal(t, []string{"10.0.0.1:31001"}, application.TaskEndpoints(1, true))
+	assert.Equal(t, []string{"10.0.0.1:31000", "10.0.0.2:31002"}, application.TaskEndpoints(0, false))
+	assert.Empty(t, application.TaskEndpoints(2, false))
+}
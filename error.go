@@ -18,6 +18,7 @@ package marathon
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -46,6 +47,42 @@ const (
 	ErrCodeMethodNotAllowed
 )
 
+var (
+	// ErrBadRequest matches the APIError of the 400 Bad Request responses with errors.Is
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized matches the APIError of the 401 Unauthorized responses with errors.Is
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden matches the APIError of the 403 Forbidden responses with errors.Is
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound matches the APIError of the 404 Not Found responses with errors.Is
+	ErrNotFound = errors.New("not found")
+	// ErrMethodNotAllowed matches the APIError of the 405 Method Not Allowed responses with errors.Is
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	// ErrConflict matches the APIError of the 409 Conflict responses with errors.Is, i.e. of
+	// ErrCodeDuplicateID and ErrCodeAppLocked
+	ErrConflict = errors.New("conflict")
+	// ErrInvalidDefinition matches the APIError of the 422 Unprocessable Entity responses with
+	// errors.Is
+	ErrInvalidDefinition = errors.New("invalid definition")
+	// ErrServer matches the APIError of the 5xx responses with errors.Is
+	ErrServer = errors.New("server error")
+	// ErrTimeout is ErrTimeoutError, which the waits wrapping it match with errors.Is
+	ErrTimeout = ErrTimeoutError
+)
+
+// the sentinel errors matching the error codes of the APIError
+var apiErrorSentinels = map[int]error{
+	ErrCodeBadRequest:       ErrBadRequest,
+	ErrCodeUnauthorized:     ErrUnauthorized,
+	ErrCodeForbidden:        ErrForbidden,
+	ErrCodeNotFound:         ErrNotFound,
+	ErrCodeMethodNotAllowed: ErrMethodNotAllowed,
+	ErrCodeDuplicateID:      ErrConflict,
+	ErrCodeAppLocked:        ErrConflict,
+	ErrCodeInvalidBean:      ErrInvalidDefinition,
+	ErrCodeServer:           ErrServer,
+}
+
 // InvalidEndpointError indicates a endpoint error in the marathon urls
 type InvalidEndpointError struct {
 	message string
@@ -58,20 +95,28 @@ func (e *InvalidEndpointError) Error() string {
 
 // newInvalidEndpointError creates a new error
 func newInvalidEndpointError(message string, args ...interface{}) error {
-	return &InvalidEndpointError{message: fmt.Sprintf(message, args)}
+	return &InvalidEndpointError{message: fmt.Sprintf(message, args...)}
 }
 
 // APIError represents a generic API error.
 type APIError struct {
 	// ErrCode specifies the nature of the error.
 	ErrCode int
-	message string
+	// Deployments are the ids of the deployments locking the application of an ErrCodeAppLocked
+	Deployments []string
+	message     string
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("Marathon API error: %s", e.message)
 }
 
+// Is matches the sentinel error of the error code, e.g. errors.Is(err, ErrNotFound)
+func (e *APIError) Is(target error) bool {
+	sentinel, found := apiErrorSentinels[e.ErrCode]
+	return found && sentinel == target
+}
+
 // NewAPIError creates a new APIError instance from the given response code and content.
 func NewAPIError(code int, content []byte) error {
 	var errDef errorDefinition
@@ -111,8 +156,26 @@ func parseContent(errDef errorDefinition, content []byte) error {
 	if err := json.Unmarshal(content, errDef); err == nil {
 		errMessage = errDef.message()
 	}
+	apiErr := &APIError{message: errMessage, ErrCode: errDef.errCode()}
+	if conflict, ok := errDef.(*conflictDef); ok {
+		for _, deployment := range conflict.Deployments {
+			apiErr.Deployments = append(apiErr.Deployments, deployment.ID)
+		}
+	}
+
+	return apiErr
+}
+
+// ConflictingDeployments returns the ids of the deployments locking the application of a 409
+// Conflict error, e.g. to wait on them or to decide to force the change, nil for any other error
+//		err:	the error of the change, possibly wrapped
+func ConflictingDeployments(err error) []string {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
 
-	return &APIError{message: errMessage, ErrCode: errDef.errCode()}
+	return apiErr.Deployments
 }
 
 type simpleErrDef struct {
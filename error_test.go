@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -193,3 +194,40 @@ func content422V1() string {
 	]
 }`
 }
+
+func TestAPIErrorIs(t *testing.T) {
+	cases := []struct {
+		httpCode int
+		content  string
+		sentinel error
+	}{
+		{http.StatusBadRequest, `{"message": "Invalid JSON"}`, ErrBadRequest},
+		{http.StatusUnauthorized, `{"message": "Unauthorized"}`, ErrUnauthorized},
+		{http.StatusForbidden, `{"message": "Forbidden"}`, ErrForbidden},
+		{http.StatusNotFound, `{"message": "App '/web' does not exist"}`, ErrNotFound},
+		{http.StatusMethodNotAllowed, `{"message": "Method not allowed"}`, ErrMethodNotAllowed},
+		{http.StatusConflict, `{"message": "An app with id [/web] already exists."}`, ErrConflict},
+		{http.StatusConflict, `{"message": "App is locked", "deployments": [{"id": "97c136bf"}]}`, ErrConflict},
+		{422, `{"message": "Object is not valid"}`, ErrInvalidDefinition},
+		{http.StatusServiceUnavailable, `{"message": "Not leader"}`, ErrServer},
+	}
+	for _, x := range cases {
+		err := NewAPIError(x.httpCode, []byte(x.content))
+		assert.True(t, errors.Is(err, x.sentinel), "%d %s", x.httpCode, x.content)
+		assert.True(t, errors.Is(fmt.Errorf("deploying /web: %w", err), x.sentinel), "%d wrapped", x.httpCode)
+		if x.sentinel != ErrNotFound {
+			assert.False(t, errors.Is(err, ErrNotFound), "%d", x.httpCode)
+		}
+	}
+	assert.False(t, errors.Is(NewAPIError(http.StatusTeapot, nil), ErrServer))
+}
+
+func TestConflictingDeployments(t *testing.T) {
+	err := NewAPIError(http.StatusConflict, []byte(`{"message": "App is locked", "deployments": [{"id": "97c136bf"}, {"id": "5a2c1e7d"}]}`))
+	assert.Equal(t, []string{"97c136bf", "5a2c1e7d"}, ConflictingDeployments(err))
+	assert.Equal(t, []string{"97c136bf", "5a2c1e7d"}, ConflictingDeployments(fmt.Errorf("deleting /web: %w", err)))
+
+	assert.Nil(t, ConflictingDeployments(NewAPIError(http.StatusConflict, []byte(`{"message": "An app with id [/web] already exists."}`))))
+	assert.Nil(t, ConflictingDeployments(NewAPIError(http.StatusNotFound, []byte(`{"message": "App '/web' does not exist"}`))))
+	assert.Nil(t, ConflictingDeployments(errors.New("connection refused")))
+}
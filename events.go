@@ -66,6 +66,15 @@ const (
 	EventIDApplications = EventIDStatusUpdate | EventIDChangedHealthCheck | EventIDFailedHealthCheck | EventIDAppTerminated
 	//EventIDSubscriptions comprises all listener IDs for subscription events.
 	EventIDSubscriptions = EventIDSubscription | EventIDUnsubscribed | EventIDStreamAttached | EventIDStreamDetached
+	//EventIDDeployments comprises all listener IDs for deployment and group change events.
+	EventIDDeployments = EventIDGroupChangeSuccess | EventIDGroupChangeFailed | EventIDDeploymentSuccess | EventIDDeploymentFailed |
+		EventIDDeploymentInfo | EventIDDeploymentStepSuccess | EventIDDeploymentStepFailed
+	//EventIDHealthChecks comprises all listener IDs for health check events.
+	EventIDHealthChecks = EventIDAddHealthCheck | EventIDRemoveHealthCheck | EventIDFailedHealthCheck | EventIDChangedHealthCheck
+	//EventIDTasks comprises all listener IDs for task events.
+	EventIDTasks = EventIDStatusUpdate | EventIDAppTerminated
+	//EventIDAll comprises all listener IDs.
+	EventIDAll = EventIDAppTerminated<<1 - 1
 )
 
 var (
@@ -310,6 +319,21 @@ type EventDeploymentStepFailure struct {
 	Plan        *DeploymentPlan `json:"plan"`
 }
 
+// EventIDsByType returns the listener IDs of the event types, e.g. to filter the events of a
+// listener by their names in the configuration of a service
+//		eventTypes:			the types of Marathon event, e.g. deployment_success
+func EventIDsByType(eventTypes ...string) (int, error) {
+	filter := 0
+	for _, eventType := range eventTypes {
+		id, found := eventTypesMap[eventType]
+		if !found {
+			return 0, fmt.Errorf("the event type: %s was not found or supported", eventType)
+		}
+		filter |= id
+	}
+	return filter, nil
+}
+
 // GetEvent returns allocated empty event object which corresponds to provided event type
 //		eventType:			the type of Marathon event
 func GetEvent(eventType string) (*Event, error) {
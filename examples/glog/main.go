@@ -20,6 +20,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 
 	marathon "github.com/gambol99/go-marathon"
 	"github.com/golang/glog"
@@ -27,11 +28,19 @@ import (
 
 var marathonURL string
 
-type logBridge struct{}
+// glogLogger routes the log messages of go-marathon to glog
+type glogLogger struct{}
 
-func (l *logBridge) Write(b []byte) (n int, err error) {
-	glog.InfoDepth(3, "go-marathon: "+string(b))
-	return len(b), nil
+func (glogLogger) Debugf(format string, v ...interface{}) {
+	glog.V(2).Infof("go-marathon: "+format, v...)
+}
+
+func (glogLogger) Infof(format string, v ...interface{}) {
+	glog.InfoDepth(1, fmt.Sprintf("go-marathon: "+format, v...))
+}
+
+func (glogLogger) Errorf(format string, v ...interface{}) {
+	glog.ErrorDepth(1, fmt.Sprintf("go-marathon: "+format, v...))
 }
 
 func init() {
@@ -42,7 +51,7 @@ func main() {
 	flag.Parse()
 	config := marathon.NewDefaultConfig()
 	config.URL = marathonURL
-	config.LogOutput = new(logBridge)
+	config.Logger = glogLogger{}
 	client, err := marathon.NewClient(config)
 	if err != nil {
 		glog.Exitln(err)
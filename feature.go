@@ -0,0 +1,158 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// the first Marathon version supporting pods
+	podsMinVersion = "1.4.0"
+)
+
+// Feature is a feature of the Marathon API introduced by a given Marathon version
+type Feature string
+
+const (
+	// FeatureFetch is the fetch field of the applications, replacing the uris
+	FeatureFetch Feature = "fetch"
+	// FeaturePortDefinitions is the portDefinitions field of the applications, replacing the ports
+	FeaturePortDefinitions Feature = "portDefinitions"
+	// FeaturePods is the /v2/pods API
+	FeaturePods Feature = "pods"
+	// FeatureNetworks is the networks field of the applications, replacing the docker network
+	FeatureNetworks Feature = "networks"
+)
+
+// featureMinVersions are the first Marathon versions supporting the features
+var featureMinVersions = map[Feature]string{
+	FeatureFetch:           fetchMinVersion,
+	FeaturePortDefinitions: portDefinitionsMinVersion,
+	FeaturePods:            podsMinVersion,
+	FeatureNetworks:        networksMinVersion,
+}
+
+// ErrUnsupportedFeature matches the UnsupportedFeatureError with errors.Is
+var ErrUnsupportedFeature = errors.New("unsupported feature")
+
+// UnsupportedFeatureError is returned in place of the 404 or 422 of Marathon when the request used
+// a feature the version of the Marathon server doesn't support. It wraps the APIError, so it
+// still matches ErrNotFound or ErrInvalidDefinition as well as ErrUnsupportedFeature.
+type UnsupportedFeatureError struct {
+	// Feature is the feature used by the request
+	Feature Feature
+	// Version is the version of the Marathon server
+	Version string
+	// MinVersion is the first Marathon version supporting the feature
+	MinVersion string
+	// Err is the error returned by Marathon
+	Err error
+}
+
+// Error returns the feature and the versions
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("%s requires Marathon %s, the server runs %s: %s", e.Feature, e.MinVersion, e.Version, e.Err)
+}
+
+// Is matches ErrUnsupportedFeature
+func (e *UnsupportedFeatureError) Is(target error) bool {
+	return target == ErrUnsupportedFeature
+}
+
+// Unwrap returns the error returned by Marathon
+func (e *UnsupportedFeatureError) Unwrap() error {
+	return e.Err
+}
+
+// FeatureSupported checks if a Marathon version supports the feature. An empty version is
+// considered to be the latest Marathon version, and an unknown feature to be supported.
+//		feature:	the feature to check
+//		version:	the Marathon version, e.g. the one of Info
+func FeatureSupported(feature Feature, version string) bool {
+	minimum, found := featureMinVersions[feature]
+	return !found || versionAtLeast(version, minimum)
+}
+
+// ServerVersion retrieves the version of the Marathon server, cached once retrieved
+func (r *marathonClient) ServerVersion() (string, error) {
+	r.versionLock.Lock()
+	defer r.versionLock.Unlock()
+	if r.serverVersion == "" {
+		info, err := r.Info()
+		if err != nil {
+			return "", err
+		}
+		r.serverVersion = info.Version
+	}
+
+	return r.serverVersion, nil
+}
+
+// Supports checks if the Marathon server supports the feature
+//		feature:	the feature to check
+func (r *marathonClient) Supports(feature Feature) (bool, error) {
+	version, err := r.ServerVersion()
+	if err != nil {
+		return false, err
+	}
+
+	return FeatureSupported(feature, version), nil
+}
+
+// applicationFeatures turns the 404 or 422 of a request with the application into an
+// UnsupportedFeatureError for the first feature it uses the Marathon server doesn't support
+func (r *marathonClient) applicationFeatures(application *Application, err error) error {
+	var features []Feature
+	if application.Networks != nil && len(*application.Networks) > 0 {
+		features = append(features, FeatureNetworks)
+	}
+	if application.PortDefinitions != nil && len(*application.PortDefinitions) > 0 {
+		features = append(features, FeaturePortDefinitions)
+	}
+	if application.Fetch != nil && len(*application.Fetch) > 0 {
+		features = append(features, FeatureFetch)
+	}
+	for _, feature := range features {
+		if featureErr := r.unsupportedFeature(feature, err); featureErr != err {
+			return featureErr
+		}
+	}
+
+	return err
+}
+
+// unsupportedFeature turns the 404 or 422 of a request using the feature into an
+// UnsupportedFeatureError when the Marathon server is too old for it. The version is only
+// retrieved once the request failed, and any other error returned as is.
+func (r *marathonClient) unsupportedFeature(feature Feature, err error) error {
+	if !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrInvalidDefinition) {
+		return err
+	}
+	version, versionErr := r.ServerVersion()
+	if versionErr != nil || FeatureSupported(feature, version) {
+		return err
+	}
+
+	return &UnsupportedFeatureError{
+		Feature:    feature,
+		Version:    version,
+		MinVersion: featureMinVersions[feature],
+		Err:        err,
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeatureSupported(t *testing.T) {
+	cases := []struct {
+		feature   Feature
+		version   string
+		supported bool
+	}{
+		{FeaturePods, "1.3.13", false},
+		{FeaturePods, "1.4.0", true},
+		{FeatureNetworks, "1.4.11", false},
+		{FeatureNetworks, "1.5.0-RC1", true},
+		{FeatureFetch, "0.14.2", false},
+		{FeaturePortDefinitions, "0.16.0", true},
+		{FeatureNetworks, "", true},
+		{Feature("unknown"), "0.1.0", true},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.supported, FeatureSupported(c.feature, c.version), "%s on %s", c.feature, c.version)
+	}
+}
+
+func TestUnsupportedFeature(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{server: &serverConfig{scope: "v1.3.13"}})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	// step: the 404 of the pods is explained by the version
+	_, err := client.Pods()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUnsupportedFeature))
+	assert.True(t, errors.Is(err, ErrNotFound))
+	var featureErr *UnsupportedFeatureError
+	require.True(t, errors.As(err, &featureErr))
+	assert.Equal(t, FeaturePods, featureErr.Feature)
+	assert.Equal(t, "1.3.13", featureErr.Version)
+	assert.Equal(t, "1.4.0", featureErr.MinVersion)
+
+	// step: and the 422 of an application with networks
+	application := NewDockerApplication().Name("/app")
+	application.AddNetwork("", HostNetworkMode)
+	_, err = client.CreateApplication(application)
+	require.True(t, errors.As(err, &featureErr))
+	assert.Equal(t, FeatureNetworks, featureErr.Feature)
+	assert.True(t, errors.Is(err, ErrInvalidDefinition))
+
+	// step: the version is retrieved once
+	supported, err := client.Supports(FeaturePods)
+	require.NoError(t, err)
+	assert.False(t, supported)
+	assert.Len(t, endpoint.Server.Requests("GET", "/"+marathonAPIInfo), 1)
+}
+
+func TestSupportedFeatureError(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{server: &serverConfig{scope: "v1.10.0"}})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	// step: the errors of a server supporting the features are returned as is
+	_, err := client.Pods()
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrUnsupportedFeature))
+
+	_, err = client.CreateApplication(NewDockerApplication().Name("/app"))
+	assert.True(t, errors.Is(err, ErrInvalidDefinition))
+	assert.False(t, errors.Is(err, ErrUnsupportedFeature))
+	// step: the application using no versioned feature didn't need the version
+	assert.Len(t, endpoint.Server.Requests("GET", "/"+marathonAPIInfo), 1)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -27,6 +28,13 @@ type Group struct {
 	Apps         []*Application `json:"apps"`
 	Dependencies []string       `json:"dependencies"`
 	Groups       []*Group       `json:"groups"`
+	// Pods are the pods of the group, populated by Marathon 1.4 and later
+	Pods []*Pod `json:"pods,omitempty"`
+	// Version is the version of the group, populated by Marathon
+	Version string `json:"version,omitempty"`
+	// EnforceRole makes the applications of a top-level group use the role named after the
+	// group, available since Marathon 1.9
+	EnforceRole *bool `json:"enforceRole,omitempty"`
 }
 
 // Groups is a collection of marathon application groups
@@ -84,10 +92,30 @@ func (r *Group) App(application *Application) *Group {
 	return r
 }
 
+// Subgroup adds a nested group to the group in question
+// 		group:	a pointer to the nested Group, its id can be relative to the group
+func (r *Group) Subgroup(group *Group) *Group {
+	if r.Groups == nil {
+		r.Groups = make([]*Group, 0)
+	}
+	r.Groups = append(r.Groups, group)
+	return r
+}
+
+// DependsOn adds the ids of the applications or groups to be deployed before the group
+// 		ids:	the identifiers of the dependencies
+func (r *Group) DependsOn(ids ...string) *Group {
+	if r.Dependencies == nil {
+		r.Dependencies = make([]string, 0)
+	}
+	r.Dependencies = append(r.Dependencies, ids...)
+	return r
+}
+
 // Groups retrieves a list of all the groups from marathon
 func (r *marathonClient) Groups() (*Groups, error) {
 	groups := new(Groups)
-	if err := r.apiGet(marathonAPIGroups, "", groups); err != nil {
+	if err := r.apiGet("Groups", marathonAPIGroups, "", groups); err != nil {
 		return nil, err
 	}
 	return groups, nil
@@ -97,7 +125,7 @@ func (r *marathonClient) Groups() (*Groups, error) {
 //		name:			the identifier for the group
 func (r *marathonClient) Group(name string) (*Group, error) {
 	group := new(Group)
-	if err := r.apiGet(fmt.Sprintf("%s/%s", marathonAPIGroups, trimRootPath(name)), nil, group); err != nil {
+	if err := r.apiGet("Group", fmt.Sprintf("%s/%s", marathonAPIGroups, trimRootPath(name)), nil, group); err != nil {
 		return nil, err
 	}
 	return group, nil
@@ -111,7 +139,7 @@ func (r *marathonClient) GroupsBy(opts *GetGroupOpts) (*Groups, error) {
 		return nil, err
 	}
 	groups := new(Groups)
-	if err := r.apiGet(path, "", groups); err != nil {
+	if err := r.apiGet("GroupsBy", path, "", groups); err != nil {
 		return nil, err
 	}
 	return groups, nil
@@ -126,7 +154,7 @@ func (r *marathonClient) GroupBy(name string, opts *GetGroupOpts) (*Group, error
 		return nil, err
 	}
 	group := new(Group)
-	if err := r.apiGet(path, nil, group); err != nil {
+	if err := r.apiGet("GroupBy", path, nil, group); err != nil {
 		return nil, err
 	}
 	return group, nil
@@ -136,9 +164,9 @@ func (r *marathonClient) GroupBy(name string, opts *GetGroupOpts) (*Group, error
 // 		name:			the identifier for the group
 func (r *marathonClient) HasGroup(name string) (bool, error) {
 	path := fmt.Sprintf("%s/%s", marathonAPIGroups, trimRootPath(name))
-	err := r.apiGet(path, "", nil)
+	err := r.apiGet("HasGroup", path, "", nil)
 	if err != nil {
-		if apiErr, ok := err.(*APIError); ok && apiErr.ErrCode == ErrCodeNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return false, nil
 		}
 		return false, err
@@ -146,61 +174,112 @@ func (r *marathonClient) HasGroup(name string) (bool, error) {
 	return true, nil
 }
 
+// applications returns the applications of the group and of its nested groups
+func (r *Group) applications() []*Application {
+	applications := append([]*Application{}, r.Apps...)
+	for _, group := range r.Groups {
+		applications = append(applications, group.applications()...)
+	}
+	return applications
+}
+
 // CreateGroup creates a new group in marathon
 //		group:			a pointer the Group structure defining the group
 func (r *marathonClient) CreateGroup(group *Group) error {
-	return r.apiPost(marathonAPIGroups, group, nil)
+	if err := group.CheckLimits(r.config.Limits); err != nil {
+		return err
+	}
+	return r.apiPost("CreateGroup", marathonAPIGroups, group, nil)
 }
 
-// WaitOnGroup waits for all the applications in a group to be deployed
+// DeployGroup creates or updates a group with all its nested groups and applications in a single
+// deployment, Marathon ordering the applications by their dependencies
+//		group:			the group structure, identified by its id
+//		force:			used to force the operation in case of blocked deployment
+func (r *marathonClient) DeployGroup(group *Group, force bool) (*DeploymentID, error) {
+	if err := group.CheckLimits(r.config.Limits); err != nil {
+		return nil, err
+	}
+	deploymentID := new(DeploymentID)
+	path := marathonAPIGroups
+	if force {
+		path += "?force=true"
+	}
+	if err := r.apiPut("DeployGroup", path, group, deploymentID); err != nil {
+		return nil, err
+	}
+
+	return deploymentID, nil
+}
+
+// WaitOnGroup waits for all the applications in a group, including the nested groups, to be deployed
 // 		group:			the identifier for the group
 //		timeout: 		a duration of time to wait before considering it failed (all tasks in all apps running defined as deployed)
 func (r *marathonClient) WaitOnGroup(name string, timeout time.Duration) error {
-	err := deadline(timeout, func(stop_channel chan bool) error {
-		var flick atomicSwitch
-		go func() {
-			<-stop_channel
-			close(stop_channel)
-			flick.SwitchOn()
-		}()
-		for !flick.IsSwitched() {
-			if group, err := r.Group(name); err != nil {
+	return r.waitOnGroup(name, timeout, false)
+}
+
+// WaitOnGroupHealthy waits for all the applications in a group, including the nested groups, to be
+// deployed with all their tasks passing their health checks
+// 		group:			the identifier for the group
+//		timeout: 		a duration of time to wait before considering it failed
+func (r *marathonClient) WaitOnGroupHealthy(name string, timeout time.Duration) error {
+	return r.waitOnGroup(name, timeout, true)
+}
+
+// waitOnGroup waits for the applications of the group, returning a WaitError with the status of
+// the applications not deployed yet on the timeout
+func (r *marathonClient) waitOnGroup(name string, timeout time.Duration, healthy bool) error {
+	var pending []ApplicationWaitStatus
+	err := r.wait(name, timeout, func(name string) bool {
+		group, err := r.Group(name)
+		if err != nil {
+			return false
+		}
+		statuses := []ApplicationWaitStatus{}
+		for _, definition := range group.applications() {
+			// Arrrgghhh!! .. so we can't use application instances from the Application struct like with app wait on as it
+			// appears the instance count is not set straight away!! .. it defaults to zero and changes probably at the
+			// dependencies gets deployed. Which is probably how it internally handles dependencies ..
+			status := ApplicationWaitStatus{ID: definition.ID}
+			if definition.Instances != nil {
+				status.Instances = *definition.Instances
+			}
+			// step: grab the application
+			application, err := r.Application(definition.ID)
+			if err != nil {
+				statuses = append(statuses, status)
 				continue
-			} else {
-				allRunning := true
-				// for each of the application, check if the tasks and running
-				for _, appID := range group.Apps {
-					// Arrrgghhh!! .. so we can't use application instances from the Application struct like with app wait on as it
-					// appears the instance count is not set straight away!! .. it defaults to zero and changes probably at the
-					// dependencies gets deployed. Which is probably how it internally handles dependencies ..
-					// step: grab the application
-					application, err := r.Application(appID.ID)
-					if err != nil {
-						allRunning = false
-						break
-					}
-
-					if application.Tasks == nil {
-						allRunning = false
-					} else if len(application.Tasks) != *appID.Instances {
-						allRunning = false
-					} else if application.TasksRunning != *appID.Instances {
-						allRunning = false
-					} else if len(application.DeploymentIDs()) > 0 {
-						allRunning = false
-					}
-				}
-				// has anyone toggle the flag?
-				if allRunning {
-					return nil
-				}
 			}
-			time.Sleep(r.config.PollingWaitTime)
+			status.TasksRunning = application.TasksRunning
+			status.TasksHealthy = application.TasksHealthy
+			status.Deployments = len(application.DeploymentIDs())
+
+			if len(application.Tasks) != status.Instances || application.TasksRunning != status.Instances ||
+				status.Deployments > 0 || (healthy && !application.AllTasksHealthy()) {
+				statuses = append(statuses, status)
+			}
 		}
-		return nil
+		pending = statuses
+		return len(pending) == 0
 	})
+	if !errors.Is(err, ErrTimeoutError) {
+		return err
+	}
 
-	return err
+	r.log(LogModuleWaits).Infof("WaitOnGroup(): timed out waiting on the group %s", name)
+	// step: look for the cause among the applications not deployed yet
+	var ids []string
+	for _, status := range pending {
+		ids = append(ids, status.ID)
+	}
+	waitErr, ok := diagnoseTimeout(r, err, ids...).(*WaitError)
+	if !ok {
+		waitErr = &WaitError{Reason: ReasonTimeout, ID: name, Err: err}
+	}
+	waitErr.Applications = pending
+
+	return waitErr
 }
 
 // DeleteGroup deletes a group from marathon
@@ -212,7 +291,7 @@ func (r *marathonClient) DeleteGroup(name string, force bool) (*DeploymentID, er
 	if force {
 		path += "?force=true"
 	}
-	if err := r.apiDelete(path, nil, version); err != nil {
+	if err := r.apiDelete("DeleteGroup", path, nil, version); err != nil {
 		return nil, err
 	}
 
@@ -224,12 +303,15 @@ func (r *marathonClient) DeleteGroup(name string, force bool) (*DeploymentID, er
 //		group:  		the group structure with the new params
 //		force:			used to force the update operation in case of blocked deployment
 func (r *marathonClient) UpdateGroup(name string, group *Group, force bool) (*DeploymentID, error) {
+	if err := group.CheckLimits(r.config.Limits); err != nil {
+		return nil, err
+	}
 	deploymentID := new(DeploymentID)
 	path := fmt.Sprintf("%s/%s", marathonAPIGroups, trimRootPath(name))
 	if force {
 		path += "?force=true"
 	}
-	if err := r.apiPut(path, group, deploymentID); err != nil {
+	if err := r.apiPut("UpdateGroup", path, group, deploymentID); err != nil {
 		return nil, err
 	}
 
@@ -18,8 +18,10 @@ package marathon
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGroups(t *testing.T) {
@@ -64,3 +66,52 @@ func TestGroup(t *testing.T) {
 		}
 	}
 }
+
+func TestWaitOnGroup(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 10 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	assert.NoError(t, endpoint.Client.WaitOnGroup("/fake-wait-group", 50*time.Millisecond))
+
+	// step: the tasks of the application of the nested group fail their health checks
+	err := endpoint.Client.WaitOnGroupHealthy("/fake-wait-group", 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Equal(t, ReasonTimeout, Reason(err))
+	waitErr, ok := err.(*WaitError)
+	require.True(t, ok)
+	assert.Equal(t, []ApplicationWaitStatus{
+		{ID: fakeAppNameUnhealthy, Instances: 2, TasksRunning: 2},
+	}, waitErr.Applications)
+	assert.Contains(t, err.Error(), "/no-health-check-results-app: 2/2 running, 0 healthy, 0 deployments")
+}
+
+func TestDeployGroup(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	group := NewApplicationGroup("/product").
+		App(NewDockerApplication().Name("/product/web")).
+		Subgroup(NewApplicationGroup("backend").App(NewDockerApplication().Name("api")))
+	group.Groups[0].DependsOn("/product/web")
+	assert.Equal(t, []string{"/product/web"}, group.Groups[0].Dependencies)
+
+	deployment, err := endpoint.Client.DeployGroup(group, false)
+	require.NoError(t, err)
+	assert.Equal(t, "5ed4c0c5-9ff8-4a6f-a0cd-f57f59a34b43", deployment.DeploymentID)
+}
+
+func TestGroupApplications(t *testing.T) {
+	group := NewApplicationGroup("/product")
+	group.App(NewDockerApplication().Name("/product/web"))
+	group.Groups = []*Group{
+		{ID: "/product/backend", Apps: []*Application{NewDockerApplication().Name("/product/backend/api")}},
+	}
+
+	var ids []string
+	for _, application := range group.applications() {
+		ids = append(ids, application.ID)
+	}
+	assert.Equal(t, []string{"/product/web", "/product/backend/api"}, ids)
+}
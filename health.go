@@ -27,6 +27,7 @@ type HealthCheck struct {
 	GracePeriodSeconds     int      `json:"gracePeriodSeconds,omitempty"`
 	IntervalSeconds        int      `json:"intervalSeconds,omitempty"`
 	TimeoutSeconds         int      `json:"timeoutSeconds,omitempty"`
+	DelaySeconds           int      `json:"delaySeconds,omitempty"`
 	IgnoreHTTP1xx          *bool    `json:"ignoreHttp1xx,omitempty"`
 }
 
@@ -65,15 +66,48 @@ func (h *HealthCheck) SetCommand(c Command) *HealthCheck {
 	return h
 }
 
-// SetPortIndex sets the given port index on the health check.
+// SetPortIndex sets the given port index on the health check, replacing the port if any.
 func (h *HealthCheck) SetPortIndex(i int) *HealthCheck {
 	h.PortIndex = &i
+	h.Port = nil
 	return h
 }
 
-// SetPort sets the given port on the health check.
+// SetPort sets the given port on the health check, replacing the port index if any.
 func (h *HealthCheck) SetPort(i int) *HealthCheck {
 	h.Port = &i
+	h.PortIndex = nil
+	return h
+}
+
+// SetProtocol sets the protocol of the health check, e.g. HTTP, HTTPS, TCP, COMMAND or their
+// MESOS_ variants performed by the agents.
+func (h *HealthCheck) SetProtocol(protocol string) *HealthCheck {
+	h.Protocol = protocol
+	return h
+}
+
+// SetGracePeriod sets the time the failures are ignored for after the task starts.
+func (h *HealthCheck) SetGracePeriod(seconds int) *HealthCheck {
+	h.GracePeriodSeconds = seconds
+	return h
+}
+
+// SetInterval sets the time between the checks.
+func (h *HealthCheck) SetInterval(seconds int) *HealthCheck {
+	h.IntervalSeconds = seconds
+	return h
+}
+
+// SetTimeout sets the time after which a check is considered failed.
+func (h *HealthCheck) SetTimeout(seconds int) *HealthCheck {
+	h.TimeoutSeconds = seconds
+	return h
+}
+
+// SetDelay sets the time before the first check of the MESOS_ protocols.
+func (h *HealthCheck) SetDelay(seconds int) *HealthCheck {
+	h.DelaySeconds = seconds
 	return h
 }
 
@@ -112,6 +146,40 @@ func NewDefaultHealthCheck() *HealthCheck {
 	}
 }
 
+// NewHTTPHealthCheck creates an HTTP health check of the first port with the default settings
+//		path:		the path of the check, e.g. /health
+func NewHTTPHealthCheck(path string) *HealthCheck {
+	return NewDefaultHealthCheck().SetPath(path)
+}
+
+// NewTCPHealthCheck creates a TCP health check of the first port with the default settings
+func NewTCPHealthCheck() *HealthCheck {
+	check := NewDefaultHealthCheck().SetProtocol("TCP")
+	check.Path = nil
+	return check
+}
+
+// NewCommandHealthCheck creates a health check running the command in the task, with the default
+// settings
+//		command:	the shell command, healthy when it exits with 0
+func NewCommandHealthCheck(command string) *HealthCheck {
+	check := NewDefaultHealthCheck().SetProtocol("COMMAND").SetCommand(Command{Value: command})
+	check.Path = nil
+	check.PortIndex = nil
+	return check
+}
+
+// Validate checks the health check against the constraints Marathon enforces, e.g. the fields of
+// its protocol, port and port index being exclusive and the timeout being lower than the interval.
+// It returns a ValidationError listing all the violations, nil if there is none. The port index is
+// checked against the ports of the application by Application.Validate.
+func (h *HealthCheck) Validate() error {
+	if violations := h.violations(0, false); len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
 // HealthCheckResult is the health check result
 type HealthCheckResult struct {
 	Alive               bool   `json:"alive"`
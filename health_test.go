@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommand(t *testing.T) {
@@ -58,3 +59,52 @@ func TestIgnoreHTTP1xx(t *testing.T) {
 	hc.SetIgnoreHTTP1xx(true)
 	assert.True(t, (*hc.IgnoreHTTP1xx))
 }
+
+func TestPortSelection(t *testing.T) {
+	hc := NewDefaultHealthCheck().SetPort(8080)
+	assert.Equal(t, 8080, *hc.Port)
+	assert.Nil(t, hc.PortIndex)
+
+	hc.SetPortIndex(1)
+	assert.Equal(t, 1, *hc.PortIndex)
+	assert.Nil(t, hc.Port)
+}
+
+func TestHealthCheckBuilder(t *testing.T) {
+	hc := NewHTTPHealthCheck("/health").
+		SetProtocol("MESOS_HTTP").
+		SetGracePeriod(60).
+		SetInterval(20).
+		SetTimeout(10).
+		SetDelay(5).
+		SetMaxConsecutiveFailures(5).
+		SetIgnoreHTTP1xx(true)
+	assert.Equal(t, "MESOS_HTTP", hc.Protocol)
+	assert.Equal(t, "/health", *hc.Path)
+	assert.Equal(t, 0, *hc.PortIndex)
+	assert.Equal(t, 60, hc.GracePeriodSeconds)
+	assert.Equal(t, 20, hc.IntervalSeconds)
+	assert.Equal(t, 10, hc.TimeoutSeconds)
+	assert.Equal(t, 5, hc.DelaySeconds)
+	assert.Equal(t, 5, *hc.MaxConsecutiveFailures)
+	assert.True(t, *hc.IgnoreHTTP1xx)
+	assert.NoError(t, hc.Validate())
+
+	tcp := NewTCPHealthCheck()
+	assert.Equal(t, "TCP", tcp.Protocol)
+	assert.Nil(t, tcp.Path)
+	assert.NoError(t, tcp.Validate())
+
+	command := NewCommandHealthCheck("curl -f localhost:8080")
+	assert.Equal(t, "COMMAND", command.Protocol)
+	assert.Equal(t, "curl -f localhost:8080", command.Command.Value)
+	assert.Nil(t, command.PortIndex)
+	assert.NoError(t, command.Validate())
+}
+
+func TestHealthCheckValidate(t *testing.T) {
+	err := NewTCPHealthCheck().SetPath("/health").SetTimeout(30).Validate()
+	require.Error(t, err)
+	assert.Equal(t, "invalid definition: path: is only supported by the HTTP protocols; "+
+		"timeoutSeconds: 30 must be lower than the interval of 10 seconds", err.Error())
+}
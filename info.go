@@ -16,58 +16,152 @@ limitations under the License.
 
 package marathon
 
+import "strings"
+
+// defaultMesosRole is the Mesos role of the frameworks which don't set one
+const defaultMesosRole = "*"
+
 // Info is the detailed stats returned from marathon info
 type Info struct {
-	EventSubscriber struct {
-		HTTPEndpoints []string `json:"http_endpoints"`
-		Type          string   `json:"type"`
-	} `json:"event_subscriber"`
-	FrameworkID string `json:"frameworkId"`
-	HTTPConfig  struct {
-		AssetsPath interface{} `json:"assets_path"`
-		HTTPPort   float64     `json:"http_port"`
-		HTTPSPort  float64     `json:"https_port"`
-	} `json:"http_config"`
-	Leader         string `json:"leader"`
-	MarathonConfig struct {
-		Checkpoint                     bool    `json:"checkpoint"`
-		Executor                       string  `json:"executor"`
-		FailoverTimeout                float64 `json:"failover_timeout"`
-		FrameworkName                  string  `json:"framework_name"`
-		Ha                             bool    `json:"ha"`
-		Hostname                       string  `json:"hostname"`
-		LeaderProxyConnectionTimeoutMs float64 `json:"leader_proxy_connection_timeout_ms"`
-		LeaderProxyReadTimeoutMs       float64 `json:"leader_proxy_read_timeout_ms"`
-		LocalPortMax                   float64 `json:"local_port_max"`
-		LocalPortMin                   float64 `json:"local_port_min"`
-		Master                         string  `json:"master"`
-		MesosLeaderUIURL               string  `json:"mesos_leader_ui_url"`
-		WebUIURL                       string  `json:"webui_url"`
-		MesosRole                      string  `json:"mesos_role"`
-		MesosUser                      string  `json:"mesos_user"`
-		ReconciliationInitialDelay     float64 `json:"reconciliation_initial_delay"`
-		ReconciliationInterval         float64 `json:"reconciliation_interval"`
-		TaskLaunchTimeout              float64 `json:"task_launch_timeout"`
-		TaskReservationTimeout         float64 `json:"task_reservation_timeout"`
-	} `json:"marathon_config"`
-	Name            string `json:"name"`
-	Version         string `json:"version"`
-	ZookeeperConfig struct {
-		Zk              string `json:"zk"`
-		ZkFutureTimeout struct {
-			Duration float64 `json:"duration"`
-		} `json:"zk_future_timeout"`
-		ZkHosts   string  `json:"zk_hosts"`
-		ZkPath    string  `json:"zk_path"`
-		ZkState   string  `json:"zk_state"`
-		ZkTimeout float64 `json:"zk_timeout"`
-	} `json:"zookeeper_config"`
+	EventSubscriber InfoEventSubscriber `json:"event_subscriber"`
+	FrameworkID     string              `json:"frameworkId"`
+	HTTPConfig      InfoHTTPConfig      `json:"http_config"`
+	Leader          string              `json:"leader"`
+	// Elected tells whether the Marathon member is the leader
+	Elected         bool                `json:"elected"`
+	Buildref        string              `json:"buildref,omitempty"`
+	MarathonConfig  InfoMarathonConfig  `json:"marathon_config"`
+	Name            string              `json:"name"`
+	Version         string              `json:"version"`
+	ZookeeperConfig InfoZookeeperConfig `json:"zookeeper_config"`
+}
+
+// InfoEventSubscriber is the configuration of the event subscribers of Marathon
+type InfoEventSubscriber struct {
+	HTTPEndpoints []string `json:"http_endpoints"`
+	Type          string   `json:"type"`
+}
+
+// InfoHTTPConfig is the configuration of the HTTP API of Marathon
+type InfoHTTPConfig struct {
+	AssetsPath interface{} `json:"assets_path"`
+	HTTPPort   float64     `json:"http_port"`
+	HTTPSPort  float64     `json:"https_port"`
+}
+
+// InfoMarathonConfig is the configuration of Marathon
+type InfoMarathonConfig struct {
+	Checkpoint                     bool     `json:"checkpoint"`
+	Executor                       string   `json:"executor"`
+	FailoverTimeout                float64  `json:"failover_timeout"`
+	Features                       []string `json:"features,omitempty"`
+	FrameworkName                  string   `json:"framework_name"`
+	Ha                             bool     `json:"ha"`
+	Hostname                       string   `json:"hostname"`
+	LeaderProxyConnectionTimeoutMs float64  `json:"leader_proxy_connection_timeout_ms"`
+	LeaderProxyReadTimeoutMs       float64  `json:"leader_proxy_read_timeout_ms"`
+	LocalPortMax                   float64  `json:"local_port_max"`
+	LocalPortMin                   float64  `json:"local_port_min"`
+	Master                         string   `json:"master"`
+	MesosLeaderUIURL               string   `json:"mesos_leader_ui_url"`
+	WebUIURL                       string   `json:"webui_url"`
+	MesosRole                      string   `json:"mesos_role"`
+	MesosUser                      string   `json:"mesos_user"`
+	DefaultNetworkName             string   `json:"default_network_name,omitempty"`
+	MesosBridgeName                string   `json:"mesos_bridge_name,omitempty"`
+	AccessControlAllowOrigin       []string `json:"access_control_allow_origin,omitempty"`
+	MaxInstancesPerOffer           float64  `json:"max_instances_per_offer,omitempty"`
+	DeclineOfferDuration           float64  `json:"decline_offer_duration,omitempty"`
+	ReconciliationInitialDelay     float64  `json:"reconciliation_initial_delay"`
+	ReconciliationInterval         float64  `json:"reconciliation_interval"`
+	TaskLaunchTimeout              float64  `json:"task_launch_timeout"`
+	TaskReservationTimeout         float64  `json:"task_reservation_timeout"`
+}
+
+// InfoZookeeperConfig is the configuration of the ZooKeeper state of Marathon
+type InfoZookeeperConfig struct {
+	Zk              string `json:"zk"`
+	ZkFutureTimeout struct {
+		Duration float64 `json:"duration"`
+	} `json:"zk_future_timeout"`
+	ZkHosts   string  `json:"zk_hosts"`
+	ZkPath    string  `json:"zk_path"`
+	ZkState   string  `json:"zk_state"`
+	ZkTimeout float64 `json:"zk_timeout"`
+}
+
+// the features Marathon may be started with, as listed by the features of its configuration
+const (
+	// FeatureVIPs enables the virtual IPs of the port mappings and definitions
+	FeatureVIPs = "vips"
+	// FeatureTaskKilling enables the TASK_KILLING state of the tasks
+	FeatureTaskKilling = "task_killing"
+	// FeatureExternalVolumes enables the external volumes
+	FeatureExternalVolumes = "external_volumes"
+	// FeatureGPUResources enables the gpus of the applications and pods
+	FeatureGPUResources = "gpu_resources"
+	// FeatureSecrets enables the secrets of the applications and pods
+	FeatureSecrets = "secrets"
+)
+
+// HasFeature checks if Marathon was started with the feature, e.g. FeatureGPUResources
+//		feature:	the name of the feature
+func (c *InfoMarathonConfig) HasFeature(feature string) bool {
+	return contains(c.Features, feature)
+}
+
+// Masters returns the addresses of the Mesos masters of Marathon, either the masters listed or the
+// ZooKeeper hosts the masters are discovered with, e.g. for zk://user:pass@zk1:2181,zk2:2181/mesos
+func (c *InfoMarathonConfig) Masters() []string {
+	master := c.Master
+	if strings.HasPrefix(master, "zk://") {
+		master = strings.TrimPrefix(master, "zk://")
+		if index := strings.Index(master, "/"); index >= 0 {
+			master = master[:index]
+		}
+		if index := strings.LastIndex(master, "@"); index >= 0 {
+			master = master[index+1:]
+		}
+	}
+	var masters []string
+	for _, address := range strings.Split(master, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			masters = append(masters, address)
+		}
+	}
+	return masters
+}
+
+// ZookeeperHosts returns the hosts of the ZooKeeper ensemble Marathon stores its state in
+func (c *InfoZookeeperConfig) ZookeeperHosts() []string {
+	var hosts []string
+	for _, host := range strings.Split(c.ZkHosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// Roles returns the Mesos roles an application or a pod may use, since Marathon 1.9: the role of
+// Marathon, and the role named after its top-level group, e.g. dev for /dev/web
+//		id:		the id of the application or the pod
+func (i *Info) Roles(id string) []string {
+	roles := []string{i.MarathonConfig.MesosRole}
+	if roles[0] == "" {
+		roles[0] = defaultMesosRole
+	}
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	if len(segments) > 1 && segments[0] != roles[0] {
+		roles = append(roles, segments[0])
+	}
+	return roles
 }
 
 // Info retrieves the info stats from marathon
 func (r *marathonClient) Info() (*Info, error) {
 	info := new(Info)
-	if err := r.apiGet(marathonAPIInfo, nil, info); err != nil {
+	if err := r.apiGet("Info", marathonAPIInfo, nil, info); err != nil {
 		return nil, err
 	}
 
@@ -79,7 +173,7 @@ func (r *marathonClient) Leader() (string, error) {
 	var leader struct {
 		Leader string `json:"leader"`
 	}
-	if err := r.apiGet(marathonAPILeader, nil, &leader); err != nil {
+	if err := r.apiGet("Leader", marathonAPILeader, nil, &leader); err != nil {
 		return "", err
 	}
 
@@ -92,7 +186,7 @@ func (r *marathonClient) AbdicateLeader() (string, error) {
 		Message string `json:"message"`
 	}
 
-	if err := r.apiDelete(marathonAPILeader, nil, &message); err != nil {
+	if err := r.apiDelete("AbdicateLeader", marathonAPILeader, nil, &message); err != nil {
 		return "", err
 	}
 
@@ -31,6 +31,23 @@ func TestInfo(t *testing.T) {
 	assert.Equal(t, info.FrameworkID, "20140730-222531-1863654316-5050-10422-0000")
 	assert.Equal(t, info.Leader, "127.0.0.1:8080")
 	assert.Equal(t, info.Version, "0.7.0-SNAPSHOT")
+	assert.True(t, info.Elected)
+	assert.Equal(t, "http_callback", info.EventSubscriber.Type)
+	assert.Equal(t, 8443.0, info.HTTPConfig.HTTPSPort)
+	assert.Equal(t, "dcos", info.MarathonConfig.DefaultNetworkName)
+	assert.Equal(t, []string{"zk1:2181", "zk2:2181"}, info.MarathonConfig.Masters())
+	assert.True(t, info.MarathonConfig.HasFeature(FeatureGPUResources))
+	assert.False(t, info.MarathonConfig.HasFeature(FeatureSecrets))
+	assert.Equal(t, []string{"localhost:2181"}, info.ZookeeperConfig.ZookeeperHosts())
+}
+
+func TestInfoMasters(t *testing.T) {
+	config := InfoMarathonConfig{Master: "10.0.0.1:5050"}
+	assert.Equal(t, []string{"10.0.0.1:5050"}, config.Masters())
+	config.Master = "zk://10.0.0.1:2181,10.0.0.2:2181/mesos"
+	assert.Equal(t, []string{"10.0.0.1:2181", "10.0.0.2:2181"}, config.Masters())
+	config.Master = ""
+	assert.Empty(t, config.Masters())
 }
 
 func TestLeader(t *testing.T) {
@@ -50,3 +67,15 @@ func TestAbdicateLeader(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, message, "Leadership abdicted")
 }
+
+func TestInfoRoles(t *testing.T) {
+	info := new(Info)
+	assert.Equal(t, []string{"*"}, info.Roles("/web"))
+	assert.Equal(t, []string{"*", "dev"}, info.Roles("/dev/web"))
+	assert.Equal(t, []string{"*", "dev"}, info.Roles("dev/backend/api"))
+
+	info.MarathonConfig.MesosRole = "marathon"
+	assert.Equal(t, []string{"marathon"}, info.Roles("/web"))
+	assert.Equal(t, []string{"marathon"}, info.Roles("/marathon/web"))
+	assert.Equal(t, []string{"marathon", "dev"}, info.Roles("/dev/web"))
+}
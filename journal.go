@@ -0,0 +1,256 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// JournalKindEvent is the kind of the journal entries recording the events received
+	JournalKindEvent = "event"
+	// JournalKindDeployment is the kind of the journal entries recording the deployments started
+	// by the client
+	JournalKindDeployment = "deployment"
+)
+
+// JournalEntry is an entry of the event journal and deployment history
+type JournalEntry struct {
+	// Time is when the event occurred or the deployment started
+	Time time.Time `json:"time"`
+	// Kind is either JournalKindEvent or JournalKindDeployment
+	Kind string `json:"kind"`
+	// Type is the event type, e.g. status_update_event, or the HTTP method starting the deployment
+	Type string `json:"type"`
+	// AppID is the id of the application concerned, when known
+	AppID string `json:"appId,omitempty"`
+	// DeploymentID is the id of the deployment, when known
+	DeploymentID string `json:"deploymentId,omitempty"`
+	// Payload is the content of the event or the definition sent with the deployment
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// JournalQuery selects journal entries, the zero value selecting all the entries
+//		kind:		the kind of the entries
+//		appID:		the id of the application, or of a group containing it
+//		since:		the entries at or after the time
+//		until:		the entries before the time
+//		limit:		the maximum number of entries, the most recent ones being kept
+type JournalQuery struct {
+	Kind  string
+	AppID string
+	Since time.Time
+	Until time.Time
+	Limit int
+}
+
+// JournalStore persists the event journal and the deployment history of the client, e.g. so a
+// long-running controller can look them up after a restart
+type JournalStore interface {
+	// Append stores the entry
+	Append(entry JournalEntry) error
+	// Query retrieves the entries selected by the query, the oldest first
+	Query(query JournalQuery) ([]JournalEntry, error)
+}
+
+// Matches checks if the entry is selected by the query, for use by JournalStore implementations
+func (q JournalQuery) Matches(entry JournalEntry) bool {
+	if q.Kind != "" && q.Kind != entry.Kind {
+		return false
+	}
+	if q.AppID != "" {
+		id := validateID(q.AppID)
+		if entry.AppID != id && !strings.HasPrefix(entry.AppID, strings.TrimSuffix(id, "/")+"/") {
+			return false
+		}
+	}
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !entry.Time.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// apply selects the entries of the query
+func (q JournalQuery) apply(entries []JournalEntry) []JournalEntry {
+	selected := []JournalEntry{}
+	for _, entry := range entries {
+		if q.Matches(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	if q.Limit > 0 && len(selected) > q.Limit {
+		selected = selected[len(selected)-q.Limit:]
+	}
+	return selected
+}
+
+// memoryJournalStore keeps the journal in memory
+type memoryJournalStore struct {
+	sync.RWMutex
+	entries []JournalEntry
+}
+
+// NewMemoryJournalStore creates a journal store keeping the entries in memory, i.e. they are lost
+// when the process exits
+func NewMemoryJournalStore() JournalStore {
+	return &memoryJournalStore{}
+}
+
+func (m *memoryJournalStore) Append(entry JournalEntry) error {
+	m.Lock()
+	defer m.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryJournalStore) Query(query JournalQuery) ([]JournalEntry, error) {
+	m.RLock()
+	defer m.RUnlock()
+	return query.apply(m.entries), nil
+}
+
+// fileJournalStore appends the journal to a file, one JSON entry per line
+type fileJournalStore struct {
+	sync.Mutex
+	path string
+}
+
+// NewFileJournalStore creates a journal store appending the entries to a file, one JSON entry per
+// line, so the journal survives process restarts without any external database
+//		path:		the file of the journal, created if missing
+func NewFileJournalStore(path string) (JournalStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+
+	return &fileJournalStore{path: path}, nil
+}
+
+func (f *fileJournalStore) Append(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (f *fileJournalStore) Query(query JournalQuery) ([]JournalEntry, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		if query.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return query.apply(entries), nil
+}
+
+// journalEvent records the event received in the journal
+func (r *marathonClient) journalEvent(eventType, content string) {
+	if r.config.Journal == nil {
+		return
+	}
+	var fields struct {
+		AppID     string `json:"appId"`
+		Timestamp string `json:"timestamp"`
+		ID        string `json:"id"`
+	}
+	json.Unmarshal([]byte(content), &fields)
+
+	entry := JournalEntry{
+		Time:    time.Now(),
+		Kind:    JournalKindEvent,
+		Type:    eventType,
+		AppID:   fields.AppID,
+		Payload: json.RawMessage(content),
+	}
+	if timestamp, err := time.Parse(time.RFC3339Nano, fields.Timestamp); err == nil {
+		entry.Time = timestamp
+	}
+	if strings.HasPrefix(eventType, "deployment_") {
+		entry.DeploymentID = fields.ID
+	}
+	if err := r.config.Journal.Append(entry); err != nil {
+		r.log(LogModuleEvents).Errorf("journalEvent(): failed to append the event to the journal, error: %s", err)
+	}
+}
+
+// journalDeployment records the deployment started by the API call in the deployment history
+func (r *marathonClient) journalDeployment(method, path string, requestBody, respBody []byte) {
+	if r.config.Journal == nil || method == "GET" {
+		return
+	}
+	deploymentID := callDeploymentID(path, respBody)
+	if deploymentID == "" {
+		return
+	}
+	entry := JournalEntry{
+		Time:         time.Now(),
+		Kind:         JournalKindDeployment,
+		Type:         method,
+		AppID:        pathAppID(path),
+		DeploymentID: deploymentID,
+	}
+	if len(requestBody) > 0 && json.Valid(requestBody) {
+		entry.Payload = json.RawMessage(requestBody)
+	}
+	if err := r.config.Journal.Append(entry); err != nil {
+		r.log(LogModuleAPI).Errorf("journalDeployment(): failed to append the deployment to the journal, error: %s", err)
+	}
+}
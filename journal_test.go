@@ -0,0 +1,123 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalQueryMatches(t *testing.T) {
+	now := time.Now()
+	entry := JournalEntry{Time: now, Kind: JournalKindEvent, AppID: "/prod/api"}
+
+	assert.True(t, JournalQuery{}.Matches(entry))
+	assert.True(t, JournalQuery{Kind: JournalKindEvent, AppID: "prod/api"}.Matches(entry))
+	assert.True(t, JournalQuery{AppID: "/prod"}.Matches(entry))
+	assert.False(t, JournalQuery{AppID: "/pro"}.Matches(entry))
+	assert.False(t, JournalQuery{Kind: JournalKindDeployment}.Matches(entry))
+	assert.True(t, JournalQuery{Since: now, Until: now.Add(time.Second)}.Matches(entry))
+	assert.False(t, JournalQuery{Since: now.Add(time.Second)}.Matches(entry))
+	assert.False(t, JournalQuery{Until: now}.Matches(entry))
+}
+
+func testJournalStore(t *testing.T, store JournalStore) {
+	start := time.Now()
+	for i, id := range []string{"/prod/api", "/prod/web", "/dev/api"} {
+		require.NoError(t, store.Append(JournalEntry{
+			Time:  start.Add(time.Duration(i) * time.Second),
+			Kind:  JournalKindDeployment,
+			Type:  "PUT",
+			AppID: id,
+		}))
+	}
+
+	entries, err := store.Query(JournalQuery{AppID: "/prod"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "/prod/api", entries[0].AppID)
+	assert.Equal(t, "/prod/web", entries[1].AppID)
+
+	entries, err = store.Query(JournalQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/dev/api", entries[0].AppID)
+
+	entries, err = store.Query(JournalQuery{Kind: JournalKindEvent})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestMemoryJournalStore(t *testing.T) {
+	testJournalStore(t, NewMemoryJournalStore())
+}
+
+func TestFileJournalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-marathon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal")
+
+	store, err := NewFileJournalStore(path)
+	require.NoError(t, err)
+	testJournalStore(t, store)
+
+	// step: the entries survive reopening the journal
+	store, err = NewFileJournalStore(path)
+	require.NoError(t, err)
+	entries, err := store.Query(JournalQuery{})
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+
+	_, err = NewFileJournalStore(filepath.Join(dir, "missing", "journal"))
+	assert.Error(t, err)
+}
+
+func TestJournal(t *testing.T) {
+	journal := NewMemoryJournalStore()
+	config := NewDefaultConfig()
+	config.Journal = journal
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	_, err = endpoint.Client.Application(fakeAppName)
+	require.NoError(t, err)
+	require.NoError(t, endpoint.Client.(*marathonClient).handleEvent(testCases[0].source))
+
+	entries, err := journal.Query(JournalQuery{Kind: JournalKindDeployment})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "PUT", entries[0].Type)
+	assert.Equal(t, "/fake-app", entries[0].AppID)
+	assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", entries[0].DeploymentID)
+	assert.NotEmpty(t, entries[0].Payload)
+
+	entries, err = journal.Query(JournalQuery{Kind: JournalKindEvent, AppID: "/my-app"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "status_update_event", entries[0].Type)
+	assert.Equal(t, time.Date(2014, 3, 1, 23, 29, 30, 158000000, time.UTC), entries[0].Time.UTC())
+	assert.JSONEq(t, testCases[0].source, string(entries[0].Payload))
+}
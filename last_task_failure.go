@@ -16,7 +16,10 @@ limitations under the License.
 
 package marathon
 
-// LastTaskFailure provides details on the last error experienced by an application
+import "time"
+
+// LastTaskFailure provides details on the last error experienced by an application, embedded in
+// the applications with app.lastTaskFailure, apps.lastTaskFailure or apps.failures
 type LastTaskFailure struct {
 	AppID     string `json:"appId,omitempty"`
 	Host      string `json:"host,omitempty"`
@@ -27,3 +30,40 @@ type LastTaskFailure struct {
 	Timestamp string `json:"timestamp,omitempty"`
 	Version   string `json:"version,omitempty"`
 }
+
+// Time parses the time the task failed at, see ParseVersionTime
+func (r *LastTaskFailure) Time() (time.Time, error) {
+	return ParseVersionTime(r.Timestamp)
+}
+
+// VersionTime parses the version of the application the task was launched with, see
+// ParseVersionTime
+func (r *LastTaskFailure) VersionTime() (time.Time, error) {
+	return ParseVersionTime(r.Version)
+}
+
+// RecentFailures returns the task failures of the application at or after the time, e.g. for a
+// post-mortem of an incident. Marathon only keeps the last task failure of an application, so
+// there is at most one, and none when it isn't embedded or its time doesn't parse.
+//		since:	the time the failures happened at or after
+func (r *Application) RecentFailures(since time.Time) []*LastTaskFailure {
+	if r.LastTaskFailure == nil {
+		return nil
+	}
+	failedAt, err := r.LastTaskFailure.Time()
+	if err != nil || failedAt.Before(since) {
+		return nil
+	}
+	return []*LastTaskFailure{r.LastTaskFailure}
+}
+
+// RecentFailures returns the last task failures of the applications at or after the time, the
+// applications being listed with the apps.lastTaskFailure embed, see Application.RecentFailures
+//		since:	the time the failures happened at or after
+func (r *Applications) RecentFailures(since time.Time) []*LastTaskFailure {
+	var failures []*LastTaskFailure
+	for i := range r.Apps {
+		failures = append(failures, r.Apps[i].RecentFailures(since)...)
+	}
+	return failures
+}
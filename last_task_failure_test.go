@@ -0,0 +1,74 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastTaskFailure(t *testing.T) {
+	var application Application
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "/web",
+		"lastTaskFailure": {
+			"appId": "/web",
+			"host": "agent-1",
+			"message": "Container exited with status 1",
+			"state": "TASK_FAILED",
+			"taskId": "web.6e4a7a2b-a6e1-11e7-ae2e-70b3d5800001",
+			"timestamp": "2017-10-02T10:05:00.000Z",
+			"version": "2017-10-02T10:00:00.000Z",
+			"slaveId": "a7d3d8ce-a6e1-11e7-ae2e-70b3d5800001-S1"
+		}
+	}`), &application))
+
+	failure := application.LastTaskFailure
+	require.NotNil(t, failure)
+	assert.Equal(t, "TASK_FAILED", failure.State)
+	assert.Equal(t, "agent-1", failure.Host)
+	assert.Equal(t, "a7d3d8ce-a6e1-11e7-ae2e-70b3d5800001-S1", failure.SlaveID)
+	failedAt, err := failure.Time()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 5, 0, 0, time.UTC), failedAt)
+	version, err := failure.VersionTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 0, 0, 0, time.UTC), version)
+}
+
+func TestRecentFailures(t *testing.T) {
+	failure := &LastTaskFailure{TaskID: "web.1", Timestamp: "2017-10-02T10:05:00.000Z"}
+	applications := &Applications{Apps: []Application{
+		{ID: "/web", LastTaskFailure: failure},
+		{ID: "/api"},
+		{ID: "/db", LastTaskFailure: &LastTaskFailure{TaskID: "db.1", Timestamp: "2017-10-01T10:00:00.000Z"}},
+		{ID: "/queue", LastTaskFailure: &LastTaskFailure{TaskID: "queue.1", Timestamp: "unknown"}},
+	}}
+
+	since := time.Date(2017, 10, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []*LastTaskFailure{failure}, applications.Apps[0].RecentFailures(since))
+	assert.Empty(t, applications.Apps[1].RecentFailures(since))
+	assert.Empty(t, applications.Apps[2].RecentFailures(since))
+	assert.Equal(t, []*LastTaskFailure{failure}, applications.RecentFailures(since))
+	assert.Len(t, applications.RecentFailures(time.Time{}), 2)
+	// step: the failure at the very time is recent
+	assert.Len(t, applications.Apps[0].RecentFailures(time.Date(2017, 10, 2, 10, 5, 0, 0, time.UTC)), 1)
+}
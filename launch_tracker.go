@@ -0,0 +1,248 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"time"
+)
+
+// LaunchTrackerOpts contains the options of a LaunchTracker
+//		interval:	the interval the launch queue and the tasks are sampled at, defaults to the
+//				polling wait time of the client
+//		timeout:	stops tracking after the timeout, zero tracking until the deployment of the
+//				scaling is finished or the tracker is stopped
+//		useEvents:	samples on each status update event of the application's tasks as well,
+//				requires the events transport of the client to be configured
+type LaunchTrackerOpts struct {
+	Interval  time.Duration
+	Timeout   time.Duration
+	UseEvents bool
+}
+
+// LaunchSample is a snapshot of the launch of the instances of an application
+type LaunchSample struct {
+	// the time the sample was taken at
+	Time time.Time
+	// the number of instances requested
+	Requested int
+	// the number of instances still waiting in the launch queue
+	Queued int
+	// the number of tasks launched by the deployment, i.e. staging, starting or running
+	Launched int
+	// the number of tasks launched by the deployment running
+	Running int
+	// whether the deployment of the scaling is finished
+	Finished bool
+}
+
+// LaunchTracker reports the instances requested vs launched vs running over time, e.g. for an
+// autoscaler to measure the scheduling latency of its cluster
+type LaunchTracker struct {
+	sync.RWMutex
+	// the client the launch queue and the tasks are sampled with
+	client Marathon
+	// the id of the tracked application
+	appID string
+	// the number of instances requested
+	requested int
+	// the deployment of the scaling, the version of which the tasks it launches are of
+	deployment DeploymentID
+	// the time the tracking started at
+	started time.Time
+	samples []LaunchSample
+	// the error tracking stopped with
+	err      error
+	events   EventsChannel
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewLaunchTracker starts tracking the launch of the instances of the application by the deployment
+// of its scaling, e.g. as returned by ScaleApplicationInstances. Only the tasks launched by the
+// deployment are counted, not the ones it replaces, and the tracking finishes with the deployment.
+//		client:		the client used to sample the launch queue and the tasks
+//		name:		the id of the application
+//		instances:	the number of instances requested
+//		deployment:	the deployment of the scaling
+//		opts:		the tracking options
+func NewLaunchTracker(client Marathon, name string, instances int, deployment *DeploymentID, opts *LaunchTrackerOpts) (*LaunchTracker, error) {
+	if opts == nil {
+		opts = &LaunchTrackerOpts{}
+	}
+	tracker := &LaunchTracker{
+		client:     client,
+		appID:      validateID(name),
+		requested:  instances,
+		deployment: *deployment,
+		started:    time.Now(),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	if opts.UseEvents {
+		events, err := client.AddEventsListener(EventIDStatusUpdate)
+		if err != nil {
+			return nil, err
+		}
+		tracker.events = events
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultPollingWaitTime
+	}
+	go tracker.track(interval, opts.Timeout)
+
+	return tracker, nil
+}
+
+// Samples retrieves the samples taken so far, the oldest first
+func (t *LaunchTracker) Samples() []LaunchSample {
+	t.RLock()
+	defer t.RUnlock()
+
+	return append([]LaunchSample{}, t.samples...)
+}
+
+// SchedulingLatency retrieves the time it took for the deployment of the scaling to finish, i.e. for
+// all the requested instances to run, the boolean being false if it is not finished yet
+func (t *LaunchTracker) SchedulingLatency() (time.Duration, bool) {
+	t.RLock()
+	defer t.RUnlock()
+
+	for _, sample := range t.samples {
+		if sample.complete() {
+			return sample.Time.Sub(t.started), true
+		}
+	}
+	return 0, false
+}
+
+// Done returns a channel closed when the tracking has stopped
+func (t *LaunchTracker) Done() <-chan struct{} {
+	return t.done
+}
+
+// Err retrieves the error the tracking stopped with, ErrTimeoutError if the deployment was not
+// finished before the timeout
+func (t *LaunchTracker) Err() error {
+	t.RLock()
+	defer t.RUnlock()
+
+	return t.err
+}
+
+// Stop stops the tracking
+func (t *LaunchTracker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stop)
+	})
+	<-t.done
+}
+
+func (t *LaunchTracker) track(interval, timeout time.Duration) {
+	defer close(t.done)
+	if t.events != nil {
+		defer t.client.RemoveEventsListener(t.events)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		if t.sample() {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case event, ok := <-t.events:
+			if !ok {
+				t.events = nil
+				continue
+			}
+			if update, ok := event.Event.(*EventStatusUpdate); !ok || update.AppID != t.appID {
+				continue
+			}
+		case <-expired:
+			t.Lock()
+			t.err = diagnoseTimeout(t.client, ErrTimeoutError, t.appID)
+			t.Unlock()
+			return
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// sample records the current state of the launch, returning true once the deployment is finished
+func (t *LaunchTracker) sample() bool {
+	sample := LaunchSample{Requested: t.requested}
+
+	// step: the deployment is checked first, so the tasks of its last sample are the final ones
+	deployment, err := findDeployment(t.client, t.deployment.DeploymentID)
+	if err != nil {
+		// step: a failed sample is skipped, the next one will catch up
+		return false
+	}
+	sample.Finished = deployment == nil
+
+	queue, err := t.client.Queue()
+	if err != nil {
+		return false
+	}
+	for _, item := range queue.Items {
+		if item.Application.ID == t.appID {
+			sample.Queued += item.Count
+		}
+	}
+
+	tasks, err := t.client.Tasks(t.appID)
+	if err != nil {
+		return false
+	}
+	for _, task := range tasks.Tasks {
+		// step: the tasks of the other versions were there before the scaling
+		if task.Version != t.deployment.Version {
+			continue
+		}
+		switch task.State {
+		case "TASK_RUNNING":
+			sample.Running++
+			sample.Launched++
+		case "TASK_STAGING", "TASK_STARTING":
+			sample.Launched++
+		}
+	}
+	sample.Time = time.Now()
+
+	t.Lock()
+	defer t.Unlock()
+	t.samples = append(t.samples, sample)
+
+	return sample.complete()
+}
+
+// complete checks if the deployment of the scaling is finished
+func (s LaunchSample) complete() bool {
+	return s.Finished
+}
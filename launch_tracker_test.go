@@ -0,0 +1,190 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// launchingClient simulates the launch of instances by a deployment, one step per sample
+type launchingClient struct {
+	Marathon
+	sync.Mutex
+	// the launch queue count, the task states of the deployment and whether it is still deploying,
+	// returned by each sample
+	queued    []int
+	states    [][]string
+	deploying []bool
+	// the states of the tasks which were there before the scaling
+	previous []string
+	calls    int
+}
+
+var launchingDeployment = &DeploymentID{DeploymentID: "scale-deployment", Version: "2018-01-02T00:00:00.000Z"}
+
+func (c *launchingClient) Deployments() ([]*Deployment, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.deploying[c.step()] {
+		return nil, nil
+	}
+	return []*Deployment{{ID: launchingDeployment.DeploymentID}}, nil
+}
+
+func (c *launchingClient) Queue() (*Queue, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	queue := &Queue{Items: []Item{
+		{Count: c.queued[c.step()], Application: Application{ID: "/fake-app"}},
+		{Count: 5, Application: Application{ID: "/other-app"}},
+	}}
+	return queue, nil
+}
+
+func (c *launchingClient) Tasks(name string) (*Tasks, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	tasks := new(Tasks)
+	for _, state := range c.previous {
+		tasks.Tasks = append(tasks.Tasks, Task{AppID: name, State: state, Version: "2018-01-01T00:00:00.000Z"})
+	}
+	for _, state := range c.states[c.step()] {
+		tasks.Tasks = append(tasks.Tasks, Task{AppID: name, State: state, Version: launchingDeployment.Version})
+	}
+	c.calls++
+	return tasks, nil
+}
+
+func (c *launchingClient) Application(name string) (*Application, error) {
+	return &Application{ID: name}, nil
+}
+
+func (c *launchingClient) step() int {
+	if c.calls < len(c.states) {
+		return c.calls
+	}
+	return len(c.states) - 1
+}
+
+func TestLaunchTracker(t *testing.T) {
+	client := &launchingClient{
+		queued: []int{3, 1, 0, 0},
+		states: [][]string{
+			{},
+			{"TASK_STAGING", "TASK_RUNNING"},
+			{"TASK_RUNNING", "TASK_RUNNING", "TASK_RUNNING"},
+			{"TASK_RUNNING", "TASK_RUNNING", "TASK_RUNNING"},
+		},
+		deploying: []bool{true, true, true, false},
+		// step: the running tasks being replaced aren't counted
+		previous: []string{"TASK_RUNNING", "TASK_RUNNING", "TASK_RUNNING"},
+	}
+	tracker, err := NewLaunchTracker(client, "fake-app", 3, launchingDeployment, &LaunchTrackerOpts{Interval: time.Millisecond})
+	require.NoError(t, err)
+
+	select {
+	case <-tracker.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the deployment to finish")
+	}
+	assert.NoError(t, tracker.Err())
+
+	samples := tracker.Samples()
+	require.Len(t, samples, 4)
+	assert.Equal(t, LaunchSample{Time: samples[0].Time, Requested: 3, Queued: 3}, samples[0])
+	assert.Equal(t, LaunchSample{Time: samples[1].Time, Requested: 3, Queued: 1, Launched: 2, Running: 1}, samples[1])
+	assert.Equal(t, LaunchSample{Time: samples[2].Time, Requested: 3, Launched: 3, Running: 3}, samples[2])
+	assert.Equal(t, LaunchSample{Time: samples[3].Time, Requested: 3, Launched: 3, Running: 3, Finished: true}, samples[3])
+
+	latency, ok := tracker.SchedulingLatency()
+	assert.True(t, ok)
+	assert.True(t, latency > 0)
+}
+
+func TestLaunchTrackerScaleDown(t *testing.T) {
+	// step: the instances already running don't finish the tracking, the deployment does
+	client := &launchingClient{
+		queued:    []int{0, 0},
+		states:    [][]string{{}, {}},
+		deploying: []bool{true, false},
+		previous:  []string{"TASK_RUNNING", "TASK_RUNNING"},
+	}
+	tracker, err := NewLaunchTracker(client, "fake-app", 1, launchingDeployment, &LaunchTrackerOpts{Interval: time.Millisecond})
+	require.NoError(t, err)
+
+	<-tracker.Done()
+	samples := tracker.Samples()
+	require.Len(t, samples, 2)
+	assert.False(t, samples[0].Finished)
+	assert.True(t, samples[1].Finished)
+	assert.Equal(t, 0, samples[1].Running)
+}
+
+func TestLaunchTrackerTimeout(t *testing.T) {
+	client := &launchingClient{queued: []int{1}, states: [][]string{{"TASK_STAGING"}}, deploying: []bool{true}}
+	tracker, err := NewLaunchTracker(client, "fake-app", 1, launchingDeployment, &LaunchTrackerOpts{
+		Interval: time.Millisecond,
+		Timeout:  20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	<-tracker.Done()
+	assert.Equal(t, ErrTimeoutError, tracker.Err())
+	_, ok := tracker.SchedulingLatency()
+	assert.False(t, ok)
+}
+
+func TestLaunchTrackerStop(t *testing.T) {
+	client := &launchingClient{queued: []int{1}, states: [][]string{{}}, deploying: []bool{true}}
+	tracker, err := NewLaunchTracker(client, "fake-app", 1, launchingDeployment, &LaunchTrackerOpts{Interval: time.Hour})
+	require.NoError(t, err)
+
+	tracker.Stop()
+	assert.NoError(t, tracker.Err())
+	assert.Len(t, tracker.Samples(), 1)
+}
+
+func TestScaleApplication(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	id, tracker, err := endpoint.Client.ScaleApplication(fakeAppName, 2, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", id.DeploymentID)
+	assert.Nil(t, tracker)
+
+	id, tracker, err = endpoint.Client.ScaleApplication(fakeAppName, 2, &ScaleAppOpts{Track: &LaunchTrackerOpts{}})
+	require.NoError(t, err)
+	require.NotNil(t, id)
+	require.NotNil(t, tracker)
+	tracker.Stop()
+	samples := tracker.Samples()
+	require.NotEmpty(t, samples)
+	assert.Equal(t, 2, samples[0].Requested)
+
+	_, tracker, err = endpoint.Client.ScaleApplication("/not/there", 2, &ScaleAppOpts{Track: &LaunchTrackerOpts{}})
+	assert.Error(t, err)
+	assert.Nil(t, tracker)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"time"
+)
+
+// the default interval the leader is checked at
+const defaultLeaderWatchInterval = 10 * time.Second
+
+// LeaderChange is a change of the leader of the Marathon cluster
+type LeaderChange struct {
+	// Previous is the address of the previous leader
+	Previous string
+	// Leader is the address of the new leader
+	Leader string
+}
+
+// LeaderHandler handles a change of the leader
+type LeaderHandler func(change LeaderChange)
+
+// LeaderWatcherOpts contains the options of the LeaderWatcher
+//		interval:	the interval the leader is checked at, defaults to 10 seconds
+//		onError:	called with the errors retrieving the leader, e.g. during an election
+type LeaderWatcherOpts struct {
+	Interval time.Duration
+	OnError  func(err error)
+}
+
+// LeaderWatcher checks the leader of the Marathon cluster periodically and notifies its handler of
+// the changes, so the companion services can re-establish their connections to the leader after a
+// failover
+type LeaderWatcher struct {
+	sync.RWMutex
+	// the client the leader is retrieved with
+	client  Marathon
+	opts    LeaderWatcherOpts
+	handler LeaderHandler
+	// the last leader retrieved
+	leader   string
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewLeaderWatcher retrieves the leader and starts watching its changes
+//		client:		the client the leader is retrieved with
+//		handler:	the handler of the changes of the leader
+//		opts:		the options of the watcher
+func NewLeaderWatcher(client Marathon, handler LeaderHandler, opts *LeaderWatcherOpts) (*LeaderWatcher, error) {
+	watcher := &LeaderWatcher{
+		client:  client,
+		handler: handler,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if opts != nil {
+		watcher.opts = *opts
+	}
+	if watcher.opts.Interval <= 0 {
+		watcher.opts.Interval = defaultLeaderWatchInterval
+	}
+
+	leader, err := client.Leader()
+	if err != nil {
+		return nil, err
+	}
+	watcher.leader = leader
+	go watcher.watch()
+
+	return watcher, nil
+}
+
+// Leader returns the last leader retrieved
+func (w *LeaderWatcher) Leader() string {
+	w.RLock()
+	defer w.RUnlock()
+	return w.leader
+}
+
+// Stop stops watching the leader, waiting for the handler in progress to return
+func (w *LeaderWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// watch checks the leader at each interval until stopped
+func (w *LeaderWatcher) watch() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check retrieves the leader, notifying the handler when it changed
+func (w *LeaderWatcher) check() {
+	leader, err := w.client.Leader()
+	if err != nil {
+		if w.opts.OnError != nil {
+			w.opts.OnError(err)
+		}
+		return
+	}
+
+	w.Lock()
+	change := LeaderChange{Previous: w.leader, Leader: leader}
+	w.leader = leader
+	w.Unlock()
+
+	if change.Leader != change.Previous && w.handler != nil {
+		w.handler(change)
+	}
+}
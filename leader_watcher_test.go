@@ -0,0 +1,80 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// electingClient returns the leaders in turn, an empty leader failing as during an election
+type electingClient struct {
+	Marathon
+	sync.Mutex
+	leaders []string
+	calls   int
+}
+
+func (c *electingClient) Leader() (string, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	leader := c.leaders[len(c.leaders)-1]
+	if c.calls < len(c.leaders) {
+		leader = c.leaders[c.calls]
+	}
+	c.calls++
+	if leader == "" {
+		return "", ErrMarathonDown
+	}
+	return leader, nil
+}
+
+func TestLeaderWatcher(t *testing.T) {
+	client := &electingClient{leaders: []string{"10.0.0.1:8080", "10.0.0.1:8080", "", "10.0.0.2:8080"}}
+	changes := make(chan LeaderChange, 10)
+	errs := make(chan error, 10)
+
+	watcher, err := NewLeaderWatcher(client, func(change LeaderChange) {
+		changes <- change
+	}, &LeaderWatcherOpts{Interval: 5 * time.Millisecond, OnError: func(err error) { errs <- err }})
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:8080", watcher.Leader())
+
+	// step: the election fails the check, then the new leader is notified once
+	select {
+	case change := <-changes:
+		assert.Equal(t, LeaderChange{Previous: "10.0.0.1:8080", Leader: "10.0.0.2:8080"}, change)
+	case <-time.After(time.Second):
+		require.Fail(t, "the leader change was not notified")
+	}
+	assert.Equal(t, ErrMarathonDown, <-errs)
+	time.Sleep(20 * time.Millisecond)
+	watcher.Stop()
+
+	assert.Empty(t, changes)
+	assert.Equal(t, "10.0.0.2:8080", watcher.Leader())
+}
+
+func TestLeaderWatcherNoLeader(t *testing.T) {
+	_, err := NewLeaderWatcher(&electingClient{leaders: []string{""}}, nil, nil)
+	assert.Equal(t, ErrMarathonDown, err)
+}
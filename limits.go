@@ -0,0 +1,159 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"sort"
+)
+
+// defaultMaxPayloadSize is the default size limit of the request payloads, i.e. the default
+// maximum size of the ZooKeeper nodes (jute.maxbuffer) Marathon stores its state in, which is also
+// the default body limit of nginx
+const defaultMaxPayloadSize = 1024 * 1024
+
+// Limits are checked before sending requests, so oversized definitions are rejected with a precise
+// error instead of an opaque 413 or 422 response from a proxy or Marathon. A zero limit is not
+// checked.
+type Limits struct {
+	// MaxPayloadSize is the maximum size in bytes of the JSON payload of a request
+	MaxPayloadSize int
+	// MaxLabels is the maximum number of labels of an application
+	MaxLabels int
+	// MaxLabelKeySize is the maximum size in bytes of a label key
+	MaxLabelKeySize int
+	// MaxLabelValueSize is the maximum size in bytes of a label value
+	MaxLabelValueSize int
+	// MaxEnvVars is the maximum number of environment variables of an application
+	MaxEnvVars int
+	// MaxEnvValueSize is the maximum size in bytes of an environment variable value
+	MaxEnvValueSize int
+	// MaxConstraints is the maximum number of constraints of an application
+	MaxConstraints int
+}
+
+// DefaultLimits returns the limits of a default Marathon installation, i.e. only the payload size
+// is limited. The limits are opt-in, none being checked by NewDefaultConfig.
+func DefaultLimits() Limits {
+	return Limits{MaxPayloadSize: defaultMaxPayloadSize}
+}
+
+// LimitError is returned when a request exceeds one of the configured Limits
+type LimitError struct {
+	// ID is the id of the application or group exceeding the limit, when known
+	ID string
+	// Field is the part of the definition exceeding the limit, e.g. labels or env[JAVA_OPTS]
+	Field string
+	// Size is the size or the count exceeding the limit
+	Size int
+	// Limit is the limit exceeded
+	Limit int
+}
+
+// Error returns the string message
+func (e *LimitError) Error() string {
+	subject := e.Field
+	if e.ID != "" {
+		subject = e.ID + ": " + e.Field
+	}
+	return fmt.Sprintf("%s is %d, exceeding the limit of %d", subject, e.Size, e.Limit)
+}
+
+// exceeds checks the size against the limit
+func exceeds(size, limit int) bool {
+	return limit > 0 && size > limit
+}
+
+// CheckLimits checks the application against the limits
+//		limits:		the limits to check, see DefaultLimits
+func (r *Application) CheckLimits(limits Limits) error {
+	newError := func(field string, size, limit int) error {
+		return &LimitError{ID: r.ID, Field: field, Size: size, Limit: limit}
+	}
+
+	if r.Labels != nil {
+		labels := *r.Labels
+		if exceeds(len(labels), limits.MaxLabels) {
+			return newError("labels count", len(labels), limits.MaxLabels)
+		}
+		for _, key := range sortedKeys(labels) {
+			if exceeds(len(key), limits.MaxLabelKeySize) {
+				return newError(fmt.Sprintf("labels[%s] key size", key), len(key), limits.MaxLabelKeySize)
+			}
+			if exceeds(len(labels[key]), limits.MaxLabelValueSize) {
+				return newError(fmt.Sprintf("labels[%s] value size", key), len(labels[key]), limits.MaxLabelValueSize)
+			}
+		}
+	}
+	if r.Env != nil {
+		env := *r.Env
+		if exceeds(len(env), limits.MaxEnvVars) {
+			return newError("env count", len(env), limits.MaxEnvVars)
+		}
+		for _, key := range sortedKeys(env) {
+			if exceeds(len(env[key]), limits.MaxEnvValueSize) {
+				return newError(fmt.Sprintf("env[%s] value size", key), len(env[key]), limits.MaxEnvValueSize)
+			}
+		}
+	}
+	if r.Constraints != nil && exceeds(len(*r.Constraints), limits.MaxConstraints) {
+		return newError("constraints count", len(*r.Constraints), limits.MaxConstraints)
+	}
+
+	return nil
+}
+
+// CheckLimits checks the applications of the group and its subgroups against the limits
+//		limits:		the limits to check, see DefaultLimits
+func (r *Group) CheckLimits(limits Limits) error {
+	for _, application := range r.Apps {
+		if err := application.CheckLimits(limits); err != nil {
+			return err
+		}
+	}
+	for _, group := range r.Groups {
+		if err := group.CheckLimits(limits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPayloadSize checks the size of the payload of the request to the path, as sent, i.e.
+// once compressed
+func (r *marathonClient) checkPayloadSize(path string, sentBody []byte) error {
+	if exceeds(len(sentBody), r.config.Limits.MaxPayloadSize) {
+		return &LimitError{
+			ID:    pathAppID(path),
+			Field: "payload size",
+			Size:  len(sentBody),
+			Limit: r.config.Limits.MaxPayloadSize,
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of the map in order, so the first offending key is reported
+// consistently
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
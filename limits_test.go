@@ -0,0 +1,150 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationCheckLimits(t *testing.T) {
+	limits := Limits{
+		MaxLabels:         2,
+		MaxLabelKeySize:   8,
+		MaxLabelValueSize: 16,
+		MaxEnvVars:        2,
+		MaxEnvValueSize:   16,
+		MaxConstraints:    1,
+	}
+
+	cases := []struct {
+		desc     string
+		app      *Application
+		field    string
+		size     int
+		limit    int
+		expected string
+	}{
+		{
+			desc: "within the limits",
+			app:  NewDockerApplication().Name("/app").AddLabel("a", "b").AddEnv("A", "B").AddConstraint("hostname", "UNIQUE"),
+		},
+		{
+			desc:  "too many labels",
+			app:   NewDockerApplication().Name("/app").AddLabel("a", "1").AddLabel("b", "2").AddLabel("c", "3"),
+			field: "labels count", size: 3, limit: 2,
+		},
+		{
+			desc:  "label key too large",
+			app:   NewDockerApplication().Name("/app").AddLabel("oversized-key", "1"),
+			field: "labels[oversized-key] key size", size: 13, limit: 8,
+		},
+		{
+			desc:  "label value too large",
+			app:   NewDockerApplication().Name("/app").AddLabel("key", strings.Repeat("v", 17)),
+			field: "labels[key] value size", size: 17, limit: 16,
+		},
+		{
+			desc:  "too many environment variables",
+			app:   NewDockerApplication().Name("/app").AddEnv("A", "1").AddEnv("B", "2").AddEnv("C", "3"),
+			field: "env count", size: 3, limit: 2,
+		},
+		{
+			desc:  "environment variable too large",
+			app:   NewDockerApplication().Name("/app").AddEnv("JAVA_OPTS", strings.Repeat("v", 20)),
+			field: "env[JAVA_OPTS] value size", size: 20, limit: 16,
+		},
+		{
+			desc:  "too many constraints",
+			app:   NewDockerApplication().Name("/app").AddConstraint("hostname", "UNIQUE").AddConstraint("rack", "GROUP_BY"),
+			field: "constraints count", size: 2, limit: 1,
+		},
+	}
+	for _, test := range cases {
+		err := test.app.CheckLimits(limits)
+		if test.field == "" {
+			assert.NoError(t, err, test.desc)
+			continue
+		}
+		if assert.IsType(t, &LimitError{}, err, test.desc) {
+			assert.Equal(t, &LimitError{ID: "/app", Field: test.field, Size: test.size, Limit: test.limit}, err, test.desc)
+		}
+	}
+
+	// step: zero limits are not checked
+	assert.NoError(t, cases[1].app.CheckLimits(Limits{}))
+}
+
+func TestGroupCheckLimits(t *testing.T) {
+	group := NewApplicationGroup("/prod")
+	sub := NewApplicationGroup("/prod/backend")
+	sub.App(NewDockerApplication().Name("/prod/backend/api").AddLabel("a", "1").AddLabel("b", "2"))
+	group.Groups = append(group.Groups, sub)
+
+	assert.NoError(t, group.CheckLimits(Limits{MaxLabels: 2}))
+	err := group.CheckLimits(Limits{MaxLabels: 1})
+	require.Error(t, err)
+	assert.Equal(t, "/prod/backend/api: labels count is 2, exceeding the limit of 1", err.Error())
+}
+
+func TestLimits(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Limits.MaxEnvVars = 1
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	application := NewDockerApplication().Name(fakeAppName).AddEnv("A", "1").AddEnv("B", "2")
+	_, err := endpoint.Client.CreateApplication(application)
+	assert.IsType(t, &LimitError{}, err)
+	_, err = endpoint.Client.UpdateApplication(application, false)
+	assert.IsType(t, &LimitError{}, err)
+
+	group := NewApplicationGroup("/fake-group").App(application)
+	assert.IsType(t, &LimitError{}, endpoint.Client.CreateGroup(group))
+
+	// step: the payload size is checked on every request
+	config = NewDefaultConfig()
+	config.Limits.MaxPayloadSize = 256
+	endpoint = newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err = endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName).AddArgs(strings.Repeat("a", 1024)), false)
+	require.IsType(t, &LimitError{}, err)
+	assert.Equal(t, "/fake-app", err.(*LimitError).ID)
+	assert.Equal(t, "payload size", err.(*LimitError).Field)
+
+	// step: the size checked is the one of the compressed payload
+	config.GzipRequestThreshold = 256
+	endpoint = newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err = endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName).AddArgs(strings.Repeat("a", 1024)), false)
+	assert.NoError(t, err)
+}
+
+func TestLimitsOptIn(t *testing.T) {
+	config := NewDefaultConfig()
+	assert.Equal(t, Limits{}, config.Limits)
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName).AddArgs(strings.Repeat("a", 2*defaultMaxPayloadSize)), false)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+// IPCMode is the IPC namespace mode of a container
+type IPCMode string
+
+const (
+	// IPCModePrivate gives the container its own IPC namespace and shared memory
+	IPCModePrivate IPCMode = "PRIVATE"
+	// IPCModeShareParent shares the IPC namespace and shared memory of the parent, i.e. of the
+	// agent for an application or of the pod for a pod container
+	IPCModeShareParent IPCMode = "SHARE_PARENT"
+)
+
+// LinuxInfo is the Linux specific settings of a container, available since Marathon 1.8
+type LinuxInfo struct {
+	Seccomp *Seccomp `json:"seccomp,omitempty"`
+	IPCInfo *IPCInfo `json:"ipcInfo,omitempty"`
+}
+
+// Seccomp is the seccomp profile applied to a container, the default profile of the agent
+// applying when it's not set
+type Seccomp struct {
+	ProfileName string `json:"profileName,omitempty"`
+	Unconfined  *bool  `json:"unconfined,omitempty"`
+}
+
+// IPCInfo is the IPC namespace of a container
+type IPCInfo struct {
+	Mode    IPCMode `json:"mode,omitempty"`
+	ShmSize *int    `json:"shmSize,omitempty"`
+}
+
+// SetSeccompProfile confines the container with the seccomp profile
+//		name:		the name of the profile on the agents
+func (l *LinuxInfo) SetSeccompProfile(name string) *LinuxInfo {
+	unconfined := false
+	l.Seccomp = &Seccomp{ProfileName: name, Unconfined: &unconfined}
+	return l
+}
+
+// SetSeccompUnconfined runs the container without seccomp confinement
+func (l *LinuxInfo) SetSeccompUnconfined() *LinuxInfo {
+	unconfined := true
+	l.Seccomp = &Seccomp{Unconfined: &unconfined}
+	return l
+}
+
+// SetIPCInfo sets the IPC namespace of the container
+//		mode:		the IPC mode
+//		shmSize:	the size of the shared memory in MB, only for the PRIVATE mode, 0 for the default size
+func (l *LinuxInfo) SetIPCInfo(mode IPCMode, shmSize int) *LinuxInfo {
+	l.IPCInfo = &IPCInfo{Mode: mode}
+	if shmSize > 0 {
+		l.IPCInfo.ShmSize = &shmSize
+	}
+	return l
+}
+
+// SetLinuxInfo sets the Linux specific settings of the container
+//		linuxInfo:	the Linux specific settings
+func (container *Container) SetLinuxInfo(linuxInfo LinuxInfo) *Container {
+	container.LinuxInfo = &linuxInfo
+	return container
+}
+
+// SetLinuxInfo sets the Linux specific settings of the pod container
+//		linuxInfo:	the Linux specific settings
+func (p *PodContainer) SetLinuxInfo(linuxInfo LinuxInfo) *PodContainer {
+	p.LinuxInfo = &linuxInfo
+	return p
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerLinuxInfo(t *testing.T) {
+	container := NewDockerContainer().SetLinuxInfo(*new(LinuxInfo).
+		SetSeccompProfile("hardened").
+		SetIPCInfo(IPCModePrivate, 64))
+
+	content, err := json.Marshal(container)
+	require.NoError(t, err)
+	assert.Contains(t, string(content),
+		`"linuxInfo":{"seccomp":{"profileName":"hardened","unconfined":false},"ipcInfo":{"mode":"PRIVATE","shmSize":64}}`)
+
+	decoded := new(Container)
+	require.NoError(t, json.Unmarshal(content, decoded))
+	assert.Equal(t, container.LinuxInfo, decoded.LinuxInfo)
+}
+
+func TestLinuxInfo(t *testing.T) {
+	linuxInfo := new(LinuxInfo).SetSeccompUnconfined().SetIPCInfo(IPCModeShareParent, 0)
+	assert.Empty(t, linuxInfo.Seccomp.ProfileName)
+	assert.True(t, *linuxInfo.Seccomp.Unconfined)
+	assert.Equal(t, IPCModeShareParent, linuxInfo.IPCInfo.Mode)
+	assert.Nil(t, linuxInfo.IPCInfo.ShmSize)
+
+	container := NewPodContainer().SetLinuxInfo(*linuxInfo)
+	content, err := json.Marshal(container)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"linuxInfo":{"seccomp":{"unconfined":true},"ipcInfo":{"mode":"SHARE_PARENT"}}`)
+}
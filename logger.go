@@ -0,0 +1,183 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"io"
+	"log"
+	"sync"
+)
+
+// LogModule is a subsystem of the client whose log level can be set independently
+type LogModule string
+
+const (
+	// LogModuleAPI is the module of the API calls
+	LogModuleAPI LogModule = "api"
+	// LogModuleEvents is the module of the event subscriptions and the events received
+	LogModuleEvents LogModule = "events"
+	// LogModuleCluster is the module of the Marathon hosts, i.e. marked down, up or failing fast
+	LogModuleCluster LogModule = "cluster"
+	// LogModuleWaits is the module of the WaitOn methods
+	LogModuleWaits LogModule = "waits"
+	// LogModuleOrchestration is the module of the operations built on several calls, e.g. the
+	// tracked scaling of an application
+	LogModuleOrchestration LogModule = "orchestration"
+)
+
+// LogLevel is the most verbose level of the messages logged
+type LogLevel int32
+
+const (
+	// LogLevelOff logs no message
+	LogLevelOff LogLevel = iota
+	// LogLevelError logs the errors only
+	LogLevelError
+	// LogLevelInfo logs the errors and the informational messages
+	LogLevelInfo
+	// LogLevelDebug logs all the messages
+	LogLevelDebug
+)
+
+// Logger receives the log messages of the client, allowing them to be routed to the logging
+// library of the application
+type Logger interface {
+	// Debugf logs a message useful when debugging the client, e.g. the API calls made
+	Debugf(format string, v ...interface{})
+	// Infof logs a message about the normal operation of the client
+	Infof(format string, v ...interface{})
+	// Errorf logs an error the client recovered from, e.g. a failed event subscription
+	Errorf(format string, v ...interface{})
+}
+
+// noopLogger discards all the messages
+type noopLogger struct{}
+
+func (noopLogger) Debugf(string, ...interface{}) {}
+func (noopLogger) Infof(string, ...interface{})  {}
+func (noopLogger) Errorf(string, ...interface{}) {}
+
+// writerLogger writes all the messages to a writer, whatever their level
+type writerLogger struct {
+	logger *log.Logger
+}
+
+func newWriterLogger(output io.Writer) *writerLogger {
+	return &writerLogger{logger: log.New(output, "", 0)}
+}
+
+func (w *writerLogger) Debugf(format string, v ...interface{}) {
+	w.logger.Printf(format, v...)
+}
+
+func (w *writerLogger) Infof(format string, v ...interface{}) {
+	w.logger.Printf(format, v...)
+}
+
+func (w *writerLogger) Errorf(format string, v ...interface{}) {
+	w.logger.Printf(format, v...)
+}
+
+// newLogger returns the logger of the configuration, falling back to the log output and then to
+// a no-op logger
+func newLogger(config Config) Logger {
+	switch {
+	case config.Logger != nil:
+		return config.Logger
+	case config.LogOutput != nil:
+		return newWriterLogger(config.LogOutput)
+	default:
+		return noopLogger{}
+	}
+}
+
+// LogLevels holds the log level of each module, and can be changed at runtime, e.g. to turn on
+// the debug messages of the events without the ones of the API calls
+type LogLevels struct {
+	sync.RWMutex
+	// the level of the modules with no level set
+	defaultLevel LogLevel
+	levels       map[LogModule]LogLevel
+}
+
+// NewLogLevels creates the log levels of the modules
+//		defaultLevel:	the level of the modules with no level set
+func NewLogLevels(defaultLevel LogLevel) *LogLevels {
+	return &LogLevels{
+		defaultLevel: defaultLevel,
+		levels:       make(map[LogModule]LogLevel),
+	}
+}
+
+// Set sets the log level of the module
+func (l *LogLevels) Set(module LogModule, level LogLevel) *LogLevels {
+	l.Lock()
+	defer l.Unlock()
+	l.levels[module] = level
+	return l
+}
+
+// SetDefault sets the log level of the modules with no level set
+func (l *LogLevels) SetDefault(level LogLevel) *LogLevels {
+	l.Lock()
+	defer l.Unlock()
+	l.defaultLevel = level
+	return l
+}
+
+// Level returns the log level of the module
+func (l *LogLevels) Level(module LogModule) LogLevel {
+	l.RLock()
+	defer l.RUnlock()
+	if level, found := l.levels[module]; found {
+		return level
+	}
+	return l.defaultLevel
+}
+
+// moduleLogger filters the messages of a module according to its log level
+type moduleLogger struct {
+	logger Logger
+	levels *LogLevels
+	module LogModule
+}
+
+func (m *moduleLogger) Debugf(format string, v ...interface{}) {
+	if m.levels.Level(m.module) >= LogLevelDebug {
+		m.logger.Debugf(format, v...)
+	}
+}
+
+func (m *moduleLogger) Infof(format string, v ...interface{}) {
+	if m.levels.Level(m.module) >= LogLevelInfo {
+		m.logger.Infof(format, v...)
+	}
+}
+
+func (m *moduleLogger) Errorf(format string, v ...interface{}) {
+	if m.levels.Level(m.module) >= LogLevelError {
+		m.logger.Errorf(format, v...)
+	}
+}
+
+// log returns the logger of the module, which logs all the messages when no log levels are set
+func (r *marathonClient) log(module LogModule) Logger {
+	if r.config.LogLevels == nil {
+		return r.logger
+	}
+	return &moduleLogger{logger: r.logger, levels: r.config.LogLevels, module: module}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+const (
+	// MaintenanceLabel is the label marking an application as in planned maintenance, its value
+	// being the reason of the maintenance
+	MaintenanceLabel = "maintenance"
+
+	// the value of the label when no reason is given
+	defaultMaintenanceReason = "true"
+)
+
+// SetMaintenance marks the application as in planned maintenance, shielding it from the health
+// checks of the client when Config.TolerateMaintenance is set
+//		reason:		why the application is in maintenance, e.g. "replacing the disks"
+func (r *Application) SetMaintenance(reason string) *Application {
+	if reason == "" {
+		reason = defaultMaintenanceReason
+	}
+	return r.AddLabel(MaintenanceLabel, reason)
+}
+
+// ClearMaintenance removes the maintenance mark of the application. Updating the application
+// removes the label in Marathon when the labels are set, e.g. retrieved; unset labels are left
+// unset, so an update of a partial definition doesn't wipe the other labels of the application.
+func (r *Application) ClearMaintenance() *Application {
+	if r.Labels != nil {
+		delete(*r.Labels, MaintenanceLabel)
+	}
+
+	return r
+}
+
+// InMaintenance checks if the application is marked as in planned maintenance
+func (r *Application) InMaintenance() bool {
+	_, found := r.maintenanceLabel()
+	return found
+}
+
+// MaintenanceReason retrieves why the application is in maintenance, empty if it is not
+func (r *Application) MaintenanceReason() string {
+	reason, _ := r.maintenanceLabel()
+	return reason
+}
+
+func (r *Application) maintenanceLabel() (string, bool) {
+	if r.Labels == nil {
+		return "", false
+	}
+	reason, found := (*r.Labels)[MaintenanceLabel]
+	return reason, found
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeMaintenanceAppName = "/fake-app-maintenance"
+
+func TestMaintenanceLabel(t *testing.T) {
+	app := NewDockerApplication()
+	assert.False(t, app.InMaintenance())
+	assert.Equal(t, "", app.MaintenanceReason())
+
+	app.SetMaintenance("replacing the disks")
+	assert.True(t, app.InMaintenance())
+	assert.Equal(t, "replacing the disks", app.MaintenanceReason())
+	assert.Equal(t, "replacing the disks", (*app.Labels)[MaintenanceLabel])
+
+	app.SetMaintenance("")
+	assert.Equal(t, "true", app.MaintenanceReason())
+
+	app.ClearMaintenance()
+	assert.False(t, app.InMaintenance())
+	require.NotNil(t, app.Labels)
+	assert.Empty(t, *app.Labels)
+
+	// step: unset labels are left unset, so the update keeps the labels in Marathon
+	app = NewDockerApplication().ClearMaintenance()
+	assert.Nil(t, app.Labels)
+}
+
+func TestApplicationOKInMaintenance(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	ok, err := endpoint.Client.ApplicationOK(fakeMaintenanceAppName)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	config := NewDefaultConfig()
+	config.TolerateMaintenance = true
+	endpoint = newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	ok, err = endpoint.Client.ApplicationOK(fakeMaintenanceAppName)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestWaitOnApplicationInMaintenance(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingWaitTime = 10 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	err := endpoint.Client.WaitOnApplication(fakeMaintenanceAppName, 50*time.Millisecond)
+	assert.Equal(t, ErrTimeoutError, err)
+
+	config = NewDefaultConfig()
+	config.PollingWaitTime = 10 * time.Millisecond
+	config.TolerateMaintenance = true
+	endpoint = newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	assert.NoError(t, endpoint.Client.WaitOnApplication(fakeMaintenanceAppName, 50*time.Millisecond))
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+const (
+	defaultConformancePrefix  = "/go-marathon-conformance"
+	defaultConformanceTimeout = time.Minute
+)
+
+// ConformanceOpts contains the options of the conformance suite
+//		prefix:		the group the applications of the suite are created in, defaults to
+//				/go-marathon-conformance, it must not exist
+//		timeout:	the time the deployments and the events are waited for, defaults to a minute
+//		skipEvents:	skips the events checks, e.g. when the implementation doesn't stream events
+type ConformanceOpts struct {
+	Prefix     string
+	Timeout    time.Duration
+	SkipEvents bool
+}
+
+// RunConformance checks that the Marathon-compatible implementation listening on the URL behaves
+// as the client expects, e.g. for a shim or a mock of Marathon:
+//
+//	func TestConformance(t *testing.T) {
+//		marathontest.RunConformance(t, "http://127.0.0.1:8080", nil)
+//	}
+//
+// The applications of the suite have no instances, so it doesn't require any resources.
+//		url:		the URL of the implementation
+//		opts:		the options of the suite
+func RunConformance(t *testing.T, url string, opts *ConformanceOpts) {
+	config := marathon.NewDefaultConfig()
+	config.URL = url
+	config.EventsTransport = marathon.EventsTransportSSE
+	client, err := marathon.NewClient(config)
+	if err != nil {
+		t.Fatalf("failed to create the client: %s", err)
+	}
+	RunClientConformance(t, client, opts)
+}
+
+// RunClientConformance runs the conformance suite with the client, e.g. a client configured with
+// some authentication
+//		client:		the client of the implementation, with the SSE events transport unless the
+//				events are skipped
+//		opts:		the options of the suite
+func RunClientConformance(t *testing.T, client marathon.Marathon, opts *ConformanceOpts) {
+	suite := &conformance{client: client}
+	if opts != nil {
+		suite.opts = *opts
+	}
+	if suite.opts.Prefix == "" {
+		suite.opts.Prefix = defaultConformancePrefix
+	}
+	if suite.opts.Timeout <= 0 {
+		suite.opts.Timeout = defaultConformanceTimeout
+	}
+
+	t.Run("Ping", suite.ping)
+	t.Run("Applications", suite.applications)
+	t.Run("Groups", suite.groups)
+	t.Run("Deployments", suite.deployments)
+	if !suite.opts.SkipEvents {
+		t.Run("Events", suite.events)
+	}
+
+	// step: clean up whatever the failed checks left behind
+	if found, err := client.HasGroup(suite.opts.Prefix); err == nil && found {
+		if deployment, err := client.DeleteGroup(suite.opts.Prefix, true); err == nil {
+			client.WaitOnDeployment(deployment.DeploymentID, suite.opts.Timeout)
+		}
+	}
+}
+
+type conformance struct {
+	client marathon.Marathon
+	opts   ConformanceOpts
+}
+
+// application creates the definition of an application of the suite
+func (c *conformance) application(name string) *marathon.Application {
+	return new(marathon.Application).Name(c.opts.Prefix + "/" + name).Command("sleep 3600").
+		CPU(0.1).Memory(32).Count(0)
+}
+
+// isNotFound checks if the error is a 404 Not Found API error
+func isNotFound(err error) bool {
+	return errors.Is(err, marathon.ErrNotFound)
+}
+
+// waitOnDeployment waits for the deployment to complete
+func (c *conformance) waitOnDeployment(t *testing.T, deployment *marathon.DeploymentID) {
+	if deployment == nil || deployment.DeploymentID == "" {
+		t.Fatalf("no deployment id returned")
+	}
+	if err := c.client.WaitOnDeployment(deployment.DeploymentID, c.opts.Timeout); err != nil {
+		t.Fatalf("deployment %s did not complete: %s", deployment.DeploymentID, err)
+	}
+}
+
+// deleteApplication deletes the application and checks it is gone
+func (c *conformance) deleteApplication(t *testing.T, id string) {
+	deployment, err := c.client.DeleteApplication(id, false)
+	if err != nil {
+		t.Fatalf("failed to delete the application: %s", err)
+	}
+	c.waitOnDeployment(t, deployment)
+	if _, err := c.client.Application(id); !isNotFound(err) {
+		t.Errorf("expected a not found error for the deleted application, got: %v", err)
+	}
+}
+
+func (c *conformance) ping(t *testing.T) {
+	if _, err := c.client.Ping(); err != nil {
+		t.Fatalf("failed to ping: %s", err)
+	}
+	if _, err := c.client.Info(); err != nil {
+		t.Errorf("failed to retrieve the info: %s", err)
+	}
+}
+
+func (c *conformance) applications(t *testing.T) {
+	application := c.application("app")
+	created, err := c.client.CreateApplication(application)
+	if err != nil {
+		t.Fatalf("failed to create the application: %s", err)
+	}
+	if created.ID != application.ID {
+		t.Errorf("expected the created application %s, got: %s", application.ID, created.ID)
+	}
+	defer c.deleteApplication(t, application.ID)
+	if err := c.client.WaitOnApplication(application.ID, c.opts.Timeout); err != nil {
+		t.Fatalf("the application was not deployed: %s", err)
+	}
+
+	// step: the duplicate is rejected
+	if _, err := c.client.CreateApplication(application); err == nil {
+		t.Errorf("expected an error creating the application twice")
+	}
+
+	retrieved, err := c.client.Application(application.ID)
+	if err != nil {
+		t.Fatalf("failed to retrieve the application: %s", err)
+	}
+	if retrieved.Cmd == nil || *retrieved.Cmd != *application.Cmd {
+		t.Errorf("expected the command %q, got: %v", *application.Cmd, retrieved.Cmd)
+	}
+
+	ids, err := c.client.ListApplications(nil)
+	if err != nil {
+		t.Fatalf("failed to list the applications: %s", err)
+	}
+	if !contains(ids, application.ID) {
+		t.Errorf("expected the application %s in the list, got: %v", application.ID, ids)
+	}
+
+	// step: the update is a new version
+	application.AddLabel("conformance", "updated")
+	deployment, err := c.client.UpdateApplication(application, false)
+	if err != nil {
+		t.Fatalf("failed to update the application: %s", err)
+	}
+	c.waitOnDeployment(t, deployment)
+	if retrieved, err = c.client.Application(application.ID); err != nil {
+		t.Fatalf("failed to retrieve the updated application: %s", err)
+	}
+	if retrieved.Labels == nil || (*retrieved.Labels)["conformance"] != "updated" {
+		t.Errorf("expected the updated label, got: %v", retrieved.Labels)
+	}
+	versions, err := c.client.ApplicationVersions(application.ID)
+	if err != nil {
+		t.Fatalf("failed to retrieve the versions: %s", err)
+	}
+	if len(versions.Versions) < 2 {
+		t.Errorf("expected at least 2 versions, got: %v", versions.Versions)
+	}
+
+	if _, err := c.client.Application(c.opts.Prefix + "/missing"); !isNotFound(err) {
+		t.Errorf("expected a not found error for a missing application, got: %v", err)
+	}
+}
+
+func (c *conformance) groups(t *testing.T) {
+	id := c.opts.Prefix + "/group"
+	group := marathon.NewApplicationGroup(id).App(c.application("group/app"))
+	if err := c.client.CreateGroup(group); err != nil {
+		t.Fatalf("failed to create the group: %s", err)
+	}
+	if err := c.client.WaitOnGroup(id, c.opts.Timeout); err != nil {
+		t.Fatalf("the group was not deployed: %s", err)
+	}
+
+	found, err := c.client.HasGroup(id)
+	if err != nil || !found {
+		t.Errorf("expected the group to exist, got: %t, %v", found, err)
+	}
+	retrieved, err := c.client.Group(id)
+	if err != nil {
+		t.Fatalf("failed to retrieve the group: %s", err)
+	}
+	if len(retrieved.Apps) != 1 || retrieved.Apps[0].ID != id+"/app" {
+		t.Errorf("expected the application %s/app in the group, got: %v", id, retrieved.Apps)
+	}
+
+	deployment, err := c.client.DeleteGroup(id, false)
+	if err != nil {
+		t.Fatalf("failed to delete the group: %s", err)
+	}
+	c.waitOnDeployment(t, deployment)
+	if found, err = c.client.HasGroup(id); err != nil || found {
+		t.Errorf("expected the group to be deleted, got: %t, %v", found, err)
+	}
+}
+
+func (c *conformance) deployments(t *testing.T) {
+	application := c.application("deployed")
+	if _, err := c.client.CreateApplication(application); err != nil {
+		t.Fatalf("failed to create the application: %s", err)
+	}
+	defer c.deleteApplication(t, application.ID)
+	if err := c.client.WaitOnApplication(application.ID, c.opts.Timeout); err != nil {
+		t.Fatalf("the application was not deployed: %s", err)
+	}
+
+	deployment, err := c.client.ScaleApplicationInstances(application.ID, 0, true)
+	if err != nil {
+		t.Fatalf("failed to scale the application: %s", err)
+	}
+	c.waitOnDeployment(t, deployment)
+	found, err := c.client.HasDeployment(deployment.DeploymentID)
+	if err != nil || found {
+		t.Errorf("expected the deployment to be complete, got: %t, %v", found, err)
+	}
+	if _, err := c.client.Deployments(); err != nil {
+		t.Errorf("failed to list the deployments: %s", err)
+	}
+}
+
+func (c *conformance) events(t *testing.T) {
+	events, err := c.client.AddEventsListener(marathon.EventIDDeploymentSuccess)
+	if err != nil {
+		t.Fatalf("failed to listen to the events: %s", err)
+	}
+	defer c.client.RemoveEventsListener(events)
+
+	application := c.application("events")
+	if _, err := c.client.CreateApplication(application); err != nil {
+		t.Fatalf("failed to create the application: %s", err)
+	}
+	defer c.deleteApplication(t, application.ID)
+
+	select {
+	case event := <-events:
+		if _, ok := event.Event.(*marathon.EventDeploymentSuccess); !ok {
+			t.Errorf("expected a deployment_success event, got: %s", event)
+		}
+	case <-time.After(c.opts.Timeout):
+		t.Errorf("no deployment_success event received")
+	}
+}
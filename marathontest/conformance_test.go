@@ -0,0 +1,40 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gambol99/go-marathon/marathontest"
+)
+
+func TestFakeMarathonConformance(t *testing.T) {
+	marathontest.RunClientConformance(t, marathontest.NewFakeMarathon(), &marathontest.ConformanceOpts{
+		Timeout: time.Second,
+	})
+}
+
+// TestConformance runs the suite against the implementation at MARATHON_CONFORMANCE_URL, if set
+func TestConformance(t *testing.T) {
+	url := os.Getenv("MARATHON_CONFORMANCE_URL")
+	if url == "" {
+		t.Skip("MARATHON_CONFORMANCE_URL is not set")
+	}
+	marathontest.RunConformance(t, url, nil)
+}
@@ -0,0 +1,1707 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+const (
+	// FakeMarathonURL is the URL reported by the fake Marathon client
+	FakeMarathonURL = "http://fake-marathon:8080"
+	// FakeMarathonVersion is the Marathon version reported by the fake Marathon client
+	FakeMarathonVersion = "1.5.0"
+
+	fakeLeader = "fake-marathon:8080"
+	fakeHost   = "fake-agent"
+	// the first host port allocated to tasks
+	fakeFirstPort = 31000
+	// the interval the wait methods check the state at
+	fakePollInterval = 10 * time.Millisecond
+)
+
+// make sure the fake always implements the complete client interface
+var _ marathon.Marathon = &FakeMarathon{}
+
+// FakeMarathon is an in-memory implementation of the Marathon interface, for unit testing code
+// using the client without any HTTP. Deployments complete immediately: created and scaled
+// applications have their tasks running as soon as the call returns.
+type FakeMarathon struct {
+	sync.RWMutex
+	// the applications, keyed by id
+	apps map[string]*fakeApp
+	// the pods, keyed by id
+	pods map[string]*fakePod
+	// the groups created explicitly, groups holding applications exist implicitly
+	groups map[string]bool
+	// the content of the artifact store, keyed by path
+	artifacts map[string][]byte
+	// the registered callback URLs
+	subscriptions []string
+	// the event listeners
+	listeners map[marathon.EventsChannel]*fakeListener
+	// the start of the fake clock versions are derived from
+	epoch time.Time
+	// the sequence used to generate versions and identifiers
+	sequence int
+}
+
+type fakeApp struct {
+	// the versions of the application, the last one being the current definition
+	versions []*marathon.Application
+	tasks    []*marathon.Task
+}
+
+type fakePod struct {
+	// the versions of the pod, the last one being the current definition
+	versions []*marathon.Pod
+}
+
+type fakeListener struct {
+	filter     int
+	done       chan struct{}
+	completion *sync.WaitGroup
+}
+
+// NewFakeMarathon creates an empty in-memory Marathon
+func NewFakeMarathon() *FakeMarathon {
+	return &FakeMarathon{
+		apps:      make(map[string]*fakeApp),
+		pods:      make(map[string]*fakePod),
+		groups:    make(map[string]bool),
+		artifacts: make(map[string][]byte),
+		listeners: make(map[marathon.EventsChannel]*fakeListener),
+		epoch:     time.Now().UTC(),
+	}
+}
+
+// -- APPLICATIONS ---
+
+// ListApplications retrieves an array of the application names currently stored
+func (f *FakeMarathon) ListApplications(v url.Values) ([]string, error) {
+	applications, err := f.Applications(v)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, application := range applications.Apps {
+		list = append(list, application.ID)
+	}
+	return list, nil
+}
+
+// ApplicationVersions retrieves the versions of the application, the latest first
+func (f *FakeMarathon) ApplicationVersions(name string) (*marathon.ApplicationVersions, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	versions := new(marathon.ApplicationVersions)
+	for i := len(app.versions) - 1; i >= 0; i-- {
+		versions.Versions = append(versions.Versions, app.versions[i].Version)
+	}
+	return versions, nil
+}
+
+// HasApplication checks if the application exists
+func (f *FakeMarathon) HasApplication(name string) (bool, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	_, found := f.apps[canonicalID(name)]
+	return found, nil
+}
+
+// HasApplicationVersion checks if the application has the version
+func (f *FakeMarathon) HasApplicationVersion(name, version string) (bool, error) {
+	versions, err := f.ApplicationVersions(name)
+	if err != nil {
+		return false, err
+	}
+	return contains(versions.Versions, version), nil
+}
+
+// SetApplicationVersion rolls the application back to a previous version
+func (f *FakeMarathon) SetApplicationVersion(name string, version *marathon.ApplicationVersion) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := app.version(version.Version)
+	if err != nil {
+		return nil, err
+	}
+	return f.deployApplication(copyApplication(previous), false)
+}
+
+// SetApplicationVersionBy rolls the application back to a previous version, which never conflicts
+// with a deployment
+func (f *FakeMarathon) SetApplicationVersionBy(name string, version *marathon.ApplicationVersion, opts *marathon.UpdateAppOpts) (*marathon.DeploymentID, error) {
+	return f.SetApplicationVersion(name, version)
+}
+
+// ApplicationOK checks if all the tasks of the application are running
+func (f *FakeMarathon) ApplicationOK(name string) (bool, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return false, err
+	}
+	return len(app.tasks) == instances(app.current()), nil
+}
+
+// CreateApplication creates the application, failing if it already exists
+func (f *FakeMarathon) CreateApplication(application *marathon.Application) (*marathon.Application, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	id := canonicalID(application.ID)
+	if _, found := f.apps[id]; found {
+		return nil, conflict("An app with id [%s] already exists.", id)
+	}
+	if f.groupExists(id) {
+		return nil, conflict("A group with id [%s] already exists.", id)
+	}
+	created := copyApplication(application)
+	created.ID = id
+	if _, err := f.deployApplication(created, false); err != nil {
+		return nil, err
+	}
+	return f.render(f.apps[id]), nil
+}
+
+// DeleteApplication deletes the application and kills its tasks
+func (f *FakeMarathon) DeleteApplication(name string, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	f.killTasks(app, len(app.tasks))
+	delete(f.apps, app.current().ID)
+	return f.deployed(), nil
+}
+
+// DeleteApplicationBy deletes the application and kills its tasks, which never conflicts with a
+// deployment
+func (f *FakeMarathon) DeleteApplicationBy(name string, opts *marathon.DeleteAppOpts) (*marathon.DeploymentID, error) {
+	if opts == nil {
+		opts = &marathon.DeleteAppOpts{}
+	}
+	deployID, err := f.DeleteApplication(name, opts.Force)
+	if opts.IgnoreNotFound && errors.Is(err, marathon.ErrNotFound) {
+		return nil, nil
+	}
+	return deployID, err
+}
+
+// UpdateApplication updates the fields set on the application, creating it if it doesn't exist
+func (f *FakeMarathon) UpdateApplication(application *marathon.Application, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	id := canonicalID(application.ID)
+	app, found := f.apps[id]
+	if !found {
+		created := copyApplication(application)
+		created.ID = id
+		return f.deployApplication(created, false)
+	}
+	updated, err := overlayApplication(app.current(), application)
+	if err != nil {
+		return nil, err
+	}
+	updated.ID = id
+	return f.deployApplication(updated, false)
+}
+
+// UpdateApplicationBy updates the fields set on the application, which never conflicts with a
+// deployment
+func (f *FakeMarathon) UpdateApplicationBy(application *marathon.Application, opts *marathon.UpdateAppOpts) (*marathon.DeploymentID, error) {
+	return f.UpdateApplication(application, opts != nil && opts.Force)
+}
+
+// ApplicationDeployments retrieves the deployments of the application, which are always complete
+func (f *FakeMarathon) ApplicationDeployments(name string) ([]*marathon.DeploymentID, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	if _, err := f.app(name); err != nil {
+		return nil, err
+	}
+	return []*marathon.DeploymentID{}, nil
+}
+
+// ScaleApplicationInstances changes the number of instances of the application
+func (f *FakeMarathon) ScaleApplicationInstances(name string, count int, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	scaled := copyApplication(app.current())
+	scaled.Count(count)
+	return f.deployApplication(scaled, true)
+}
+
+// ScaleApplication changes the number of instances of the application, optionally tracking the
+// launch of the instances
+func (f *FakeMarathon) ScaleApplication(name string, count int, opts *marathon.ScaleAppOpts) (*marathon.DeploymentID, *marathon.LaunchTracker, error) {
+	if opts == nil {
+		opts = &marathon.ScaleAppOpts{}
+	}
+	deployID, err := f.ScaleApplicationInstances(name, count, opts.Force)
+	if err != nil {
+		return nil, nil, err
+	}
+	var tracker *marathon.LaunchTracker
+	if opts.Track != nil {
+		if tracker, err = marathon.NewLaunchTracker(f, name, count, deployID, opts.Track); err != nil {
+			return deployID, nil, err
+		}
+	}
+	return deployID, tracker, nil
+}
+
+// ScaleBy changes the number of instances of the application relatively to its current number,
+// never below zero
+func (f *FakeMarathon) ScaleBy(name string, delta int, opts *marathon.ScaleAppOpts) (*marathon.DeploymentID, *marathon.LaunchTracker, error) {
+	application, err := f.Application(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	instances := delta
+	if application.Instances != nil {
+		instances += *application.Instances
+	}
+	if instances < 0 {
+		instances = 0
+	}
+	return f.ScaleApplication(name, instances, opts)
+}
+
+// RestartApplication replaces all the tasks of the application
+func (f *FakeMarathon) RestartApplication(name string, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	f.killTasks(app, len(app.tasks))
+	return f.deployApplication(copyApplication(app.current()), false)
+}
+
+// RestartApplicationBy replaces all the tasks of the application, the deployment finishing at once
+func (f *FakeMarathon) RestartApplicationBy(name string, opts *marathon.RestartAppOpts) (*marathon.DeploymentID, error) {
+	if opts == nil {
+		opts = &marathon.RestartAppOpts{}
+	}
+	return f.RestartApplication(name, opts.Force)
+}
+
+// Applications retrieves the applications, supporting the id and label filters
+func (f *FakeMarathon) Applications(v url.Values) (*marathon.Applications, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	applications := &marathon.Applications{Apps: []marathon.Application{}}
+	for _, id := range f.appIDs() {
+		application := f.render(f.apps[id])
+		if !matchesFilters(application, v) {
+			continue
+		}
+		applications.Apps = append(applications.Apps, *application)
+	}
+	return applications, nil
+}
+
+// EachApplication calls the function with each of the applications, stopping on its first error
+func (f *FakeMarathon) EachApplication(v url.Values, fn func(*marathon.Application) error) error {
+	applications, err := f.Applications(v)
+	if err != nil {
+		return err
+	}
+	for i := range applications.Apps {
+		if err := fn(&applications.Apps[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Application retrieves the application
+func (f *FakeMarathon) Application(name string) (*marathon.Application, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	return f.render(app), nil
+}
+
+// ApplicationBy retrieves the application, the options are ignored
+func (f *FakeMarathon) ApplicationBy(name string, opts *marathon.GetAppOpts) (*marathon.Application, error) {
+	return f.Application(name)
+}
+
+// ApplicationByVersion retrieves the definition of the application at the version
+func (f *FakeMarathon) ApplicationByVersion(name, version string) (*marathon.Application, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	application, err := app.version(version)
+	if err != nil {
+		return nil, err
+	}
+	return copyApplication(application), nil
+}
+
+// WaitOnApplication waits for the application to exist
+func (f *FakeMarathon) WaitOnApplication(name string, timeout time.Duration) error {
+	return waitOn(timeout, func() bool {
+		ok, err := f.ApplicationOK(name)
+		return err == nil && ok
+	})
+}
+
+// WaitOnApplicationHealthy waits for the application like WaitOnApplication, the tasks of the fake
+// being healthy as soon as they run
+func (f *FakeMarathon) WaitOnApplicationHealthy(name string, timeout time.Duration) error {
+	return f.WaitOnApplication(name, timeout)
+}
+
+// ModernizeApplication leaves the application untouched, the fake supporting every field
+func (f *FakeMarathon) ModernizeApplication(application *marathon.Application) ([]string, error) {
+	return nil, nil
+}
+
+// -- PODS ---
+
+// SupportsPods always returns true
+func (f *FakeMarathon) SupportsPods() (bool, error) {
+	return true, nil
+}
+
+// PodStatus retrieves the status of the pod, which is always stable
+func (f *FakeMarathon) PodStatus(name string) (*marathon.PodStatus, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	pod, err := f.pod(name)
+	if err != nil {
+		return nil, err
+	}
+	return podStatus(pod.current()), nil
+}
+
+// PodStatuses retrieves the status of all the pods
+func (f *FakeMarathon) PodStatuses() ([]*marathon.PodStatus, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	statuses := []*marathon.PodStatus{}
+	for _, id := range f.podIDs() {
+		statuses = append(statuses, podStatus(f.pods[id].current()))
+	}
+	return statuses, nil
+}
+
+// Pod retrieves the pod
+func (f *FakeMarathon) Pod(name string) (*marathon.Pod, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	pod, err := f.pod(name)
+	if err != nil {
+		return nil, err
+	}
+	return copyPod(pod.current()), nil
+}
+
+// Pods retrieves all the pods
+func (f *FakeMarathon) Pods() ([]marathon.Pod, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	pods := []marathon.Pod{}
+	for _, id := range f.podIDs() {
+		pods = append(pods, *copyPod(f.pods[id].current()))
+	}
+	return pods, nil
+}
+
+// CreatePod creates the pod, failing if it already exists
+func (f *FakeMarathon) CreatePod(pod *marathon.Pod) (*marathon.Pod, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	id := canonicalID(pod.ID)
+	if _, found := f.pods[id]; found {
+		return nil, conflict("Pod %s already exists", id)
+	}
+	created := copyPod(pod)
+	created.ID = id
+	f.deployPod(created)
+	return copyPod(created), nil
+}
+
+// UpdatePod replaces the definition of the pod, creating it if it doesn't exist
+func (f *FakeMarathon) UpdatePod(pod *marathon.Pod, force bool) (*marathon.Pod, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	updated := copyPod(pod)
+	updated.ID = canonicalID(pod.ID)
+	f.deployPod(updated)
+	return copyPod(updated), nil
+}
+
+// DeletePod deletes the pod
+func (f *FakeMarathon) DeletePod(name string, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	pod, err := f.pod(name)
+	if err != nil {
+		return nil, err
+	}
+	delete(f.pods, pod.current().ID)
+	return f.deployed(), nil
+}
+
+// WaitOnPod waits for the pod to exist
+func (f *FakeMarathon) WaitOnPod(name string, timeout time.Duration) error {
+	return waitOn(timeout, func() bool {
+		return f.PodIsRunning(name)
+	})
+}
+
+// PodIsRunning checks if the pod exists, pods running as soon as they are deployed
+func (f *FakeMarathon) PodIsRunning(name string) bool {
+	_, err := f.PodStatus(name)
+	return err == nil
+}
+
+// PodVersions retrieves the versions of the pod, the latest first
+func (f *FakeMarathon) PodVersions(name string) ([]string, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	pod, err := f.pod(name)
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for i := len(pod.versions) - 1; i >= 0; i-- {
+		versions = append(versions, pod.versions[i].Version)
+	}
+	return versions, nil
+}
+
+// PodByVersion retrieves the definition of the pod at the version
+func (f *FakeMarathon) PodByVersion(name, version string) (*marathon.Pod, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	pod, err := f.pod(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, definition := range pod.versions {
+		if definition.Version == version {
+			return copyPod(definition), nil
+		}
+	}
+	return nil, notFound("Pod %s does not exist in version %s", pod.current().ID, version)
+}
+
+// DeletePodInstances deletes the instances of the pod
+func (f *FakeMarathon) DeletePodInstances(name string, instances []string) ([]*marathon.PodInstance, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	if _, err := f.pod(name); err != nil {
+		return nil, err
+	}
+	var deleted []*marathon.PodInstance
+	for _, instance := range instances {
+		deleted = append(deleted, &marathon.PodInstance{
+			InstanceID: marathon.PodInstanceID{ID: instance},
+			AgentInfo:  marathon.PodAgentInfo{Host: fakeHost},
+		})
+	}
+	return deleted, nil
+}
+
+// DeletePodInstance deletes the instance of the pod
+func (f *FakeMarathon) DeletePodInstance(name, instance string) (*marathon.PodInstance, error) {
+	deleted, err := f.DeletePodInstances(name, []string{instance})
+	if err != nil {
+		return nil, err
+	}
+	return deleted[0], nil
+}
+
+// -- TASKS ---
+
+// Tasks retrieves the tasks of the application
+func (f *FakeMarathon) Tasks(name string) (*marathon.Tasks, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	return &marathon.Tasks{Tasks: copyTasks(app.tasks)}, nil
+}
+
+// TasksBy retrieves the tasks of the application by the options, all of them being running
+func (f *FakeMarathon) TasksBy(name string, opts *marathon.TasksOpts) (*marathon.Tasks, error) {
+	tasks, err := f.Tasks(name)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Status != "" && opts.Status != marathon.TaskStatusRunning {
+		tasks.Tasks = []marathon.Task{}
+	}
+	return tasks, nil
+}
+
+// AllTasks retrieves the tasks of all the applications, all of them being running
+func (f *FakeMarathon) AllTasks(opts *marathon.AllTasksOpts) (*marathon.Tasks, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	tasks := &marathon.Tasks{Tasks: []marathon.Task{}}
+	if opts != nil && opts.Status != "" && opts.Status != marathon.TaskStatusRunning {
+		return tasks, nil
+	}
+	for _, id := range f.appIDs() {
+		tasks.Tasks = append(tasks.Tasks, copyTasks(f.apps[id].tasks)...)
+	}
+	return tasks, nil
+}
+
+// AllTaskEndpoints retrieves the endpoints of the tasks of all the applications per service port,
+// all of them being running
+func (f *FakeMarathon) AllTaskEndpoints(opts *marathon.AllTasksOpts) ([]*marathon.ServiceEndpoints, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	list := []*marathon.ServiceEndpoints{}
+	if opts != nil && opts.Status != "" && opts.Status != marathon.TaskStatusRunning {
+		return list, nil
+	}
+	for _, id := range f.appIDs() {
+		app := f.apps[id]
+		for index, servicePort := range servicePorts(app.current()) {
+			endpoints := &marathon.ServiceEndpoints{AppID: id, ServicePort: servicePort, Endpoints: []string{}}
+			for _, task := range app.tasks {
+				if index < len(task.Ports) {
+					endpoints.Endpoints = append(endpoints.Endpoints, fmt.Sprintf("%s:%d", task.Host, task.Ports[index]))
+				}
+			}
+			list = append(list, endpoints)
+		}
+	}
+	return list, nil
+}
+
+// TaskEndpoints retrieves the host:port endpoints of the tasks for the service port, the tasks of
+// the fake being healthy as soon as they run
+func (f *FakeMarathon) TaskEndpoints(name string, port int, healthCheck bool) ([]string, error) {
+	application, err := f.Application(name)
+	if err != nil {
+		return nil, err
+	}
+	portIndex, err := application.PortIndex(port)
+	if err != nil {
+		return nil, err
+	}
+	return application.TaskEndpoints(portIndex, false), nil
+}
+
+// TaskEndpointsByPortName retrieves the host:port endpoints of the tasks for the named port
+func (f *FakeMarathon) TaskEndpointsByPortName(name, portName string, healthCheck bool) ([]string, error) {
+	application, err := f.Application(name)
+	if err != nil {
+		return nil, err
+	}
+	portIndex, err := application.PortIndexByName(portName)
+	if err != nil {
+		return nil, err
+	}
+	return application.TaskEndpoints(portIndex, false), nil
+}
+
+// KillApplicationTasks kills the tasks of the application, which are restarted unless scaling
+func (f *FakeMarathon) KillApplicationTasks(name string, opts *marathon.KillApplicationTasksOpts) (*marathon.Tasks, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	app, err := f.app(name)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &marathon.KillApplicationTasksOpts{}
+	}
+	killed := &marathon.Tasks{Tasks: []marathon.Task{}}
+	for _, task := range copyTasks(app.tasks) {
+		if opts.Host != "" && opts.Host != task.Host {
+			continue
+		}
+		f.killTask(app, task.ID, opts.Scale)
+		killed.Tasks = append(killed.Tasks, task)
+	}
+	return killed, nil
+}
+
+// KillTask kills the task, which is restarted unless scaling
+func (f *FakeMarathon) KillTask(taskID string, opts *marathon.KillTaskOpts) (*marathon.Task, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if opts == nil {
+		opts = &marathon.KillTaskOpts{}
+	}
+	return f.killTaskByID(taskID, opts.Scale)
+}
+
+// KillTasks kills the tasks, which are restarted unless scaling
+func (f *FakeMarathon) KillTasks(taskIDs []string, opts *marathon.KillTaskOpts) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if opts == nil {
+		opts = &marathon.KillTaskOpts{}
+	}
+	for _, taskID := range taskIDs {
+		if _, err := f.killTaskByID(taskID, opts.Scale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -- GROUPS ---
+
+// Groups retrieves the root group
+func (f *FakeMarathon) Groups() (*marathon.Groups, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	root := f.group("/")
+	return &marathon.Groups{
+		ID:           root.ID,
+		Apps:         root.Apps,
+		Dependencies: root.Dependencies,
+		Groups:       root.Groups,
+	}, nil
+}
+
+// Group retrieves the group
+func (f *FakeMarathon) Group(name string) (*marathon.Group, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	id := canonicalID(name)
+	if !f.groupExists(id) {
+		return nil, notFound("Group '%s' does not exist", id)
+	}
+	return f.group(id), nil
+}
+
+// GroupsBy retrieves the root group, the options are ignored
+func (f *FakeMarathon) GroupsBy(opts *marathon.GetGroupOpts) (*marathon.Groups, error) {
+	return f.Groups()
+}
+
+// GroupBy retrieves the group, the options are ignored
+func (f *FakeMarathon) GroupBy(name string, opts *marathon.GetGroupOpts) (*marathon.Group, error) {
+	return f.Group(name)
+}
+
+// CreateGroup creates the group with its applications and subgroups
+func (f *FakeMarathon) CreateGroup(group *marathon.Group) error {
+	f.Lock()
+	defer f.Unlock()
+
+	id := canonicalID(group.ID)
+	if f.groupExists(id) {
+		return conflict("Group %s is already created. Use PUT to change this group.", id)
+	}
+	return f.deployGroup(id, group)
+}
+
+// DeleteGroup deletes the group with its applications and subgroups
+func (f *FakeMarathon) DeleteGroup(name string, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	id := canonicalID(name)
+	if !f.groupExists(id) {
+		return nil, notFound("Group '%s' does not exist", id)
+	}
+	for _, appID := range f.appIDs() {
+		if withinGroup(id, appID) {
+			app := f.apps[appID]
+			f.killTasks(app, len(app.tasks))
+			delete(f.apps, appID)
+		}
+	}
+	for groupID := range f.groups {
+		if groupID == id || withinGroup(id, groupID) {
+			delete(f.groups, groupID)
+		}
+	}
+	return f.deployed(), nil
+}
+
+// UpdateGroup updates the applications of the group, creating it if it doesn't exist
+func (f *FakeMarathon) UpdateGroup(name string, group *marathon.Group, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.deployGroup(canonicalID(name), group); err != nil {
+		return nil, err
+	}
+	return f.deployed(), nil
+}
+
+// DeployGroup deploys the group with its applications and subgroups, creating it if it doesn't exist
+func (f *FakeMarathon) DeployGroup(group *marathon.Group, force bool) (*marathon.DeploymentID, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.deployGroup(canonicalID(group.ID), group); err != nil {
+		return nil, err
+	}
+	return f.deployed(), nil
+}
+
+// HasGroup checks if the group exists
+func (f *FakeMarathon) HasGroup(name string) (bool, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	return f.groupExists(canonicalID(name)), nil
+}
+
+// WaitOnGroup waits for the group to exist
+func (f *FakeMarathon) WaitOnGroup(name string, timeout time.Duration) error {
+	return waitOn(timeout, func() bool {
+		found, _ := f.HasGroup(name)
+		return found
+	})
+}
+
+// WaitOnGroupHealthy waits for the group like WaitOnGroup, the tasks of the fake being healthy as
+// soon as they run
+func (f *FakeMarathon) WaitOnGroupHealthy(name string, timeout time.Duration) error {
+	return f.WaitOnGroup(name, timeout)
+}
+
+// -- DEPLOYMENTS ---
+
+// Deployments retrieves the running deployments, which is always empty
+func (f *FakeMarathon) Deployments() ([]*marathon.Deployment, error) {
+	return []*marathon.Deployment{}, nil
+}
+
+// DeleteDeployment fails, as the deployments are complete as soon as they start
+func (f *FakeMarathon) DeleteDeployment(id string, force bool) (*marathon.DeploymentID, error) {
+	return nil, notFound("DeploymentPlan %s does not exist", id)
+}
+
+// HasDeployment always returns false, as the deployments are complete as soon as they start
+func (f *FakeMarathon) HasDeployment(id string) (bool, error) {
+	return false, nil
+}
+
+// WaitOnDeployment returns immediately, as the deployments are complete as soon as they start
+func (f *FakeMarathon) WaitOnDeployment(id string, timeout time.Duration) error {
+	return nil
+}
+
+// -- SUBSCRIPTIONS ---
+
+// Subscriptions retrieves the registered callback URLs
+func (f *FakeMarathon) Subscriptions() (*marathon.Subscriptions, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	return &marathon.Subscriptions{CallbackURLs: append([]string{}, f.subscriptions...)}, nil
+}
+
+// AddEventsListener adds a listener for the events of the fake, e.g. the deployment_success and
+// status_update_event events
+func (f *FakeMarathon) AddEventsListener(filter int) (marathon.EventsChannel, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	channel := make(marathon.EventsChannel)
+	f.listeners[channel] = &fakeListener{
+		filter:     filter,
+		done:       make(chan struct{}),
+		completion: &sync.WaitGroup{},
+	}
+	return channel, nil
+}
+
+// RemoveEventsListener removes the listener, closing the channel
+func (f *FakeMarathon) RemoveEventsListener(channel marathon.EventsChannel) {
+	f.Lock()
+	defer f.Unlock()
+
+	if listener, found := f.listeners[channel]; found {
+		close(listener.done)
+		delete(f.listeners, channel)
+		go func() {
+			listener.completion.Wait()
+			close(channel)
+		}()
+	}
+}
+
+// Subscribe registers the callback URL
+func (f *FakeMarathon) Subscribe(callback string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if !contains(f.subscriptions, callback) {
+		f.subscriptions = append(f.subscriptions, callback)
+	}
+	return nil
+}
+
+// Unsubscribe removes the callback URL
+func (f *FakeMarathon) Unsubscribe(callback string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	for i, subscription := range f.subscriptions {
+		if subscription == callback {
+			f.subscriptions = append(f.subscriptions[:i], f.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return notFound("Callback URL %s does not exist", callback)
+}
+
+// -- QUEUE ---
+
+// Queue retrieves the launch queue, which is always empty
+func (f *FakeMarathon) Queue() (*marathon.Queue, error) {
+	return &marathon.Queue{Items: []marathon.Item{}}, nil
+}
+
+// QueueBy retrieves the launch queue, which is always empty
+func (f *FakeMarathon) QueueBy(opts *marathon.QueueOpts) (*marathon.Queue, error) {
+	return f.Queue()
+}
+
+// DeleteQueueDelay resets the launch delay of the application
+func (f *FakeMarathon) DeleteQueueDelay(appID string) error {
+	f.RLock()
+	defer f.RUnlock()
+
+	_, err := f.app(appID)
+	return err
+}
+
+// -- MISC ---
+
+// GetMarathonURL retrieves the URL of the fake
+func (f *FakeMarathon) GetMarathonURL() string {
+	return FakeMarathonURL
+}
+
+// Ping always succeeds
+func (f *FakeMarathon) Ping() (bool, error) {
+	return true, nil
+}
+
+// PingLatency always succeeds, with no latency
+func (f *FakeMarathon) PingLatency(timeout time.Duration) (time.Duration, error) {
+	return 0, nil
+}
+
+// Info retrieves the details of the fake
+func (f *FakeMarathon) Info() (*marathon.Info, error) {
+	return &marathon.Info{
+		Leader:  fakeLeader,
+		Name:    "marathon",
+		Version: FakeMarathonVersion,
+	}, nil
+}
+
+// ServerVersion returns FakeMarathonVersion
+func (f *FakeMarathon) ServerVersion() (string, error) {
+	return FakeMarathonVersion, nil
+}
+
+// Supports checks if FakeMarathonVersion supports the feature
+func (f *FakeMarathon) Supports(feature marathon.Feature) (bool, error) {
+	return marathon.FeatureSupported(feature, FakeMarathonVersion), nil
+}
+
+// Leader retrieves the leader of the fake
+func (f *FakeMarathon) Leader() (string, error) {
+	return fakeLeader, nil
+}
+
+// AbdicateLeader always succeeds, the fake remaining the leader
+func (f *FakeMarathon) AbdicateLeader() (string, error) {
+	return "Leadership abdicated", nil
+}
+
+// Scoped returns the fake bound to the group prefix
+func (f *FakeMarathon) Scoped(prefix string) marathon.Marathon {
+	return marathon.NewScopedClient(f, prefix)
+}
+
+// FollowerResponses always returns zero, the fake being the leader
+func (f *FakeMarathon) FollowerResponses() int64 {
+	return 0
+}
+
+// Close removes the events listeners, closing their channels
+func (f *FakeMarathon) Close() error {
+	f.Lock()
+	channels := make([]marathon.EventsChannel, 0, len(f.listeners))
+	for channel := range f.listeners {
+		channels = append(channels, channel)
+	}
+	f.Unlock()
+	for _, channel := range channels {
+		f.RemoveEventsListener(channel)
+	}
+	return nil
+}
+
+// -- ARTIFACTS ---
+
+// UploadArtifact stores the artifact in memory
+func (f *FakeMarathon) UploadArtifact(artifactPath string, artifact io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(artifact)
+	if err != nil {
+		return "", err
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	artifactPath = canonicalID(artifactPath)
+	f.artifacts[artifactPath] = content
+	return FakeMarathonURL + "/v2/artifacts" + artifactPath, nil
+}
+
+// GetArtifact retrieves the artifact
+func (f *FakeMarathon) GetArtifact(artifactPath string) (io.ReadCloser, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	content, found := f.artifacts[canonicalID(artifactPath)]
+	if !found {
+		return nil, notFound("Artifact %s does not exist", artifactPath)
+	}
+	return ioutil.NopCloser(bytes.NewReader(append([]byte{}, content...))), nil
+}
+
+// DeleteArtifact deletes the artifact
+func (f *FakeMarathon) DeleteArtifact(artifactPath string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	artifactPath = canonicalID(artifactPath)
+	if _, found := f.artifacts[artifactPath]; !found {
+		return notFound("Artifact %s does not exist", artifactPath)
+	}
+	delete(f.artifacts, artifactPath)
+	return nil
+}
+
+// -- STATE ---
+// the methods below expect the caller to hold the lock
+
+// app retrieves the stored application
+func (f *FakeMarathon) app(name string) (*fakeApp, error) {
+	id := canonicalID(name)
+	app, found := f.apps[id]
+	if !found {
+		return nil, notFound("App '%s' does not exist", id)
+	}
+	return app, nil
+}
+
+// pod retrieves the stored pod
+func (f *FakeMarathon) pod(name string) (*fakePod, error) {
+	id := canonicalID(name)
+	pod, found := f.pods[id]
+	if !found {
+		return nil, notFound("Pod '%s' does not exist", id)
+	}
+	return pod, nil
+}
+
+func (f *FakeMarathon) appIDs() []string {
+	var ids []string
+	for id := range f.apps {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (f *FakeMarathon) podIDs() []string {
+	var ids []string
+	for id := range f.pods {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// next returns the next value of the sequence
+func (f *FakeMarathon) next() int {
+	f.sequence++
+	return f.sequence
+}
+
+// version generates a unique version, versions being timestamps in Marathon
+func (f *FakeMarathon) version() string {
+	return f.epoch.Add(time.Duration(f.next()) * time.Millisecond).Format("2006-01-02T15:04:05.000Z")
+}
+
+// deployed generates the identifier of a deployment and notifies the listeners of its success
+func (f *FakeMarathon) deployed() *marathon.DeploymentID {
+	return f.deployedAt(f.version())
+}
+
+// deployedAt generates the identifier of a deployment of the version and notifies the listeners of
+// its success
+func (f *FakeMarathon) deployedAt(version string) *marathon.DeploymentID {
+	n := f.next()
+	deployment := &marathon.DeploymentID{
+		DeploymentID: fmt.Sprintf("%08x-0000-4000-8000-%012x", n, n),
+		Version:      version,
+	}
+	f.emit(&marathon.Event{
+		ID:   marathon.EventIDDeploymentSuccess,
+		Name: "deployment_success",
+		Event: &marathon.EventDeploymentSuccess{
+			ID:        deployment.DeploymentID,
+			EventType: "deployment_success",
+			Timestamp: version,
+			Plan: &marathon.DeploymentPlan{
+				ID:      deployment.DeploymentID,
+				Version: version,
+			},
+		},
+	})
+	return deployment
+}
+
+// deployApplication stores the application as a new version and starts or kills the tasks to
+// match its instances
+func (f *FakeMarathon) deployApplication(application *marathon.Application, scaling bool) (*marathon.DeploymentID, error) {
+	if err := validateApplication(application); err != nil {
+		return nil, err
+	}
+	app, found := f.apps[application.ID]
+	if !found {
+		app = &fakeApp{}
+		f.apps[application.ID] = app
+	}
+	application.Version = f.version()
+	application.VersionInfo = &marathon.VersionInfo{
+		LastScalingAt:      application.Version,
+		LastConfigChangeAt: application.Version,
+	}
+	if scaling && found {
+		application.VersionInfo.LastConfigChangeAt = app.current().VersionInfo.LastConfigChangeAt
+	}
+	// step: the state of the application is not part of its definition
+	application.Tasks = nil
+	application.TasksRunning = 0
+	application.TasksHealthy = 0
+	application.TasksStaged = 0
+	application.TasksUnhealthy = 0
+	application.Deployments = nil
+	app.versions = append(app.versions, application)
+
+	if count := instances(application); len(app.tasks) > count {
+		f.killTasks(app, len(app.tasks)-count)
+	}
+	for len(app.tasks) < instances(application) {
+		f.startTask(app)
+	}
+	return f.deployedAt(application.Version), nil
+}
+
+// deployPod stores the pod as a new version
+func (f *FakeMarathon) deployPod(definition *marathon.Pod) {
+	pod, found := f.pods[definition.ID]
+	if !found {
+		pod = &fakePod{}
+		f.pods[definition.ID] = pod
+	}
+	definition.Version = f.version()
+	pod.versions = append(pod.versions, definition)
+	f.deployed()
+}
+
+// deployGroup registers the group and deploys its applications and subgroups
+func (f *FakeMarathon) deployGroup(id string, group *marathon.Group) error {
+	f.groups[id] = true
+	for _, application := range group.Apps {
+		appID := resolveID(id, application.ID)
+		deployed := copyApplication(application)
+		if app, found := f.apps[appID]; found {
+			var err error
+			if deployed, err = overlayApplication(app.current(), application); err != nil {
+				return err
+			}
+		}
+		deployed.ID = appID
+		if _, err := f.deployApplication(deployed, false); err != nil {
+			return err
+		}
+	}
+	for _, subgroup := range group.Groups {
+		if err := f.deployGroup(resolveID(id, subgroup.ID), subgroup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startTask starts a new task of the application
+func (f *FakeMarathon) startTask(app *fakeApp) {
+	application := app.current()
+	n := f.next()
+	task := &marathon.Task{
+		ID:      fmt.Sprintf("%s.fake-%d", strings.Replace(strings.TrimPrefix(application.ID, "/"), "/", "_", -1), n),
+		AppID:   application.ID,
+		Host:    fakeHost,
+		SlaveID: "fake-agent-id",
+		State:   "TASK_RUNNING",
+		Version: application.Version,
+		IPAddresses: []*marathon.IPAddress{
+			{IPAddress: "127.0.0.1", Protocol: "IPv4"},
+		},
+	}
+	task.StagedAt = f.version()
+	task.StartedAt = task.StagedAt
+	for i := 0; i < portCount(application); i++ {
+		task.Ports = append(task.Ports, fakeFirstPort+f.next())
+	}
+	if application.HealthChecks != nil {
+		for range *application.HealthChecks {
+			task.HealthCheckResults = append(task.HealthCheckResults, &marathon.HealthCheckResult{
+				Alive:        true,
+				FirstSuccess: task.StartedAt,
+				LastSuccess:  task.StartedAt,
+				TaskID:       task.ID,
+			})
+		}
+	}
+	app.tasks = append(app.tasks, task)
+	f.emitStatusUpdate(task)
+}
+
+// killTasks kills the most recently started tasks of the application
+func (f *FakeMarathon) killTasks(app *fakeApp, count int) {
+	for ; count > 0 && len(app.tasks) > 0; count-- {
+		task := app.tasks[len(app.tasks)-1]
+		app.tasks = app.tasks[:len(app.tasks)-1]
+		task.State = "TASK_KILLED"
+		f.emitStatusUpdate(task)
+	}
+}
+
+// killTask kills the task of the application, scaling the application down or replacing the task
+func (f *FakeMarathon) killTask(app *fakeApp, taskID string, scale bool) {
+	for i, task := range app.tasks {
+		if task.ID != taskID {
+			continue
+		}
+		app.tasks = append(app.tasks[:i], app.tasks[i+1:]...)
+		task.State = "TASK_KILLED"
+		f.emitStatusUpdate(task)
+		break
+	}
+	if scale {
+		scaled := copyApplication(app.current())
+		scaled.Count(len(app.tasks))
+		f.deployApplication(scaled, true)
+		return
+	}
+	f.startTask(app)
+}
+
+// killTaskByID kills the task, looking up its application
+func (f *FakeMarathon) killTaskByID(taskID string, scale bool) (*marathon.Task, error) {
+	for _, id := range f.appIDs() {
+		app := f.apps[id]
+		for _, task := range app.tasks {
+			if task.ID == taskID {
+				killed := *task
+				f.killTask(app, taskID, scale)
+				return &killed, nil
+			}
+		}
+	}
+	return nil, notFound("Task '%s' does not exist", taskID)
+}
+
+// render produces the application as returned by Marathon, embedding the state of its tasks
+func (f *FakeMarathon) render(app *fakeApp) *marathon.Application {
+	application := copyApplication(app.current())
+	for _, task := range copyTasks(app.tasks) {
+		t := task
+		application.Tasks = append(application.Tasks, &t)
+	}
+	application.TasksRunning = len(app.tasks)
+	if application.HasHealthChecks() {
+		application.TasksHealthy = len(app.tasks)
+	}
+	application.Deployments = []map[string]string{}
+	return application
+}
+
+// groupExists checks if the group was created or holds applications
+func (f *FakeMarathon) groupExists(id string) bool {
+	if id == "/" || f.groups[id] {
+		return true
+	}
+	for groupID := range f.groups {
+		if withinGroup(id, groupID) {
+			return true
+		}
+	}
+	for appID := range f.apps {
+		if withinGroup(id, appID) {
+			return true
+		}
+	}
+	return false
+}
+
+// group builds the group tree from the applications and the created groups
+func (f *FakeMarathon) group(id string) *marathon.Group {
+	group := &marathon.Group{
+		ID:           id,
+		Apps:         []*marathon.Application{},
+		Dependencies: []string{},
+		Groups:       []*marathon.Group{},
+	}
+	subgroups := make(map[string]bool)
+	addParent := func(childID string) {
+		// step: find the direct subgroup of the group holding the child
+		for parent := path.Dir(childID); parent != id && parent != "/"; parent = path.Dir(parent) {
+			if path.Dir(parent) == id {
+				subgroups[parent] = true
+			}
+		}
+	}
+	for _, appID := range f.appIDs() {
+		if !withinGroup(id, appID) {
+			continue
+		}
+		if path.Dir(appID) == id {
+			group.Apps = append(group.Apps, f.render(f.apps[appID]))
+		} else {
+			addParent(appID)
+		}
+	}
+	for groupID := range f.groups {
+		if !withinGroup(id, groupID) {
+			continue
+		}
+		if path.Dir(groupID) == id {
+			subgroups[groupID] = true
+		} else {
+			addParent(groupID)
+		}
+	}
+	var ids []string
+	for subgroup := range subgroups {
+		ids = append(ids, subgroup)
+	}
+	sort.Strings(ids)
+	for _, subgroup := range ids {
+		group.Groups = append(group.Groups, f.group(subgroup))
+	}
+	return group
+}
+
+// emitStatusUpdate notifies the listeners of the change of the task state
+func (f *FakeMarathon) emitStatusUpdate(task *marathon.Task) {
+	f.emit(&marathon.Event{
+		ID:   marathon.EventIDStatusUpdate,
+		Name: "status_update_event",
+		Event: &marathon.EventStatusUpdate{
+			EventType:   "status_update_event",
+			Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+			SlaveID:     task.SlaveID,
+			TaskID:      task.ID,
+			TaskStatus:  task.State,
+			AppID:       task.AppID,
+			Host:        task.Host,
+			Ports:       task.Ports,
+			IPAddresses: task.IPAddresses,
+			Version:     task.Version,
+		},
+	})
+}
+
+// emit sends the event to the interested listeners, without blocking the fake
+func (f *FakeMarathon) emit(event *marathon.Event) {
+	for channel, listener := range f.listeners {
+		if event.ID&listener.filter == 0 {
+			continue
+		}
+		listener.completion.Add(1)
+		go func(channel marathon.EventsChannel, listener *fakeListener) {
+			defer listener.completion.Done()
+			select {
+			case channel <- event:
+			case <-listener.done:
+			}
+		}(channel, listener)
+	}
+}
+
+func (r *fakeApp) current() *marathon.Application {
+	return r.versions[len(r.versions)-1]
+}
+
+func (r *fakeApp) version(version string) (*marathon.Application, error) {
+	for _, application := range r.versions {
+		if application.Version == version {
+			return application, nil
+		}
+	}
+	return nil, notFound("App '%s' does not exist in version %s", r.current().ID, version)
+}
+
+func (r *fakePod) current() *marathon.Pod {
+	return r.versions[len(r.versions)-1]
+}
+
+// -- HELPERS ---
+
+// canonicalID returns the absolute form of the identifier
+func canonicalID(id string) string {
+	return path.Clean("/" + strings.TrimPrefix(id, "/"))
+}
+
+// resolveID resolves an identifier of a group member against the group
+func resolveID(group, id string) string {
+	if strings.HasPrefix(id, "/") {
+		return path.Clean(id)
+	}
+	return path.Clean(group + "/" + id)
+}
+
+// withinGroup checks if the identifier is nested within the group
+func withinGroup(group, id string) bool {
+	return group == "/" && id != "/" || strings.HasPrefix(id, group+"/")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilters checks the application against the id and label filters of Applications()
+func matchesFilters(application *marathon.Application, v url.Values) bool {
+	if id := v.Get("id"); id != "" && !strings.Contains(application.ID, id) {
+		return false
+	}
+	if cmd := v.Get("cmd"); cmd != "" && (application.Cmd == nil || !strings.Contains(*application.Cmd, cmd)) {
+		return false
+	}
+	if selector := v.Get("label"); selector != "" {
+		labels := map[string]string{}
+		if application.Labels != nil {
+			labels = *application.Labels
+		}
+		for _, requirement := range splitSelector(selector, ',') {
+			if !matchesRequirement(labels, requirement) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchesRequirement checks the labels meet a requirement of a label selector, i.e. name,
+// name==value, name!=value, name in (values) or name notin (values)
+func matchesRequirement(labels map[string]string, requirement string) bool {
+	requirement = strings.TrimSpace(requirement)
+	for _, operator := range []string{" notin ", " in "} {
+		if i := strings.Index(requirement, operator); i > 0 {
+			value, found := labels[unescapeSelector(strings.TrimSpace(requirement[:i]))]
+			set := strings.Trim(strings.TrimSpace(requirement[i+len(operator):]), "()")
+			in := false
+			for _, candidate := range splitSelector(set, ',') {
+				in = in || value == unescapeSelector(strings.TrimSpace(candidate))
+			}
+			return found && in == (operator == " in ")
+		}
+	}
+	for _, operator := range []string{"==", "!="} {
+		if parts := splitSelectorOnce(requirement, operator); len(parts) == 2 {
+			value, found := labels[unescapeSelector(strings.TrimSpace(parts[0]))]
+			return found && (value == unescapeSelector(strings.TrimSpace(parts[1]))) == (operator == "==")
+		}
+	}
+	_, found := labels[unescapeSelector(requirement)]
+	return found
+}
+
+// splitSelector splits the selector on the separator, outside of the escapes and parentheses
+func splitSelector(selector string, separator byte) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(selector); i++ {
+		switch selector[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case separator:
+			if depth == 0 {
+				parts = append(parts, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, selector[start:])
+}
+
+// splitSelectorOnce splits the requirement on the first unescaped operator
+func splitSelectorOnce(requirement, operator string) []string {
+	for i := 0; i < len(requirement); i++ {
+		if requirement[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(requirement[i:], operator) {
+			return []string{requirement[:i], requirement[i+len(operator):]}
+		}
+	}
+	return []string{requirement}
+}
+
+// unescapeSelector removes the backslashes escaping the characters of a label name or value
+func unescapeSelector(term string) string {
+	var unescaped strings.Builder
+	for i := 0; i < len(term); i++ {
+		if term[i] == '\\' && i+1 < len(term) {
+			i++
+		}
+		unescaped.WriteByte(term[i])
+	}
+	return unescaped.String()
+}
+
+func instances(application *marathon.Application) int {
+	if application.Instances == nil {
+		return 1
+	}
+	return *application.Instances
+}
+
+// portCount returns the number of host ports allocated to the tasks of the application
+func portCount(application *marathon.Application) int {
+	return len(servicePorts(application))
+}
+
+// servicePorts returns the service ports of the application, in the order of the ports of its tasks
+func servicePorts(application *marathon.Application) []int {
+	var mappings *[]marathon.PortMapping
+	switch {
+	case application.Container != nil && application.Container.PortMappings != nil:
+		mappings = application.Container.PortMappings
+	case application.Container != nil && application.Container.Docker != nil && application.Container.Docker.PortMappings != nil:
+		mappings = application.Container.Docker.PortMappings
+	case application.PortDefinitions != nil:
+		var ports []int
+		for _, definition := range *application.PortDefinitions {
+			port := 0
+			if definition.Port != nil {
+				port = *definition.Port
+			}
+			ports = append(ports, port)
+		}
+		return ports
+	default:
+		return application.Ports
+	}
+	var ports []int
+	for _, mapping := range *mappings {
+		ports = append(ports, mapping.ServicePort)
+	}
+	return ports
+}
+
+// validateApplication rejects the definitions Marathon would refuse
+func validateApplication(application *marathon.Application) error {
+	if application.ID == "/" {
+		return unprocessable("The application id must not be empty")
+	}
+	if instances(application) < 0 {
+		return unprocessable("The number of instances of %s must not be negative", application.ID)
+	}
+	return nil
+}
+
+// overlayApplication applies the fields set on the update to the application, the way Marathon
+// handles an application update
+func overlayApplication(application, update *marathon.Application) (*marathon.Application, error) {
+	fields := map[string]json.RawMessage{}
+	if err := remarshal(application, &fields); err != nil {
+		return nil, err
+	}
+	changes := map[string]json.RawMessage{}
+	if err := remarshal(update, &changes); err != nil {
+		return nil, err
+	}
+	for name, value := range changes {
+		if string(value) != "null" {
+			fields[name] = value
+		}
+	}
+	updated := new(marathon.Application)
+	if err := remarshal(fields, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func copyApplication(application *marathon.Application) *marathon.Application {
+	return application.Clone()
+}
+
+func copyPod(pod *marathon.Pod) *marathon.Pod {
+	copied := new(marathon.Pod)
+	remarshal(pod, copied)
+	return copied
+}
+
+func copyTasks(tasks []*marathon.Task) []marathon.Task {
+	copied := []marathon.Task{}
+	if len(tasks) > 0 {
+		remarshal(tasks, &copied)
+	}
+	return copied
+}
+
+func podStatus(pod *marathon.Pod) *marathon.PodStatus {
+	return &marathon.PodStatus{
+		ID:          pod.ID,
+		Spec:        copyPod(pod),
+		Status:      marathon.PodStateStable,
+		StatusSince: pod.Version,
+		LastUpdated: pod.Version,
+		LastChanged: pod.Version,
+	}
+}
+
+// remarshal copies the source into the target through its JSON representation
+func remarshal(source, target interface{}) error {
+	content, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, target)
+}
+
+// waitOn polls the condition until it is met or the timeout expires
+func waitOn(timeout time.Duration, condition func() bool) error {
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return marathon.ErrTimeoutError
+		}
+		time.Sleep(fakePollInterval)
+	}
+	return nil
+}
+
+func notFound(message string, args ...interface{}) error {
+	return apiError(http.StatusNotFound, message, args...)
+}
+
+func conflict(message string, args ...interface{}) error {
+	return apiError(http.StatusConflict, message, args...)
+}
+
+func unprocessable(message string, args ...interface{}) error {
+	return apiError(422, message, args...)
+}
+
+// apiError creates the error the client returns for the Marathon error response
+func apiError(code int, message string, args ...interface{}) error {
+	content, _ := json.Marshal(map[string]string{"message": fmt.Sprintf(message, args...)})
+	return marathon.NewAPIError(code, content)
+}
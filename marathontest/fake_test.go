@@ -0,0 +1,404 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/gambol99/go-marathon/marathontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertAPIError(t *testing.T, err error, code int) {
+	require.Error(t, err)
+	apiErr, ok := err.(*marathon.APIError)
+	require.True(t, ok, "expected an APIError, got %T", err)
+	assert.Equal(t, code, apiErr.ErrCode)
+}
+
+func TestFakeApplicationLifecycle(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+
+	application := marathon.NewDockerApplication().Name("web").Count(2)
+	application.Container.Docker.Container("nginx").Bridged().Expose(80)
+	created, err := fake.CreateApplication(application)
+	require.NoError(t, err)
+	assert.Equal(t, "/web", created.ID)
+	assert.Equal(t, 2, created.TasksRunning)
+	assert.Len(t, created.Tasks, 2)
+
+	_, err = fake.CreateApplication(application)
+	assertAPIError(t, err, marathon.ErrCodeDuplicateID)
+
+	_, err = fake.ScaleApplicationInstances("/web", 5, false)
+	require.NoError(t, err)
+	tasks, err := fake.Tasks("/web")
+	require.NoError(t, err)
+	assert.Len(t, tasks.Tasks, 5)
+	tasks, err = fake.TasksBy("/web", &marathon.TasksOpts{Status: marathon.TaskStatusStaging})
+	require.NoError(t, err)
+	assert.Empty(t, tasks.Tasks)
+	ok, err := fake.ApplicationOK("/web")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	endpoints, err := fake.TaskEndpoints("/web", 80, false)
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 5)
+	list, err := fake.AllTaskEndpoints(nil)
+	require.NoError(t, err)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, "/web", list[0].AppID)
+		assert.Len(t, list[0].Endpoints, 5)
+	}
+
+	update := new(marathon.Application).Name("/web").CPU(2)
+	_, err = fake.UpdateApplication(update, false)
+	require.NoError(t, err)
+	updated, err := fake.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, *updated.CPUs)
+	assert.Equal(t, 5, *updated.Instances)
+	assert.Equal(t, "nginx", updated.Container.Docker.Image)
+
+	versions, err := fake.ApplicationVersions("/web")
+	require.NoError(t, err)
+	require.Len(t, versions.Versions, 3)
+	_, err = fake.SetApplicationVersion("/web", &marathon.ApplicationVersion{Version: versions.Versions[2]})
+	require.NoError(t, err)
+	reverted, err := fake.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *reverted.Instances)
+	assert.Len(t, reverted.Tasks, 2)
+
+	_, err = fake.DeleteApplication("/web", false)
+	require.NoError(t, err)
+	_, err = fake.Application("/web")
+	assertAPIError(t, err, marathon.ErrCodeNotFound)
+}
+
+func TestFakeApplicationsFilters(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	for _, application := range []*marathon.Application{
+		marathon.NewDockerApplication().Name("/prod/api").AddLabel("tier", "backend"),
+		marathon.NewDockerApplication().Name("/prod/web").AddLabel("tier", "frontend"),
+		marathon.NewDockerApplication().Name("/dev/api"),
+	} {
+		_, err := fake.CreateApplication(application)
+		require.NoError(t, err)
+	}
+
+	ids, err := fake.ListApplications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/dev/api", "/prod/api", "/prod/web"}, ids)
+
+	ids, err = fake.ListApplications(url.Values{"id": []string{"prod"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/prod/api", "/prod/web"}, ids)
+
+	ids, err = fake.ListApplications(url.Values{"label": []string{"tier==frontend"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/prod/web"}, ids)
+
+	ids, err = fake.ListApplications(marathon.NewAppsQuery().LabelNotEquals("tier", "frontend").Values())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/prod/api"}, ids)
+
+	ids, err = fake.ListApplications(marathon.NewAppsQuery().LabelIn("tier", "backend", "frontend").ID("prod").Values())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/prod/api", "/prod/web"}, ids)
+
+	ids, err = fake.ListApplications(marathon.NewAppsQuery().LabelNotIn("tier", "frontend", "batch").Values())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/prod/api"}, ids)
+
+	ids = nil
+	require.NoError(t, fake.EachApplication(url.Values{"id": []string{"api"}}, func(application *marathon.Application) error {
+		ids = append(ids, application.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"/dev/api", "/prod/api"}, ids)
+}
+
+func TestFakeKillTasks(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	_, err := fake.CreateApplication(marathon.NewDockerApplication().Name("/worker").Count(3))
+	require.NoError(t, err)
+
+	tasks, err := fake.Tasks("/worker")
+	require.NoError(t, err)
+	killed, err := fake.KillTask(tasks.Tasks[0].ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tasks.Tasks[0].ID, killed.ID)
+
+	// step: killed tasks are replaced unless scaling
+	replaced, err := fake.Tasks("/worker")
+	require.NoError(t, err)
+	assert.Len(t, replaced.Tasks, 3)
+	assert.NotEqual(t, tasks.Tasks[0].ID, replaced.Tasks[2].ID)
+
+	_, err = fake.KillTask(replaced.Tasks[0].ID, &marathon.KillTaskOpts{Scale: true})
+	require.NoError(t, err)
+	application, err := fake.Application("/worker")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *application.Instances)
+
+	_, err = fake.KillApplicationTasks("/worker", &marathon.KillApplicationTasksOpts{Scale: true})
+	require.NoError(t, err)
+	application, err = fake.Application("/worker")
+	require.NoError(t, err)
+	assert.Equal(t, 0, *application.Instances)
+	assert.Empty(t, application.Tasks)
+
+	_, err = fake.KillTask("missing.task", nil)
+	assertAPIError(t, err, marathon.ErrCodeNotFound)
+}
+
+func TestFakeGroups(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	// step: the identifiers of group members are relative to the group
+	group := marathon.NewApplicationGroup("/product").App(&marathon.Application{ID: "api"})
+	group.Groups = append(group.Groups, marathon.NewApplicationGroup("tools").
+		App(&marathon.Application{ID: "cron"}))
+	require.NoError(t, fake.CreateGroup(group))
+	assertAPIError(t, fake.CreateGroup(group), marathon.ErrCodeDuplicateID)
+
+	found, err := fake.Group("/product")
+	require.NoError(t, err)
+	require.Len(t, found.Apps, 1)
+	assert.Equal(t, "/product/api", found.Apps[0].ID)
+	require.Len(t, found.Groups, 1)
+	assert.Equal(t, "/product/tools", found.Groups[0].ID)
+	require.Len(t, found.Groups[0].Apps, 1)
+	assert.Equal(t, "/product/tools/cron", found.Groups[0].Apps[0].ID)
+
+	groups, err := fake.Groups()
+	require.NoError(t, err)
+	require.Len(t, groups.Groups, 1)
+	assert.Equal(t, "/product", groups.Groups[0].ID)
+
+	// step: a deploy creates or updates the whole tree
+	_, err = fake.DeployGroup(marathon.NewApplicationGroup("/product").
+		Subgroup(marathon.NewApplicationGroup("tools").App(&marathon.Application{ID: "backup"})), false)
+	require.NoError(t, err)
+	found, err = fake.Group("/product/tools")
+	require.NoError(t, err)
+	assert.Len(t, found.Apps, 2)
+
+	_, err = fake.DeleteGroup("/product", false)
+	require.NoError(t, err)
+	exists, err := fake.HasGroup("/product")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	ids, err := fake.ListApplications(nil)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func TestFakePods(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	pod := marathon.NewPod().Name("/cache").Count(2)
+	_, err := fake.CreatePod(pod)
+	require.NoError(t, err)
+	assert.True(t, fake.PodIsRunning("/cache"))
+
+	status, err := fake.PodStatus("/cache")
+	require.NoError(t, err)
+	assert.Equal(t, marathon.PodStateStable, status.Status)
+
+	_, err = fake.UpdatePod(pod.Count(3), false)
+	require.NoError(t, err)
+	versions, err := fake.PodVersions("/cache")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	previous, err := fake.PodByVersion("/cache", versions[1])
+	require.NoError(t, err)
+	assert.Equal(t, 2, previous.Scaling.Instances)
+
+	_, err = fake.DeletePod("/cache", false)
+	require.NoError(t, err)
+	assert.False(t, fake.PodIsRunning("/cache"))
+}
+
+func TestFakeEvents(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	events, err := fake.AddEventsListener(marathon.EventIDDeploymentSuccess)
+	require.NoError(t, err)
+	defer fake.RemoveEventsListener(events)
+
+	deployment, err := fake.UpdateApplication(marathon.NewDockerApplication().Name("/web"), false)
+	require.NoError(t, err)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "deployment_success", event.Name)
+		success, ok := event.Event.(*marathon.EventDeploymentSuccess)
+		require.True(t, ok)
+		assert.Equal(t, deployment.DeploymentID, success.ID)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the deployment event")
+	}
+	require.NoError(t, fake.WaitOnDeployment(deployment.DeploymentID, time.Second))
+}
+
+func TestFakeClose(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	events, err := fake.AddEventsListener(marathon.EventIDApplications)
+	require.NoError(t, err)
+
+	require.NoError(t, fake.Close())
+	select {
+	case _, open := <-events:
+		assert.False(t, open)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the events channel to close")
+	}
+}
+
+func TestFakeScoped(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	scoped := fake.Scoped("/team")
+	_, err := scoped.CreateApplication(&marathon.Application{ID: "api"})
+	require.NoError(t, err)
+
+	application, err := fake.Application("/team/api")
+	require.NoError(t, err)
+	assert.Equal(t, "/team/api", application.ID)
+
+	_, err = scoped.Application("/other/api")
+	assert.Equal(t, marathon.ErrOutsideScope, err)
+}
+
+func TestFakeArtifacts(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	location, err := fake.UploadArtifact("config/bundle.tgz", bytes.NewBufferString("bundle"))
+	require.NoError(t, err)
+	assert.Equal(t, marathontest.FakeMarathonURL+"/v2/artifacts/config/bundle.tgz", location)
+
+	artifact, err := fake.GetArtifact("/config/bundle.tgz")
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(artifact)
+	require.NoError(t, err)
+	assert.NoError(t, artifact.Close())
+	assert.Equal(t, "bundle", string(content))
+
+	require.NoError(t, fake.DeleteArtifact("config/bundle.tgz"))
+	_, err = fake.GetArtifact("config/bundle.tgz")
+	assertAPIError(t, err, marathon.ErrCodeNotFound)
+}
+
+func TestFakeScaleApplicationTracked(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	_, err := fake.CreateApplication(marathon.NewDockerApplication().Name("/web"))
+	require.NoError(t, err)
+
+	_, tracker, err := fake.ScaleApplication("/web", 3, &marathon.ScaleAppOpts{
+		Track: &marathon.LaunchTrackerOpts{Interval: time.Millisecond},
+	})
+	require.NoError(t, err)
+	select {
+	case <-tracker.Done():
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the instances to run")
+	}
+	samples := tracker.Samples()
+	require.NotEmpty(t, samples)
+	// step: the instance running before the scaling isn't part of its launch
+	assert.Equal(t, 2, samples[len(samples)-1].Running)
+	assert.True(t, samples[len(samples)-1].Finished)
+}
+
+func TestFakeSupports(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	version, err := fake.ServerVersion()
+	require.NoError(t, err)
+	assert.Equal(t, marathontest.FakeMarathonVersion, version)
+
+	supported, err := fake.Supports(marathon.FeatureNetworks)
+	require.NoError(t, err)
+	assert.True(t, supported)
+}
+
+func TestFakeRestartApplicationBy(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	_, err := fake.CreateApplication(marathon.NewDockerApplication().Name("/web"))
+	require.NoError(t, err)
+
+	deployment, err := fake.RestartApplicationBy("/web", &marathon.RestartAppOpts{Wait: true})
+	require.NoError(t, err)
+	assert.NotEmpty(t, deployment.DeploymentID)
+	_, err = fake.RestartApplicationBy("/missing", nil)
+	assert.Error(t, err)
+}
+
+func TestFakeScaleBy(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	_, err := fake.CreateApplication(marathon.NewDockerApplication().Name("/web").Count(2))
+	require.NoError(t, err)
+
+	_, _, err = fake.ScaleBy("/web", 3, nil)
+	require.NoError(t, err)
+	application, err := fake.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 5, *application.Instances)
+
+	_, _, err = fake.ScaleBy("/web", -10, &marathon.ScaleAppOpts{Wait: true})
+	require.NoError(t, err)
+	application, err = fake.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 0, *application.Instances)
+}
+
+func TestFakeUpdateApplicationBy(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	_, err := fake.CreateApplication(marathon.NewDockerApplication().Name("/web").Count(1))
+	require.NoError(t, err)
+
+	_, err = fake.UpdateApplicationBy(marathon.NewDockerApplication().Name("/web").Count(3), &marathon.UpdateAppOpts{Force: true})
+	require.NoError(t, err)
+	application, err := fake.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 3, *application.Instances)
+
+	versions, err := fake.ApplicationVersions("/web")
+	require.NoError(t, err)
+	_, err = fake.SetApplicationVersionBy("/web", &marathon.ApplicationVersion{Version: versions.Versions[len(versions.Versions)-1]}, nil)
+	require.NoError(t, err)
+	application, err = fake.Application("/web")
+	require.NoError(t, err)
+	assert.Equal(t, 1, *application.Instances)
+}
+
+func TestFakeDeleteApplicationBy(t *testing.T) {
+	fake := marathontest.NewFakeMarathon()
+	_, err := fake.CreateApplication(marathon.NewDockerApplication().Name("/web"))
+	require.NoError(t, err)
+
+	_, err = fake.DeleteApplicationBy("/web", nil)
+	require.NoError(t, err)
+	_, err = fake.DeleteApplicationBy("/web", nil)
+	assert.True(t, errors.Is(err, marathon.ErrNotFound))
+	_, err = fake.DeleteApplicationBy("/web", &marathon.DeleteAppOpts{IgnoreNotFound: true})
+	assert.NoError(t, err)
+}
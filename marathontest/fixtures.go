@@ -0,0 +1,287 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+// The fixtures below are modelled on the payloads returned by Marathon 1.4.
+
+const (
+	// AppID is the identifier of the application served by the default fixtures
+	AppID = "/fake-app"
+	// DeploymentID is the identifier of the deployment served by the default fixtures
+	DeploymentID = "867ed450-f6a8-4d33-9b0e-e11c5513990b"
+)
+
+// AppFixture is the definition of the application served by the default fixtures
+const AppFixture = `{
+    "id": "/fake-app",
+    "cmd": "python3 -m http.server 8080",
+    "args": null,
+    "user": null,
+    "env": {
+        "VAR": "VALUE"
+    },
+    "instances": 2,
+    "cpus": 0.5,
+    "mem": 64,
+    "disk": 0,
+    "gpus": 0,
+    "executor": "",
+    "constraints": [],
+    "uris": [],
+    "fetch": [],
+    "storeUrls": [],
+    "backoffSeconds": 1,
+    "backoffFactor": 1.15,
+    "maxLaunchDelaySeconds": 3600,
+    "container": {
+        "type": "DOCKER",
+        "volumes": [],
+        "docker": {
+            "image": "python:3",
+            "network": "BRIDGE",
+            "portMappings": [
+                {
+                    "containerPort": 8080,
+                    "hostPort": 0,
+                    "servicePort": 10000,
+                    "protocol": "tcp",
+                    "labels": {}
+                }
+            ],
+            "privileged": false,
+            "parameters": [],
+            "forcePullImage": false
+        }
+    },
+    "healthChecks": [
+        {
+            "gracePeriodSeconds": 300,
+            "intervalSeconds": 60,
+            "timeoutSeconds": 20,
+            "maxConsecutiveFailures": 3,
+            "portIndex": 0,
+            "path": "/",
+            "protocol": "HTTP",
+            "ignoreHttp1xx": false
+        }
+    ],
+    "readinessChecks": [],
+    "dependencies": [],
+    "upgradeStrategy": {
+        "minimumHealthCapacity": 1,
+        "maximumOverCapacity": 1
+    },
+    "labels": {},
+    "ipAddress": null,
+    "version": "2017-02-20T15:12:34.567Z",
+    "residency": null,
+    "secrets": {},
+    "taskKillGracePeriodSeconds": null,
+    "unreachableStrategy": {
+        "inactiveAfterSeconds": 300,
+        "expungeAfterSeconds": 600
+    },
+    "killSelection": "YOUNGEST_FIRST",
+    "ports": [
+        10000
+    ],
+    "portDefinitions": [
+        {
+            "port": 10000,
+            "protocol": "tcp",
+            "name": "default",
+            "labels": {}
+        }
+    ],
+    "requirePorts": false,
+    "versionInfo": {
+        "lastScalingAt": "2017-02-20T15:12:34.567Z",
+        "lastConfigChangeAt": "2017-02-20T15:12:34.567Z"
+    },
+    "tasksStaged": 0,
+    "tasksRunning": 2,
+    "tasksHealthy": 2,
+    "tasksUnhealthy": 0,
+    "deployments": [],
+    "tasks": [
+        {
+            "ipAddresses": [
+                {
+                    "ipAddress": "172.17.0.2",
+                    "protocol": "IPv4"
+                }
+            ],
+            "stagedAt": "2017-02-20T15:12:35.123Z",
+            "state": "TASK_RUNNING",
+            "ports": [
+                31045
+            ],
+            "startedAt": "2017-02-20T15:12:39.456Z",
+            "version": "2017-02-20T15:12:34.567Z",
+            "id": "fake-app.fa1c6f3a-f786-11e6-8b8e-0242ac110002",
+            "appId": "/fake-app",
+            "slaveId": "0ab1b3ee-9c1d-4a87-ae3d-28e1d8d8d4c8-S0",
+            "host": "10.0.0.10",
+            "healthCheckResults": [
+                {
+                    "alive": true,
+                    "consecutiveFailures": 0,
+                    "firstSuccess": "2017-02-20T15:12:45.789Z",
+                    "lastFailure": null,
+                    "lastSuccess": "2017-02-20T15:13:45.789Z",
+                    "lastFailureCause": null,
+                    "taskId": "fake-app.fa1c6f3a-f786-11e6-8b8e-0242ac110002"
+                }
+            ]
+        },
+        {
+            "ipAddresses": [
+                {
+                    "ipAddress": "172.17.0.3",
+                    "protocol": "IPv4"
+                }
+            ],
+            "stagedAt": "2017-02-20T15:12:35.321Z",
+            "state": "TASK_RUNNING",
+            "ports": [
+                31046
+            ],
+            "startedAt": "2017-02-20T15:12:40.654Z",
+            "version": "2017-02-20T15:12:34.567Z",
+            "id": "fake-app.fa1c9641-f786-11e6-8b8e-0242ac110002",
+            "appId": "/fake-app",
+            "slaveId": "0ab1b3ee-9c1d-4a87-ae3d-28e1d8d8d4c8-S1",
+            "host": "10.0.0.11",
+            "healthCheckResults": [
+                {
+                    "alive": true,
+                    "consecutiveFailures": 0,
+                    "firstSuccess": "2017-02-20T15:12:46.987Z",
+                    "lastFailure": null,
+                    "lastSuccess": "2017-02-20T15:13:46.987Z",
+                    "lastFailureCause": null,
+                    "taskId": "fake-app.fa1c9641-f786-11e6-8b8e-0242ac110002"
+                }
+            ]
+        }
+    ]
+}`
+
+// DeploymentFixture is the deployment served by the default fixtures
+const DeploymentFixture = `{
+    "id": "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+    "version": "2017-02-20T15:20:00.000Z",
+    "affectedApps": [
+        "/fake-app"
+    ],
+    "affectedPods": [],
+    "steps": [
+        {
+            "actions": [
+                {
+                    "action": "ScaleApplication",
+                    "app": "/fake-app"
+                }
+            ]
+        }
+    ],
+    "currentActions": [
+        {
+            "action": "ScaleApplication",
+            "app": "/fake-app",
+            "readinessCheckResults": []
+        }
+    ],
+    "currentStep": 1,
+    "totalSteps": 1
+}`
+
+// DeploymentSuccessEvent is a deployment_success event for the deployment of the default fixtures
+const DeploymentSuccessEvent = `{
+    "eventType": "deployment_success",
+    "timestamp": "2017-02-20T15:20:05.000Z",
+    "id": "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+    "plan": {
+        "id": "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+        "version": "2017-02-20T15:20:00.000Z",
+        "original": {
+            "id": "/",
+            "apps": [],
+            "groups": [],
+            "dependencies": []
+        },
+        "target": {
+            "id": "/",
+            "apps": [],
+            "groups": [],
+            "dependencies": []
+        },
+        "steps": [
+            {
+                "actions": [
+                    {
+                        "action": "ScaleApplication",
+                        "app": "/fake-app"
+                    }
+                ]
+            }
+        ]
+    }
+}`
+
+// StatusUpdateEvent is a status_update_event for a task of the application of the default fixtures
+const StatusUpdateEvent = `{
+    "eventType": "status_update_event",
+    "timestamp": "2017-02-20T15:12:39.456Z",
+    "slaveId": "0ab1b3ee-9c1d-4a87-ae3d-28e1d8d8d4c8-S0",
+    "taskId": "fake-app.fa1c6f3a-f786-11e6-8b8e-0242ac110002",
+    "taskStatus": "TASK_RUNNING",
+    "message": "",
+    "appId": "/fake-app",
+    "host": "10.0.0.10",
+    "ipAddresses": [
+        {
+            "ipAddress": "172.17.0.2",
+            "protocol": "IPv4"
+        }
+    ],
+    "ports": [
+        31045
+    ],
+    "version": "2017-02-20T15:12:34.567Z"
+}`
+
+const deploymentIDFixture = `{
+    "deploymentId": "867ed450-f6a8-4d33-9b0e-e11c5513990b",
+    "version": "2017-02-20T15:20:00.000Z"
+}`
+
+// defaultResponses are the canned responses served by a new Server
+var defaultResponses = map[string]Response{
+	responseKey("GET", "/ping"):                                          {Content: "pong"},
+	responseKey("GET", "/v2/apps"):                                       {Content: `{"apps": [` + AppFixture + `]}`},
+	responseKey("POST", "/v2/apps"):                                      {StatusCode: 201, Content: AppFixture},
+	responseKey("GET", "/v2/apps/fake-app"):                              {Content: `{"app": ` + AppFixture + `}`},
+	responseKey("PUT", "/v2/apps/fake-app"):                              {Content: deploymentIDFixture},
+	responseKey("PUT", "/v2/apps/fake-app?force=true"):                   {Content: deploymentIDFixture},
+	responseKey("DELETE", "/v2/apps/fake-app"):                           {Content: deploymentIDFixture},
+	responseKey("DELETE", "/v2/apps/fake-app?force=true"):                {Content: deploymentIDFixture},
+	responseKey("POST", "/v2/apps/fake-app/restart"):                     {Content: deploymentIDFixture},
+	responseKey("GET", "/v2/deployments"):                                {Content: `[` + DeploymentFixture + `]`},
+	responseKey("DELETE", "/v2/deployments/"+DeploymentID):               {Content: deploymentIDFixture},
+	responseKey("DELETE", "/v2/deployments/"+DeploymentID+"?force=true"): {StatusCode: 202},
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// RecorderMode is the mode a Recorder runs in
+type RecorderMode int
+
+const (
+	// RecorderModeReplay serves the interactions of the golden file, without any network access
+	RecorderModeReplay RecorderMode = iota
+	// RecorderModeRecord forwards the requests to Marathon and records the interactions
+	RecorderModeRecord
+)
+
+// ErrNoInteraction is returned on replay for a request with no recorded interaction
+var ErrNoInteraction = errors.New("no recorded interaction matches the request")
+
+// the response headers which are not worth recording
+var unrecordedHeaders = map[string]bool{
+	"Content-Encoding": true,
+	"Content-Length":   true,
+	"Date":             true,
+}
+
+// Interaction is a request to Marathon and its response, as stored in a golden file
+type Interaction struct {
+	Method       string            `json:"method"`
+	URI          string            `json:"uri"`
+	RequestBody  string            `json:"requestBody,omitempty"`
+	StatusCode   int               `json:"statusCode"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	ResponseBody string            `json:"responseBody,omitempty"`
+}
+
+// Recorder is a http.RoundTripper recording the interactions with a real Marathon to a golden
+// file, and replaying them offline. A request is matched on its method, URI and body; repeated
+// requests replay the matching interactions in order, the last one being served once they are
+// exhausted, so the polling of the waits replays the final state. The event stream cannot be
+// recorded: it is passed through on record and fails on replay.
+type Recorder struct {
+	sync.Mutex
+
+	path         string
+	mode         RecorderMode
+	transport    http.RoundTripper
+	interactions []Interaction
+	replayed     []bool
+	missed       []string
+}
+
+// NewRecorder creates a recorder of the golden file
+//		path:		the path of the golden file
+//		mode:		whether to record or replay the interactions
+//		transport:	the transport to Marathon on record, http.DefaultTransport if nil
+func NewRecorder(path string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+	}
+	if mode == RecorderModeReplay {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(content, &r.interactions); err != nil {
+			return nil, fmt.Errorf("failed to decode the golden file %s: %w", path, err)
+		}
+		r.replayed = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+// HTTPClient returns a HTTP client using the recorder, suitable for the client configuration
+func (r *Recorder) HTTPClient() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Interactions returns the interactions recorded or loaded so far
+func (r *Recorder) Interactions() []Interaction {
+	r.Lock()
+	defer r.Unlock()
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Missed returns the requests with no recorded interaction on replay. The client reports them as
+// the Marathon hosts being down, so the tests are best checking there are none.
+func (r *Recorder) Missed() []string {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string(nil), r.missed...)
+}
+
+// RoundTrip records or replays the request, depending on the mode of the recorder
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+	stream := strings.Contains(request.Header.Get("Accept"), "text/event-stream") ||
+		strings.HasSuffix(request.URL.Path, "/v2/events")
+	if r.mode == RecorderModeReplay {
+		if stream {
+			return nil, fmt.Errorf("%s %s: the event stream cannot be replayed", request.Method, request.URL.RequestURI())
+		}
+		return r.replay(request, body)
+	}
+	if stream {
+		return r.transport.RoundTrip(request)
+	}
+
+	return r.record(request, body)
+}
+
+// Save writes the recorded interactions to the golden file
+func (r *Recorder) Save() error {
+	if r.mode != RecorderModeRecord {
+		return nil
+	}
+	r.Lock()
+	content, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.path, append(content, '\n'), os.FileMode(0644))
+}
+
+func (r *Recorder) record(request *http.Request, body string) (*http.Response, error) {
+	// step: let the transport negotiate and undo the compression, so the golden file is readable
+	forwarded := request.Clone(request.Context())
+	forwarded.Header.Del("Accept-Encoding")
+
+	response, err := r.transport.RoundTrip(forwarded)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(content))
+
+	interaction := Interaction{
+		Method:       request.Method,
+		URI:          request.URL.RequestURI(),
+		RequestBody:  body,
+		StatusCode:   response.StatusCode,
+		Headers:      make(map[string]string),
+		ResponseBody: string(content),
+	}
+	for name := range response.Header {
+		if !unrecordedHeaders[name] {
+			interaction.Headers[name] = response.Header.Get(name)
+		}
+	}
+	r.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.Unlock()
+
+	return response, nil
+}
+
+func (r *Recorder) replay(request *http.Request, body string) (*http.Response, error) {
+	uri := request.URL.RequestURI()
+	r.Lock()
+	defer r.Unlock()
+	// step: serve the first unreplayed match, else the last match
+	found := -1
+	for i, interaction := range r.interactions {
+		if interaction.Method != request.Method || interaction.URI != uri || interaction.RequestBody != body {
+			continue
+		}
+		found = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if found < 0 {
+		r.missed = append(r.missed, request.Method+" "+uri)
+		return nil, fmt.Errorf("%s %s: %s", request.Method, uri, ErrNoInteraction)
+	}
+	r.replayed[found] = true
+	interaction := r.interactions[found]
+
+	response := &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.ResponseBody)),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       request,
+	}
+	for name, value := range interaction.Headers {
+		response.Header.Set(name, value)
+	}
+
+	return response, nil
+}
+
+// readRequestBody reads the body of the request, leaving it readable for the transport. The
+// compressed bodies are recorded and matched uncompressed.
+func readRequestBody(request *http.Request) (string, error) {
+	if request.Body == nil {
+		return "", nil
+	}
+	content, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(content))
+	if len(content) == 0 || !strings.EqualFold(request.Header.Get("Content-Encoding"), "gzip") {
+		return string(content), nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	content, err = ioutil.ReadAll(reader)
+
+	return string(content), err
+}
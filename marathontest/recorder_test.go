@@ -0,0 +1,111 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/gambol99/go-marathon/marathontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRecordedClient(t *testing.T, url string, recorder *marathontest.Recorder) marathon.Marathon {
+	config := marathon.NewDefaultConfig()
+	config.URL = url
+	config.HTTPClient = recorder.HTTPClient()
+	client, err := marathon.NewClient(config)
+	require.NoError(t, err)
+	return client
+}
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "applications.json")
+
+	// step: record the interactions with the server
+	server := marathontest.NewServer()
+	recorder, err := marathontest.NewRecorder(golden, marathontest.RecorderModeRecord, nil)
+	require.NoError(t, err)
+	client := newRecordedClient(t, server.URL, recorder)
+	recorded, err := client.Application(marathontest.AppID)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+	server.Close()
+
+	interactions := recorder.Interactions()
+	require.Len(t, interactions, 1)
+	assert.Equal(t, "GET", interactions[0].Method)
+	assert.True(t, strings.HasPrefix(interactions[0].URI, "/v2/apps/"))
+	assert.Equal(t, 200, interactions[0].StatusCode)
+	assert.Equal(t, "application/json", interactions[0].Headers["Content-Type"])
+
+	// step: replay them with the server gone
+	replayer, err := marathontest.NewRecorder(golden, marathontest.RecorderModeReplay, nil)
+	require.NoError(t, err)
+	client = newRecordedClient(t, server.URL, replayer)
+	replayed, err := client.Application(marathontest.AppID)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+
+	// step: the polling replays the last interaction
+	replayed, err = client.Application(marathontest.AppID)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+
+	assert.Empty(t, replayer.Missed())
+	_, err = client.Applications(nil)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"GET /v2/apps"}, replayer.Missed())
+}
+
+func TestRecorderReplayOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	golden := filepath.Join(dir, "ping.json")
+	content := `[
+  {"method": "GET", "uri": "/ping", "statusCode": 503, "responseBody": "unavailable"},
+  {"method": "GET", "uri": "/ping", "statusCode": 200, "responseBody": "pong"}
+]`
+	require.NoError(t, ioutil.WriteFile(golden, []byte(content), 0644))
+
+	replayer, err := marathontest.NewRecorder(golden, marathontest.RecorderModeReplay, nil)
+	require.NoError(t, err)
+	httpClient := replayer.HTTPClient()
+	for _, expected := range []int{503, 200, 200} {
+		response, err := httpClient.Get("http://marathon/ping")
+		require.NoError(t, err)
+		response.Body.Close()
+		assert.Equal(t, expected, response.StatusCode)
+	}
+	_, err = httpClient.Post("http://marathon/ping", "text/plain", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), marathontest.ErrNoInteraction.Error())
+}
+
+func TestRecorderMissingGoldenFile(t *testing.T) {
+	_, err := marathontest.NewRecorder("/does/not/exist.json", marathontest.RecorderModeReplay, nil)
+	assert.Error(t, err)
+}
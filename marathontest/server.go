@@ -0,0 +1,225 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package marathontest provides a fake Marathon server and an in-memory fake client for
+// testing code written against the go-marathon client.
+package marathontest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Request is a request received by the fake Marathon server
+type Request struct {
+	Method string
+	URI    string
+	Header http.Header
+	Body   []byte
+}
+
+// Response is a canned response served by the fake Marathon server
+type Response struct {
+	StatusCode int
+	Headers    map[string]string
+	Content    string
+}
+
+// Server is a fake Marathon server serving canned responses. Requests to unknown endpoints
+// receive a 404 Not Found response.
+type Server struct {
+	sync.RWMutex
+	// URL is the base URL of the server, suitable for the client configuration
+	URL string
+
+	httpSrv     *httptest.Server
+	responses   map[string]Response
+	requests    []Request
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber is a client connected to the event stream
+type subscriber struct {
+	events chan string
+	done   chan struct{}
+}
+
+// NewServer creates and starts a fake Marathon server serving the default fixtures
+func NewServer() *Server {
+	s := &Server{
+		responses:   make(map[string]Response),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	for key, response := range defaultResponses {
+		s.responses[key] = response
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/events", s.handleEvents)
+	mux.HandleFunc("/", s.handleRequest)
+	s.httpSrv = httptest.NewServer(mux)
+	s.URL = s.httpSrv.URL
+
+	return s
+}
+
+// Handle sets the canned response for the given method and request URI (including the query)
+//		method:		the HTTP method, e.g. GET
+//		uri:		the request URI, e.g. /v2/apps/my-app?embed=app.tasks
+//		response:	the response to serve
+func (s *Server) Handle(method, uri string, response Response) {
+	s.Lock()
+	defer s.Unlock()
+	s.responses[responseKey(method, uri)] = response
+}
+
+// Requests returns the requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.RLock()
+	defer s.RUnlock()
+	requests := make([]Request, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// ResetRequests forgets the requests received so far
+func (s *Server) ResetRequests() {
+	s.Lock()
+	defer s.Unlock()
+	s.requests = nil
+}
+
+// PublishEvent sends the JSON encoded event to all the clients subscribed to /v2/events
+//		event:		the event as sent by Marathon, e.g. the DeploymentSuccessEvent fixture
+func (s *Server) PublishEvent(event string) {
+	s.RLock()
+	var subscribers []*subscriber
+	for sub := range s.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	s.RUnlock()
+
+	for _, sub := range subscribers {
+		select {
+		case sub.events <- event:
+		case <-sub.done:
+		}
+	}
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.Lock()
+	for sub := range s.subscribers {
+		s.unsubscribe(sub)
+	}
+	s.Unlock()
+	s.httpSrv.CloseClientConnections()
+	s.httpSrv.Close()
+}
+
+func (s *Server) record(request *http.Request) {
+	body, _ := ioutil.ReadAll(request.Body)
+	s.Lock()
+	defer s.Unlock()
+	s.requests = append(s.requests, Request{
+		Method: request.Method,
+		URI:    request.RequestURI,
+		Header: request.Header,
+		Body:   body,
+	})
+}
+
+func (s *Server) handleRequest(writer http.ResponseWriter, request *http.Request) {
+	s.record(request)
+
+	s.RLock()
+	response, found := s.responses[responseKey(request.Method, request.RequestURI)]
+	s.RUnlock()
+	if !found {
+		http.Error(writer, `{"message": "not found"}`, http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	for k, v := range response.Headers {
+		writer.Header().Set(k, v)
+	}
+	statusCode := response.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	writer.WriteHeader(statusCode)
+	writer.Write([]byte(response.Content))
+}
+
+func (s *Server) handleEvents(writer http.ResponseWriter, request *http.Request) {
+	s.record(request)
+
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		http.Error(writer, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sub := &subscriber{
+		events: make(chan string),
+		done:   make(chan struct{}),
+	}
+	s.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.Unlock()
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case event := <-sub.events:
+			fmt.Fprint(writer, "event: event_stream_message\n")
+			for _, line := range strings.Split(event, "\n") {
+				fmt.Fprintf(writer, "data: %s\n", line)
+			}
+			fmt.Fprint(writer, "\n")
+			flusher.Flush()
+		case <-request.Context().Done():
+			s.Lock()
+			s.unsubscribe(sub)
+			s.Unlock()
+			return
+		case <-sub.done:
+			return
+		}
+	}
+}
+
+// unsubscribe removes the subscriber from the event stream, the lock must be held by the caller
+func (s *Server) unsubscribe(sub *subscriber) {
+	if _, found := s.subscribers[sub]; found {
+		close(sub.done)
+		delete(s.subscribers, sub)
+	}
+}
+
+func responseKey(method, uri string) string {
+	return method + " " + uri
+}
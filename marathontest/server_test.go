@@ -0,0 +1,124 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest_test
+
+import (
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/gambol99/go-marathon/marathontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, server *marathontest.Server) marathon.Marathon {
+	config := marathon.NewDefaultConfig()
+	config.URL = server.URL
+	config.EventsTransport = marathon.EventsTransportSSE
+	client, err := marathon.NewClient(config)
+	require.NoError(t, err)
+	return client
+}
+
+func TestServerApplications(t *testing.T) {
+	server := marathontest.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	applications, err := client.Applications(nil)
+	require.NoError(t, err)
+	require.Len(t, applications.Apps, 1)
+	assert.Equal(t, marathontest.AppID, applications.Apps[0].ID)
+
+	application, err := client.Application(marathontest.AppID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *application.Instances)
+	assert.Len(t, application.Tasks, 2)
+
+	_, err = client.Application("/missing")
+	assert.Error(t, err)
+
+	requests := server.Requests()
+	require.Len(t, requests, 3)
+	assert.Equal(t, "GET", requests[0].Method)
+	assert.Equal(t, "/v2/apps", requests[0].URI)
+	assert.Equal(t, "/v2/apps/missing", requests[2].URI)
+
+	server.ResetRequests()
+	assert.Empty(t, server.Requests())
+}
+
+func TestServerDeployments(t *testing.T) {
+	server := marathontest.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	deployments, err := client.Deployments()
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	assert.Equal(t, marathontest.DeploymentID, deployments[0].ID)
+	assert.Equal(t, []string{marathontest.AppID}, deployments[0].AffectedApps)
+
+	_, err = client.ScaleApplicationInstances(marathontest.AppID, 3, false)
+	require.NoError(t, err)
+	requests := server.Requests()
+	assert.Equal(t, "PUT", requests[len(requests)-1].Method)
+	assert.Contains(t, string(requests[len(requests)-1].Body), `"instances":3`)
+}
+
+func TestServerHandle(t *testing.T) {
+	server := marathontest.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	server.Handle("GET", "/v2/apps", marathontest.Response{Content: `{"apps": []}`})
+	applications, err := client.Applications(nil)
+	require.NoError(t, err)
+	assert.Empty(t, applications.Apps)
+
+	server.Handle("GET", "/v2/apps/fake-app", marathontest.Response{StatusCode: 403, Content: `{"message": "forbidden"}`})
+	_, err = client.Application(marathontest.AppID)
+	if assert.Error(t, err) {
+		assert.Equal(t, marathon.ErrCodeForbidden, err.(*marathon.APIError).ErrCode)
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	server := marathontest.NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	events, err := client.AddEventsListener(marathon.EventIDDeploymentSuccess)
+	require.NoError(t, err)
+
+	// wait for the event stream subscription to be set up
+	time.Sleep(100 * time.Millisecond)
+	server.PublishEvent(marathontest.StatusUpdateEvent)
+	server.PublishEvent(marathontest.DeploymentSuccessEvent)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "deployment_success", event.Name)
+		deployment, ok := event.Event.(*marathon.EventDeploymentSuccess)
+		if assert.True(t, ok) {
+			assert.Equal(t, marathontest.DeploymentID, deployment.ID)
+		}
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timed out waiting for the deployment event")
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"strings"
+	"time"
+)
+
+// RequestMetrics describes a completed request to the Marathon API
+type RequestMetrics struct {
+	// Method is the HTTP method of the request
+	Method string
+	// Endpoint is the API endpoint of the request, e.g. /v2/apps. The identifiers of the resources
+	// are left out, keeping the cardinality of the endpoints low
+	Endpoint string
+	// StatusCode is the HTTP status code of the last response, zero when no response was received
+	StatusCode int
+	// Retries is the number of times the request was retried on another member of the cluster
+	Retries int
+	// Duration is the time taken by the request, retries included
+	Duration time.Duration
+}
+
+// Instrumentation is invoked on every request made by the client, e.g. to export request
+// histograms. See the prometheus subpackage for a ready-made adapter
+type Instrumentation interface {
+	// ObserveRequest is called once the request completed, successfully or not
+	ObserveRequest(metrics RequestMetrics)
+}
+
+// noopInstrumentation ignores all the requests
+type noopInstrumentation struct{}
+
+func (noopInstrumentation) ObserveRequest(RequestMetrics) {}
+
+// metricsEndpoint returns the API endpoint of the path, i.e. its first two segments
+func metricsEndpoint(path string) string {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.SplitN(strings.Trim(path, "/"), "/", 3)
+	if len(segments) > 2 {
+		segments = segments[:2]
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingInstrumentation struct {
+	sync.Mutex
+	requests []RequestMetrics
+}
+
+func (i *recordingInstrumentation) ObserveRequest(metrics RequestMetrics) {
+	i.Lock()
+	defer i.Unlock()
+	i.requests = append(i.requests, metrics)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"":                                    "/",
+		"ping":                                "/ping",
+		"v2/apps":                             "/v2/apps",
+		"v2/apps?embed=apps.tasks":            "/v2/apps",
+		"v2/apps/fake-app/tasks?force=true":   "/v2/apps",
+		"/v2/groups/fake-group/fake-subgroup": "/v2/groups",
+	}
+	for path, expected := range cases {
+		assert.Equal(t, expected, metricsEndpoint(path), path)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	instrumentation := new(recordingInstrumentation)
+	config := NewDefaultConfig()
+	config.Instrumentation = instrumentation
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Application(fakeAppName)
+	require.NoError(t, err)
+	_, err = endpoint.Client.Application("/not/there")
+	require.Error(t, err)
+
+	require.Len(t, instrumentation.requests, 2)
+	assert.Equal(t, "GET", instrumentation.requests[0].Method)
+	assert.Equal(t, "/v2/apps", instrumentation.requests[0].Endpoint)
+	assert.Equal(t, http.StatusOK, instrumentation.requests[0].StatusCode)
+	assert.Equal(t, 0, instrumentation.requests[0].Retries)
+	assert.True(t, instrumentation.requests[0].Duration > 0)
+	assert.Equal(t, http.StatusNotFound, instrumentation.requests[1].StatusCode)
+}
+
+func TestInstrumentationRetries(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "unavailable"}`, http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"leader": "127.0.0.1:8080"}`))
+	}))
+	defer healthy.Close()
+
+	instrumentation := new(recordingInstrumentation)
+	config := NewDefaultConfig()
+	config.URL = failing.URL + "," + healthy.URL
+	config.Instrumentation = instrumentation
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Leader()
+	require.NoError(t, err)
+
+	require.Len(t, instrumentation.requests, 1)
+	assert.Equal(t, "/v2/leader", instrumentation.requests[0].Endpoint)
+	assert.Equal(t, http.StatusOK, instrumentation.requests[0].StatusCode)
+	assert.Equal(t, 1, instrumentation.requests[0].Retries)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "net/http"
+
+// Doer performs an HTTP request, e.g. an *http.Client
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to a Doer
+type DoerFunc func(request *http.Request) (*http.Response, error)
+
+// Do calls the function with the request
+func (f DoerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Middleware wraps the Doer performing the requests of the client, e.g. to add headers, audit or
+// sign the requests, or inject failures. The middleware may answer the request itself without
+// calling the next Doer.
+type Middleware func(next Doer) Doer
+
+// chainMiddleware wraps the doer in the middleware, the first middleware being the outermost
+func chainMiddleware(doer Doer, middleware []Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		doer = middleware[i](doer)
+	}
+	return doer
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = append(headers, r.Header.Get("X-Audit"))
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(request *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				request.Header.Set("X-Audit", name)
+				return next.Do(request)
+			})
+		}
+	}
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.Middleware = []Middleware{trace("outer"), trace("inner")}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, calls)
+	assert.Equal(t, []string{"inner"}, headers)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	// step: the middleware answers the request without reaching Marathon
+	client := endpoint.Client.(*marathonClient)
+	client.client.config.Middleware = []Middleware{func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusForbidden,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message": "chaos"}`)),
+				Request:    request,
+			}, nil
+		})
+	}}
+
+	_, err := client.Application(fakeAppName)
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok, "unexpected error: %s", err)
+	assert.Equal(t, ErrCodeForbidden, apiErr.ErrCode)
+	assert.Equal(t, "chaos", apiErr.message)
+}
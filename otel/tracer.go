@@ -0,0 +1,101 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package otel traces the API calls of the go-marathon client with OpenTelemetry.
+//
+//	config := marathon.NewDefaultConfig()
+//	config.Tracer = otel.NewTracer(nil, nil)
+package otel
+
+import (
+	"context"
+	"net/http"
+
+	marathon "github.com/gambol99/go-marathon"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer of the client
+const instrumentationName = "github.com/gambol99/go-marathon"
+
+// Tracer is a marathon.Tracer creating an OpenTelemetry client span for each API call
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	// the context the spans are started in
+	parent func() context.Context
+}
+
+// make sure the adapter can be set on the client config
+var _ marathon.Tracer = &Tracer{}
+
+// NewTracer creates a tracer, using the global tracer provider and propagator when nil
+//		provider:	the provider of the tracer the spans are created with
+//		propagator:	the propagator injecting the trace headers into the requests to Marathon
+func NewTracer(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *Tracer {
+	if provider == nil {
+		provider = otelglobal.GetTracerProvider()
+	}
+	if propagator == nil {
+		propagator = otelglobal.GetTextMapPropagator()
+	}
+	return &Tracer{
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagator,
+		parent:     context.Background,
+	}
+}
+
+// WithParent sets the function providing the context the spans are started in, e.g. to attach
+// the spans of the client to the trace of a long-running worker
+//		parent:		returns the parent context of the next span
+func (t *Tracer) WithParent(parent func() context.Context) *Tracer {
+	t.parent = parent
+	return t
+}
+
+// StartSpan starts the client span of an API call
+func (t *Tracer) StartSpan(operation string) marathon.Span {
+	ctx, span := t.tracer.Start(t.parent(), operation, trace.WithSpanKind(trace.SpanKindClient))
+	return &otelSpan{ctx: ctx, span: span, propagator: t.propagator}
+}
+
+// otelSpan adapts an OpenTelemetry span to a marathon.Span
+type otelSpan struct {
+	ctx        context.Context
+	span       trace.Span
+	propagator propagation.TextMapPropagator
+}
+
+func (s *otelSpan) Inject(header http.Header) {
+	s.propagator.Inject(s.ctx, propagation.HeaderCarrier(header))
+}
+
+func (s *otelSpan) SetAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+func (s *otelSpan) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		if r.URL.Path == "/v2/apps/fake-app" {
+			w.Write([]byte(`{"deploymentId": "83b215a6", "version": "2014-08-26T07:37:50.462Z"}`))
+			return
+		}
+		http.Error(w, `{"message": "not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	config := marathon.NewDefaultConfig()
+	config.URL = server.URL
+	config.Tracer = NewTracer(provider, propagation.TraceContext{})
+	client, err := marathon.NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.ScaleApplicationInstances("/fake-app", 2, false)
+	require.NoError(t, err)
+	_, err = client.Application("/not/there")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "marathon.ScaleApplicationInstances", spans[0].Name())
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind())
+	attributes := map[string]string{}
+	for _, attribute := range spans[0].Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.AsString()
+	}
+	assert.Equal(t, "/fake-app", attributes[marathon.SpanAttributeAppID])
+	assert.Equal(t, "83b215a6", attributes[marathon.SpanAttributeDeploymentID])
+
+	assert.Equal(t, "marathon.Application", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	// step: the trace of the last call was propagated to Marathon
+	assert.True(t, strings.Contains(traceparent, spans[1].SpanContext().TraceID().String()))
+}
+
+func TestTracerWithParent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"leader": "127.0.0.1:8080"}`))
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	parent, span := provider.Tracer("test").Start(context.Background(), "worker")
+	defer span.End()
+
+	config := marathon.NewDefaultConfig()
+	config.URL = server.URL
+	config.Tracer = NewTracer(provider, propagation.TraceContext{}).WithParent(func() context.Context {
+		return parent
+	})
+	client, err := marathon.NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Leader()
+	require.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, span.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
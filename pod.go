@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -26,6 +27,7 @@ type Pod struct {
 	Labels  map[string]string `json:"labels,omitempty"`
 	Version string            `json:"version,omitempty"`
 	User    string            `json:"user,omitempty"`
+	Role    string            `json:"role,omitempty"`
 	// Non-secret environment variables. Actual secrets are stored in Secrets
 	// Magic happens at marshaling/unmarshaling to get them into the correct schema
 	Env               map[string]string    `json:"-"`
@@ -69,6 +71,12 @@ func (p *Pod) SetUser(user string) *Pod {
 	return p
 }
 
+// SetRole sets the Mesos role the resources of the pod are reserved for, available since Marathon 1.9
+func (p *Pod) SetRole(role string) *Pod {
+	p.Role = role
+	return p
+}
+
 // EmptyLabels empties the labels in a pod
 func (p *Pod) EmptyLabels() *Pod {
 	p.Labels = make(map[string]string)
@@ -179,10 +187,10 @@ func (p *Pod) SetExecutorResources(resources *ExecutorResources) *Pod {
 // SupportsPods determines if this version of marathon supports pods
 // If HEAD returns 200 it does
 func (r *marathonClient) SupportsPods() (bool, error) {
-	if err := r.apiHead(marathonAPIPods, nil); err != nil {
+	if err := r.apiHead("SupportsPods", marathonAPIPods, nil); err != nil {
 		// If we get a 404 we can return a strict false, otherwise it could be
 		// a valid error
-		if apiErr, ok := err.(*APIError); ok && apiErr.ErrCode == ErrCodeNotFound {
+		if errors.Is(err, ErrNotFound) {
 			return false, nil
 		}
 		return false, err
@@ -195,7 +203,7 @@ func (r *marathonClient) SupportsPods() (bool, error) {
 func (r *marathonClient) Pod(name string) (*Pod, error) {
 	uri := buildPodURI(name)
 	result := new(Pod)
-	if err := r.apiGet(uri, nil, result); err != nil {
+	if err := r.apiGet("Pod", uri, nil, result); err != nil {
 		return nil, err
 	}
 
@@ -205,8 +213,8 @@ func (r *marathonClient) Pod(name string) (*Pod, error) {
 // Pods gets all pods from marathon
 func (r *marathonClient) Pods() ([]Pod, error) {
 	var result []Pod
-	if err := r.apiGet(marathonAPIPods, nil, &result); err != nil {
-		return nil, err
+	if err := r.apiGet("Pods", marathonAPIPods, nil, &result); err != nil {
+		return nil, r.unsupportedFeature(FeaturePods, err)
 	}
 
 	return result, nil
@@ -215,8 +223,8 @@ func (r *marathonClient) Pods() ([]Pod, error) {
 // CreatePod creates a new pod in Marathon
 func (r *marathonClient) CreatePod(pod *Pod) (*Pod, error) {
 	result := new(Pod)
-	if err := r.apiPost(marathonAPIPods, &pod, result); err != nil {
-		return nil, err
+	if err := r.apiPost("CreatePod", marathonAPIPods, &pod, result); err != nil {
+		return nil, r.unsupportedFeature(FeaturePods, err)
 	}
 
 	return result, nil
@@ -227,7 +235,7 @@ func (r *marathonClient) DeletePod(name string, force bool) (*DeploymentID, erro
 	uri := fmt.Sprintf("%s?force=%v", buildPodURI(name), force)
 
 	deployID := new(DeploymentID)
-	if err := r.apiDelete(uri, nil, deployID); err != nil {
+	if err := r.apiDelete("DeletePod", uri, nil, deployID); err != nil {
 		return nil, err
 	}
 
@@ -239,8 +247,8 @@ func (r *marathonClient) UpdatePod(pod *Pod, force bool) (*Pod, error) {
 	uri := fmt.Sprintf("%s?force=%v", buildPodURI(pod.ID), force)
 	result := new(Pod)
 
-	if err := r.apiPut(uri, pod, result); err != nil {
-		return nil, err
+	if err := r.apiPut("UpdatePod", uri, pod, result); err != nil {
+		return nil, r.unsupportedFeature(FeaturePods, err)
 	}
 
 	return result, nil
@@ -250,7 +258,7 @@ func (r *marathonClient) UpdatePod(pod *Pod, force bool) (*Pod, error) {
 func (r *marathonClient) PodVersions(name string) ([]string, error) {
 	uri := buildPodVersionURI(name)
 	var result []string
-	if err := r.apiGet(uri, nil, &result); err != nil {
+	if err := r.apiGet("PodVersions", uri, nil, &result); err != nil {
 		return nil, err
 	}
 
@@ -261,7 +269,7 @@ func (r *marathonClient) PodVersions(name string) ([]string, error) {
 func (r *marathonClient) PodByVersion(name, version string) (*Pod, error) {
 	uri := fmt.Sprintf("%s/%s", buildPodVersionURI(name), version)
 	result := new(Pod)
-	if err := r.apiGet(uri, nil, result); err != nil {
+	if err := r.apiGet("PodByVersion", uri, nil, result); err != nil {
 		return nil, err
 	}
 
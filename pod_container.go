@@ -31,6 +31,8 @@ type PodContainer struct {
 	Artifacts    []*PodArtifact     `json:"artifacts,omitempty"`
 	Labels       map[string]string  `json:"labels,omitempty"`
 	Lifecycle    PodLifecycle       `json:"lifecycle,omitempty"`
+	LinuxInfo    *LinuxInfo         `json:"linuxInfo,omitempty"`
+	TTY          *bool              `json:"tty,omitempty"`
 }
 
 // PodLifecycle describes the lifecycle of a pod
@@ -162,6 +164,13 @@ func (p *PodContainer) SetUser(user string) *PodContainer {
 	return p
 }
 
+// SetTTY sets whether the pod container gets a pseudo-terminal
+//		tty:	true / false
+func (p *PodContainer) SetTTY(tty bool) *PodContainer {
+	p.TTY = &tty
+	return p
+}
+
 // SetHealthCheck sets the health check of a pod container
 func (p *PodContainer) SetHealthCheck(healthcheck *PodHealthCheck) *PodContainer {
 	p.HealthCheck = healthcheck
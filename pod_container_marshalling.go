@@ -35,7 +35,7 @@ func (p *PodContainer) UnmarshalJSON(b []byte) error {
 		PodContainerAlias: (*PodContainerAlias)(p),
 	}
 	if err := json.Unmarshal(b, aux); err != nil {
-		return fmt.Errorf("malformed pod container definition %v", err)
+		return fmt.Errorf("malformed pod container definition %w", err)
 	}
 	env := map[string]string{}
 	secrets := map[string]Secret{}
@@ -82,7 +82,7 @@ type PodNetworkInfo struct {
 func (r *marathonClient) DeletePodInstances(name string, instances []string) ([]*PodInstance, error) {
 	uri := buildPodInstancesURI(name)
 	var result []*PodInstance
-	if err := r.apiDelete(uri, instances, &result); err != nil {
+	if err := r.apiDelete("DeletePodInstances", uri, instances, &result); err != nil {
 		return nil, err
 	}
 
@@ -93,7 +93,7 @@ func (r *marathonClient) DeletePodInstances(name string, instances []string) ([]
 func (r *marathonClient) DeletePodInstance(name, instance string) (*PodInstance, error) {
 	uri := fmt.Sprintf("%s/%s", buildPodInstancesURI(name), instance)
 	result := new(PodInstance)
-	if err := r.apiDelete(uri, nil, result); err != nil {
+	if err := r.apiDelete("DeletePodInstance", uri, nil, result); err != nil {
 		return nil, err
 	}
 
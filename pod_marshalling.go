@@ -36,7 +36,7 @@ func (p *Pod) UnmarshalJSON(b []byte) error {
 		PodAlias: (*PodAlias)(p),
 	}
 	if err := json.Unmarshal(b, aux); err != nil {
-		return fmt.Errorf("malformed pod definition %v", err)
+		return fmt.Errorf("malformed pod definition %w", err)
 	}
 	env := map[string]string{}
 	secrets := map[string]Secret{}
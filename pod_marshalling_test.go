@@ -100,3 +100,14 @@ func TestPodEnvironmentVariableMarshal(t *testing.T) {
 		assert.Equal(t, targetString, pod)
 	}
 }
+
+func TestPodContainerTTYMarshal(t *testing.T) {
+	var container PodContainer
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "debug", "tty": true}`), &container))
+	require.NotNil(t, container.TTY)
+	assert.True(t, *container.TTY)
+
+	content, err := json.Marshal(NewPodContainer().SetName("debug").SetTTY(true))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"tty":true`)
+}
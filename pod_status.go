@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -68,7 +69,7 @@ type ContainerTerminationHistory struct {
 func (r *marathonClient) PodStatus(name string) (*PodStatus, error) {
 	var podStatus PodStatus
 
-	if err := r.apiGet(buildPodStatusURI(name), nil, &podStatus); err != nil {
+	if err := r.apiGet("PodStatus", buildPodStatusURI(name), nil, &podStatus); err != nil {
 		return nil, err
 	}
 
@@ -79,8 +80,8 @@ func (r *marathonClient) PodStatus(name string) (*PodStatus, error) {
 func (r *marathonClient) PodStatuses() ([]*PodStatus, error) {
 	var podStatuses []*PodStatus
 
-	if err := r.apiGet(buildPodStatusURI(""), nil, &podStatuses); err != nil {
-		return nil, err
+	if err := r.apiGet("PodStatuses", buildPodStatusURI(""), nil, &podStatuses); err != nil {
+		return nil, r.unsupportedFeature(FeaturePods, err)
 	}
 
 	return podStatuses, nil
@@ -94,7 +95,7 @@ func (r *marathonClient) WaitOnPod(name string, timeout time.Duration) error {
 // PodIsRunning returns whether the pod is stably running
 func (r *marathonClient) PodIsRunning(name string) bool {
 	podStatus, err := r.PodStatus(name)
-	if apiErr, ok := err.(*APIError); ok && apiErr.ErrCode == ErrCodeNotFound {
+	if errors.Is(err, ErrNotFound) {
 		return false
 	}
 	if err == nil && podStatus.Status == PodStateStable {
@@ -0,0 +1,70 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"math/rand"
+	"time"
+)
+
+// poller paces the polls of a wait, growing the wait time between the polls by the backoff
+// factor up to the maximum wait time, and randomizing each wait time by the jitter
+type poller struct {
+	// the wait time before the next poll, with no jitter
+	waitTime time.Duration
+	// the maximum wait time, zero for none
+	maxWaitTime time.Duration
+	// the growth factor of the wait time, no growth when not above 1
+	factor float64
+	// the fraction of the wait time randomly added or removed, between 0 and 1
+	jitter float64
+}
+
+// newPoller creates a poller of the wait with the polling options of the config
+//		waitTime:	the initial wait time between the polls
+func newPoller(config Config, waitTime time.Duration) *poller {
+	p := &poller{
+		waitTime:    waitTime,
+		maxWaitTime: config.PollingMaxWaitTime,
+		factor:      config.PollingBackoffFactor,
+		jitter:      config.PollingJitter,
+	}
+	if p.jitter < 0 {
+		p.jitter = 0
+	} else if p.jitter > 1 {
+		p.jitter = 1
+	}
+	if p.maxWaitTime > 0 && p.waitTime > p.maxWaitTime {
+		p.waitTime = p.maxWaitTime
+	}
+	return p
+}
+
+// next returns the wait time before the next poll
+func (p *poller) next() time.Duration {
+	waitTime := p.waitTime
+	if p.jitter > 0 {
+		waitTime += time.Duration((2*rand.Float64() - 1) * p.jitter * float64(waitTime))
+	}
+	if p.factor > 1 {
+		p.waitTime = time.Duration(float64(p.waitTime) * p.factor)
+		if p.maxWaitTime > 0 && p.waitTime > p.maxWaitTime {
+			p.waitTime = p.maxWaitTime
+		}
+	}
+	return waitTime
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollerConstant(t *testing.T) {
+	poll := newPoller(NewDefaultConfig(), time.Second)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Second, poll.next())
+	}
+}
+
+func TestPollerBackoff(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingBackoffFactor = 2
+	config.PollingMaxWaitTime = 5 * time.Second
+	poll := newPoller(config, time.Second)
+
+	// step: the wait time doubles up to the maximum wait time
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		assert.Equal(t, expected, poll.next())
+	}
+
+	// step: the initial wait time is capped as well
+	assert.Equal(t, 5*time.Second, newPoller(config, time.Minute).next())
+}
+
+func TestPollerJitter(t *testing.T) {
+	config := NewDefaultConfig()
+	config.PollingJitter = 0.5
+	poll := newPoller(config, time.Second)
+	for i := 0; i < 100; i++ {
+		waitTime := poll.next()
+		assert.True(t, waitTime >= 500*time.Millisecond && waitTime <= 1500*time.Millisecond, "wait time %s", waitTime)
+	}
+
+	// step: the jitter is at most the wait time
+	config.PollingJitter = 2
+	assert.Equal(t, 1.0, newPoller(config, time.Second).jitter)
+}
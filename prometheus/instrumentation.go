@@ -0,0 +1,93 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package prometheus exports the requests made by the go-marathon client as Prometheus metrics.
+//
+//	instrumentation := prometheus.NewInstrumentation("myapp")
+//	prom.MustRegister(instrumentation)
+//
+//	config := marathon.NewDefaultConfig()
+//	config.Instrumentation = instrumentation
+package prometheus
+
+import (
+	"strconv"
+
+	marathon "github.com/gambol99/go-marathon"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const subsystem = "marathon_client"
+
+// Instrumentation is a marathon.Instrumentation recording a histogram of the request durations
+// and a counter of the retries, labelled by method, endpoint and status code. It is a Prometheus
+// collector, to be registered with the registry of the application
+type Instrumentation struct {
+	durations *prom.HistogramVec
+	retries   *prom.CounterVec
+}
+
+// make sure the adapter can be set on the client config and registered
+var (
+	_ marathon.Instrumentation = &Instrumentation{}
+	_ prom.Collector           = &Instrumentation{}
+)
+
+// NewInstrumentation creates the instrumentation, the metrics being named
+// <namespace>_marathon_client_request_duration_seconds and
+// <namespace>_marathon_client_request_retries_total
+//		namespace:	the namespace of the metrics, usually the name of the application
+func NewInstrumentation(namespace string) *Instrumentation {
+	return &Instrumentation{
+		durations: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "The duration of the requests to the Marathon API, retries included.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"method", "endpoint", "code"}),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_retries_total",
+			Help:      "The number of times the requests were retried on another member of the Marathon cluster.",
+		}, []string{"method", "endpoint"}),
+	}
+}
+
+// ObserveRequest records the request
+func (i *Instrumentation) ObserveRequest(metrics marathon.RequestMetrics) {
+	code := "none"
+	if metrics.StatusCode != 0 {
+		code = strconv.Itoa(metrics.StatusCode)
+	}
+	i.durations.WithLabelValues(metrics.Method, metrics.Endpoint, code).Observe(metrics.Duration.Seconds())
+	if metrics.Retries > 0 {
+		i.retries.WithLabelValues(metrics.Method, metrics.Endpoint).Add(float64(metrics.Retries))
+	}
+}
+
+// Describe sends the descriptors of the metrics to the channel
+func (i *Instrumentation) Describe(ch chan<- *prom.Desc) {
+	i.durations.Describe(ch)
+	i.retries.Describe(ch)
+}
+
+// Collect sends the metrics to the channel
+func (i *Instrumentation) Collect(ch chan<- prom.Metric) {
+	i.durations.Collect(ch)
+	i.retries.Collect(ch)
+}
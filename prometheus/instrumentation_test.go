@@ -0,0 +1,77 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumentation(t *testing.T) {
+	instrumentation := NewInstrumentation("test")
+	registry := prom.NewRegistry()
+	require.NoError(t, registry.Register(instrumentation))
+
+	instrumentation.ObserveRequest(marathon.RequestMetrics{
+		Method:     "GET",
+		Endpoint:   "/v2/apps",
+		StatusCode: 200,
+		Duration:   100 * time.Millisecond,
+	})
+	instrumentation.ObserveRequest(marathon.RequestMetrics{
+		Method:     "GET",
+		Endpoint:   "/v2/apps",
+		StatusCode: 200,
+		Retries:    2,
+		Duration:   300 * time.Millisecond,
+	})
+	instrumentation.ObserveRequest(marathon.RequestMetrics{
+		Method:   "PUT",
+		Endpoint: "/v2/apps",
+		Retries:  1,
+		Duration: time.Second,
+	})
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 2)
+
+	durations := families[0]
+	assert.Equal(t, "test_marathon_client_request_duration_seconds", durations.GetName())
+	counts := map[string]uint64{}
+	for _, metric := range durations.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		counts[labels["method"]+" "+labels["endpoint"]+" "+labels["code"]] = metric.GetHistogram().GetSampleCount()
+	}
+	assert.Equal(t, map[string]uint64{"GET /v2/apps 200": 2, "PUT /v2/apps none": 1}, counts)
+
+	retries := families[1]
+	assert.Equal(t, "test_marathon_client_request_retries_total", retries.GetName())
+	total := 0.0
+	for _, metric := range retries.GetMetric() {
+		total += metric.GetCounter().GetValue()
+	}
+	assert.Equal(t, 3.0, total)
+}
@@ -27,9 +27,118 @@ type Queue struct {
 
 // Item is the definition of element in the queue
 type Item struct {
-	Count       int         `json:"count"`
-	Delay       Delay       `json:"delay"`
-	Application Application `json:"app"`
+	Count                  int                     `json:"count"`
+	Delay                  Delay                   `json:"delay"`
+	Application            Application             `json:"app"`
+	ProcessedOffersSummary *ProcessedOffersSummary `json:"processedOffersSummary,omitempty"`
+	// LastUnusedOffers are the last offers declined for the item, embedded with
+	// QueueEmbedLastUnusedOffers
+	LastUnusedOffers []UnusedOffer `json:"lastUnusedOffers,omitempty"`
+}
+
+// QueueOpts contains a payload for QueueBy method
+//		embed:		Embeds the resources of the items, e.g. QueueEmbedLastUnusedOffers
+type QueueOpts struct {
+	Embed []string `url:"embed,omitempty"`
+}
+
+// QueueEmbedLastUnusedOffers embeds the last offers declined for the items of the queue
+const QueueEmbedLastUnusedOffers = "lastUnusedOffers"
+
+// OfferRejectionReason is the reason an offer was declined to launch the instances of a queue item
+type OfferRejectionReason string
+
+const (
+	// RejectionUnfulfilledRole is the reason of the offers of resources of no accepted role
+	RejectionUnfulfilledRole OfferRejectionReason = "UnfulfilledRole"
+	// RejectionUnfulfilledConstraint is the reason of the offers not satisfying the constraints
+	RejectionUnfulfilledConstraint OfferRejectionReason = "UnfulfilledConstraint"
+	// RejectionNoCorrespondingReservationFound is the reason of the offers with no reservation of
+	// a resident task
+	RejectionNoCorrespondingReservationFound OfferRejectionReason = "NoCorrespondingReservationFound"
+	// RejectionAgentMaintenance is the reason of the offers of the agents in maintenance
+	RejectionAgentMaintenance OfferRejectionReason = "AgentMaintenance"
+	// RejectionInsufficientCpus is the reason of the offers with too few cpus
+	RejectionInsufficientCpus OfferRejectionReason = "InsufficientCpus"
+	// RejectionInsufficientMemory is the reason of the offers with too little memory
+	RejectionInsufficientMemory OfferRejectionReason = "InsufficientMemory"
+	// RejectionInsufficientDisk is the reason of the offers with too little disk
+	RejectionInsufficientDisk OfferRejectionReason = "InsufficientDisk"
+	// RejectionInsufficientGpus is the reason of the offers with too few gpus
+	RejectionInsufficientGpus OfferRejectionReason = "InsufficientGpus"
+	// RejectionInsufficientPorts is the reason of the offers with too few ports
+	RejectionInsufficientPorts OfferRejectionReason = "InsufficientPorts"
+	// RejectionDeclinedScarceResources is the reason of the offers of scarce resources, e.g. gpus,
+	// declined for the applications not using them
+	RejectionDeclinedScarceResources OfferRejectionReason = "DeclinedScarceResources"
+)
+
+// UnusedOffer is an offer declined to launch the instances of a queue item
+type UnusedOffer struct {
+	Offer     Offer                  `json:"offer"`
+	Timestamp string                 `json:"timestamp"`
+	Reason    []OfferRejectionReason `json:"reason"`
+}
+
+// Offer is an offer of resources of an agent
+type Offer struct {
+	ID         string           `json:"id"`
+	AgentID    string           `json:"agentId"`
+	Hostname   string           `json:"hostname"`
+	Resources  []OfferResource  `json:"resources"`
+	Attributes []OfferAttribute `json:"attributes"`
+}
+
+// OfferResource is a resource of an offer, either a scalar, ranges or a set
+type OfferResource struct {
+	Name   string        `json:"name"`
+	Role   string        `json:"role,omitempty"`
+	Scalar *float64      `json:"scalar,omitempty"`
+	Ranges []NumberRange `json:"ranges,omitempty"`
+	Set    []string      `json:"set,omitempty"`
+}
+
+// OfferAttribute is an attribute of the agent of an offer, either a text, a scalar, ranges or a set
+type OfferAttribute struct {
+	Name   string        `json:"name"`
+	Text   *string       `json:"text,omitempty"`
+	Scalar *float64      `json:"scalar,omitempty"`
+	Ranges []NumberRange `json:"ranges,omitempty"`
+	Set    []string      `json:"set,omitempty"`
+}
+
+// NumberRange is a range of numbers, e.g. of ports
+type NumberRange struct {
+	Begin int64 `json:"begin"`
+	End   int64 `json:"end"`
+}
+
+// RejectionReasons counts the reasons the last unused offers of the item were declined for, e.g.
+// to tell why its instances aren't launching
+func (r *Item) RejectionReasons() map[OfferRejectionReason]int {
+	reasons := make(map[OfferRejectionReason]int)
+	for _, offer := range r.LastUnusedOffers {
+		for _, reason := range offer.Reason {
+			reasons[reason]++
+		}
+	}
+	return reasons
+}
+
+// ProcessedOffersSummary summarizes the offers processed to launch the instances of a queue item
+type ProcessedOffersSummary struct {
+	ProcessedOffersCount    int             `json:"processedOffersCount"`
+	UnusedOffersCount       int             `json:"unusedOffersCount"`
+	LastUnusedOfferAt       string          `json:"lastUnusedOfferAt,omitempty"`
+	LastUsedOfferAt         string          `json:"lastUsedOfferAt,omitempty"`
+	RejectSummaryLastOffers []DeclineReason `json:"rejectSummaryLastOffers,omitempty"`
+}
+
+// DeclineReason counts the offers declined for a reason, e.g. UnfulfilledConstraint
+type DeclineReason struct {
+	Reason    OfferRejectionReason `json:"reason"`
+	Declined  int                  `json:"declined"`
+	Processed int                  `json:"processed"`
 }
 
 // Delay cotains the application postpone infomation
@@ -40,11 +149,20 @@ type Delay struct {
 
 // Queue retrieves content of the marathon launch queue
 func (r *marathonClient) Queue() (*Queue, error) {
-	var queue *Queue
-	err := r.apiGet(marathonAPIQueue, nil, &queue)
+	return r.QueueBy(nil)
+}
+
+// QueueBy retrieves content of the marathon launch queue by the options
+//		opts:		QueueOpts request payload
+func (r *marathonClient) QueueBy(opts *QueueOpts) (*Queue, error) {
+	path, err := addOptions(marathonAPIQueue, opts)
 	if err != nil {
 		return nil, err
 	}
+	var queue *Queue
+	if err := r.apiGet("QueueBy", path, nil, &queue); err != nil {
+		return nil, err
+	}
 	return queue, nil
 }
 
@@ -52,5 +170,5 @@ func (r *marathonClient) Queue() (*Queue, error) {
 //		appID:		the ID of the application
 func (r *marathonClient) DeleteQueueDelay(appID string) error {
 	path := fmt.Sprintf("%s/%s/delay", marathonAPIQueue, trimRootPath(appID))
-	return r.apiDelete(path, nil, nil)
+	return r.apiDelete("DeleteQueueDelay", path, nil, nil)
 }
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestQueue(t *testing.T) {
@@ -38,6 +39,31 @@ func TestQueue(t *testing.T) {
 	assert.NotEmpty(t, item.Application.ID)
 }
 
+func TestQueueLastUnusedOffers(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	queue, err := endpoint.Client.QueueBy(&QueueOpts{Embed: []string{QueueEmbedLastUnusedOffers}})
+	require.NoError(t, err)
+	require.Len(t, queue.Items, 1)
+	item := queue.Items[0]
+	require.Len(t, item.LastUnusedOffers, 2)
+
+	offer := item.LastUnusedOffers[0]
+	assert.Equal(t, "agent-1", offer.Offer.AgentID)
+	assert.Equal(t, "10.0.0.1", offer.Offer.Hostname)
+	require.Len(t, offer.Offer.Resources, 2)
+	assert.Equal(t, 0.5, *offer.Offer.Resources[0].Scalar)
+	assert.Equal(t, []NumberRange{{Begin: 31000, End: 32000}}, offer.Offer.Resources[1].Ranges)
+	assert.Equal(t, "rack-1", *offer.Offer.Attributes[0].Text)
+	assert.Equal(t, []OfferRejectionReason{RejectionInsufficientCpus, RejectionUnfulfilledConstraint}, offer.Reason)
+
+	assert.Equal(t, map[OfferRejectionReason]int{
+		RejectionInsufficientCpus:      1,
+		RejectionUnfulfilledConstraint: 2,
+	}, item.RejectionReasons())
+}
+
 func TestDeleteQueueDelay(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
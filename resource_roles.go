@@ -0,0 +1,68 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AnyResourceRole accepts the unreserved resources of the agents
+	AnyResourceRole = "*"
+	// PublicAgentRole accepts the resources of the public agents of DC/OS, e.g. the edge nodes
+	PublicAgentRole = "slave_public"
+	// PublicAgentLabel is the label marathon-lb exposes the applications of the public agents with
+	PublicAgentLabel = "HAPROXY_GROUP"
+	// PublicAgentLabelValue is the value of the label of the applications of the public agents
+	PublicAgentLabelValue = "external"
+)
+
+// AddAcceptedResourceRoles adds the roles of the resources the tasks of the application may be
+// launched with, e.g. AnyResourceRole or PublicAgentRole
+//		roles:		the Mesos roles
+func (r *Application) AddAcceptedResourceRoles(roles ...string) *Application {
+	for _, role := range roles {
+		if !contains(r.AcceptedResourceRoles, role) {
+			r.AcceptedResourceRoles = append(r.AcceptedResourceRoles, role)
+		}
+	}
+	return r
+}
+
+// PublicAgent places the application on the public agents of DC/OS, i.e. accepts the resources of
+// the PublicAgentRole only and labels it for marathon-lb with PublicAgentLabel
+func (r *Application) PublicAgent() *Application {
+	r.AcceptedResourceRoles = []string{PublicAgentRole}
+	return r.AddLabel(PublicAgentLabel, PublicAgentLabelValue)
+}
+
+// validateRole checks the name of a Mesos role, which can't be empty, . or .., start with a dash,
+// or contain slashes, backslashes or whitespaces
+func validateRole(role string) error {
+	switch {
+	case role == "":
+		return fmt.Errorf("role is empty")
+	case role == "." || role == "..":
+		return fmt.Errorf("role %q is invalid", role)
+	case strings.HasPrefix(role, "-"):
+		return fmt.Errorf("role %q starts with a dash", role)
+	case strings.ContainsAny(role, "/\\ \t\n\r\f\v"):
+		return fmt.Errorf("role %q contains a slash, a backslash or a whitespace", role)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAcceptedResourceRoles(t *testing.T) {
+	app := new(Application).AddAcceptedResourceRoles(AnyResourceRole, "batch").AddAcceptedResourceRoles("batch")
+	assert.Equal(t, []string{"*", "batch"}, app.AcceptedResourceRoles)
+}
+
+func TestPublicAgent(t *testing.T) {
+	app := new(Application).AddAcceptedResourceRoles(AnyResourceRole).AddLabel("tier", "frontend").PublicAgent()
+	assert.Equal(t, []string{"slave_public"}, app.AcceptedResourceRoles)
+	assert.Equal(t, map[string]string{"tier": "frontend", "HAPROXY_GROUP": "external"}, *app.Labels)
+}
+
+func TestValidateRoles(t *testing.T) {
+	app := NewDockerApplication().Name("/web").AddAcceptedResourceRoles("*", "", "-batch", "team/a").SetRole("..")
+	err := app.Validate()
+	require.Error(t, err)
+	assert.Equal(t, []Violation{
+		{Field: "acceptedResourceRoles[1]", Message: "role is empty"},
+		{Field: "acceptedResourceRoles[2]", Message: `role "-batch" starts with a dash`},
+		{Field: "acceptedResourceRoles[3]", Message: `role "team/a" contains a slash, a backslash or a whitespace`},
+		{Field: "role", Message: `role ".." is invalid`},
+	}, err.(*ValidationError).Violations)
+
+	assert.NoError(t, NewDockerApplication().Name("/web").PublicAgent().SetRole("web").Validate())
+}
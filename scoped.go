@@ -0,0 +1,739 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrOutsideScope is thrown when a scoped client is asked to operate outside of its group prefix
+	ErrOutsideScope = errors.New("the operation is outside of the client scope")
+)
+
+// scopedClient is a Marathon implementation bound to a group prefix. Relative identifiers are
+// resolved against the prefix, and operations on identifiers outside of it are rejected.
+type scopedClient struct {
+	Marathon
+	// the group prefix all identifiers are resolved against
+	prefix string
+}
+
+// Scoped returns a Marathon implementation where all application, pod and group identifiers are
+// resolved relative to the given group prefix, and operations outside of it are rejected
+//		prefix:		the group the client is bound to, e.g. /myteam
+func (r *marathonClient) Scoped(prefix string) Marathon {
+	return newScopedClient(r, prefix)
+}
+
+// NewScopedClient wraps a Marathon implementation in a client bound to the group prefix, e.g. to
+// provide Scoped() on custom implementations of the interface
+//		client:		the implementation being wrapped
+//		prefix:		the group the client is bound to
+func NewScopedClient(client Marathon, prefix string) Marathon {
+	return newScopedClient(client, prefix)
+}
+
+func newScopedClient(client Marathon, prefix string) *scopedClient {
+	return &scopedClient{
+		Marathon: client,
+		prefix:   path.Clean(validateID(prefix)),
+	}
+}
+
+// resolve resolves the identifier against the prefix, failing if the result is outside of it
+func (s *scopedClient) resolve(id string) (string, error) {
+	if !strings.HasPrefix(id, "/") {
+		id = s.prefix + "/" + id
+	}
+	id = path.Clean(id)
+	if !s.contains(id) {
+		return "", ErrOutsideScope
+	}
+	return id, nil
+}
+
+// contains checks if the absolute identifier is within the prefix
+func (s *scopedClient) contains(id string) bool {
+	id = validateID(id)
+	return s.prefix == "/" || id == s.prefix || strings.HasPrefix(id, s.prefix+"/")
+}
+
+// Scoped returns a client bound to a group nested within the current prefix
+func (s *scopedClient) Scoped(prefix string) Marathon {
+	id, err := s.resolve(prefix)
+	if err != nil {
+		// step: an unresolvable prefix is confined to the current scope
+		id = s.prefix
+	}
+	return newScopedClient(s.Marathon, id)
+}
+
+// -- APPLICATIONS ---
+
+func (s *scopedClient) ListApplications(v url.Values) ([]string, error) {
+	applications, err := s.Applications(v)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, application := range applications.Apps {
+		list = append(list, application.ID)
+	}
+	return list, nil
+}
+
+func (s *scopedClient) ApplicationVersions(name string) (*ApplicationVersions, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.ApplicationVersions(id)
+}
+
+func (s *scopedClient) HasApplication(name string) (bool, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return false, err
+	}
+	return s.Marathon.HasApplication(id)
+}
+
+func (s *scopedClient) HasApplicationVersion(name, version string) (bool, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return false, err
+	}
+	return s.Marathon.HasApplicationVersion(id, version)
+}
+
+func (s *scopedClient) SetApplicationVersion(name string, version *ApplicationVersion) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.SetApplicationVersion(id, version)
+}
+
+func (s *scopedClient) SetApplicationVersionBy(name string, version *ApplicationVersion, opts *UpdateAppOpts) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.SetApplicationVersionBy(id, version, opts)
+}
+
+func (s *scopedClient) ApplicationOK(name string) (bool, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return false, err
+	}
+	return s.Marathon.ApplicationOK(id)
+}
+
+func (s *scopedClient) CreateApplication(application *Application) (*Application, error) {
+	id, err := s.resolve(application.ID)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *application
+	scoped.ID = id
+	return s.Marathon.CreateApplication(&scoped)
+}
+
+func (s *scopedClient) DeleteApplication(name string, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.DeleteApplication(id, force)
+}
+
+func (s *scopedClient) DeleteApplicationBy(name string, opts *DeleteAppOpts) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.DeleteApplicationBy(id, opts)
+}
+
+func (s *scopedClient) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(application.ID)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *application
+	scoped.ID = id
+	return s.Marathon.UpdateApplication(&scoped, force)
+}
+
+func (s *scopedClient) UpdateApplicationBy(application *Application, opts *UpdateAppOpts) (*DeploymentID, error) {
+	id, err := s.resolve(application.ID)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *application
+	scoped.ID = id
+	return s.Marathon.UpdateApplicationBy(&scoped, opts)
+}
+
+func (s *scopedClient) ApplicationDeployments(name string) ([]*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.ApplicationDeployments(id)
+}
+
+func (s *scopedClient) ScaleApplicationInstances(name string, instances int, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.ScaleApplicationInstances(id, instances, force)
+}
+
+func (s *scopedClient) ScaleApplication(name string, instances int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Marathon.ScaleApplication(id, instances, opts)
+}
+
+func (s *scopedClient) ScaleBy(name string, delta int, opts *ScaleAppOpts) (*DeploymentID, *LaunchTracker, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.Marathon.ScaleBy(id, delta, opts)
+}
+
+func (s *scopedClient) RestartApplication(name string, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.RestartApplication(id, force)
+}
+
+func (s *scopedClient) RestartApplicationBy(name string, opts *RestartAppOpts) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.RestartApplicationBy(id, opts)
+}
+
+func (s *scopedClient) Applications(v url.Values) (*Applications, error) {
+	applications, err := s.Marathon.Applications(v)
+	if err != nil {
+		return nil, err
+	}
+	scoped := &Applications{Apps: []Application{}}
+	for _, application := range applications.Apps {
+		if s.contains(application.ID) {
+			scoped.Apps = append(scoped.Apps, application)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) EachApplication(v url.Values, fn func(*Application) error) error {
+	return s.Marathon.EachApplication(v, func(application *Application) error {
+		if !s.contains(application.ID) {
+			return nil
+		}
+		return fn(application)
+	})
+}
+
+func (s *scopedClient) Application(name string) (*Application, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.Application(id)
+}
+
+func (s *scopedClient) ApplicationBy(name string, opts *GetAppOpts) (*Application, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.ApplicationBy(id, opts)
+}
+
+func (s *scopedClient) ApplicationByVersion(name, version string) (*Application, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.ApplicationByVersion(id, version)
+}
+
+func (s *scopedClient) WaitOnApplication(name string, timeout time.Duration) error {
+	id, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.WaitOnApplication(id, timeout)
+}
+
+func (s *scopedClient) WaitOnApplicationHealthy(name string, timeout time.Duration) error {
+	id, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.WaitOnApplicationHealthy(id, timeout)
+}
+
+// -- PODS ---
+
+func (s *scopedClient) PodStatus(name string) (*PodStatus, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.PodStatus(id)
+}
+
+func (s *scopedClient) PodStatuses() ([]*PodStatus, error) {
+	statuses, err := s.Marathon.PodStatuses()
+	if err != nil {
+		return nil, err
+	}
+	var scoped []*PodStatus
+	for _, status := range statuses {
+		if s.contains(status.ID) {
+			scoped = append(scoped, status)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) Pod(name string) (*Pod, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.Pod(id)
+}
+
+func (s *scopedClient) Pods() ([]Pod, error) {
+	pods, err := s.Marathon.Pods()
+	if err != nil {
+		return nil, err
+	}
+	var scoped []Pod
+	for _, pod := range pods {
+		if s.contains(pod.ID) {
+			scoped = append(scoped, pod)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) CreatePod(pod *Pod) (*Pod, error) {
+	id, err := s.resolve(pod.ID)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *pod
+	scoped.ID = id
+	return s.Marathon.CreatePod(&scoped)
+}
+
+func (s *scopedClient) UpdatePod(pod *Pod, force bool) (*Pod, error) {
+	id, err := s.resolve(pod.ID)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *pod
+	scoped.ID = id
+	return s.Marathon.UpdatePod(&scoped, force)
+}
+
+func (s *scopedClient) DeletePod(name string, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.DeletePod(id, force)
+}
+
+func (s *scopedClient) WaitOnPod(name string, timeout time.Duration) error {
+	id, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.WaitOnPod(id, timeout)
+}
+
+func (s *scopedClient) PodIsRunning(name string) bool {
+	id, err := s.resolve(name)
+	if err != nil {
+		return false
+	}
+	return s.Marathon.PodIsRunning(id)
+}
+
+func (s *scopedClient) PodVersions(name string) ([]string, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.PodVersions(id)
+}
+
+func (s *scopedClient) PodByVersion(name, version string) (*Pod, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.PodByVersion(id, version)
+}
+
+func (s *scopedClient) DeletePodInstances(name string, instances []string) ([]*PodInstance, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.DeletePodInstances(id, instances)
+}
+
+func (s *scopedClient) DeletePodInstance(name, instance string) (*PodInstance, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.DeletePodInstance(id, instance)
+}
+
+// -- TASKS ---
+
+func (s *scopedClient) Tasks(application string) (*Tasks, error) {
+	id, err := s.resolve(application)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.Tasks(id)
+}
+
+func (s *scopedClient) TasksBy(application string, opts *TasksOpts) (*Tasks, error) {
+	id, err := s.resolve(application)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.TasksBy(id, opts)
+}
+
+func (s *scopedClient) AllTasks(opts *AllTasksOpts) (*Tasks, error) {
+	tasks, err := s.Marathon.AllTasks(opts)
+	if err != nil {
+		return nil, err
+	}
+	scoped := &Tasks{Tasks: []Task{}}
+	for _, task := range tasks.Tasks {
+		if s.contains(task.AppID) {
+			scoped.Tasks = append(scoped.Tasks, task)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) AllTaskEndpoints(opts *AllTasksOpts) ([]*ServiceEndpoints, error) {
+	list, err := s.Marathon.AllTaskEndpoints(opts)
+	if err != nil {
+		return nil, err
+	}
+	scoped := []*ServiceEndpoints{}
+	for _, endpoints := range list {
+		if s.contains(endpoints.AppID) {
+			scoped = append(scoped, endpoints)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) TaskEndpoints(name string, port int, healthCheck bool) ([]string, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.TaskEndpoints(id, port, healthCheck)
+}
+
+func (s *scopedClient) TaskEndpointsByPortName(name, portName string, healthCheck bool) ([]string, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.TaskEndpointsByPortName(id, portName, healthCheck)
+}
+
+func (s *scopedClient) KillApplicationTasks(applicationID string, opts *KillApplicationTasksOpts) (*Tasks, error) {
+	id, err := s.resolve(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.KillApplicationTasks(id, opts)
+}
+
+func (s *scopedClient) KillTask(taskID string, opts *KillTaskOpts) (*Task, error) {
+	if !s.contains(taskAppID(taskID)) {
+		return nil, ErrOutsideScope
+	}
+	return s.Marathon.KillTask(taskID, opts)
+}
+
+func (s *scopedClient) KillTasks(taskIDs []string, opts *KillTaskOpts) error {
+	for _, taskID := range taskIDs {
+		if !s.contains(taskAppID(taskID)) {
+			return ErrOutsideScope
+		}
+	}
+	return s.Marathon.KillTasks(taskIDs, opts)
+}
+
+// --- GROUPS ---
+
+func (s *scopedClient) Groups() (*Groups, error) {
+	return s.GroupsBy(nil)
+}
+
+func (s *scopedClient) Group(name string) (*Group, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.Group(id)
+}
+
+func (s *scopedClient) GroupsBy(opts *GetGroupOpts) (*Groups, error) {
+	group, err := s.Marathon.GroupBy(s.prefix, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Groups{
+		ID:           group.ID,
+		Apps:         group.Apps,
+		Dependencies: group.Dependencies,
+		Groups:       group.Groups,
+	}, nil
+}
+
+func (s *scopedClient) GroupBy(name string, opts *GetGroupOpts) (*Group, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.GroupBy(id, opts)
+}
+
+func (s *scopedClient) CreateGroup(group *Group) error {
+	id, err := s.resolve(group.ID)
+	if err != nil {
+		return err
+	}
+	scoped := *group
+	scoped.ID = id
+	return s.Marathon.CreateGroup(&scoped)
+}
+
+func (s *scopedClient) DeleteGroup(name string, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.DeleteGroup(id, force)
+}
+
+func (s *scopedClient) UpdateGroup(name string, group *Group, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.UpdateGroup(id, group, force)
+}
+
+func (s *scopedClient) DeployGroup(group *Group, force bool) (*DeploymentID, error) {
+	id, err := s.resolve(group.ID)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *group
+	scoped.ID = id
+	return s.Marathon.DeployGroup(&scoped, force)
+}
+
+func (s *scopedClient) HasGroup(name string) (bool, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return false, err
+	}
+	return s.Marathon.HasGroup(id)
+}
+
+func (s *scopedClient) WaitOnGroup(name string, timeout time.Duration) error {
+	id, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.WaitOnGroup(id, timeout)
+}
+
+func (s *scopedClient) WaitOnGroupHealthy(name string, timeout time.Duration) error {
+	id, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.WaitOnGroupHealthy(id, timeout)
+}
+
+// --- DEPLOYMENTS ---
+
+// Deployments retrieves the deployments affecting only applications and pods within the scope
+func (s *scopedClient) Deployments() ([]*Deployment, error) {
+	deployments, err := s.Marathon.Deployments()
+	if err != nil {
+		return nil, err
+	}
+	var scoped []*Deployment
+	for _, deployment := range deployments {
+		if s.containsDeployment(deployment) {
+			scoped = append(scoped, deployment)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) DeleteDeployment(id string, force bool) (*DeploymentID, error) {
+	found, err := s.HasDeployment(id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrOutsideScope
+	}
+	return s.Marathon.DeleteDeployment(id, force)
+}
+
+func (s *scopedClient) HasDeployment(id string) (bool, error) {
+	deployments, err := s.Deployments()
+	if err != nil {
+		return false, err
+	}
+	for _, deployment := range deployments {
+		if deployment.ID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// containsDeployment checks if all the applications and pods affected by the deployment are within the scope
+func (s *scopedClient) containsDeployment(deployment *Deployment) bool {
+	affected := append(append([]string{}, deployment.AffectedApps...), deployment.AffectedPods...)
+	if len(affected) == 0 {
+		return false
+	}
+	for _, id := range affected {
+		if !s.contains(id) {
+			return false
+		}
+	}
+	return true
+}
+
+// --- SUBSCRIPTIONS ---
+
+// Subscribe is rejected as event subscriptions are not bound to a group
+func (s *scopedClient) Subscribe(string) error {
+	return ErrOutsideScope
+}
+
+// Unsubscribe is rejected as event subscriptions are not bound to a group
+func (s *scopedClient) Unsubscribe(string) error {
+	return ErrOutsideScope
+}
+
+// --- QUEUE ---
+
+func (s *scopedClient) Queue() (*Queue, error) {
+	return s.QueueBy(nil)
+}
+
+func (s *scopedClient) QueueBy(opts *QueueOpts) (*Queue, error) {
+	queue, err := s.Marathon.QueueBy(opts)
+	if err != nil {
+		return nil, err
+	}
+	scoped := &Queue{Items: []Item{}}
+	for _, item := range queue.Items {
+		if s.contains(item.Application.ID) {
+			scoped.Items = append(scoped.Items, item)
+		}
+	}
+	return scoped, nil
+}
+
+func (s *scopedClient) DeleteQueueDelay(appID string) error {
+	id, err := s.resolve(appID)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.DeleteQueueDelay(id)
+}
+
+// --- MISC ---
+
+// AbdicateLeader is rejected as leadership is not bound to a group
+func (s *scopedClient) AbdicateLeader() (string, error) {
+	return "", ErrOutsideScope
+}
+
+// --- ARTIFACTS ---
+
+func (s *scopedClient) UploadArtifact(name string, artifact io.Reader) (string, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	return s.Marathon.UploadArtifact(id, artifact)
+}
+
+func (s *scopedClient) GetArtifact(name string) (io.ReadCloser, error) {
+	id, err := s.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.Marathon.GetArtifact(id)
+}
+
+func (s *scopedClient) DeleteArtifact(name string) error {
+	id, err := s.resolve(name)
+	if err != nil {
+		return err
+	}
+	return s.Marathon.DeleteArtifact(id)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedResolve(t *testing.T) {
+	scoped := newScopedClient(nil, "myteam/")
+
+	cases := []struct {
+		ID       string
+		Expected string
+		Err      error
+	}{
+		{ID: "app", Expected: "/myteam/app"},
+		{ID: "sub/app", Expected: "/myteam/sub/app"},
+		{ID: "/myteam/app", Expected: "/myteam/app"},
+		{ID: "/myteam", Expected: "/myteam"},
+		{ID: "../other/app", Err: ErrOutsideScope},
+		{ID: "/other/app", Err: ErrOutsideScope},
+		{ID: "/myteam-other/app", Err: ErrOutsideScope},
+	}
+	for _, x := range cases {
+		id, err := scoped.resolve(x.ID)
+		assert.Equal(t, x.Err, err, x.ID)
+		assert.Equal(t, x.Expected, id, x.ID)
+	}
+
+	nested := scoped.Scoped("sub").(*scopedClient)
+	assert.Equal(t, "/myteam/sub", nested.prefix)
+	escaped := scoped.Scoped("/other").(*scopedClient)
+	assert.Equal(t, "/myteam", escaped.prefix)
+}
+
+func TestScopedApplications(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	client := endpoint.Client.Scoped(fakeAppName)
+
+	applications, err := client.Applications(nil)
+	require.NoError(t, err)
+	require.Len(t, applications.Apps, 1)
+	assert.Equal(t, fakeAppName, applications.Apps[0].ID)
+
+	ids, err := client.ListApplications(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{fakeAppName}, ids)
+
+	application, err := client.Application(fakeAppName)
+	assert.NoError(t, err)
+	assert.NotNil(t, application)
+
+	_, err = client.Application(fakeAppNameBroken)
+	assert.Equal(t, ErrOutsideScope, err)
+	_, err = client.DeleteApplication("../other", false)
+	assert.Equal(t, ErrOutsideScope, err)
+	_, err = client.KillTask("other.fake-task", nil)
+	assert.Equal(t, ErrOutsideScope, err)
+	assert.Equal(t, ErrOutsideScope, client.Subscribe("http://localhost"))
+}
+
+func TestScopedDeployments(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	deployments, err := endpoint.Client.Scoped("/").Deployments()
+	require.NoError(t, err)
+	assert.NotEmpty(t, deployments)
+
+	deployments, err = endpoint.Client.Scoped("/myteam").Deployments()
+	require.NoError(t, err)
+	assert.Empty(t, deployments)
+
+	_, err = endpoint.Client.Scoped("/myteam").DeleteDeployment(fakeDeploymentID, false)
+	assert.Equal(t, ErrOutsideScope, err)
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package marathon
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
-	"strings"
+	"net/url"
+	"sort"
 	"sync"
 	"time"
 
@@ -37,7 +39,7 @@ type Subscriptions struct {
 // Subscriptions retrieves a list of registered subscriptions
 func (r *marathonClient) Subscriptions() (*Subscriptions, error) {
 	subscriptions := new(Subscriptions)
-	if err := r.apiGet(marathonAPISubscription, nil, subscriptions); err != nil {
+	if err := r.apiGet("Subscriptions", marathonAPISubscription, nil, subscriptions); err != nil {
 		return nil, err
 	}
 
@@ -49,10 +51,13 @@ func (r *marathonClient) Subscriptions() (*Subscriptions, error) {
 func (r *marathonClient) AddEventsListener(filter int) (EventsChannel, error) {
 	r.Lock()
 	defer r.Unlock()
+	if r.client.closed() {
+		return nil, ErrClientClosed
+	}
 
 	// step: someone has asked to start listening to event, we need to register for events
 	// if we haven't done so already
-	if err := r.registerSubscription(); err != nil {
+	if err := r.registerSubscription(filter); err != nil {
 		return nil, err
 	}
 
@@ -75,9 +80,13 @@ func (r *marathonClient) RemoveEventsListener(channel EventsChannel) {
 		close(context.done)
 		delete(r.listeners, channel)
 		// step: if there is no one else listening, let's remove ourselves
-		// from the events callback
+		// from the events callback, or close the event stream
 		if r.config.EventsTransport == EventsTransportCallback && len(r.listeners) == 0 {
-			r.Unsubscribe(r.SubscriptionURL())
+			r.Unsubscribe(r.subscriptionURL())
+		}
+		if r.config.EventsTransport == EventsTransportSSE && len(r.listeners) == 0 && r.sseStop != nil {
+			close(r.sseStop)
+			r.sseStop = nil
 		}
 
 		// step: wait for pending goroutines to finish and close channel
@@ -90,6 +99,13 @@ func (r *marathonClient) RemoveEventsListener(channel EventsChannel) {
 
 // SubscriptionURL retrieves the subscription callback URL used when registering
 func (r *marathonClient) SubscriptionURL() string {
+	r.RLock()
+	defer r.RUnlock()
+	return r.subscriptionURL()
+}
+
+// subscriptionURL returns the subscription callback URL, the caller holding the lock
+func (r *marathonClient) subscriptionURL() string {
 	if r.config.CallbackURL != "" {
 		return fmt.Sprintf("%s%s", r.config.CallbackURL, defaultEventsURL)
 	}
@@ -98,12 +114,13 @@ func (r *marathonClient) SubscriptionURL() string {
 }
 
 // registerSubscription registers ourselves with Marathon to receive events from configured transport facility
-func (r *marathonClient) registerSubscription() error {
+//		filter:		the event types of the new listener
+func (r *marathonClient) registerSubscription(filter int) error {
 	switch r.config.EventsTransport {
 	case EventsTransportCallback:
 		return r.registerCallbackSubscription()
 	case EventsTransportSSE:
-		return r.registerSSESubscription()
+		return r.registerSSESubscription(filter)
 	default:
 		return fmt.Errorf("the events transport: %d is not supported", r.config.EventsTransport)
 	}
@@ -137,15 +154,17 @@ func (r *marathonClient) registerCallbackSubscription() error {
 			return nil
 		}
 
-		go func() {
+		go func(server *http.Server) {
 			for {
-				r.eventsHTTP.Serve(listener)
+				if err := server.Serve(listener); err == http.ErrServerClosed {
+					return
+				}
 			}
-		}()
+		}(r.eventsHTTP)
 	}
 
 	// step: get the callback url
-	callback := r.SubscriptionURL()
+	callback := r.subscriptionURL()
 
 	// step: check if the callback is registered
 	found, err := r.HasSubscription(callback)
@@ -166,9 +185,22 @@ func (r *marathonClient) registerCallbackSubscription() error {
 // connect to the SSE stream and to process the received events. To establish
 // the connection it tries the active cluster members until no more member is
 // active. When this happens it will retry to get a connection every 5 seconds.
-func (r *marathonClient) registerSSESubscription() error {
-	if r.subscribedToSSE {
-		return nil
+// All the listeners share the one connection, since Marathon limits the number
+// of SSE clients, which is closed once the last listener is removed. The stream
+// is restricted to the event types of the listeners, so it is reconnected when
+// a listener needs other event types.
+//		filter:		the event types of the new listener
+func (r *marathonClient) registerSSESubscription(filter int) error {
+	if r.sseStop != nil {
+		if filter&^r.sseFilter == 0 {
+			return nil
+		}
+		// step: reconnect with the event types of the new listener
+		close(r.sseStop)
+		r.sseStop = nil
+	}
+	for _, context := range r.listeners {
+		filter |= context.filter
 	}
 
 	if r.config.HTTPSSEClient.Timeout != 0 {
@@ -178,30 +210,130 @@ func (r *marathonClient) registerSSESubscription() error {
 		)
 	}
 
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	previous := r.sseDone
 	go func() {
+		defer close(done)
+		// step: shut down the connection being replaced before opening the new one
+		if previous != nil {
+			<-previous
+		}
 		for {
-			stream, err := r.connectToSSE()
+			stream, err := r.connectToSSE(filter)
 			if err != nil {
-				r.debugLog("Error connecting SSE subscription: %s", err)
-				<-time.After(5 * time.Second)
+				r.log(LogModuleEvents).Errorf("Error connecting SSE subscription: %s", err)
+				select {
+				case <-stop:
+					return
+				case <-r.client.done():
+					return
+				case <-time.After(5 * time.Second):
+				}
 				continue
 			}
-			err = r.listenToSSE(stream)
+			err = r.listenToSSE(stream, stop)
 			stream.Close()
-			r.debugLog("Error on SSE subscription: %s", err)
+			if err == nil {
+				r.log(LogModuleEvents).Debugf("SSE subscription closed, no listener left")
+				return
+			}
+			r.log(LogModuleEvents).Errorf("Error on SSE subscription: %s", err)
 		}
 	}()
 
-	r.subscribedToSSE = true
+	r.sseStop = stop
+	r.sseDone = done
+	r.sseFilter = filter
 	return nil
 }
 
-// connectToSSE tries to establish an *eventsource.Stream to any of the Marathon cluster members, marking the
+// eventStreamPath returns the path of the event stream, restricted to the event types of the
+// filter by the event_type parameters. The Marathon versions which don't support them send all
+// the events, filtered by the client anyway.
+func eventStreamPath(filter int) string {
+	if filter&EventIDAll == EventIDAll {
+		return marathonAPIEventStream
+	}
+	var types []string
+	for eventType, id := range eventTypesMap {
+		if filter&id != 0 {
+			types = append(types, eventType)
+		}
+	}
+	sort.Strings(types)
+	query := url.Values{"event_type": types}
+	return marathonAPIEventStream + "?" + query.Encode()
+}
+
+// sseStream is an event stream with its own request context, cancelled to close its connection
+type sseStream struct {
+	*eventsource.Stream
+	// cancels the request of the stream
+	cancel context.CancelFunc
+	// the response bodies of the stream
+	transport *sseTransport
+	// whether the reader of the event source has given up on the stream, sending its error
+	failed bool
+}
+
+// Close closes the connection of the stream. The event source closes its channels on Close,
+// which its reader must no longer be sending on, so the events are drained until the reader
+// gives up on the cancelled connection.
+func (s *sseStream) Close() {
+	s.cancel()
+	for !s.failed {
+		select {
+		case <-s.Events:
+		case <-s.Errors:
+			s.failed = true
+		}
+	}
+	s.Stream.Close()
+	s.transport.closeBodies()
+}
+
+// sseTransport keeps the response bodies of the event stream, which the event source only
+// closes once it gives up reconnecting
+type sseTransport struct {
+	transport http.RoundTripper
+	sync.Mutex
+	bodies []io.Closer
+}
+
+// RoundTrip performs the request with the underlying transport, keeping the response body
+func (t *sseTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	transport := t.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	response, err := transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	t.Lock()
+	defer t.Unlock()
+	t.bodies = append(t.bodies, response.Body)
+	return response, nil
+}
+
+// closeBodies closes the response bodies of the stream
+func (t *sseTransport) closeBodies() {
+	t.Lock()
+	defer t.Unlock()
+	for _, body := range t.bodies {
+		body.Close()
+	}
+	t.bodies = nil
+}
+
+// connectToSSE tries to establish an *sseStream to any of the Marathon cluster members, marking the
 // member as down on connection failure, until there is no more active member in the cluster.
 // Given the http request can not be built, it will panic as this case should never happen.
-func (r *marathonClient) connectToSSE() (*eventsource.Stream, error) {
+//		filter:		the event types of the stream
+func (r *marathonClient) connectToSSE(filter int) (*sseStream, error) {
 	for {
-		request, member, err := r.buildAPIRequest("GET", marathonAPIEventStream, nil)
+		request, member, err := r.buildAPIRequest("GET", eventStreamPath(filter), nil)
 		if err != nil {
 			switch err.(type) {
 			case newRequestError:
@@ -214,34 +346,41 @@ func (r *marathonClient) connectToSSE() (*eventsource.Stream, error) {
 		// The event source library manipulates the HTTPClient. So we create a new one and copy
 		// its underlying fields for performance reasons. See note that at least the Transport
 		// should be reused here: https://golang.org/pkg/net/http/#Client
+		transport := &sseTransport{transport: r.config.HTTPSSEClient.Transport}
 		httpClient := &http.Client{
-			Transport:     r.config.HTTPSSEClient.Transport,
+			Transport:     transport,
 			CheckRedirect: r.config.HTTPSSEClient.CheckRedirect,
 			Jar:           r.config.HTTPSSEClient.Jar,
 			Timeout:       r.config.HTTPSSEClient.Timeout,
 		}
 
-		stream, err := eventsource.SubscribeWith("", httpClient, request)
+		ctx, cancel := context.WithCancel(request.Context())
+		stream, err := eventsource.SubscribeWith("", httpClient, request.WithContext(ctx))
 		if err != nil {
-			r.debugLog("Error subscribing to Marathon event stream: %s", err)
+			cancel()
+			transport.closeBodies()
+			r.log(LogModuleEvents).Errorf("Error subscribing to Marathon event stream: %s", err)
 			r.hosts.markDown(member)
 			continue
 		}
 
-		return stream, nil
+		return &sseStream{Stream: stream, cancel: cancel, transport: transport}, nil
 	}
 }
 
-func (r *marathonClient) listenToSSE(stream *eventsource.Stream) error {
+// listenToSSE processes the events of the stream until it fails, or returns nil once stopped
+func (r *marathonClient) listenToSSE(stream *sseStream, stop <-chan struct{}) error {
 	for {
 		select {
+		case <-stop:
+			return nil
 		case ev := <-stream.Events:
 			if err := r.handleEvent(ev.Data()); err != nil {
-				r.debugLog("listenToSSE(): failed to handle event: %v", err)
+				r.log(LogModuleEvents).Errorf("listenToSSE(): failed to handle event: %v", err)
 			}
 		case err := <-stream.Errors:
+			stream.failed = true
 			return err
-
 		}
 	}
 }
@@ -250,7 +389,7 @@ func (r *marathonClient) listenToSSE(stream *eventsource.Stream) error {
 //	callback	: the URL you wish to subscribe
 func (r *marathonClient) Subscribe(callback string) error {
 	path := fmt.Sprintf("%s?callbackUrl=%s", marathonAPISubscription, callback)
-	return r.apiPost(path, "", nil)
+	return r.apiPost("Subscribe", path, "", nil)
 
 }
 
@@ -258,7 +397,7 @@ func (r *marathonClient) Subscribe(callback string) error {
 //	callback	: the URL you wish to unsubscribe
 func (r *marathonClient) Unsubscribe(callback string) error {
 	// step: remove from the list of subscriptions
-	return r.apiDelete(fmt.Sprintf("%s?callbackUrl=%s", marathonAPISubscription, callback), nil, nil)
+	return r.apiDelete("Unsubscribe", fmt.Sprintf("%s?callbackUrl=%s", marathonAPISubscription, callback), nil, nil)
 }
 
 // HasSubscription checks to see a subscription already exists with Marathon
@@ -282,22 +421,23 @@ func (r *marathonClient) HasSubscription(callback string) (bool, error) {
 func (r *marathonClient) handleEvent(content string) error {
 	// step: process and decode the event
 	eventType := new(EventType)
-	err := json.NewDecoder(strings.NewReader(content)).Decode(eventType)
+	err := r.codec.Unmarshal([]byte(content), eventType)
 	if err != nil {
-		return fmt.Errorf("failed to decode the event type, content: %s, error: %s", content, err)
+		return fmt.Errorf("failed to decode the event type, content: %s, error: %w", content, err)
 	}
 
 	// step: check whether event type is handled
 	event, err := GetEvent(eventType.EventType)
 	if err != nil {
-		return fmt.Errorf("unable to handle event, type: %s, error: %s", eventType.EventType, err)
+		return fmt.Errorf("unable to handle event, type: %s, error: %w", eventType.EventType, err)
 	}
 
 	// step: let's decode message
-	err = json.NewDecoder(strings.NewReader(content)).Decode(event.Event)
+	err = r.codec.Unmarshal([]byte(content), event.Event)
 	if err != nil {
-		return fmt.Errorf("failed to decode the event, id: %d, error: %s", event.ID, err)
+		return fmt.Errorf("failed to decode the event, id: %d, error: %w", event.ID, err)
 	}
+	r.journalEvent(eventType.EventType, content)
 
 	r.RLock()
 	defer r.RUnlock()
@@ -307,6 +447,7 @@ func (r *marathonClient) handleEvent(content string) error {
 		// step: check if this listener wants this event type
 		if event.ID&context.filter != 0 {
 			context.completion.Add(1)
+			// step: the listeners share the event, which they must not modify
 			go func(ch EventsChannel, context EventsChannelContext, e *Event) {
 				defer context.completion.Done()
 				select {
@@ -325,12 +466,12 @@ func (r *marathonClient) handleCallbackEvent(writer http.ResponseWriter, request
 	body, err := ioutil.ReadAll(request.Body)
 	if err != nil {
 		// TODO should this return a 500?
-		r.debugLog("handleCallbackEvent(): failed to read request body, error: %s", err)
+		r.log(LogModuleEvents).Errorf("handleCallbackEvent(): failed to read request body, error: %s", err)
 		return
 	}
 
 	if err := r.handleEvent(string(body[:])); err != nil {
 		// TODO should this return a 500?
-		r.debugLog("handleCallbackEvent(): failed to handle event: %v", err)
+		r.log(LogModuleEvents).Errorf("handleCallbackEvent(): failed to handle event: %v", err)
 	}
 }
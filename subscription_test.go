@@ -17,8 +17,11 @@ limitations under the License.
 package marathon
 
 import (
+	"io"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -374,6 +377,194 @@ func TestEventStreamEventsReceived(t *testing.T) {
 	}
 }
 
+func TestEventIDsByType(t *testing.T) {
+	filter, err := EventIDsByType("deployment_success", "deployment_failed")
+	require.NoError(t, err)
+	assert.Equal(t, EventIDDeploymentSuccess|EventIDDeploymentFailed, filter)
+
+	_, err = EventIDsByType("deployment_success", "no_such_event")
+	assert.Error(t, err)
+
+	for _, id := range eventTypesMap {
+		assert.NotZero(t, id&EventIDAll)
+	}
+	assert.Zero(t, EventIDAll&(EventIDAppTerminated<<1))
+}
+
+func TestEventListenersFilters(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+	client := endpoint.Client.(*marathonClient)
+
+	listeners := map[string]int{
+		"deployment_info":             EventIDDeployments,
+		"health_status_changed_event": EventIDHealthChecks,
+		"status_update_event":         EventIDTasks,
+	}
+	channels := map[string]EventsChannel{}
+	for name, filter := range listeners {
+		events, err := client.AddEventsListener(filter)
+		require.NoError(t, err)
+		defer client.RemoveEventsListener(events)
+		channels[name] = events
+	}
+	all, err := client.AddEventsListener(EventIDAll)
+	require.NoError(t, err)
+	defer client.RemoveEventsListener(all)
+
+	// step: each listener receives the events of its types from the one stream
+	for name := range listeners {
+		require.NoError(t, client.handleEvent(testCases.find(name).source))
+	}
+	for name, events := range channels {
+		select {
+		case event := <-events:
+			assert.Equal(t, name, event.Name)
+		case <-time.After(eventPublishTimeout):
+			assert.Fail(t, "did not receive event in time", name)
+		}
+		select {
+		case event := <-events:
+			assert.Fail(t, "received an event of another type", "%s: %s", name, event.Name)
+		default:
+		}
+	}
+	for range listeners {
+		select {
+		case <-all:
+		case <-time.After(eventPublishTimeout):
+			assert.Fail(t, "did not receive event in time")
+		}
+	}
+}
+
+// streamCounter counts the event streams opened and still open
+type streamCounter struct {
+	sync.Mutex
+	opened  int
+	open    int
+	maxOpen int
+	queries []string
+}
+
+func (c *streamCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := http.DefaultTransport.RoundTrip(request)
+	if err != nil || !strings.HasSuffix(request.URL.Path, marathonAPIEventStream) {
+		return response, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.opened++
+	c.open++
+	if c.open > c.maxOpen {
+		c.maxOpen = c.open
+	}
+	c.queries = append(c.queries, request.URL.RawQuery)
+	response.Body = &countedBody{ReadCloser: response.Body, counter: c}
+	return response, nil
+}
+
+func (c *streamCounter) counts() (int, int) {
+	c.Lock()
+	defer c.Unlock()
+	return c.opened, c.open
+}
+
+type countedBody struct {
+	io.ReadCloser
+	counter *streamCounter
+	once    sync.Once
+}
+
+func (b *countedBody) Close() error {
+	b.once.Do(func() {
+		b.counter.Lock()
+		b.counter.open--
+		b.counter.Unlock()
+	})
+	return b.ReadCloser.Close()
+}
+
+func TestSSESubscriptionShared(t *testing.T) {
+	counter := &streamCounter{}
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	clientCfg.HTTPSSEClient = &http.Client{Transport: counter}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	var listeners []EventsChannel
+	for _, filter := range []int{EventIDAll, EventIDApplications, EventIDDeployments} {
+		events, err := endpoint.Client.AddEventsListener(filter)
+		require.NoError(t, err)
+		listeners = append(listeners, events)
+	}
+	time.Sleep(SSEConnectWaitTime)
+	opened, open := counter.counts()
+	assert.Equal(t, 1, opened)
+	assert.Equal(t, 1, open)
+
+	// step: the stream is closed with the last listener
+	for _, events := range listeners {
+		endpoint.Client.RemoveEventsListener(events)
+	}
+	time.Sleep(SSEConnectWaitTime)
+	_, open = counter.counts()
+	assert.Equal(t, 0, open)
+
+	// step: and opened again for the next listener
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+	defer endpoint.Client.RemoveEventsListener(events)
+	time.Sleep(SSEConnectWaitTime)
+	opened, open = counter.counts()
+	assert.Equal(t, 2, opened)
+	assert.Equal(t, 1, open)
+
+	endpoint.Server.PublishEvent(testCases[0].source)
+	select {
+	case <-events:
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "did not receive event in time")
+	}
+}
+
+func TestEventStreamPath(t *testing.T) {
+	assert.Equal(t, marathonAPIEventStream, eventStreamPath(EventIDAll))
+	assert.Equal(t, marathonAPIEventStream+"?event_type=deployment_failed&event_type=deployment_success",
+		eventStreamPath(EventIDDeploymentSuccess|EventIDDeploymentFailed))
+}
+
+func TestSSESubscriptionEventTypes(t *testing.T) {
+	counter := &streamCounter{}
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	clientCfg.HTTPSSEClient = &http.Client{Transport: counter}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	for _, filter := range []int{EventIDDeploymentSuccess | EventIDDeploymentFailed, EventIDDeploymentSuccess, EventIDStatusUpdate} {
+		events, err := endpoint.Client.AddEventsListener(filter)
+		require.NoError(t, err)
+		defer endpoint.Client.RemoveEventsListener(events)
+		time.Sleep(SSEConnectWaitTime)
+	}
+
+	// step: the stream is reconnected for the new event types only, once the previous one is closed
+	opened, open := counter.counts()
+	assert.Equal(t, 2, opened)
+	assert.Equal(t, 1, open)
+	counter.Lock()
+	defer counter.Unlock()
+	assert.Equal(t, 1, counter.maxOpen)
+	assert.Equal(t, []string{
+		"event_type=deployment_failed&event_type=deployment_success",
+		"event_type=deployment_failed&event_type=deployment_success&event_type=status_update_event",
+	}, counter.queries)
+}
+
 func TestConnectToSSESuccess(t *testing.T) {
 	clientCfg := NewDefaultConfig()
 	// Use non-existent address as first cluster member
@@ -389,7 +580,7 @@ func TestConnectToSSESuccess(t *testing.T) {
 	client.hosts.members = append(client.hosts.members, &member{endpoint: endpoint.Server.httpSrv.URL})
 
 	// Connection should work as one of the Marathon members is up
-	stream, err := client.connectToSSE()
+	stream, err := client.connectToSSE(EventIDAll)
 	if assert.NoError(t, err, "expected no error in connectToSSE") {
 		stream.Close()
 	}
@@ -406,7 +597,7 @@ func TestConnectToSSEFailure(t *testing.T) {
 	client := endpoint.Client.(*marathonClient)
 
 	// No Marathon member is up, we should get an error
-	stream, err := client.connectToSSE()
+	stream, err := client.connectToSSE(EventIDAll)
 	if !assert.Error(t, err, "expected error in connectToSSE when all cluster members are down") {
 		stream.Close()
 	}
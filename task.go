@@ -17,10 +17,19 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+// ErrInvalidTaskID is the error of the task killing methods given a task id naming no application
+var ErrInvalidTaskID = errors.New("the task id names no application")
+
+// ErrScaleAndWipe is the error of the task killing methods asked to both scale the application
+// down and wipe the tasks, which Marathon rejects
+var ErrScaleAndWipe = errors.New("the killed tasks can't be both scaled down and wiped")
+
 // Tasks is a collection of marathon tasks
 type Tasks struct {
 	Tasks []Task `json:"tasks"`
@@ -48,27 +57,55 @@ type IPAddress struct {
 	Protocol  string `json:"protocol"`
 }
 
+const (
+	// IPProtocolIPv4 is the protocol of the IPv4 addresses of the tasks
+	IPProtocolIPv4 = "IPv4"
+	// IPProtocolIPv6 is the protocol of the IPv6 addresses of the tasks
+	IPProtocolIPv6 = "IPv6"
+)
+
+// TaskStatus is the status the tasks are listed by
+type TaskStatus string
+
+const (
+	// TaskStatusRunning lists the running tasks
+	TaskStatusRunning TaskStatus = "running"
+	// TaskStatusStaging lists the staging tasks
+	TaskStatusStaging TaskStatus = "staging"
+)
+
 // AllTasksOpts contains a payload for AllTasks method
 //		status:		Return only those tasks whose status matches this parameter.
 //				If not specified, all tasks are returned. Possible values: running, staging. Default: none.
 type AllTasksOpts struct {
-	Status string `url:"status,omitempty"`
+	Status TaskStatus `url:"status,omitempty"`
+}
+
+// TasksOpts contains a payload for TasksBy method
+//		status:		Return only those tasks whose status matches this parameter.
+//				If not specified, all tasks are returned. Possible values: running, staging. Default: none.
+type TasksOpts struct {
+	Status TaskStatus `url:"status,omitempty"`
 }
 
 // KillApplicationTasksOpts contains a payload for KillApplicationTasks method
 //		host:		kill only those tasks on a specific host (optional)
 //		scale:		Scale the app down (i.e. decrement its instances setting by the number of tasks killed) after killing the specified tasks
+//		wipe:		Destroy the persistent volumes and unreserve the resources of the resident tasks, not with scale
 type KillApplicationTasksOpts struct {
 	Host  string `url:"host,omitempty"`
 	Scale bool   `url:"scale,omitempty"`
 	Force bool   `url:"force,omitempty"`
+	Wipe  bool   `url:"wipe,omitempty"`
 }
 
 // KillTaskOpts contains a payload for task killing methods
 //		scale:		Scale the app down
+//		wipe:		Destroy the persistent volumes and unreserve the resources of the resident tasks, not with scale
 type KillTaskOpts struct {
 	Scale bool `url:"scale,omitempty"`
 	Force bool `url:"force,omitempty"`
+	Wipe  bool `url:"wipe,omitempty"`
 }
 
 // HasHealthCheckResults checks if the task has any health checks
@@ -76,6 +113,33 @@ func (r *Task) HasHealthCheckResults() bool {
 	return r.HealthCheckResults != nil && len(r.HealthCheckResults) > 0
 }
 
+// IPs returns the IP addresses of the task, i.e. its own addresses on a USER network, or else
+// its host
+func (r *Task) IPs() []string {
+	var ips []string
+	for _, address := range r.IPAddresses {
+		if address != nil && address.IPAddress != "" {
+			ips = append(ips, address.IPAddress)
+		}
+	}
+	if len(ips) == 0 && r.Host != "" {
+		ips = append(ips, r.Host)
+	}
+	return ips
+}
+
+// PrimaryIP returns the first IP address of the task of the protocol, or else its host, e.g. to
+// reach the tasks of the applications on an overlay network
+//		protocol:	IPProtocolIPv4 or IPProtocolIPv6, any protocol when empty
+func (r *Task) PrimaryIP(protocol string) string {
+	for _, address := range r.IPAddresses {
+		if address != nil && address.IPAddress != "" && (protocol == "" || address.Protocol == protocol) {
+			return address.IPAddress
+		}
+	}
+	return r.Host
+}
+
 // AllTasks lists tasks of all applications.
 //		opts: 		AllTasksOpts request payload
 func (r *marathonClient) AllTasks(opts *AllTasksOpts) (*Tasks, error) {
@@ -85,18 +149,89 @@ func (r *marathonClient) AllTasks(opts *AllTasksOpts) (*Tasks, error) {
 	}
 
 	tasks := new(Tasks)
-	if err := r.apiGet(path, nil, tasks); err != nil {
+	if err := r.apiGet("AllTasks", path, nil, tasks); err != nil {
 		return nil, err
 	}
 
 	return tasks, nil
 }
 
+// ServiceEndpoints are the endpoints of the tasks of an application for one of its service ports
+type ServiceEndpoints struct {
+	// AppID is the id of the application
+	AppID string
+	// ServicePort is the service port, zero for the applications with no ports
+	ServicePort int
+	// Endpoints are the host:port endpoints of the tasks, or their hosts for the applications
+	// with no ports
+	Endpoints []string
+}
+
+// AllTaskEndpoints lists the endpoints of the tasks of all applications per service port, as
+// listed in plain text by Marathon, e.g. to configure a load balancer with a single request
+//		opts: 		AllTasksOpts request payload
+func (r *marathonClient) AllTaskEndpoints(opts *AllTasksOpts) ([]*ServiceEndpoints, error) {
+	path, err := addOptions(marathonAPITasks, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	_, content, err := r.apiRequest("AllTaskEndpoints", "GET", path, nil, "application/json", "text/plain")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseServiceEndpoints(string(content))
+}
+
+// parseServiceEndpoints parses the tasks listed in plain text, each line being the application id
+// with the slashes replaced by underscores, the service port and the endpoints, separated by tabs
+func parseServiceEndpoints(content string) ([]*ServiceEndpoints, error) {
+	list := []*ServiceEndpoints{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Split(strings.TrimRight(line, "\t\r"), "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		endpoints := &ServiceEndpoints{
+			AppID:     validateID(strings.Replace(fields[0], "_", "/", -1)),
+			Endpoints: []string{},
+		}
+		if servicePort := strings.TrimSpace(fields[1]); servicePort != "" {
+			port, err := strconv.Atoi(servicePort)
+			if err != nil {
+				return nil, fmt.Errorf("invalid service port of %s: %s", endpoints.AppID, servicePort)
+			}
+			endpoints.ServicePort = port
+		}
+		for _, endpoint := range fields[2:] {
+			if endpoint != "" {
+				endpoints.Endpoints = append(endpoints.Endpoints, endpoint)
+			}
+		}
+		list = append(list, endpoints)
+	}
+
+	return list, nil
+}
+
 // Tasks retrieves a list of tasks for an application
 //		id:		the id of the application
 func (r *marathonClient) Tasks(id string) (*Tasks, error) {
+	return r.TasksBy(id, nil)
+}
+
+// TasksBy retrieves a list of tasks for an application by the options
+//		id:		the id of the application
+//		opts:		TasksOpts request payload
+func (r *marathonClient) TasksBy(id string, opts *TasksOpts) (*Tasks, error) {
+	path, err := addOptions(fmt.Sprintf("%s/%s/tasks", marathonAPIApps, trimRootPath(id)), opts)
+	if err != nil {
+		return nil, err
+	}
+
 	tasks := new(Tasks)
-	if err := r.apiGet(fmt.Sprintf("%s/%s/tasks", marathonAPIApps, trimRootPath(id)), nil, tasks); err != nil {
+	if err := r.apiGet("TasksBy", path, nil, tasks); err != nil {
 		return nil, err
 	}
 
@@ -107,6 +242,9 @@ func (r *marathonClient) Tasks(id string) (*Tasks, error) {
 //		id:		the id of the application
 //		opts: 		KillApplicationTasksOpts request payload
 func (r *marathonClient) KillApplicationTasks(id string, opts *KillApplicationTasksOpts) (*Tasks, error) {
+	if opts != nil && opts.Scale && opts.Wipe {
+		return nil, ErrScaleAndWipe
+	}
 	path := fmt.Sprintf("%s/%s/tasks", marathonAPIApps, trimRootPath(id))
 	path, err := addOptions(path, opts)
 	if err != nil {
@@ -114,7 +252,7 @@ func (r *marathonClient) KillApplicationTasks(id string, opts *KillApplicationTa
 	}
 
 	tasks := new(Tasks)
-	if err := r.apiDelete(path, nil, tasks); err != nil {
+	if err := r.apiDelete("KillApplicationTasks", path, nil, tasks); err != nil {
 		return nil, err
 	}
 
@@ -125,11 +263,16 @@ func (r *marathonClient) KillApplicationTasks(id string, opts *KillApplicationTa
 // 	taskID:		the id for the task
 //	opts:		KillTaskOpts request payload
 func (r *marathonClient) KillTask(taskID string, opts *KillTaskOpts) (*Task, error) {
-	appName := taskID[0:strings.LastIndex(taskID, ".")]
-	appName = strings.Replace(appName, "_", "/", -1)
+	if opts != nil && opts.Scale && opts.Wipe {
+		return nil, ErrScaleAndWipe
+	}
+	appID := taskAppID(taskID)
+	if appID == "" {
+		return nil, ErrInvalidTaskID
+	}
 	taskID = strings.Replace(taskID, "/", "_", -1)
 
-	path := fmt.Sprintf("%s/%s/tasks/%s", marathonAPIApps, appName, taskID)
+	path := fmt.Sprintf("%s/%s/tasks/%s", marathonAPIApps, trimRootPath(appID), taskID)
 	path, err := addOptions(path, opts)
 	if err != nil {
 		return nil, err
@@ -139,29 +282,58 @@ func (r *marathonClient) KillTask(taskID string, opts *KillTaskOpts) (*Task, err
 		Task Task `json:"task"`
 	})
 
-	if err := r.apiDelete(path, nil, wrappedTask); err != nil {
+	if err := r.apiDelete("KillTask", path, nil, wrappedTask); err != nil {
 		return nil, err
 	}
 
 	return &wrappedTask.Task, nil
 }
 
-// KillTasks kills tasks associated with given array of ids
+// the separators of the application and the instance in the instance based task ids, e.g.
+// "product_web.instance-<uuid>._app.1", as of Marathon 1.5
+var taskInstanceSeparators = []string{".instance-", ".marathon-"}
+
+// taskAppID derives the identifier of the application from the identifier of one of its tasks,
+// either "<app>.<uuid>" or instance based, returning an empty string for a malformed id
+func taskAppID(taskID string) string {
+	end := strings.LastIndex(taskID, ".")
+	for _, separator := range taskInstanceSeparators {
+		if index := strings.LastIndex(taskID, separator); index >= 0 {
+			end = index
+			break
+		}
+	}
+	if end <= 0 {
+		return ""
+	}
+	return validateID(strings.Replace(taskID[:end], "_", "/", -1))
+}
+
+// KillTasks kills tasks associated with given array of ids in a single request, across the
+// applications, e.g. to drain a host
 //	tasks:		the array of task ids
 //	opts:		KillTaskOpts request payload
 func (r *marathonClient) KillTasks(tasks []string, opts *KillTaskOpts) error {
+	if opts != nil && opts.Scale && opts.Wipe {
+		return ErrScaleAndWipe
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
 	path := fmt.Sprintf("%s/delete", marathonAPITasks)
 	path, err := addOptions(path, opts)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	var post struct {
 		IDs []string `json:"ids"`
 	}
-	post.IDs = tasks
+	for _, taskID := range tasks {
+		post.IDs = append(post.IDs, strings.Replace(taskID, "/", "_", -1))
+	}
 
-	return r.apiPost(path, &post, nil)
+	return r.apiPost("KillTasks", path, &post, nil)
 }
 
 // TaskEndpoints gets the endpoints i.e. HOST_IP:DYNAMIC_PORT for a specific application service
@@ -184,29 +356,31 @@ func (r *marathonClient) TaskEndpoints(name string, port int, healthCheck bool)
 	}
 
 	// step: we need to get the port index of the service we are interested in
-	portIndex, err := application.Container.Docker.ServicePortIndex(port)
+	portIndex, err := application.PortIndex(port)
 	if err != nil {
 		return nil, err
 	}
 
-	// step: do we have any tasks?
-	if application.Tasks == nil || len(application.Tasks) == 0 {
-		return nil, nil
-	}
+	return application.TaskEndpoints(portIndex, healthCheck), nil
+}
 
-	// step: if we are checking health the 'service' has a health check?
-	healthCheck = healthCheck && application.HasHealthChecks()
+// TaskEndpointsByPortName gets the endpoints i.e. HOST_IP:DYNAMIC_PORT of the tasks of an
+// application for the named port, e.g. of one of its several port mappings
+//		name:		the identifier for the application
+//		portName:	the name of the port mapping or port definition
+//		health: 	whether to check the health or not
+func (r *marathonClient) TaskEndpointsByPortName(name, portName string, healthCheck bool) ([]string, error) {
+	application, err := r.Application(name)
+	if err != nil {
+		return nil, err
+	}
 
-	// step: iterate the tasks and extract the dynamic ports
-	var list []string
-	for _, task := range application.Tasks {
-		if !healthCheck || task.allHealthChecksAlive() {
-			endpoint := fmt.Sprintf("%s:%d", task.Host, task.Ports[portIndex])
-			list = append(list, endpoint)
-		}
+	portIndex, err := application.PortIndexByName(portName)
+	if err != nil {
+		return nil, err
 	}
 
-	return list, nil
+	return application.TaskEndpoints(portIndex, healthCheck), nil
 }
 
 func (r *Task) allHealthChecksAlive() bool {
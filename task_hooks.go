@@ -0,0 +1,212 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTaskHookRetries    = 5
+	defaultTaskHookBackoff    = time.Second
+	defaultTaskHookMaxBackoff = 30 * time.Second
+)
+
+// TaskHandler handles a status update of a task, the update being retried when an error is returned
+type TaskHandler func(update *EventStatusUpdate) error
+
+// TaskHooksOpts contains the options of the TaskHooks
+//		retries:	the number of times a failing handler is retried, defaults to 5, negative
+//				not retrying
+//		backoff:	the delay before the first retry, doubled on each retry, defaults to a second
+//		maxBackoff:	the maximum delay between the retries, defaults to 30 seconds
+//		onError:	called with the last error of a handler which failed all its retries
+type TaskHooksOpts struct {
+	Retries    int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	OnError    func(update *EventStatusUpdate, err error)
+}
+
+// taskHook is a handler registered for some task statuses of the matching applications
+type taskHook struct {
+	pattern  string
+	statuses []string
+	handler  TaskHandler
+}
+
+// matches checks if the hook handles the status update
+func (h *taskHook) matches(update *EventStatusUpdate) bool {
+	if !contains(h.statuses, update.TaskStatus) {
+		return false
+	}
+	if h.pattern == "" {
+		return true
+	}
+	matched, _ := path.Match(h.pattern, update.AppID)
+	return matched
+}
+
+// TaskHooks invokes handlers on the lifecycle of the tasks, so small operators can react to the
+// tasks starting, failing or being killed without handling the events themselves. The hooks
+// require the events transport of the client to be configured.
+type TaskHooks struct {
+	sync.RWMutex
+	// the client the events are received from
+	client Marathon
+	opts   TaskHooksOpts
+	hooks  []*taskHook
+	events EventsChannel
+	// the handlers in progress, including their retries
+	handlers sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewTaskHooks starts listening to the status updates of the tasks, the handlers being registered
+// with OnTaskStarted, OnTaskFailed and OnTaskKilled
+//		client:		the client the events are received from
+//		opts:		the retry options of the handlers
+func NewTaskHooks(client Marathon, opts *TaskHooksOpts) (*TaskHooks, error) {
+	hooks := &TaskHooks{
+		client: client,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if opts != nil {
+		hooks.opts = *opts
+	}
+	if hooks.opts.Retries == 0 {
+		hooks.opts.Retries = defaultTaskHookRetries
+	}
+	if hooks.opts.Backoff <= 0 {
+		hooks.opts.Backoff = defaultTaskHookBackoff
+	}
+	if hooks.opts.MaxBackoff <= 0 {
+		hooks.opts.MaxBackoff = defaultTaskHookMaxBackoff
+	}
+
+	events, err := client.AddEventsListener(EventIDStatusUpdate)
+	if err != nil {
+		return nil, err
+	}
+	hooks.events = events
+	go hooks.listen()
+
+	return hooks, nil
+}
+
+// OnTaskStarted registers a handler invoked when a task of the matching applications is running
+//		appIDPattern:	the pattern of the application ids, as matched by path.Match (e.g.
+//				/prod/*), empty matching all the applications
+//		handler:	the handler of the status update
+func (h *TaskHooks) OnTaskStarted(appIDPattern string, handler TaskHandler) *TaskHooks {
+	return h.on(appIDPattern, handler, "TASK_RUNNING")
+}
+
+// OnTaskFailed registers a handler invoked when a task of the matching applications failed
+//		appIDPattern:	the pattern of the application ids, as matched by path.Match (e.g.
+//				/prod/*), empty matching all the applications
+//		handler:	the handler of the status update
+func (h *TaskHooks) OnTaskFailed(appIDPattern string, handler TaskHandler) *TaskHooks {
+	return h.on(appIDPattern, handler, "TASK_FAILED", "TASK_ERROR")
+}
+
+// OnTaskKilled registers a handler invoked when a task of the matching applications was killed
+//		appIDPattern:	the pattern of the application ids, as matched by path.Match (e.g.
+//				/prod/*), empty matching all the applications
+//		handler:	the handler of the status update
+func (h *TaskHooks) OnTaskKilled(appIDPattern string, handler TaskHandler) *TaskHooks {
+	return h.on(appIDPattern, handler, "TASK_KILLED")
+}
+
+func (h *TaskHooks) on(appIDPattern string, handler TaskHandler, statuses ...string) *TaskHooks {
+	h.Lock()
+	defer h.Unlock()
+	h.hooks = append(h.hooks, &taskHook{pattern: appIDPattern, statuses: statuses, handler: handler})
+	return h
+}
+
+// Stop stops listening to the events, cancels the pending retries and waits for the handlers in
+// progress to return
+func (h *TaskHooks) Stop() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+	<-h.done
+}
+
+// listen dispatches the status updates to the matching hooks until stopped
+func (h *TaskHooks) listen() {
+	defer close(h.done)
+	defer h.handlers.Wait()
+	defer h.client.RemoveEventsListener(h.events)
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case event, more := <-h.events:
+			if !more {
+				return
+			}
+			update, ok := event.Event.(*EventStatusUpdate)
+			if !ok {
+				continue
+			}
+			h.RLock()
+			for _, hook := range h.hooks {
+				if hook.matches(update) {
+					h.handlers.Add(1)
+					go h.handle(hook, update)
+				}
+			}
+			h.RUnlock()
+		}
+	}
+}
+
+// handle invokes the handler of the hook, retrying with an exponential backoff on error
+func (h *TaskHooks) handle(hook *taskHook, update *EventStatusUpdate) {
+	defer h.handlers.Done()
+
+	backoff := h.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		err := hook.handler(update)
+		if err == nil {
+			return
+		}
+		if attempt >= h.opts.Retries {
+			if h.opts.OnError != nil {
+				h.opts.OnError(update, err)
+			}
+			return
+		}
+
+		select {
+		case <-h.stop:
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > h.opts.MaxBackoff {
+			backoff = h.opts.MaxBackoff
+		}
+	}
+}
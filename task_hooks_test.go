@@ -0,0 +1,141 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statusUpdateEvent(appID, taskID, status string) string {
+	return fmt.Sprintf(`{"eventType": "status_update_event", "appId": "%s", "taskId": "%s", "taskStatus": "%s"}`,
+		appID, taskID, status)
+}
+
+func TestTaskHookMatches(t *testing.T) {
+	hook := &taskHook{pattern: "/prod/*", statuses: []string{"TASK_FAILED", "TASK_ERROR"}}
+	assert.True(t, hook.matches(&EventStatusUpdate{AppID: "/prod/api", TaskStatus: "TASK_FAILED"}))
+	assert.True(t, hook.matches(&EventStatusUpdate{AppID: "/prod/web", TaskStatus: "TASK_ERROR"}))
+	assert.False(t, hook.matches(&EventStatusUpdate{AppID: "/prod/api", TaskStatus: "TASK_RUNNING"}))
+	assert.False(t, hook.matches(&EventStatusUpdate{AppID: "/dev/api", TaskStatus: "TASK_FAILED"}))
+	assert.False(t, hook.matches(&EventStatusUpdate{AppID: "/prod/backend/api", TaskStatus: "TASK_FAILED"}))
+
+	hook.pattern = ""
+	assert.True(t, hook.matches(&EventStatusUpdate{AppID: "/dev/api", TaskStatus: "TASK_FAILED"}))
+}
+
+func TestTaskHooks(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	var lock sync.Mutex
+	var started, killed []string
+	failures := 0
+	failed := make(chan error, 1)
+	hooks, err := NewTaskHooks(endpoint.Client, &TaskHooksOpts{
+		Retries: 2,
+		Backoff: time.Millisecond,
+		OnError: func(update *EventStatusUpdate, err error) {
+			failed <- err
+		},
+	})
+	require.NoError(t, err)
+	defer hooks.Stop()
+
+	hooks.OnTaskStarted("/prod/*", func(update *EventStatusUpdate) error {
+		lock.Lock()
+		defer lock.Unlock()
+		started = append(started, update.TaskID)
+		return nil
+	}).OnTaskKilled("", func(update *EventStatusUpdate) error {
+		lock.Lock()
+		defer lock.Unlock()
+		killed = append(killed, update.TaskID)
+		return nil
+	}).OnTaskFailed("/prod/*", func(update *EventStatusUpdate) error {
+		lock.Lock()
+		defer lock.Unlock()
+		failures++
+		return errors.New("failed to reschedule")
+	})
+
+	// Give it a bit of time so that the subscription can be set up
+	time.Sleep(SSEConnectWaitTime)
+
+	endpoint.Server.PublishEvent(statusUpdateEvent("/prod/api", "api.1", "TASK_RUNNING"))
+	endpoint.Server.PublishEvent(statusUpdateEvent("/dev/api", "api.2", "TASK_RUNNING"))
+	endpoint.Server.PublishEvent(statusUpdateEvent("/dev/api", "api.3", "TASK_KILLED"))
+	endpoint.Server.PublishEvent(statusUpdateEvent("/prod/api", "api.4", "TASK_FAILED"))
+
+	select {
+	case err := <-failed:
+		assert.EqualError(t, err, "failed to reschedule")
+	case <-time.After(eventPublishTimeout):
+		require.Fail(t, "the failing handler did not give up in time")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Equal(t, []string{"api.1"}, started)
+	assert.Equal(t, []string{"api.3"}, killed)
+	// step: the handler was retried twice
+	assert.Equal(t, 3, failures)
+}
+
+func TestTaskHooksStop(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	calls := make(chan struct{}, 10)
+	hooks, err := NewTaskHooks(endpoint.Client, &TaskHooksOpts{Backoff: time.Hour})
+	require.NoError(t, err)
+	hooks.OnTaskFailed("", func(update *EventStatusUpdate) error {
+		calls <- struct{}{}
+		return errors.New("failed")
+	})
+	time.Sleep(SSEConnectWaitTime)
+
+	endpoint.Server.PublishEvent(statusUpdateEvent("/app", "app.1", "TASK_FAILED"))
+	select {
+	case <-calls:
+	case <-time.After(eventPublishTimeout):
+		require.Fail(t, "the handler was not called in time")
+	}
+
+	// step: the pending retry is cancelled
+	stopped := make(chan struct{})
+	go func() {
+		hooks.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "the hooks did not stop in time")
+	}
+	assert.Len(t, calls, 0)
+}
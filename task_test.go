@@ -17,9 +17,13 @@ limitations under the License.
 package marathon
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHasHealthCheckResults(t *testing.T) {
@@ -29,6 +33,24 @@ func TestHasHealthCheckResults(t *testing.T) {
 	assert.True(t, task.HasHealthCheckResults())
 }
 
+func TestTaskIPs(t *testing.T) {
+	task := Task{Host: "agent-1"}
+	assert.Equal(t, []string{"agent-1"}, task.IPs())
+	assert.Equal(t, "agent-1", task.PrimaryIP(""))
+
+	// step: the tasks on a USER network have their own addresses
+	task.IPAddresses = []*IPAddress{
+		{IPAddress: "fd00::1", Protocol: IPProtocolIPv6},
+		{IPAddress: "9.0.0.1", Protocol: IPProtocolIPv4},
+	}
+	assert.Equal(t, []string{"fd00::1", "9.0.0.1"}, task.IPs())
+	assert.Equal(t, "fd00::1", task.PrimaryIP(""))
+	assert.Equal(t, "9.0.0.1", task.PrimaryIP(IPProtocolIPv4))
+
+	task.IPAddresses = task.IPAddresses[:1]
+	assert.Equal(t, "agent-1", task.PrimaryIP(IPProtocolIPv4))
+}
+
 func TestAllTasks(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -39,13 +61,39 @@ func TestAllTasks(t *testing.T) {
 		assert.Equal(t, len(tasks.Tasks), 2)
 	}
 
-	tasks, err = endpoint.Client.AllTasks(&AllTasksOpts{Status: "staging"})
+	tasks, err = endpoint.Client.AllTasks(&AllTasksOpts{Status: TaskStatusStaging})
 	assert.Nil(t, err)
 	if assert.NotNil(t, tasks) {
 		assert.Equal(t, len(tasks.Tasks), 0)
 	}
 }
 
+func TestAllTaskEndpoints(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	list, err := endpoint.Client.AllTaskEndpoints(&AllTasksOpts{Status: TaskStatusRunning})
+	require.NoError(t, err)
+	assert.Equal(t, []*ServiceEndpoints{
+		{AppID: "/product/web", ServicePort: 10000, Endpoints: []string{"10.0.0.1:31000", "10.0.0.2:31002"}},
+		{AppID: "/product/web", ServicePort: 10001, Endpoints: []string{"10.0.0.1:31001", "10.0.0.2:31003"}},
+		{AppID: "/worker", Endpoints: []string{"10.0.0.3"}},
+	}, list)
+}
+
+func TestParseServiceEndpoints(t *testing.T) {
+	list, err := parseServiceEndpoints("")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	list, err = parseServiceEndpoints("web\t10000\t\n")
+	require.NoError(t, err)
+	assert.Equal(t, []*ServiceEndpoints{{AppID: "/web", ServicePort: 10000, Endpoints: []string{}}}, list)
+
+	_, err = parseServiceEndpoints("web\thttp\t10.0.0.1:31000\t\n")
+	assert.Error(t, err)
+}
+
 func TestTasks(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -57,6 +105,22 @@ func TestTasks(t *testing.T) {
 	}
 }
 
+func TestTasksBy(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	tasks, err := endpoint.Client.TasksBy(fakeAppName, &TasksOpts{Status: TaskStatusRunning})
+	require.NoError(t, err)
+	if assert.Len(t, tasks.Tasks, 1) {
+		assert.Equal(t, "TASK_RUNNING", tasks.Tasks[0].State)
+	}
+
+	// step: no status lists all the tasks
+	tasks, err = endpoint.Client.TasksBy(fakeAppName, &TasksOpts{})
+	require.NoError(t, err)
+	assert.Len(t, tasks.Tasks, 2)
+}
+
 func TestKillApplicationTasks(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -64,6 +128,19 @@ func TestKillApplicationTasks(t *testing.T) {
 	tasks, err := endpoint.Client.KillApplicationTasks(fakeAppName, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, tasks)
+
+	// step: drain the resident tasks of a host
+	tasks, err = endpoint.Client.KillApplicationTasks(fakeAppName, &KillApplicationTasksOpts{Host: "fake-host", Wipe: true})
+	require.NoError(t, err)
+	if assert.Len(t, tasks.Tasks, 1) {
+		assert.Equal(t, "fake-host", tasks.Tasks[0].Host)
+	}
+
+	_, err = endpoint.Client.KillApplicationTasks(fakeAppName, &KillApplicationTasksOpts{Scale: true, Wipe: true})
+	assert.Equal(t, ErrScaleAndWipe, err)
+	_, err = endpoint.Client.KillTask(fakeTaskID, &KillTaskOpts{Scale: true, Wipe: true})
+	assert.Equal(t, ErrScaleAndWipe, err)
+	assert.Equal(t, ErrScaleAndWipe, endpoint.Client.KillTasks([]string{fakeTaskID}, &KillTaskOpts{Scale: true, Wipe: true}))
 }
 
 func TestKillTask(t *testing.T) {
@@ -85,6 +162,32 @@ func TestKillTask(t *testing.T) {
 	}
 }
 
+func TestKillTaskInvalidID(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.KillTask("fake-task", nil)
+	assert.Equal(t, ErrInvalidTaskID, err)
+}
+
+func TestTaskAppID(t *testing.T) {
+	cases := []struct {
+		taskID   string
+		expected string
+	}{
+		{"fake-app.fake-task", "/fake-app"},
+		{"fake-group_fake-app.fake-task", "/fake-group/fake-app"},
+		{"fake-group/fake-app.fake-task", "/fake-group/fake-app"},
+		{"product_web.instance-4b5c7ad2-8cc1-11e7-bb31-be2e44b06b34._app.1", "/product/web"},
+		{"product_web.marathon-4b5c7ad2-8cc1-11e7-bb31-be2e44b06b34", "/product/web"},
+		{"fake-task", ""},
+		{".fake-task", ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, taskAppID(c.taskID), "task id %s", c.taskID)
+	}
+}
+
 func TestKillTasks(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -93,6 +196,28 @@ func TestKillTasks(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestKillTasksRequest(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.URL.RequestURI()+" "+string(body))
+		w.Write([]byte(`{"tasks": []}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	// step: no task is killed with no request
+	require.NoError(t, client.KillTasks(nil, nil))
+	assert.Empty(t, requests)
+
+	require.NoError(t, client.KillTasks([]string{"product/web.1", "worker.2"}, &KillTaskOpts{Wipe: true}))
+	assert.Equal(t, []string{`/v2/tasks/delete?wipe=true {"ids":["product_web.1","worker.2"]}`}, requests)
+}
+
 func TestTaskEndpoints(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
@@ -113,3 +238,15 @@ func TestTaskEndpoints(t *testing.T) {
 	_, err = endpoint.Client.TaskEndpoints(fakeAppNameBroken, 80, true)
 	assert.Error(t, err)
 }
+
+func TestTaskEndpointsByPortName(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	endpoints, err := endpoint.Client.TaskEndpointsByPortName(fakeAppNameBroken, "http", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.141.141.10:31045", "10.141.141.10:31234"}, endpoints)
+
+	_, err = endpoint.Client.TaskEndpointsByPortName(fakeAppNameBroken, "admin", false)
+	assert.Error(t, err)
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package marathon
 
 import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -50,6 +53,7 @@ var (
 
 type indexedResponse struct {
 	Index   int               `yaml:"index,omitempty"`
+	Status  int               `yaml:"status,omitempty"`
 	Content string            `yaml:"content,omitempty"`
 	Headers map[string]string `yaml:"headers,omitempty"`
 }
@@ -69,6 +73,8 @@ type restMethod struct {
 	URI string `yaml:"uri,omitempty"`
 	// the http method type (GET|PUT etc)
 	Method string `yaml:"method,omitempty"`
+	// the status code of the response, 200 by default
+	Status int `yaml:"status,omitempty"`
 	// the content i.e. response
 	Content string `yaml:"content,omitempty"`
 	// ContentSequence is a sequence of responses that are returned in order.
@@ -81,6 +87,7 @@ type restMethod struct {
 
 // serverConfig holds the Marathon server configuration
 type serverConfig struct {
+	sync.Mutex
 	// Username for basic auth
 	username string
 	// Password for basic auth
@@ -90,6 +97,22 @@ type serverConfig struct {
 	// scope is an arbitrary test scope to distinguish fake responses from
 	// otherwise equal HTTP methods and query strings.
 	scope string
+	// the key of the service account logging in for the DCOS token
+	serviceAccountKey *rsa.PublicKey
+}
+
+// token returns the DCOS token accepted by the server
+func (s *serverConfig) token() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.dcosToken
+}
+
+// rotateToken changes the DCOS token accepted by the server, e.g. to expire the one of the client
+func (s *serverConfig) rotateToken(token string) {
+	s.Lock()
+	defer s.Unlock()
+	s.dcosToken = token
 }
 
 // configContainer holds both server and client Marathon configuration
@@ -104,6 +127,20 @@ type fakeServer struct {
 	eventSrv        *eventsource.Server
 	httpSrv         *httptest.Server
 	fakeRespIndices *responseIndices
+	requests        *requestLog
+}
+
+// fakeRequest is a request received by the fake server
+type fakeRequest struct {
+	method string
+	uri    string
+	body   []byte
+}
+
+// requestLog records the requests received by the fake server
+type requestLog struct {
+	sync.Mutex
+	requests []fakeRequest
 }
 
 type endpoint struct {
@@ -146,9 +183,13 @@ func newFakeMarathonEndpoint(t *testing.T, configs *configContainer) *endpoint {
 	}
 
 	fakeRespIndices := newResponseIndices()
+	requests := &requestLog{}
 
 	// step: create the HTTP router
 	mux := http.NewServeMux()
+	if configs.server.serviceAccountKey != nil {
+		mux.HandleFunc(dcosLoginPath, loginHandler(configs.server))
+	}
 	mux.HandleFunc("/v2/events", authMiddleware(configs.server, eventSrv.Handler("event")))
 	mux.HandleFunc("/", authMiddleware(configs.server, func(writer http.ResponseWriter, reader *http.Request) {
 		respKey := fakeResponseMapKey(reader.Method, reader.RequestURI, configs.server.scope)
@@ -165,6 +206,9 @@ func newFakeMarathonEndpoint(t *testing.T, configs *configContainer) *endpoint {
 					for k, v := range response.Headers {
 						writer.Header().Add(k, v)
 					}
+					if response.Status != 0 {
+						writer.WriteHeader(response.Status)
+					}
 
 					writer.Write([]byte(response.Content))
 					return
@@ -176,7 +220,7 @@ func newFakeMarathonEndpoint(t *testing.T, configs *configContainer) *endpoint {
 	}))
 
 	// step: create HTTP test server
-	httpSrv := httptest.NewServer(mux)
+	httpSrv := httptest.NewServer(requests.record(mux))
 
 	if configs.client.URL == defaultConfig.URL {
 		configs.client.URL = getTestURL(httpSrv.URL)
@@ -193,12 +237,41 @@ func newFakeMarathonEndpoint(t *testing.T, configs *configContainer) *endpoint {
 			eventSrv:        eventSrv,
 			httpSrv:         httpSrv,
 			fakeRespIndices: fakeRespIndices,
+			requests:        requests,
 		},
 		Client: client,
 		URL:    configs.client.URL,
 	}
 }
 
+// record records the requests before handling them
+func (l *requestLog) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		l.Lock()
+		l.requests = append(l.requests, fakeRequest{method: r.Method, uri: r.RequestURI, body: body})
+		l.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loginHandler logs the service account in, issuing the DCOS token for a login token signed by
+// its key
+func loginHandler(server *serverConfig) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var login struct {
+			UID   string `json:"uid"`
+			Token string `json:"token"`
+		}
+		if json.NewDecoder(r.Body).Decode(&login) != nil || !verifyLoginToken(server.serviceAccountKey, login.UID, login.Token) {
+			http.Error(w, `{"title": "Invalid authentication proof", "message": "invalid token"}`, 401)
+			return
+		}
+		fmt.Fprintf(w, `{"token": %q}`, server.token())
+	}
+}
+
 // basicAuthMiddleware handles basic auth
 func basicAuthMiddleware(server *serverConfig, next http.HandlerFunc) func(http.ResponseWriter, *http.Request) {
 	unauthorized := `{"message": "invalid username or password"}`
@@ -230,7 +303,7 @@ func authMiddleware(server *serverConfig, next http.HandlerFunc) func(http.Respo
 	return func(w http.ResponseWriter, r *http.Request) {
 		// step: is authentication required?
 
-		if server.dcosToken != "" {
+		if token := server.token(); token != "" {
 			headerValue := r.Header.Get("Authorization")
 			// step: if no auth found, error it
 			if headerValue == "" {
@@ -240,7 +313,7 @@ func authMiddleware(server *serverConfig, next http.HandlerFunc) func(http.Respo
 
 			s := strings.Split(headerValue, "=")
 
-			if s[1] != server.dcosToken {
+			if s[1] != token {
 				http.Error(w, unauthorized, 401)
 				return
 			}
@@ -292,6 +365,7 @@ func initFakeMarathonResponses(t *testing.T) {
 					indexedResponse{
 						// Index -1 indicates a static response.
 						Index:   -1,
+						Status:  method.Status,
 						Content: method.Content,
 						Headers: method.Headers,
 					},
@@ -321,6 +395,19 @@ func (s *fakeServer) PublishEvent(event string) {
 	s.eventSrv.Publish([]string{"event"}, fakeEvent{event})
 }
 
+// Requests returns the requests received on the path, whatever their query, in the order received
+func (s *fakeServer) Requests(method, path string) []fakeRequest {
+	s.requests.Lock()
+	defer s.requests.Unlock()
+	var requests []fakeRequest
+	for _, request := range s.requests.requests {
+		if request.method == method && strings.SplitN(request.uri, "?", 2)[0] == path {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
 func (s *fakeServer) Close() {
 	s.eventSrv.Close()
 	s.httpSrv.Close()
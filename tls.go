@@ -0,0 +1,211 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrTLSWithTransport is returned when the TLS options are combined with a custom transport, which
+// they can't be applied to
+var ErrTLSWithTransport = errors.New("the TLS options can't be applied to a custom transport, configure its TLSClientConfig instead")
+
+// ErrTLSPinMismatch is the error of the connections to a Marathon host whose certificate matches
+// none of its pins
+var ErrTLSPinMismatch = errors.New("the certificate of the Marathon host matches none of its pins")
+
+const (
+	// the prefix of the pins of the SHA-256 hash of the subject public key info, base64 encoded
+	spkiPinPrefix = "sha256/"
+	// the prefix of the pins of the SHA-256 fingerprint of the certificate, hex encoded
+	fingerprintPinPrefix = "sha256:"
+	// the host of the pins applying to the hosts with no pins
+	anyHostPin = "*"
+)
+
+// hasTLSOptions checks if any of the TLS options is set
+func (c Config) hasTLSOptions() bool {
+	return c.TLSCAFile != "" || c.TLSCertFile != "" || c.TLSKeyFile != "" || c.TLSServerName != "" ||
+		c.TLSInsecureSkipVerify || len(c.TLSPins) > 0
+}
+
+// tlsPins holds the pins of each host, as raw SHA-256 hashes
+type tlsPins map[string]struct {
+	spki         [][]byte
+	fingerprints [][]byte
+}
+
+// parseTLSPins parses and validates the pins of the hosts
+func parseTLSPins(pins map[string][]string) (tlsPins, error) {
+	parsed := make(tlsPins)
+	for host, hostPins := range pins {
+		entry := parsed[host]
+		for _, pin := range hostPins {
+			switch {
+			case strings.HasPrefix(pin, spkiPinPrefix):
+				hash, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, spkiPinPrefix))
+				if err != nil || len(hash) != sha256.Size {
+					return nil, fmt.Errorf("invalid SPKI pin %q of %s", pin, host)
+				}
+				entry.spki = append(entry.spki, hash)
+			case strings.HasPrefix(pin, fingerprintPinPrefix):
+				// step: the fingerprints are commonly written with colons, e.g. by openssl
+				encoded := strings.Replace(strings.TrimPrefix(pin, fingerprintPinPrefix), ":", "", -1)
+				hash, err := hex.DecodeString(encoded)
+				if err != nil || len(hash) != sha256.Size {
+					return nil, fmt.Errorf("invalid certificate fingerprint pin %q of %s", pin, host)
+				}
+				entry.fingerprints = append(entry.fingerprints, hash)
+			default:
+				return nil, fmt.Errorf("unsupported pin %q of %s, expected sha256/<base64> or sha256:<hex>", pin, host)
+			}
+		}
+		parsed[host] = entry
+	}
+	return parsed, nil
+}
+
+// verify checks that a certificate of the chain presented by the host matches one of its pins.
+// The hosts with no pins, nor any pins for all the hosts, aren't checked, unless the host is
+// unknown, e.g. an IP address reached through a proxy.
+func (p tlsPins) verify(host string, state tls.ConnectionState) error {
+	entry, found := p[host]
+	if !found {
+		if entry, found = p[anyHostPin]; !found {
+			if host == "" {
+				return ErrTLSPinMismatch
+			}
+			return nil
+		}
+	}
+	for _, certificate := range state.PeerCertificates {
+		spki := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+		fingerprint := sha256.Sum256(certificate.Raw)
+		for _, pin := range entry.spki {
+			if string(pin) == string(spki[:]) {
+				return nil
+			}
+		}
+		for _, pin := range entry.fingerprints {
+			if string(pin) == string(fingerprint[:]) {
+				return nil
+			}
+		}
+	}
+	return ErrTLSPinMismatch
+}
+
+// dialTLS returns the dialer of the TLS connections checking the pins of the host dialed, which
+// the TLS connection state doesn't carry for the IP addresses
+func (p tlsPins) dialTLS(dialer *net.Dialer, tlsConfig *tls.Config, handshakeTimeout time.Duration) func(context.Context, string, string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		hostConfig := tlsConfig.Clone()
+		if hostConfig.ServerName == "" {
+			hostConfig.ServerName = host
+		}
+		hostConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return p.verify(host, state)
+		}
+		ctx, cancel := context.WithTimeout(ctx, dialer.Timeout+handshakeTimeout)
+		defer cancel()
+		return (&tls.Dialer{NetDialer: dialer, Config: hostConfig}).DialContext(ctx, network, addr)
+	}
+}
+
+// newTLSConfig creates the TLS configuration of the connections to Marathon from the TLS options
+func newTLSConfig(config Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         config.TLSServerName,
+		InsecureSkipVerify: config.TLSInsecureSkipVerify,
+	}
+
+	// step: trust the CA bundle instead of the system roots
+	if config.TLSCAFile != "" {
+		bundle, err := ioutil.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CA bundle: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle %s", config.TLSCAFile)
+		}
+	}
+
+	// step: present the client certificate for mutual TLS
+	if config.TLSCertFile != "" || config.TLSKeyFile != "" {
+		if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+			return nil, errors.New("both the client certificate and key are required")
+		}
+		certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// newTLSTransport creates the transport of the default HTTP clients applying the TLS options
+func newTLSTransport(config Config) (http.RoundTripper, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		Dial:                  dialer.Dial,
+		ResponseHeaderTimeout: 10 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+
+	// step: check the pins once the chain is verified, or even when it isn't
+	if len(config.TLSPins) > 0 {
+		pins, err := parseTLSPins(config.TLSPins)
+		if err != nil {
+			return nil, err
+		}
+		// step: the connections through a proxy are only known by their server name
+		tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+			return pins.verify(state.ServerName, state)
+		}
+		transport.DialTLSContext = pins.dialTLS(dialer, tlsConfig, transport.TLSHandshakeTimeout)
+	}
+
+	return transport, nil
+}
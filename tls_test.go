@@ -0,0 +1,196 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes the PEM block to a file of the directory
+func writePEM(t *testing.T, dir, name, blockType string, content []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: content}), 0600))
+	return path
+}
+
+// newClientCertificate generates a self-signed client certificate, returning the paths of the
+// certificate and key files
+func newClientCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "go-marathon"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return writePEM(t, dir, "client.crt", "CERTIFICATE", certificate), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyBytes)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-marathon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var clientCN string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientCN = r.TLS.PeerCertificates[0].Subject.CommonName
+		w.Write([]byte(`pong`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.TLSCAFile = writePEM(t, dir, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+	config.TLSCertFile, config.TLSKeyFile = newClientCertificate(t, dir)
+	// step: the certificate of the test server is issued to example.com
+	config.TLSServerName = "example.com"
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, "go-marathon", clientCN)
+
+	// step: the connection fails without the client certificate
+	config.TLSCertFile, config.TLSKeyFile = "", ""
+	client, err = NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Ping()
+	assert.Error(t, err)
+}
+
+func TestTLSInsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`pong`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Ping()
+	assert.Error(t, err)
+
+	config.TLSInsecureSkipVerify = true
+	client, err = NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Ping()
+	assert.NoError(t, err)
+}
+
+func TestTLSPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`pong`))
+	}))
+	defer server.Close()
+	spki := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	fingerprint := sha256.Sum256(server.Certificate().Raw)
+	otherHash := sha256.Sum256([]byte("other"))
+
+	cases := []struct {
+		pins     map[string][]string
+		expected bool
+	}{
+		{map[string][]string{"127.0.0.1": {"sha256/" + base64.StdEncoding.EncodeToString(spki[:])}}, true},
+		{map[string][]string{"127.0.0.1": {"sha256:" + strings.ToUpper(hex.EncodeToString(fingerprint[:]))}}, true},
+		{map[string][]string{"*": {"sha256/" + base64.StdEncoding.EncodeToString(otherHash[:])}}, false},
+		{map[string][]string{"127.0.0.1": {"sha256:" + hex.EncodeToString(otherHash[:])}}, false},
+		// step: the hosts with no pins aren't checked
+		{map[string][]string{"marathon.example.com": {"sha256:" + hex.EncodeToString(otherHash[:])}}, true},
+	}
+	for i, c := range cases {
+		config := NewDefaultConfig()
+		config.URL = server.URL
+		// step: the pins are enforced even though the chain isn't verified
+		config.TLSInsecureSkipVerify = true
+		config.TLSPins = c.pins
+		client, err := NewClient(config)
+		require.NoError(t, err)
+		_, err = client.Ping()
+		assert.Equal(t, c.expected, err == nil, "case %d: %v", i, err)
+	}
+
+	// step: the unknown hosts are rejected, as they might be pinned
+	pins, err := parseTLSPins(map[string][]string{"127.0.0.1": {"sha256:" + hex.EncodeToString(fingerprint[:])}})
+	require.NoError(t, err)
+	assert.Equal(t, ErrTLSPinMismatch, pins.verify("", tls.ConnectionState{}))
+	assert.NoError(t, pins.verify("127.0.0.1", tls.ConnectionState{PeerCertificates: []*x509.Certificate{server.Certificate()}}))
+}
+
+func TestTLSOptionsErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-marathon")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config := NewDefaultConfig()
+	config.TLSInsecureSkipVerify = true
+	config.Transport = http.DefaultTransport
+	_, err = NewClient(config)
+	assert.Equal(t, ErrTLSWithTransport, err)
+
+	config = NewDefaultConfig()
+	config.TLSCAFile = filepath.Join(dir, "missing.crt")
+	_, err = NewClient(config)
+	assert.Error(t, err)
+
+	config.TLSCAFile = writePEM(t, dir, "empty.crt", "EMPTY", nil)
+	_, err = NewClient(config)
+	assert.Error(t, err)
+
+	config = NewDefaultConfig()
+	config.TLSCertFile, _ = newClientCertificate(t, dir)
+	_, err = NewClient(config)
+	assert.Error(t, err)
+
+	for _, pin := range []string{"md5:abcd", "sha256/not-base64", "sha256:abcd"} {
+		config = NewDefaultConfig()
+		config.TLSPins = map[string][]string{"*": {pin}}
+		_, err = NewClient(config)
+		assert.Error(t, err, pin)
+	}
+}
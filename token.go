@@ -0,0 +1,95 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// TokenProvider acquires the DC/OS authentication tokens of the client, e.g. logging in with the
+// credentials of a service account, as the tokens expire
+type TokenProvider interface {
+	// Token returns a new token, called once the current token is rejected
+	Token() (string, error)
+}
+
+// TokenProviderFunc is a TokenProvider function
+type TokenProviderFunc func() (string, error)
+
+// Token calls the function
+func (f TokenProviderFunc) Token() (string, error) {
+	return f()
+}
+
+// tokenSource holds the current token of the client, refreshed from the provider. The refreshes
+// are serialized, the requests rejected with a token which was already replaced using the new one
+type tokenSource struct {
+	sync.Mutex
+	provider TokenProvider
+	token    string
+}
+
+// newTokenSource returns the token source of the configuration, starting with its DC/OS token
+func newTokenSource(config Config) *tokenSource {
+	return &tokenSource{provider: config.TokenProvider, token: config.DCOSToken}
+}
+
+// current returns the current token, empty when there is none yet
+func (s *tokenSource) current() string {
+	s.Lock()
+	defer s.Unlock()
+	return s.token
+}
+
+// refresh acquires a new token unless the rejected one was already replaced
+//		rejected:	the token the request was rejected with, empty if it had none
+func (s *tokenSource) refresh(rejected string) error {
+	s.Lock()
+	defer s.Unlock()
+	if s.token != rejected {
+		return nil
+	}
+	token, err := s.provider.Token()
+	if err != nil {
+		return fmt.Errorf("failed to refresh the token: %w", err)
+	}
+	s.token = token
+	return nil
+}
+
+// token returns the token the requests are authenticated with, empty for none
+func (rc *httpClient) token() string {
+	if rc.tokens == nil {
+		return rc.config.DCOSToken
+	}
+	return rc.tokens.current()
+}
+
+// refreshesToken checks if the response rejected the token of the request, and the client can
+// acquire a new one to replay it with
+func (rc *httpClient) refreshesToken(response *http.Response) bool {
+	return response.StatusCode == http.StatusUnauthorized && rc.tokens != nil && rc.tokens.provider != nil
+}
+
+// refreshToken acquires a new token, the request having been rejected
+func (rc *httpClient) refreshToken(request *http.Request) error {
+	rejected := strings.TrimPrefix(request.Header.Get("Authorization"), "token=")
+	return rc.tokens.refresh(rejected)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countedTokens returns the tokens token-1, token-2... counting the calls
+type countedTokens struct {
+	calls int64
+	delay time.Duration
+}
+
+func (c *countedTokens) Token() (string, error) {
+	time.Sleep(c.delay)
+	return fmt.Sprintf("token-%d", atomic.AddInt64(&c.calls, 1)), nil
+}
+
+func TestTokenRefresh(t *testing.T) {
+	server := &serverConfig{dcosToken: "token-1"}
+	provider := &countedTokens{}
+	config := NewDefaultConfig()
+	config.DCOSToken = "expired"
+	config.TokenProvider = provider
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: server})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	// step: the request rejected with the expired token is replayed with a new one
+	_, err := client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&provider.calls))
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&provider.calls))
+
+	// step: and again once the new token expires
+	server.rotateToken("token-2")
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&provider.calls))
+
+	// step: a token rejected again isn't refreshed in a loop
+	server.rotateToken("revoked")
+	_, err = client.Applications(nil)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.Equal(t, int64(3), atomic.LoadInt64(&provider.calls))
+}
+
+func TestTokenRefreshSingleFlight(t *testing.T) {
+	provider := &countedTokens{delay: 20 * time.Millisecond}
+	config := NewDefaultConfig()
+	config.TokenProvider = provider
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{dcosToken: "token-1"}})
+	defer endpoint.Close()
+	client := endpoint.Client
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Applications(nil)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&provider.calls))
+}
+
+func TestTokenRefreshFailure(t *testing.T) {
+	failure := errors.New("invalid credentials")
+	config := NewDefaultConfig()
+	config.TokenProvider = TokenProviderFunc(func() (string, error) {
+		return "", failure
+	})
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{dcosToken: "token-1"}})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	assert.True(t, errors.Is(err, failure))
+}
+
+func TestTokenWithoutProvider(t *testing.T) {
+	config := NewDefaultConfig()
+	config.DCOSToken = "expired"
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config, server: &serverConfig{dcosToken: "token-1"}})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SpanAttributeAppID is the span attribute holding the id of the application of the API call
+	SpanAttributeAppID = "marathon.app_id"
+	// SpanAttributeDeploymentID is the span attribute holding the id of the deployment started or
+	// targeted by the API call
+	SpanAttributeDeploymentID = "marathon.deployment_id"
+	// SpanAttributeHTTPMethod is the span attribute holding the HTTP method of the API call
+	SpanAttributeHTTPMethod = "http.method"
+	// SpanAttributeHTTPStatusCode is the span attribute holding the HTTP status code of the API call
+	SpanAttributeHTTPStatusCode = "http.status_code"
+)
+
+// Tracer creates a span for each API call of the client. See the otel subpackage for an
+// OpenTelemetry implementation
+type Tracer interface {
+	// StartSpan starts the span of an API call
+	//		operation:	the name of the operation, e.g. marathon.CreateApplication
+	StartSpan(operation string) Span
+}
+
+// Span is the span of an API call
+type Span interface {
+	// Inject adds the headers propagating the trace to the request sent to Marathon
+	Inject(header http.Header)
+	// SetAttribute sets an attribute of the span
+	SetAttribute(key, value string)
+	// End ends the span, the error being nil if the call succeeded
+	End(err error)
+}
+
+// noopTracer creates spans doing nothing
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(string) Span { return noopSpan{} }
+
+type noopSpan struct{}
+
+func (noopSpan) Inject(http.Header)          {}
+func (noopSpan) SetAttribute(string, string) {}
+func (noopSpan) End(error)                   {}
+
+// startSpan starts the span of the API call to the path, named after the client method making it
+func (r *marathonClient) startSpan(operation, method, path string) Span {
+	if _, ok := r.tracer.(noopTracer); ok {
+		return noopSpan{}
+	}
+	span := r.tracer.StartSpan("marathon." + operation)
+	span.SetAttribute(SpanAttributeHTTPMethod, method)
+	if id := pathAppID(path); id != "" {
+		span.SetAttribute(SpanAttributeAppID, id)
+	}
+	if id := callDeploymentID(path, nil); id != "" {
+		span.SetAttribute(SpanAttributeDeploymentID, id)
+	}
+	return span
+}
+
+// endSpan records the outcome of the API call on the span and ends it
+func endSpan(span Span, path string, statusCode int, respBody []byte, err error) {
+	if statusCode != 0 {
+		span.SetAttribute(SpanAttributeHTTPStatusCode, strconv.Itoa(statusCode))
+	}
+	if err == nil {
+		if id := callDeploymentID(path, respBody); id != "" {
+			span.SetAttribute(SpanAttributeDeploymentID, id)
+		}
+	}
+	span.End(err)
+}
+
+// pathAppID extracts the id of the application from the path of the API call
+func pathAppID(path string) string {
+	path = strings.Trim(strings.SplitN(path, "?", 2)[0], "/")
+	for _, prefix := range []string{marathonAPIApps + "/", marathonAPIQueue + "/"} {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		segments := strings.Split(strings.TrimPrefix(path, prefix), "/")
+		for i, segment := range segments {
+			// step: the segments following the id address a sub resource of the application
+			if segment == "tasks" || segment == "versions" || segment == "restart" || segment == "delay" {
+				segments = segments[:i]
+				break
+			}
+		}
+		return "/" + strings.Join(segments, "/")
+	}
+	return ""
+}
+
+// callDeploymentID extracts the id of the deployment from the path of the API call or its response
+func callDeploymentID(path string, respBody []byte) string {
+	path = strings.Trim(strings.SplitN(path, "?", 2)[0], "/")
+	if strings.HasPrefix(path, marathonAPIDeployments+"/") {
+		return strings.TrimPrefix(path, marathonAPIDeployments+"/")
+	}
+	if len(respBody) == 0 || respBody[0] != '{' {
+		return ""
+	}
+	var deployment DeploymentID
+	if err := json.Unmarshal(respBody, &deployment); err != nil {
+		return ""
+	}
+	return deployment.DeploymentID
+}
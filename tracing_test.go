@@ -0,0 +1,133 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTracer struct {
+	sync.Mutex
+	spans []*recordingSpan
+}
+
+type recordingSpan struct {
+	operation  string
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+func (t *recordingTracer) StartSpan(operation string) Span {
+	t.Lock()
+	defer t.Unlock()
+	span := &recordingSpan{operation: operation, attributes: map[string]string{}}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func (s *recordingSpan) Inject(header http.Header) {
+	header.Set("X-Trace-Operation", s.operation)
+}
+
+func (s *recordingSpan) SetAttribute(key, value string) {
+	s.attributes[key] = value
+}
+
+func (s *recordingSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+func TestPathAppID(t *testing.T) {
+	cases := map[string]string{
+		"v2/apps":                                   "",
+		"v2/apps/fake-app":                          "/fake-app",
+		"v2/apps/fake-group/fake-app?force=true":    "/fake-group/fake-app",
+		"v2/apps/fake-app/tasks/fake-app.fake-task": "/fake-app",
+		"v2/apps/fake-app/versions/2014-08-26":      "/fake-app",
+		"v2/apps/fake-app/restart":                  "/fake-app",
+		"v2/queue/fake-app/delay":                   "/fake-app",
+		"v2/groups/fake-group":                      "",
+	}
+	for path, expected := range cases {
+		assert.Equal(t, expected, pathAppID(path), path)
+	}
+}
+
+func TestCallDeploymentID(t *testing.T) {
+	assert.Equal(t, "867ed450", callDeploymentID("v2/deployments/867ed450", nil))
+	assert.Equal(t, "83b215a6", callDeploymentID("v2/apps/fake-app", []byte(`{"deploymentId": "83b215a6"}`)))
+	assert.Equal(t, "", callDeploymentID("v2/apps/fake-app", []byte(`{"app": {}}`)))
+	assert.Equal(t, "", callDeploymentID("ping", []byte(`pong`)))
+}
+
+func TestTracer(t *testing.T) {
+	tracer := new(recordingTracer)
+	config := NewDefaultConfig()
+	config.Tracer = tracer
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	_, err = endpoint.Client.Application("/not/there")
+	require.Error(t, err)
+
+	require.Len(t, tracer.spans, 2)
+	span := tracer.spans[0]
+	assert.Equal(t, "marathon.UpdateApplication", span.operation)
+	assert.True(t, span.ended)
+	assert.NoError(t, span.err)
+	assert.Equal(t, map[string]string{
+		SpanAttributeHTTPMethod:     "PUT",
+		SpanAttributeHTTPStatusCode: "200",
+		SpanAttributeAppID:          "/fake-app",
+		SpanAttributeDeploymentID:   "83b215a6-4e26-4e44-9333-5c385eda6438",
+	}, span.attributes)
+
+	span = tracer.spans[1]
+	assert.Equal(t, "marathon.Application", span.operation)
+	assert.Equal(t, "/not/there", span.attributes[SpanAttributeAppID])
+	assert.Equal(t, "404", span.attributes[SpanAttributeHTTPStatusCode])
+	assert.Error(t, span.err)
+}
+
+func TestTracerPropagation(t *testing.T) {
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Trace-Operation")
+		w.Write([]byte(`{"leader": "127.0.0.1:8080"}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.Tracer = new(recordingTracer)
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Leader()
+	require.NoError(t, err)
+	assert.Equal(t, "marathon.Leader", header)
+}
@@ -22,6 +22,28 @@ type UpgradeStrategy struct {
 	MaximumOverCapacity   *float64 `json:"maximumOverCapacity,omitempty"`
 }
 
+// NewUpgradeStrategy creates an upgrade strategy
+//		minimumHealthCapacity:	the fraction of the instances kept healthy during the upgrade, between 0 and 1
+//		maximumOverCapacity:	the fraction of extra instances launched during the upgrade, between 0 and 1
+func NewUpgradeStrategy(minimumHealthCapacity, maximumOverCapacity float64) *UpgradeStrategy {
+	return new(UpgradeStrategy).
+		SetMinimumHealthCapacity(minimumHealthCapacity).
+		SetMaximumOverCapacity(maximumOverCapacity)
+}
+
+// UpgradeStrategyAtomicSwap launches all the new instances alongside the old ones, and kills the
+// old instances once the new ones are healthy, i.e. it needs twice the resources during the upgrade
+func UpgradeStrategyAtomicSwap() *UpgradeStrategy {
+	return NewUpgradeStrategy(1, 1)
+}
+
+// UpgradeStrategyInPlace kills all the old instances before launching the new ones, i.e. it needs no
+// extra resources but the application is down during the upgrade, e.g. for the applications with
+// persistent volumes or fixed host ports
+func UpgradeStrategyInPlace() *UpgradeStrategy {
+	return NewUpgradeStrategy(0, 0)
+}
+
 // SetMinimumHealthCapacity sets the minimum health capacity.
 func (us *UpgradeStrategy) SetMinimumHealthCapacity(cap float64) *UpgradeStrategy {
 	us.MinimumHealthCapacity = &cap
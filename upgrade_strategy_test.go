@@ -0,0 +1,45 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUpgradeStrategy(t *testing.T) {
+	strategy := NewUpgradeStrategy(0.5, 0.2)
+	assert.Equal(t, 0.5, *strategy.MinimumHealthCapacity)
+	assert.Equal(t, 0.2, *strategy.MaximumOverCapacity)
+}
+
+func TestUpgradeStrategyPresets(t *testing.T) {
+	content, err := json.Marshal(UpgradeStrategyAtomicSwap())
+	require.NoError(t, err)
+	assert.Equal(t, `{"minimumHealthCapacity":1,"maximumOverCapacity":1}`, string(content))
+
+	// step: the zero capacities are sent
+	content, err = json.Marshal(UpgradeStrategyInPlace())
+	require.NoError(t, err)
+	assert.Equal(t, `{"minimumHealthCapacity":0,"maximumOverCapacity":0}`, string(content))
+
+	app := new(Application).SetUpgradeStrategy(*UpgradeStrategyInPlace())
+	assert.Equal(t, 0.0, *app.UpgradeStrategy.MinimumHealthCapacity)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// idSegmentPattern is the format of the segments of the ids Marathon accepts
+var idSegmentPattern = regexp.MustCompile(`^(([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])\.)*([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])$`)
+
+// Violation is a constraint of Marathon an application definition breaks
+type Violation struct {
+	// Field is the path of the offending field, e.g. healthChecks[0].portIndex
+	Field string
+	// Message describes the violation
+	Message string
+}
+
+// String returns a description of the violation
+func (v Violation) String() string {
+	return v.Field + ": " + v.Message
+}
+
+// ValidationError is returned when an application definition would be rejected by Marathon
+type ValidationError struct {
+	// ID is the id of the application, empty for a part of a definition, e.g. a health check
+	ID string
+	// Violations are all the violations of the definition
+	Violations []Violation
+}
+
+// Error returns the string message
+func (e *ValidationError) Error() string {
+	var violations []string
+	for _, violation := range e.Violations {
+		violations = append(violations, violation.String())
+	}
+	if e.ID == "" {
+		return "invalid definition: " + strings.Join(violations, "; ")
+	}
+	return fmt.Sprintf("invalid application %s: %s", e.ID, strings.Join(violations, "; "))
+}
+
+// Validate checks the application against the constraints Marathon enforces, e.g. the id format,
+// cmd and args being exclusive, the placement constraints, the roles, the port indexes of the
+// health checks and the bounds of the upgrade strategy, so the mistakes are caught before
+// submitting it. It returns a ValidationError listing all the violations, nil if there is none.
+func (r *Application) Validate() error {
+	var violations []Violation
+	violate := func(field, format string, args ...interface{}) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if r.ID == "" {
+		violate("id", "is required")
+	} else {
+		for _, segment := range strings.Split(strings.TrimPrefix(r.ID, "/"), "/") {
+			if !idSegmentPattern.MatchString(segment) {
+				violate("id", "%q must consist of lowercase alphanumeric segments, dots and dashes, separated by slashes", r.ID)
+				break
+			}
+		}
+	}
+
+	if r.Cmd != nil && r.Args != nil {
+		violate("cmd", "cmd and args are exclusive")
+	}
+	if r.Cmd == nil && r.Args == nil && r.Container == nil {
+		violate("cmd", "one of cmd, args or container is required")
+	}
+	if r.Instances != nil && *r.Instances < 0 {
+		violate("instances", "must not be negative")
+	}
+	if r.CPUs != nil && *r.CPUs < 0 {
+		violate("cpus", "must not be negative")
+	}
+	if r.Mem != nil && *r.Mem < 0 {
+		violate("mem", "must not be negative")
+	}
+	if r.Disk != nil && *r.Disk < 0 {
+		violate("disk", "must not be negative")
+	}
+
+	if r.Constraints != nil {
+		for i, constraint := range *r.Constraints {
+			if err := ValidateConstraint(constraint); err != nil {
+				violate(fmt.Sprintf("constraints[%d]", i), "%s", err)
+			}
+		}
+	}
+
+	for i, role := range r.AcceptedResourceRoles {
+		if err := validateRole(role); err != nil {
+			violate(fmt.Sprintf("acceptedResourceRoles[%d]", i), "%s", err)
+		}
+	}
+	if r.Role != nil {
+		if err := validateRole(*r.Role); err != nil {
+			violate("role", "%s", err)
+		}
+	}
+
+	if r.HealthChecks != nil {
+		ports, known := r.portCount()
+		for i, check := range *r.HealthChecks {
+			field := fmt.Sprintf("healthChecks[%d]", i)
+			for _, violation := range check.violations(ports, known) {
+				violate(field+"."+violation.Field, violation.Message)
+			}
+		}
+	}
+
+	if strategy := r.UpgradeStrategy; strategy != nil {
+		if capacity := strategy.MinimumHealthCapacity; capacity != nil && (*capacity < 0 || *capacity > 1) {
+			violate("upgradeStrategy.minimumHealthCapacity", "%v must be between 0 and 1", *capacity)
+		}
+		if capacity := strategy.MaximumOverCapacity; capacity != nil && (*capacity < 0 || *capacity > 1) {
+			violate("upgradeStrategy.maximumOverCapacity", "%v must be between 0 and 1", *capacity)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{ID: r.ID, Violations: violations}
+	}
+	return nil
+}
+
+// portCount returns the number of ports of the application, i.e. of its port mappings or its
+// port definitions, and whether it's known
+func (r *Application) portCount() (int, bool) {
+	switch {
+	case r.Container != nil && r.Container.PortMappings != nil:
+		return len(*r.Container.PortMappings), true
+	case r.Container != nil && r.Container.Docker != nil && r.Container.Docker.PortMappings != nil:
+		return len(*r.Container.Docker.PortMappings), true
+	case r.PortDefinitions != nil:
+		return len(*r.PortDefinitions), true
+	case r.Ports != nil:
+		return len(r.Ports), true
+	}
+	return 0, false
+}
+
+// violations returns the violations of the health check, given the number of ports of the application
+func (h *HealthCheck) violations(ports int, known bool) []Violation {
+	var violations []Violation
+	violate := func(field, format string, args ...interface{}) {
+		violations = append(violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch h.Protocol {
+	case "", "HTTP", "HTTPS", "MESOS_HTTP", "MESOS_HTTPS":
+	case "TCP", "MESOS_TCP":
+		if h.Path != nil {
+			violate("path", "is only supported by the HTTP protocols")
+		}
+	case "COMMAND":
+		if h.Command == nil {
+			violate("command", "is required by the COMMAND protocol")
+		}
+	default:
+		violate("protocol", "unknown protocol %q", h.Protocol)
+	}
+	if h.Command != nil && h.Protocol != "COMMAND" {
+		violate("command", "is only supported by the COMMAND protocol")
+	}
+
+	if h.Port != nil && h.PortIndex != nil {
+		violate("port", "port and portIndex are exclusive")
+	}
+	if h.PortIndex != nil && h.Protocol != "COMMAND" {
+		if *h.PortIndex < 0 || (known && *h.PortIndex >= ports) {
+			violate("portIndex", "%d is out of the range of the %d ports of the application", *h.PortIndex, ports)
+		}
+	}
+	if h.IntervalSeconds > 0 && h.TimeoutSeconds >= h.IntervalSeconds {
+		violate("timeoutSeconds", "%d must be lower than the interval of %d seconds", h.TimeoutSeconds, h.IntervalSeconds)
+	}
+	return violations
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationValidate(t *testing.T) {
+	application := NewDockerApplication().Name("/product/web-1.v2").Count(2).CPU(0.5).Memory(64)
+	application.Container.Docker.Container("nginx").Bridged().Expose(80)
+	application.AddHealthCheck(*NewDefaultHealthCheck().SetPath("/health"))
+	application.SetUpgradeStrategy(*new(UpgradeStrategy).SetMinimumHealthCapacity(0.5).SetMaximumOverCapacity(0.2))
+	assert.NoError(t, application.Validate())
+
+	invalid := new(Application).Name("/Product/web_1").Command("serve").AddArgs("--debug")
+	invalid.EmptyPortDefinitions()
+	invalid.Count(-1)
+	invalid.AddHealthCheck(*NewDefaultHealthCheck())
+	port, portIndex := 8080, 0
+	invalid.AddHealthCheck(HealthCheck{Port: &port, PortIndex: &portIndex})
+	invalid.AddHealthCheck(HealthCheck{Protocol: "COMMAND", IntervalSeconds: 10, TimeoutSeconds: 10})
+	invalid.AddHealthCheck(HealthCheck{Protocol: "UDP", Command: &Command{Value: "true"}})
+	invalid.SetUpgradeStrategy(*new(UpgradeStrategy).SetMinimumHealthCapacity(1.5))
+
+	err := invalid.Validate()
+	require.Error(t, err)
+	require.IsType(t, &ValidationError{}, err)
+	assert.Equal(t, []Violation{
+		{Field: "id", Message: `"/Product/web_1" must consist of lowercase alphanumeric segments, dots and dashes, separated by slashes`},
+		{Field: "cmd", Message: "cmd and args are exclusive"},
+		{Field: "instances", Message: "must not be negative"},
+		{Field: "healthChecks[0].portIndex", Message: "0 is out of the range of the 0 ports of the application"},
+		{Field: "healthChecks[1].port", Message: "port and portIndex are exclusive"},
+		{Field: "healthChecks[1].portIndex", Message: "0 is out of the range of the 0 ports of the application"},
+		{Field: "healthChecks[2].command", Message: "is required by the COMMAND protocol"},
+		{Field: "healthChecks[2].timeoutSeconds", Message: "10 must be lower than the interval of 10 seconds"},
+		{Field: "healthChecks[3].protocol", Message: `unknown protocol "UDP"`},
+		{Field: "healthChecks[3].command", Message: "is only supported by the COMMAND protocol"},
+		{Field: "upgradeStrategy.minimumHealthCapacity", Message: "1.5 must be between 0 and 1"},
+	}, err.(*ValidationError).Violations)
+	assert.Contains(t, err.Error(), "invalid application /Product/web_1: id: ")
+
+	assert.Equal(t, []Violation{
+		{Field: "id", Message: "is required"},
+		{Field: "cmd", Message: "one of cmd, args or container is required"},
+	}, new(Application).Validate().(*ValidationError).Violations)
+}
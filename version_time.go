@@ -0,0 +1,155 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"sort"
+	"time"
+)
+
+// ParseVersionTime parses a version of Marathon, i.e. the time a definition was deployed at, e.g.
+// 2017-10-02T10:00:00.000Z
+//		version:	the version of an application, a pod, a group or a deployment
+func ParseVersionTime(version string) (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, version)
+}
+
+// VersionTime parses the version of the application, see ParseVersionTime
+func (r *Application) VersionTime() (time.Time, error) {
+	return ParseVersionTime(r.Version)
+}
+
+// VersionTime parses the version of the deployment, see ParseVersionTime
+func (r *Deployment) VersionTime() (time.Time, error) {
+	return ParseVersionTime(r.Version)
+}
+
+// LastScalingTime parses the time the application was last scaled at, see ParseVersionTime
+func (r *VersionInfo) LastScalingTime() (time.Time, error) {
+	return ParseVersionTime(r.LastScalingAt)
+}
+
+// LastConfigChangeTime parses the time the definition of the application was last changed at,
+// e.g. to compute the time since, see ParseVersionTime
+func (r *VersionInfo) LastConfigChangeTime() (time.Time, error) {
+	return ParseVersionTime(r.LastConfigChangeAt)
+}
+
+// FirstSuccessTime parses the time the health check first succeeded at, the zero time when it
+// never succeeded, see ParseVersionTime
+func (r *HealthCheckResult) FirstSuccessTime() (time.Time, error) {
+	return parseOptionalTime(r.FirstSuccess)
+}
+
+// LastSuccessTime parses the time the health check last succeeded at, the zero time when it never
+// succeeded, see ParseVersionTime
+func (r *HealthCheckResult) LastSuccessTime() (time.Time, error) {
+	return parseOptionalTime(r.LastSuccess)
+}
+
+// LastFailureTime parses the time the health check last failed at, LastFailureCause being the
+// cause, the zero time when it never failed, see ParseVersionTime
+func (r *HealthCheckResult) LastFailureTime() (time.Time, error) {
+	return parseOptionalTime(r.LastFailure)
+}
+
+// FailingSince returns the time the health check has been failing since, i.e. its last success,
+// e.g. to alert on how long the task has been unhealthy with LastFailureCause. It is false while
+// the check is alive, and when the check never succeeded or the time doesn't parse.
+func (r *HealthCheckResult) FailingSince() (time.Time, bool) {
+	if r.Alive {
+		return time.Time{}, false
+	}
+	lastSuccess, err := r.LastSuccessTime()
+	if err != nil || lastSuccess.IsZero() {
+		return time.Time{}, false
+	}
+	return lastSuccess, true
+}
+
+// parseOptionalTime parses the time of an event which may not have happened, the zero time when
+// the time is empty
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return ParseVersionTime(value)
+}
+
+// versionBefore compares the versions by their time, or as strings when they don't parse
+func versionBefore(a, b string) bool {
+	at, errA := ParseVersionTime(a)
+	bt, errB := ParseVersionTime(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return at.Before(bt)
+}
+
+// Sorted returns the versions sorted by time, the oldest first
+func (r *ApplicationVersions) Sorted() []string {
+	versions := append([]string{}, r.Versions...)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versionBefore(versions[i], versions[j])
+	})
+	return versions
+}
+
+// Times returns the times of the versions, the oldest first
+func (r *ApplicationVersions) Times() ([]time.Time, error) {
+	var times []time.Time
+	for _, version := range r.Sorted() {
+		at, err := ParseVersionTime(version)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, at)
+	}
+	return times, nil
+}
+
+// LatestBefore returns the most recent version deployed at or before the time, e.g. to roll back
+// to the definition of a given day, false if the versions start later
+//		at:		the time
+func (r *ApplicationVersions) LatestBefore(at time.Time) (string, bool) {
+	var latest string
+	found := false
+	for _, version := range r.Sorted() {
+		versionTime, err := ParseVersionTime(version)
+		if err != nil {
+			continue
+		}
+		if versionTime.After(at) {
+			break
+		}
+		latest, found = version, true
+	}
+	return latest, found
+}
+
+// Previous returns the version deployed before the version, e.g. to roll back the last
+// deployment, false if it is the oldest or an unknown version
+//		version:	the version
+func (r *ApplicationVersions) Previous(version string) (string, bool) {
+	versions := r.Sorted()
+	for i, candidate := range versions {
+		if candidate == version && i > 0 {
+			return versions[i-1], true
+		}
+	}
+	return "", false
+}
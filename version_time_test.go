@@ -0,0 +1,124 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersionTime(t *testing.T) {
+	at, err := ParseVersionTime("2017-10-02T10:00:00.451Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 0, 0, 451000000, time.UTC), at)
+
+	application := &Application{Version: "2017-10-02T10:00:00Z"}
+	at, err = application.VersionTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 0, 0, 0, time.UTC), at)
+
+	_, err = (&Deployment{Version: "latest"}).VersionTime()
+	assert.Error(t, err)
+}
+
+func TestVersionInfoTimes(t *testing.T) {
+	info := &VersionInfo{LastScalingAt: "2017-10-02T10:00:00.000Z", LastConfigChangeAt: "2017-10-01T10:00:00.000Z"}
+	scaled, err := info.LastScalingTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 0, 0, 0, time.UTC), scaled)
+	changed, err := info.LastConfigChangeTime()
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, scaled.Sub(changed))
+
+	_, err = new(VersionInfo).LastConfigChangeTime()
+	assert.Error(t, err)
+}
+
+func TestHealthCheckResultTimes(t *testing.T) {
+	var result HealthCheckResult
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"alive": false,
+		"consecutiveFailures": 4,
+		"firstSuccess": "2017-10-02T10:00:00.451Z",
+		"lastFailure": "2017-10-02T10:06:00.000Z",
+		"lastFailureCause": "ConnectionRefused",
+		"lastSuccess": "2017-10-02T10:05:00.000Z",
+		"taskId": "web.1"
+	}`), &result))
+
+	firstSuccess, err := result.FirstSuccessTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 0, 0, 451000000, time.UTC), firstSuccess)
+	lastFailure, err := result.LastFailureTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 6, 0, 0, time.UTC), lastFailure)
+	since, failing := result.FailingSince()
+	require.True(t, failing)
+	assert.Equal(t, time.Date(2017, 10, 2, 10, 5, 0, 0, time.UTC), since)
+	assert.Equal(t, "ConnectionRefused", result.LastFailureCause)
+
+	// step: a healthy check never failed
+	result = HealthCheckResult{}
+	require.NoError(t, json.Unmarshal([]byte(`{"alive": true, "lastFailure": null, "lastSuccess": "2017-10-02T10:05:00.000Z"}`), &result))
+	lastFailure, err = result.LastFailureTime()
+	require.NoError(t, err)
+	assert.True(t, lastFailure.IsZero())
+	_, failing = result.FailingSince()
+	assert.False(t, failing)
+
+	// step: a check which never succeeded has no known start of failure
+	result = HealthCheckResult{LastFailure: "2017-10-02T10:06:00.000Z"}
+	_, failing = result.FailingSince()
+	assert.False(t, failing)
+	result.LastSuccess = "yesterday"
+	_, err = result.LastSuccessTime()
+	assert.Error(t, err)
+}
+
+func TestApplicationVersionsSorted(t *testing.T) {
+	versions := &ApplicationVersions{Versions: []string{
+		"2017-10-03T10:00:00.000Z",
+		"2017-10-01T10:00:00.000Z",
+		// step: the precision of the versions varies
+		"2017-10-02T10:00:00Z",
+	}}
+	assert.Equal(t, []string{"2017-10-01T10:00:00.000Z", "2017-10-02T10:00:00Z", "2017-10-03T10:00:00.000Z"}, versions.Sorted())
+
+	times, err := versions.Times()
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{
+		time.Date(2017, 10, 1, 10, 0, 0, 0, time.UTC),
+		time.Date(2017, 10, 2, 10, 0, 0, 0, time.UTC),
+		time.Date(2017, 10, 3, 10, 0, 0, 0, time.UTC),
+	}, times)
+
+	previous, found := versions.Previous("2017-10-03T10:00:00.000Z")
+	assert.True(t, found)
+	assert.Equal(t, "2017-10-02T10:00:00Z", previous)
+	_, found = versions.Previous("2017-10-01T10:00:00.000Z")
+	assert.False(t, found)
+
+	latest, found := versions.LatestBefore(time.Date(2017, 10, 2, 12, 0, 0, 0, time.UTC))
+	assert.True(t, found)
+	assert.Equal(t, "2017-10-02T10:00:00Z", latest)
+	_, found = versions.LatestBefore(time.Date(2017, 9, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, found)
+}
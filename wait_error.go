@@ -0,0 +1,233 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDeploymentCancelled is the error of the waits on a deployment which was cancelled
+var ErrDeploymentCancelled = errors.New("the deployment was cancelled")
+
+// ErrDeploymentFailed is the error of the watches of a deployment which failed
+var ErrDeploymentFailed = errors.New("the deployment failed")
+
+// ReasonCode is the machine-readable cause of the failure of a wait or an orchestration
+type ReasonCode int
+
+const (
+	// ReasonUnknown is the reason of the errors with no known cause
+	ReasonUnknown ReasonCode = iota
+	// ReasonTimeout is the reason of the waits which timed out with no more specific cause
+	ReasonTimeout
+	// ReasonTaskFailing is the reason of the waits on an application whose tasks are failing or
+	// unhealthy
+	ReasonTaskFailing
+	// ReasonQueueDelayed is the reason of the waits on an application whose launches are delayed
+	// by the backoff of the launch queue
+	ReasonQueueDelayed
+	// ReasonConstraintUnsatisfiable is the reason of the waits on an application whose constraints
+	// none of the offers satisfies
+	ReasonConstraintUnsatisfiable
+	// ReasonDeploymentCancelled is the reason of the waits on a deployment which was cancelled
+	ReasonDeploymentCancelled
+	// ReasonLeaderLost is the reason of the waits which failed as no Marathon member was available,
+	// e.g. during a leader election
+	ReasonLeaderLost
+	// ReasonDeploymentFailed is the reason of the watches of a deployment which failed
+	ReasonDeploymentFailed
+)
+
+// the reasons as strings, e.g. for logging
+var reasonNames = map[ReasonCode]string{
+	ReasonUnknown:                 "Unknown",
+	ReasonTimeout:                 "Timeout",
+	ReasonTaskFailing:             "TaskFailing",
+	ReasonQueueDelayed:            "QueueDelayed",
+	ReasonConstraintUnsatisfiable: "ConstraintUnsatisfiable",
+	ReasonDeploymentCancelled:     "DeploymentCancelled",
+	ReasonLeaderLost:              "LeaderLost",
+	ReasonDeploymentFailed:        "DeploymentFailed",
+}
+
+// String returns the name of the reason
+func (r ReasonCode) String() string {
+	if name, found := reasonNames[r]; found {
+		return name
+	}
+	return fmt.Sprintf("ReasonCode(%d)", int(r))
+}
+
+// WaitError is the error of a wait or an orchestration which failed for a known cause
+type WaitError struct {
+	// Reason is the cause of the failure
+	Reason ReasonCode
+	// ID is the id of the application or deployment waited on
+	ID string
+	// Message details the cause, e.g. the message of the last task failure
+	Message string
+	// Err is the underlying error, e.g. ErrTimeoutError
+	Err error
+	// Applications is the status of the applications not deployed yet, for the waits on a group
+	Applications []ApplicationWaitStatus
+}
+
+// Error returns the string message
+func (e *WaitError) Error() string {
+	message := fmt.Sprintf("%s: %s (%s)", e.ID, e.Err, e.Reason)
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if len(e.Applications) > 0 {
+		var statuses []string
+		for _, status := range e.Applications {
+			statuses = append(statuses, status.String())
+		}
+		message += fmt.Sprintf(" [%s]", strings.Join(statuses, ", "))
+	}
+	return message
+}
+
+// Unwrap returns the underlying error, e.g. for errors.Is(err, ErrTimeout)
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+// ApplicationWaitStatus is the status of an application of a group waited on
+type ApplicationWaitStatus struct {
+	// ID is the id of the application
+	ID string
+	// Instances is the target instance count of the application
+	Instances int
+	// TasksRunning is the count of the running tasks
+	TasksRunning int
+	// TasksHealthy is the count of the tasks passing their health checks
+	TasksHealthy int
+	// Deployments is the count of the deployments of the application in progress
+	Deployments int
+}
+
+// String returns the status as a string, e.g. "/web: 1/2 running, 0 healthy, 1 deployments"
+func (s ApplicationWaitStatus) String() string {
+	return fmt.Sprintf("%s: %d/%d running, %d healthy, %d deployments",
+		s.ID, s.TasksRunning, s.Instances, s.TasksHealthy, s.Deployments)
+}
+
+// Reason returns the cause of the error returned by a wait or an orchestration, e.g. to branch on
+// it without matching the message. The timeouts with no known cause, which are still reported as
+// ErrTimeoutError, are ReasonTimeout.
+func Reason(err error) ReasonCode {
+	var waitErr *WaitError
+	switch {
+	case errors.As(err, &waitErr):
+		return waitErr.Reason
+	case errors.Is(err, ErrTimeoutError):
+		return ReasonTimeout
+	case errors.Is(err, ErrMarathonDown), errors.Is(err, ErrCircuitOpen):
+		return ReasonLeaderLost
+	}
+	return ReasonUnknown
+}
+
+// deploymentOutcome checks whether the deployment, which is no longer running, was cancelled, i.e.
+// an affected application was deployed at a later version since, e.g. rolled back or force updated
+func deploymentOutcome(client Marathon, deployment *Deployment) error {
+	if deployment.Version == "" {
+		return nil
+	}
+	for _, id := range deployment.AffectedApps {
+		application, err := client.Application(id)
+		if err != nil {
+			// step: a deleted application is the outcome of its deletion
+			continue
+		}
+		if application.Version != "" && application.Version > deployment.Version {
+			return &WaitError{
+				Reason:  ReasonDeploymentCancelled,
+				ID:      deployment.ID,
+				Message: fmt.Sprintf("%s was deployed at version %s since", id, application.Version),
+				Err:     ErrDeploymentCancelled,
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// diagnoseTimeout looks for the cause of the timeout of the wait on the applications, returning
+// a WaitError for the first application with a known cause, and the error unchanged otherwise
+//		client:		the client the launch queue and the applications are retrieved with
+//		err:		the error of the wait, e.g. ErrTimeoutError
+//		ids:		the ids of the applications waited on
+func diagnoseTimeout(client Marathon, err error, ids ...string) error {
+	// step: the waits of a closed client have nothing to diagnose
+	if len(ids) == 0 || errors.Is(err, ErrClientClosed) {
+		return err
+	}
+	queue, queueErr := client.Queue()
+	for _, id := range ids {
+		id = validateID(id)
+		// step: the launch queue tells whether the instances can't be placed or are delayed
+		if queueErr == nil && queue != nil {
+			for _, item := range queue.Items {
+				if item.Application.ID != id {
+					continue
+				}
+				if summary := item.ProcessedOffersSummary; summary != nil {
+					for _, reason := range summary.RejectSummaryLastOffers {
+						if reason.Reason == RejectionUnfulfilledConstraint && reason.Declined > 0 {
+							return &WaitError{
+								Reason:  ReasonConstraintUnsatisfiable,
+								ID:      id,
+								Message: fmt.Sprintf("%d of the last %d offers declined", reason.Declined, reason.Processed),
+								Err:     err,
+							}
+						}
+					}
+				}
+				if !item.Delay.Overdue && item.Delay.TimeLeftSeconds > 0 {
+					return &WaitError{
+						Reason:  ReasonQueueDelayed,
+						ID:      id,
+						Message: fmt.Sprintf("launch delayed for %d seconds", item.Delay.TimeLeftSeconds),
+						Err:     err,
+					}
+				}
+			}
+		}
+
+		// step: the application tells whether its tasks are failing
+		application, appErr := client.Application(id)
+		if appErr != nil {
+			continue
+		}
+		if failure := application.LastTaskFailure; failure != nil && failure.Version == application.Version {
+			return &WaitError{Reason: ReasonTaskFailing, ID: id, Message: failure.Message, Err: err}
+		}
+		if application.TasksUnhealthy > 0 {
+			return &WaitError{
+				Reason:  ReasonTaskFailing,
+				ID:      id,
+				Message: fmt.Sprintf("%d unhealthy tasks", application.TasksUnhealthy),
+				Err:     err,
+			}
+		}
+	}
+	return err
+}
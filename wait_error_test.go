@@ -0,0 +1,146 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diagnosedClient returns a fixed launch queue and applications
+type diagnosedClient struct {
+	Marathon
+	queue        *Queue
+	applications map[string]*Application
+}
+
+func (c *diagnosedClient) Queue() (*Queue, error) {
+	return c.queue, nil
+}
+
+func (c *diagnosedClient) Application(name string) (*Application, error) {
+	if application, found := c.applications[name]; found {
+		return application, nil
+	}
+	return nil, &APIError{ErrCode: ErrCodeNotFound, message: "not found"}
+}
+
+func TestReason(t *testing.T) {
+	assert.Equal(t, ReasonTimeout, Reason(ErrTimeoutError))
+	assert.Equal(t, ReasonLeaderLost, Reason(ErrMarathonDown))
+	assert.Equal(t, ReasonTaskFailing, Reason(&WaitError{Reason: ReasonTaskFailing}))
+	assert.Equal(t, ReasonUnknown, Reason(errors.New("failed")))
+	assert.Equal(t, ReasonUnknown, Reason(nil))
+
+	// step: the reasons are found through the wrapping errors
+	assert.Equal(t, ReasonQueueDelayed, Reason(fmt.Errorf("deploying /web: %w", &WaitError{Reason: ReasonQueueDelayed})))
+	assert.Equal(t, ReasonTimeout, Reason(fmt.Errorf("deploying /web: %w", ErrTimeout)))
+
+	assert.Equal(t, "ConstraintUnsatisfiable", ReasonConstraintUnsatisfiable.String())
+	assert.Equal(t, "ReasonCode(42)", ReasonCode(42).String())
+}
+
+func TestWaitErrorUnwrap(t *testing.T) {
+	err := error(&WaitError{Reason: ReasonQueueDelayed, ID: "/web", Err: ErrTimeoutError})
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.True(t, errors.Is(err, ErrTimeoutError))
+	assert.False(t, errors.Is(err, ErrMarathonDown))
+
+	var waitErr *WaitError
+	require.True(t, errors.As(fmt.Errorf("deploying: %w", err), &waitErr))
+	assert.Equal(t, "/web", waitErr.ID)
+}
+
+func TestDiagnoseTimeout(t *testing.T) {
+	client := &diagnosedClient{
+		queue: &Queue{Items: []Item{
+			{
+				Application: Application{ID: "/constrained"},
+				ProcessedOffersSummary: &ProcessedOffersSummary{
+					RejectSummaryLastOffers: []DeclineReason{
+						{Reason: "UnfulfilledRole", Declined: 0, Processed: 3},
+						{Reason: "UnfulfilledConstraint", Declined: 3, Processed: 3},
+					},
+				},
+			},
+			{Application: Application{ID: "/delayed"}, Delay: Delay{TimeLeftSeconds: 120}},
+			{Application: Application{ID: "/overdue"}, Delay: Delay{Overdue: true, TimeLeftSeconds: 120}},
+		}},
+		applications: map[string]*Application{
+			"/failing": {
+				ID:              "/failing",
+				Version:         "2017-03-01T10:00:00.000Z",
+				LastTaskFailure: &LastTaskFailure{Message: "exit code 1", Version: "2017-03-01T10:00:00.000Z"},
+			},
+			"/recovered": {
+				ID:              "/recovered",
+				Version:         "2017-03-01T10:00:00.000Z",
+				LastTaskFailure: &LastTaskFailure{Message: "exit code 1", Version: "2017-02-01T10:00:00.000Z"},
+			},
+			"/unhealthy": {ID: "/unhealthy", TasksUnhealthy: 2},
+		},
+	}
+
+	cases := map[string]struct {
+		reason  ReasonCode
+		message string
+	}{
+		"constrained": {ReasonConstraintUnsatisfiable, "3 of the last 3 offers declined"},
+		"/delayed":    {ReasonQueueDelayed, "launch delayed for 120 seconds"},
+		"/failing":    {ReasonTaskFailing, "exit code 1"},
+		"/unhealthy":  {ReasonTaskFailing, "2 unhealthy tasks"},
+		"/overdue":    {ReasonTimeout, ""},
+		"/recovered":  {ReasonTimeout, ""},
+		"/missing":    {ReasonTimeout, ""},
+	}
+	for id, expected := range cases {
+		err := diagnoseTimeout(client, ErrTimeoutError, id)
+		assert.Equal(t, expected.reason, Reason(err), id)
+		if waitErr, ok := err.(*WaitError); ok {
+			assert.Equal(t, expected.message, waitErr.Message, id)
+			assert.Equal(t, ErrTimeoutError, waitErr.Err, id)
+		} else {
+			assert.Equal(t, ErrTimeoutError, err, id)
+		}
+	}
+
+	// step: the first application with a known cause is reported
+	err := diagnoseTimeout(client, ErrTimeoutError, "/missing", "/failing")
+	require.IsType(t, &WaitError{}, err)
+	assert.Equal(t, "/failing: the operation has timed out (TaskFailing): exit code 1", err.Error())
+	assert.Equal(t, ErrTimeoutError, diagnoseTimeout(client, ErrTimeoutError))
+}
+
+func TestDeploymentOutcome(t *testing.T) {
+	client := &diagnosedClient{applications: map[string]*Application{
+		"/deployed":    {ID: "/deployed", Version: "2017-03-01T10:00:00.000Z"},
+		"/rolled-back": {ID: "/rolled-back", Version: "2017-03-01T10:05:00.000Z"},
+	}}
+	deployment := &Deployment{ID: "867ed450", Version: "2017-03-01T10:00:00.000Z"}
+
+	deployment.AffectedApps = []string{"/deleted", "/deployed"}
+	assert.NoError(t, deploymentOutcome(client, deployment))
+
+	deployment.AffectedApps = []string{"/rolled-back"}
+	err := deploymentOutcome(client, deployment)
+	assert.Equal(t, ReasonDeploymentCancelled, Reason(err))
+	assert.Equal(t, "867ed450: the deployment was cancelled (DeploymentCancelled): /rolled-back was deployed at version 2017-03-01T10:05:00.000Z since", err.Error())
+}
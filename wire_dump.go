@@ -0,0 +1,177 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// redactedHeaders are the headers carrying credentials, never written in the dumps
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// WireDump writes the requests sent by the client and the responses it received, e.g. to find out
+// why Marathon rejects a definition with a 422. The credentials are redacted and the gzipped bodies
+// written decompressed. The dump can be enabled and disabled while the client is running.
+type WireDump struct {
+	// the lock serializes the dumps of the concurrent requests
+	sync.Mutex
+	// the writer receiving the dumps
+	output io.Writer
+	// whether the dumps are written, 1 when enabled
+	enabled int32
+}
+
+// NewWireDump creates an enabled dump writing to the output, to be set as Config.WireDump
+//		output:		the writer receiving the dumps, e.g. os.Stderr
+func NewWireDump(output io.Writer) *WireDump {
+	return &WireDump{output: output, enabled: 1}
+}
+
+// Enable starts writing the dumps
+func (d *WireDump) Enable() {
+	atomic.StoreInt32(&d.enabled, 1)
+}
+
+// Disable stops writing the dumps
+func (d *WireDump) Disable() {
+	atomic.StoreInt32(&d.enabled, 0)
+}
+
+// Enabled checks if the dumps are written
+func (d *WireDump) Enabled() bool {
+	return d != nil && atomic.LoadInt32(&d.enabled) == 1
+}
+
+// wrap dumps the requests performed by the doer while the dump is enabled
+func (d *WireDump) wrap(next Doer) Doer {
+	return DoerFunc(func(request *http.Request) (*http.Response, error) {
+		if !d.Enabled() {
+			return next.Do(request)
+		}
+		dumpedRequest := dumpRequest(request)
+		started := time.Now()
+		response, err := next.Do(request)
+		elapsed := time.Since(started)
+
+		var dumpedResponse []byte
+		if err == nil {
+			dumpedResponse = dumpResponse(response)
+		}
+
+		d.Lock()
+		defer d.Unlock()
+		fmt.Fprintf(d.output, "--> %s %s\n%s\n", request.Method, request.URL, dumpedRequest)
+		if err != nil {
+			fmt.Fprintf(d.output, "<-- %s %s failed after %s: %s\n\n", request.Method, request.URL, elapsed, err)
+		} else {
+			fmt.Fprintf(d.output, "<-- %s %s %d (%s)\n%s\n", request.Method, request.URL, response.StatusCode, elapsed, dumpedResponse)
+		}
+		return response, err
+	})
+}
+
+// dumpRequest dumps the request with its credentials redacted, leaving the request untouched
+func dumpRequest(request *http.Request) []byte {
+	dumped := request.Clone(request.Context())
+	dumped.Header = redactHeaders(request.Header)
+	dumped.Body = nil
+	// step: the body is read from a copy, the request must still send its own
+	if request.Body != nil && request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			content, err := readDumpedBody(body, request.Header)
+			if err != nil {
+				return []byte(fmt.Sprintf("unable to read the request body: %s", err))
+			}
+			dumped.Body = ioutil.NopCloser(bytes.NewReader(content))
+			dumped.ContentLength = int64(len(content))
+			dumped.Header.Del("Content-Encoding")
+		}
+	}
+	content, err := httputil.DumpRequestOut(dumped, dumped.Body != nil)
+	if err != nil {
+		return []byte(fmt.Sprintf("unable to dump the request: %s", err))
+	}
+	return content
+}
+
+// dumpResponse dumps the response with its credentials redacted, the body being read and replaced
+// by a copy. The event streams are dumped without their body, which never ends.
+func dumpResponse(response *http.Response) []byte {
+	dumped := *response
+	dumped.Header = redactHeaders(response.Header)
+	dumped.Body = nil
+	streamed := strings.HasPrefix(response.Header.Get("Content-Type"), "text/event-stream")
+	if response.Body != nil && !streamed {
+		content, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		response.Body = ioutil.NopCloser(bytes.NewReader(content))
+		if err != nil {
+			return []byte(fmt.Sprintf("unable to read the response body: %s", err))
+		}
+		if content, err = readDumpedBody(ioutil.NopCloser(bytes.NewReader(content)), response.Header); err == nil {
+			dumped.Body = ioutil.NopCloser(bytes.NewReader(content))
+			dumped.ContentLength = int64(len(content))
+			dumped.Header.Del("Content-Encoding")
+		}
+	}
+	content, err := httputil.DumpResponse(&dumped, dumped.Body != nil)
+	if err != nil {
+		return []byte(fmt.Sprintf("unable to dump the response: %s", err))
+	}
+	return content
+}
+
+// readDumpedBody reads and closes a body to dump, decompressing it when gzipped
+func readDumpedBody(body io.ReadCloser, header http.Header) ([]byte, error) {
+	defer body.Close()
+	content, err := ioutil.ReadAll(body)
+	if err != nil || len(content) == 0 || !strings.EqualFold(header.Get("Content-Encoding"), gzipEncoding) {
+		return content, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// redactHeaders copies the headers, replacing the values of the credentials
+func redactHeaders(header http.Header) http.Header {
+	redactedHeader := header.Clone()
+	if redactedHeader == nil {
+		redactedHeader = make(http.Header)
+	}
+	for _, name := range redactedHeaders {
+		if _, found := redactedHeader[name]; found {
+			redactedHeader.Set(name, redacted)
+		}
+	}
+	return redactedHeader
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedBuffer is a buffer safe to read while the client writes the dumps
+type lockedBuffer struct {
+	sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(content []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buffer.Write(content)
+}
+
+func (b *lockedBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buffer.String()
+}
+
+func TestWireDump(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := readResponseBody(&http.Response{Header: r.Header, Body: r.Body})
+		received = content
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=cookie-secret")
+		w.WriteHeader(422)
+		w.Write([]byte(`{"message": "Object is not valid", "details": [{"path": "/cpus", "errors": ["error.min"]}]}`))
+	}))
+	defer server.Close()
+
+	output := &lockedBuffer{}
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.DCOSToken = "token-secret"
+	config.GzipRequestThreshold = 1
+	config.WireDump = NewWireDump(output)
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	// step: the rejected request and its response are dumped, the credentials redacted
+	_, err = client.CreateApplication(NewDockerApplication().Name("/invalid").CPU(-1))
+	require.Error(t, err)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, ErrCodeInvalidBean, apiErr.ErrCode)
+	assert.Contains(t, string(received), `"id":"/invalid"`)
+
+	dump := output.String()
+	assert.Contains(t, dump, "--> POST "+server.URL)
+	assert.Contains(t, dump, `"id":"/invalid"`)
+	assert.Contains(t, dump, "/v2/apps 422 (")
+	assert.Contains(t, dump, "error.min")
+	assert.Contains(t, dump, redacted)
+	assert.NotContains(t, dump, "token-secret")
+	assert.NotContains(t, dump, "cookie-secret")
+
+	// step: nothing is dumped once disabled, and again once enabled
+	config.WireDump.Disable()
+	assert.False(t, config.WireDump.Enabled())
+	client.CreateApplication(NewDockerApplication().Name("/disabled"))
+	assert.NotContains(t, output.String(), "/disabled")
+
+	config.WireDump.Enable()
+	client.CreateApplication(NewDockerApplication().Name("/enabled"))
+	assert.Contains(t, output.String(), "/enabled")
+}
+
+func TestWireDumpFailedRequest(t *testing.T) {
+	output := &lockedBuffer{}
+	dump := NewWireDump(output)
+	doer := dump.wrap(DoerFunc(func(request *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	request, err := http.NewRequest("GET", "http://marathon/v2/apps", nil)
+	require.NoError(t, err)
+	request.Header.Set("Authorization", "token=secret")
+	_, err = doer.Do(request)
+	assert.Error(t, err)
+	assert.Contains(t, output.String(), "<-- GET http://marathon/v2/apps failed")
+	assert.Contains(t, output.String(), "connection refused")
+	assert.NotContains(t, output.String(), "secret")
+	// step: the request itself keeps its credentials
+	assert.Equal(t, "token=secret", request.Header.Get("Authorization"))
+}
+
+func TestWireDumpKeepsResponseBody(t *testing.T) {
+	dump := NewWireDump(ioutil.Discard)
+	doer := dump.wrap(DoerFunc(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 200,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"apps": []}`)),
+		}, nil
+	}))
+
+	request, err := http.NewRequest("GET", "http://marathon/v2/apps", nil)
+	require.NoError(t, err)
+	response, err := doer.Do(request)
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"apps": []}`, string(content))
+}
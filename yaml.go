@@ -0,0 +1,175 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The definitions are marshaled to and unmarshaled from YAML (e.g. gopkg.in/yaml.v2) through their
+// JSON representation, so the YAML fields are the fields of the Marathon API, e.g.
+//
+//	id: /product/web
+//	instances: 2
+//	container:
+//	  type: DOCKER
+//	  docker:
+//	    image: nginx
+//	env:
+//	  LOG_LEVEL: info
+
+// MarshalYAML marshals the application to its YAML fields
+func (r Application) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&r)
+}
+
+// UnmarshalYAML unmarshals the application from its YAML fields
+func (r *Application) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, r)
+}
+
+// MarshalYAML marshals the group to its YAML fields
+func (r Group) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&r)
+}
+
+// UnmarshalYAML unmarshals the group from its YAML fields
+func (r *Group) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, r)
+}
+
+// MarshalYAML marshals the pod to its YAML fields
+func (p Pod) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&p)
+}
+
+// UnmarshalYAML unmarshals the pod from its YAML fields
+func (p *Pod) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, p)
+}
+
+// MarshalYAML marshals the pod container to its YAML fields
+func (p PodContainer) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&p)
+}
+
+// UnmarshalYAML unmarshals the pod container from its YAML fields
+func (p *PodContainer) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, p)
+}
+
+// MarshalYAML marshals the container to its YAML fields
+func (container Container) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&container)
+}
+
+// UnmarshalYAML unmarshals the container from its YAML fields
+func (container *Container) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, container)
+}
+
+// MarshalYAML marshals the health check to its YAML fields
+func (h HealthCheck) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&h)
+}
+
+// UnmarshalYAML unmarshals the health check from its YAML fields
+func (h *HealthCheck) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, h)
+}
+
+// MarshalYAML marshals the readiness check to its YAML fields
+func (rc ReadinessCheck) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&rc)
+}
+
+// UnmarshalYAML unmarshals the readiness check from its YAML fields
+func (rc *ReadinessCheck) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, rc)
+}
+
+// MarshalYAML marshals the upgrade strategy to its YAML fields
+func (us UpgradeStrategy) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&us)
+}
+
+// UnmarshalYAML unmarshals the upgrade strategy from its YAML fields
+func (us *UpgradeStrategy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, us)
+}
+
+// MarshalYAML marshals the unreachable strategy to its YAML fields
+func (us UnreachableStrategy) MarshalYAML() (interface{}, error) {
+	return marshalYAMLFields(&us)
+}
+
+// UnmarshalYAML unmarshals the unreachable strategy from its YAML fields
+func (us *UnreachableStrategy) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalYAMLFields(unmarshal, us)
+}
+
+// marshalYAMLFields returns the decoded JSON representation of the definition
+func marshalYAMLFields(definition interface{}) (interface{}, error) {
+	content, err := json.Marshal(definition)
+	if err != nil {
+		return nil, err
+	}
+	var fields interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// unmarshalYAMLFields decodes the YAML value and unmarshals it into the definition as JSON
+func unmarshalYAMLFields(unmarshal func(interface{}) error, definition interface{}) error {
+	var fields interface{}
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	content, err := json.Marshal(jsonFields(fields))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, definition)
+}
+
+// jsonFields converts the maps decoded from YAML, whose keys may be of any type, to JSON objects
+func jsonFields(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			object[fmt.Sprint(key)] = jsonFields(item)
+		}
+		return object
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			object[key] = jsonFields(item)
+		}
+		return object
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = jsonFields(item)
+		}
+		return items
+	}
+	return value
+}
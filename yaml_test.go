@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const applicationYAML = `
+id: /product/web
+instances: 2
+cpus: 0.5
+container:
+  type: DOCKER
+  docker:
+    image: nginx
+    portMappings:
+      - containerPort: 80
+env:
+  LOG_LEVEL: info
+  TOKEN:
+    secret: token
+secrets:
+  token:
+    source: /secrets/token
+healthChecks:
+  - protocol: HTTP
+    path: /health
+labels:
+  tier: frontend
+`
+
+func TestApplicationYAML(t *testing.T) {
+	application := new(Application)
+	require.NoError(t, yaml.Unmarshal([]byte(applicationYAML), application))
+	assert.Equal(t, "/product/web", application.ID)
+	assert.Equal(t, 2, *application.Instances)
+	assert.Equal(t, 0.5, *application.CPUs)
+	assert.Equal(t, "nginx", application.Container.Docker.Image)
+	assert.Equal(t, 80, (*application.Container.Docker.PortMappings)[0].ContainerPort)
+	assert.Equal(t, map[string]string{"LOG_LEVEL": "info"}, *application.Env)
+	assert.Equal(t, map[string]Secret{"token": {EnvVar: "TOKEN", Source: "/secrets/token"}}, *application.Secrets)
+	assert.Equal(t, "/health", *(*application.HealthChecks)[0].Path)
+	assert.Equal(t, map[string]string{"tier": "frontend"}, *application.Labels)
+
+	content, err := yaml.Marshal(application)
+	require.NoError(t, err)
+	decoded := new(Application)
+	require.NoError(t, yaml.Unmarshal(content, decoded))
+	assert.Equal(t, application, decoded)
+}
+
+func TestUnmarshalYAMLFields(t *testing.T) {
+	// step: the YAML decoders produce maps with keys of any type
+	unmarshal := func(value interface{}) error {
+		*(value.(*interface{})) = map[interface{}]interface{}{
+			"id":           "/product",
+			"apps":         []interface{}{map[interface{}]interface{}{"id": "/product/web", "instances": 3}},
+			"dependencies": []interface{}{"/database"},
+		}
+		return nil
+	}
+	group := new(Group)
+	require.NoError(t, group.UnmarshalYAML(unmarshal))
+	assert.Equal(t, "/product", group.ID)
+	require.Len(t, group.Apps, 1)
+	assert.Equal(t, 3, *group.Apps[0].Instances)
+	assert.Equal(t, []string{"/database"}, group.Dependencies)
+}
+
+func TestMarshalYAMLFields(t *testing.T) {
+	fields, err := NewDefaultHealthCheck().SetPath("/health").MarshalYAML()
+	require.NoError(t, err)
+	assert.Equal(t, "/health", fields.(map[string]interface{})["path"])
+
+	fields, err = new(Application).Name("/web").AddEnv("LOG_LEVEL", "info").MarshalYAML()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"LOG_LEVEL": "info"}, fields.(map[string]interface{})["env"])
+}
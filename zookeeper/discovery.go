@@ -0,0 +1,204 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package zookeeper discovers the members of a Marathon cluster from its ZooKeeper state, so the
+// go-marathon client follows the replacements of the masters.
+//
+//	discovery, err := zookeeper.NewDiscovery("zk://zk-1:2181,zk-2:2181/marathon", nil)
+//	defer discovery.Close()
+//
+//	config := marathon.NewDefaultConfig()
+//	config.URL = ""
+//	config.MemberDiscovery = discovery
+package zookeeper
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const (
+	defaultScheme         = "http"
+	defaultSessionTimeout = 10 * time.Second
+)
+
+// the paths of the leader election of Marathon, relative to its ZooKeeper path, the most recent first
+var defaultLeaderPaths = []string{"leader-curator", "leader"}
+
+// Opts contains the options of the discovery
+//		scheme:		the scheme of the URLs of the members, defaults to http
+//		leaderPath:	the path of the leader election relative to the path of Marathon, defaults to
+//				leader-curator, or leader for the versions of Marathon before 1.4
+//		sessionTimeout:	the timeout of the ZooKeeper session, defaults to 10 seconds
+type Opts struct {
+	Scheme         string
+	LeaderPath     string
+	SessionTimeout time.Duration
+}
+
+// conn is the part of the ZooKeeper connection the discovery reads with
+type conn interface {
+	Children(path string) ([]string, *zk.Stat, error)
+	Get(path string) ([]byte, *zk.Stat, error)
+}
+
+// Discovery is a marathon.MemberDiscovery reading the members of the cluster from the candidates
+// of the leader election of Marathon, the leader first
+type Discovery struct {
+	conn   conn
+	close  func()
+	path   string
+	scheme string
+	// the paths of the leader election tried in turn
+	leaderPaths []string
+}
+
+// ParseConnection parses a ZooKeeper connection string, e.g. zk://zk-1:2181,zk-2:2181/marathon
+//		connection:	the connection string
+func ParseConnection(connection string) ([]string, string, error) {
+	u, err := url.Parse(connection)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid ZooKeeper connection string %q: %w", connection, err)
+	}
+	if u.Scheme != "zk" || u.Host == "" {
+		return nil, "", fmt.Errorf("invalid ZooKeeper connection string %q, expected zk://host:port[,host:port]/path", connection)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		return nil, "", fmt.Errorf("no Marathon path in the ZooKeeper connection string %q", connection)
+	}
+	return strings.Split(u.Host, ","), path.Clean(u.Path), nil
+}
+
+// NewDiscovery connects to ZooKeeper to discover the members of the Marathon cluster
+//		connection:	the ZooKeeper connection string, e.g. zk://zk-1:2181,zk-2:2181/marathon
+//		opts:		the options of the discovery
+func NewDiscovery(connection string, opts *Opts) (*Discovery, error) {
+	if opts == nil {
+		opts = &Opts{}
+	}
+	servers, marathonPath, err := ParseConnection(connection)
+	if err != nil {
+		return nil, err
+	}
+	sessionTimeout := opts.SessionTimeout
+	if sessionTimeout <= 0 {
+		sessionTimeout = defaultSessionTimeout
+	}
+	zkConn, _, err := zk.Connect(servers, sessionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	discovery := newDiscovery(zkConn, marathonPath, opts)
+	discovery.close = zkConn.Close
+	return discovery, nil
+}
+
+// newDiscovery creates a discovery reading with the connection
+func newDiscovery(c conn, marathonPath string, opts *Opts) *Discovery {
+	discovery := &Discovery{
+		conn:        c,
+		close:       func() {},
+		path:        marathonPath,
+		scheme:      opts.Scheme,
+		leaderPaths: defaultLeaderPaths,
+	}
+	if discovery.scheme == "" {
+		discovery.scheme = defaultScheme
+	}
+	if opts.LeaderPath != "" {
+		discovery.leaderPaths = []string{opts.LeaderPath}
+	}
+	return discovery
+}
+
+// candidate is a member taking part in the leader election
+type candidate struct {
+	sequence int
+	hostPort string
+}
+
+// Members returns the URLs of the members of the cluster, the leader first
+func (d *Discovery) Members() ([]string, error) {
+	var err error
+	for _, leaderPath := range d.leaderPaths {
+		var members []string
+		if members, err = d.members(path.Join(d.path, leaderPath)); err != zk.ErrNoNode {
+			return members, err
+		}
+	}
+	return nil, fmt.Errorf("no leader election found under %s: %w", d.path, err)
+}
+
+// members returns the URLs of the candidates of the leader election, the lowest sequence, i.e. the
+// leader, first
+func (d *Discovery) members(electionPath string) ([]string, error) {
+	children, _, err := d.conn.Children(electionPath)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []candidate
+	for _, child := range children {
+		data, _, err := d.conn.Get(path.Join(electionPath, child))
+		if err == zk.ErrNoNode {
+			// step: the candidate left the election since
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if hostPort := strings.TrimSpace(string(data)); hostPort != "" {
+			candidates = append(candidates, candidate{sequence: sequence(child), hostPort: hostPort})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].sequence < candidates[j].sequence
+	})
+
+	var members []string
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if !seen[c.hostPort] {
+			seen[c.hostPort] = true
+			members = append(members, d.scheme+"://"+c.hostPort)
+		}
+	}
+	return members, nil
+}
+
+// Close closes the connection to ZooKeeper
+func (d *Discovery) Close() {
+	d.close()
+}
+
+// sequence returns the sequence number ZooKeeper suffixed the node of the candidate with, e.g.
+// member_0000000003 or _c_6d9a-latch-0000000003
+func sequence(node string) int {
+	i := len(node)
+	for i > 0 && node[i-1] >= '0' && node[i-1] <= '9' {
+		i--
+	}
+	value, err := strconv.Atoi(node[i:])
+	if err != nil {
+		return int(^uint(0) >> 1)
+	}
+	return value
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2017 The go-marathon Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zookeeper
+
+import (
+	"path"
+	"testing"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn holds the data of the ZooKeeper nodes
+type fakeConn struct {
+	nodes map[string]string
+}
+
+func (c *fakeConn) Children(parent string) ([]string, *zk.Stat, error) {
+	var children []string
+	found := false
+	for node := range c.nodes {
+		if node == parent {
+			found = true
+		} else if path.Dir(node) == parent {
+			found = true
+			children = append(children, path.Base(node))
+		}
+	}
+	if !found {
+		return nil, nil, zk.ErrNoNode
+	}
+	return children, nil, nil
+}
+
+func (c *fakeConn) Get(node string) ([]byte, *zk.Stat, error) {
+	data, found := c.nodes[node]
+	if !found {
+		return nil, nil, zk.ErrNoNode
+	}
+	return []byte(data), nil, nil
+}
+
+func TestParseConnection(t *testing.T) {
+	servers, marathonPath, err := ParseConnection("zk://zk-1:2181,zk-2:2181/marathon/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"zk-1:2181", "zk-2:2181"}, servers)
+	assert.Equal(t, "/marathon", marathonPath)
+
+	for _, connection := range []string{"http://zk-1:2181/marathon", "zk:///marathon", "zk://zk-1:2181", "zk://zk-1:2181/", "%zk"} {
+		_, _, err := ParseConnection(connection)
+		assert.Error(t, err, connection)
+	}
+}
+
+func TestMembers(t *testing.T) {
+	conn := &fakeConn{nodes: map[string]string{
+		"/marathon/leader-curator":                          "",
+		"/marathon/leader-curator/_c_2f6c-latch-0000000012": "master-2:8080",
+		"/marathon/leader-curator/_c_8d1e-latch-0000000007": "master-1:8080",
+		"/marathon/leader-curator/_c_0a4b-latch-0000000015": "master-3:8080",
+		"/marathon/leader-curator/_c_77aa-latch-0000000016": "master-3:8080",
+		"/marathon/leader-curator/_c_0000-latch-0000000020": " ",
+		"/marathon/state/framework:id":                      "",
+		"/other/leader/member_0000000001":                   "10.0.0.1:8080",
+		"/other/leader/member_0000000000":                   "10.0.0.2:8080",
+		"/custom/election/member_0000000000":                "10.0.0.3:8443",
+	}}
+
+	discovery := newDiscovery(conn, "/marathon", &Opts{})
+	members, err := discovery.Members()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://master-1:8080", "http://master-2:8080", "http://master-3:8080"}, members)
+
+	// step: the election of the versions before 1.4
+	members, err = newDiscovery(conn, "/other", &Opts{}).Members()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://10.0.0.2:8080", "http://10.0.0.1:8080"}, members)
+
+	members, err = newDiscovery(conn, "/custom", &Opts{LeaderPath: "election", Scheme: "https"}).Members()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://10.0.0.3:8443"}, members)
+
+	_, err = newDiscovery(conn, "/missing", &Opts{}).Members()
+	assert.Error(t, err)
+
+	// step: the discovery is a member discovery of the client
+	var _ marathon.MemberDiscovery = discovery
+}
+
+func TestSequence(t *testing.T) {
+	assert.Equal(t, 3, sequence("member_0000000003"))
+	assert.Equal(t, 12, sequence("_c_6d9a-latch-0000000012"))
+	assert.True(t, sequence("unsequenced") > sequence("member_0000000003"))
+}